@@ -1,6 +1,10 @@
 package configs
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -12,31 +16,107 @@ const (
 
 	DatabaseTimeout    = time.Second * 5
 	ProductCachingTime = time.Minute * 1
+
+	DefaultReminderOffsets      = "24h,1h"
+	DefaultAnnouncementCooldown = time.Hour
+
+	MaxAttachmentSize        = 10 << 20
+	MaxAttachmentsPerMessage = 5
+	ThumbnailWidth           = 320
+
+	TicketQrSize     = 512
+	MaxScanClockSkew = 5 * time.Minute
+	ManifestVersion  = 1
+	// MaxOfflineScanAge is how long a door device may stay offline before its scans are refused
+	MaxOfflineScanAge = 72 * time.Hour
+	// CheckInOpensBefore is how long before its start an occurrence of a recurring event admits its tickets
+	CheckInOpensBefore = 2 * time.Hour
+
+	PdfCoverWidth      = 1200
+	PdfCoverMaxSize    = 10 << 20
+	PdfDownloadTimeout = 10 * time.Second
+	// StorageHost serves the uploaded files, the only host files are downloaded from
+	StorageHost = "res.cloudinary.com"
+
+	AdminRoleName = "Admin"
+
+	BankTransferHoldTime   = 24 * time.Hour
+	MaxBankStatementSize   = 5 << 20
+	BankTransferExpiryTick = 5 * time.Minute
+
+	// CheckoutHoldTime is how long a checkout session holds its seats, the provider wants at least 30 minutes
+	CheckoutHoldTime   = time.Hour
+	CheckoutExpiryTick = 5 * time.Minute
+
+	PayoutScheduleTick = time.Hour
+
+	QuoteValidity = 15 * time.Minute
+
+	EventLifecycleTick      = time.Minute
+	CancellationRefundBatch = 50
+
+	// Refunds still pending after PendingRefundGrace were cut off between the provider and the database, they are
+	// retried for as long as the provider keeps their idempotency key
+	PendingRefundGrace       = 10 * time.Minute
+	PendingRefundRetryWindow = 24 * time.Hour
+	PendingRefundBatch       = 50
+
+	// RescheduleResponseWindow is how long attendees have to ask a refund for a rescheduled event, the new
+	// start time closes it earlier
+	RescheduleResponseWindow = 7 * 24 * time.Hour
+
+	MaxGalleryImageSize      = 10 << 20
+	MaxGalleryImages         = 20
+	GalleryUploadConcurrency = 4
 )
 
+// MinPayoutAmounts are the smallest balances paid out per currency, in minor units. Balances in a currency
+// without an entry are never paid out automatically.
+var MinPayoutAmounts = map[string]int64{
+	"VND": 100000,
+	"USD": 2000,
+	"EUR": 2000,
+}
+
 var AuthIgnoreMethods = []string{
 	"/user.UserService/Login",
 	"/user.UserService/Register",
 }
 
 type Config struct {
-	Environment            string `mapstructure:"ENVIRONMENT"`
-	HttpPort               int    `mapstructure:"HTTP_PORT"`
-	GrpcPort               int    `mapstructure:"GRPC_PORT"`
-	SocketPort             int    `mapstructure:"SOCKET_PORT"`
-	AuthSecret             string `mapstructure:"AUTH_SECRET"`
-	DatabaseURI            string `mapstructure:"DATABASE_URI"`
-	RedisURI               string `mapstructure:"REDIS_URI"`
-	RedisPassword          string `mapstructure:"REDIS_PASSWORD"`
-	RedisDB                int    `mapstructure:"REDIS_DB"`
-	GoogleClientID         string `mapstructure:"GOOGLE_CLIENT_ID"`
-	GoogleClientSecret     string `mapstructure:"GOOGLE_CLIENT_SECRET"`
-	CloudinaryCloudName    string `mapstructure:"CLOUDINARY_CLOUD_NAME"`
-	CloudinaryApiKey       string `mapstructure:"CLOUDINARY_API_KEY"`
-	CloudinaryApiSecret    string `mapstructure:"CLOUDINARY_API_SECRET"`
-	CloudinaryUploadFolder string `mapstructure:"CLOUDINARY_UPLOAD_FOLDER"`
-	UrlCloudinary          string `mapstructure:"URL_CLOUDINARY"`
-	StripeSecretKey        string `mapstructure:"STRIPE_SECRET_KEY"`
+	Environment            string  `mapstructure:"ENVIRONMENT"`
+	HttpPort               int     `mapstructure:"HTTP_PORT"`
+	GrpcPort               int     `mapstructure:"GRPC_PORT"`
+	SocketPort             int     `mapstructure:"SOCKET_PORT"`
+	AuthSecret             string  `mapstructure:"AUTH_SECRET"`
+	DatabaseURI            string  `mapstructure:"DATABASE_URI"`
+	RedisURI               string  `mapstructure:"REDIS_URI"`
+	RedisPassword          string  `mapstructure:"REDIS_PASSWORD"`
+	RedisDB                int     `mapstructure:"REDIS_DB"`
+	GoogleClientID         string  `mapstructure:"GOOGLE_CLIENT_ID"`
+	GoogleClientSecret     string  `mapstructure:"GOOGLE_CLIENT_SECRET"`
+	CloudinaryCloudName    string  `mapstructure:"CLOUDINARY_CLOUD_NAME"`
+	CloudinaryApiKey       string  `mapstructure:"CLOUDINARY_API_KEY"`
+	CloudinaryApiSecret    string  `mapstructure:"CLOUDINARY_API_SECRET"`
+	CloudinaryUploadFolder string  `mapstructure:"CLOUDINARY_UPLOAD_FOLDER"`
+	UrlCloudinary          string  `mapstructure:"URL_CLOUDINARY"`
+	StripeSecretKey        string  `mapstructure:"STRIPE_SECRET_KEY"`
+	StripeWebhookSecret    string  `mapstructure:"STRIPE_WEBHOOK_SECRET"`
+	PaymentProvider        string  `mapstructure:"PAYMENT_PROVIDER"`
+	PaymentWebhookUrl      string  `mapstructure:"PAYMENT_WEBHOOK_URL"`
+	PaymentFakeOutcome     string  `mapstructure:"PAYMENT_FAKE_OUTCOME"`
+	PlatformFeePercent     float64 `mapstructure:"PLATFORM_FEE_PERCENT"`
+	SmtpHost               string  `mapstructure:"SMTP_HOST"`
+	SmtpPort               int     `mapstructure:"SMTP_PORT"`
+	SmtpUsername           string  `mapstructure:"SMTP_USERNAME"`
+	SmtpPassword           string  `mapstructure:"SMTP_PASSWORD"`
+	SmtpSender             string  `mapstructure:"SMTP_SENDER"`
+	ReminderOffsets        string  `mapstructure:"REMINDER_OFFSETS"`
+	AnnouncementCooldown   string  `mapstructure:"ANNOUNCEMENT_COOLDOWN"`
+	TicketSecret           string  `mapstructure:"TICKET_SECRET"`
+	PdfFontPath            string  `mapstructure:"PDF_FONT_PATH"`
+	QuoteSecret            string  `mapstructure:"QUOTE_SECRET"`
+	ScanSigningKey         string  `mapstructure:"SCAN_SIGNING_KEY"`
 }
 
 var (
@@ -66,3 +146,48 @@ func LoadConfig(path string) *Config {
 func GetConfig() *Config {
 	return &cfg
 }
+
+// CheckSecrets reports the signing secrets that are not set. They sign what attendees carry around, a
+// fallback on another secret would let a leak of one forge the other.
+func (c *Config) CheckSecrets() error {
+	secrets := []struct {
+		name  string
+		value string
+	}{
+		{name: "TICKET_SECRET", value: c.TicketSecret},
+		{name: "QUOTE_SECRET", value: c.QuoteSecret},
+		{name: "SCAN_SIGNING_KEY", value: c.ScanSigningKey},
+	}
+
+	var missing []string
+	for _, secret := range secrets {
+		if secret.value == "" {
+			missing = append(missing, secret.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required secrets: %s", strings.Join(missing, ", "))
+	}
+
+	if _, err := c.ScanKey(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ScanKey is the Ed25519 key offline manifests are signed with, SCAN_SIGNING_KEY holds its 32 byte seed in
+// base64. Door devices pin the public key, it is never derived from another secret.
+func (c *Config) ScanKey() (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(c.ScanSigningKey)
+	if err != nil {
+		seed, err = base64.RawURLEncoding.DecodeString(c.ScanSigningKey)
+	}
+
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("SCAN_SIGNING_KEY must be a base64 encoded %d byte seed", ed25519.SeedSize)
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
@@ -12,7 +12,9 @@ import (
 	eventModel "gohub/domains/events/model"
 	expenseModel "gohub/domains/expense/model"
 	functionModel "gohub/domains/functions/model"
+	notificationModel "gohub/domains/notifications/model"
 	paymentModel "gohub/domains/payments/model"
+	payoutModel "gohub/domains/payouts/model"
 	permissionModel "gohub/domains/permissions/model"
 	reviewModel "gohub/domains/reviews/model"
 	roleModel "gohub/domains/roles/model"
@@ -21,6 +23,10 @@ import (
 )
 
 func AutoMigrate(db *database.Database) error {
+	if err := PreMigrate(db); err != nil {
+		return err
+	}
+
 	err := db.AutoMigrate(
 		&permissionModel.Permission{},
 		&commandModel.Command{},
@@ -32,31 +38,55 @@ func AutoMigrate(db *database.Database) error {
 		&conversationModel.Conversation{},
 		&conversationModel.Message{},
 		&conversationModel.MessageAttachment{},
+		&conversationModel.ConversationParticipant{},
 		&eventModel.Event{},
 		&eventModel.EventSubImage{},
 		&eventModel.Reason{},
 		&eventModel.TicketType{},
+		&eventModel.EventOccurrence{},
+		&eventModel.OccurrenceInventory{},
+		&eventModel.EventModeration{},
+		&eventModel.EventReschedule{},
 		&expenseModel.Expense{},
 		&expenseModel.SubExpense{},
 		&reviewModel.Review{},
 		&couponModel.Coupon{},
+		&couponModel.CouponTicketType{},
+		&couponModel.CouponCode{},
+		&couponModel.CouponRedemption{},
 		&ticketModel.Ticket{},
+		&ticketModel.TicketScan{},
+		&ticketModel.TicketTransfer{},
 		&paymentModel.Payment{},
 		&paymentModel.PaymentLine{},
 		&paymentModel.PaymentMethod{},
+		&paymentModel.Refund{},
+		&paymentModel.RescheduleResponse{},
+		&paymentModel.RefundPolicy{},
+		&paymentModel.PricingPolicy{},
+		&payoutModel.LedgerEntry{},
+		&payoutModel.Payout{},
 		&commandModel.CommandInFunction{},
 		&eventModel.EventCategory{},
 		&eventModel.EventCoupons{},
 		&eventModel.EventFavourite{},
 		&eventModel.Invitation{},
+		&eventModel.EventStaff{},
 		&userModel.UserPayment{},
 		&userModel.UserRole{},
+		&notificationModel.Notification{},
+		&notificationModel.ReminderDelivery{},
+		&notificationModel.Announcement{},
 	)
 
 	if err != nil {
 		return err
 	}
 
+	if err := RawMigrate(db); err != nil {
+		return err
+	}
+
 	logger.Info("Migration database successfully")
 	return nil
 }
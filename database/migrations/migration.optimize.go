@@ -12,7 +12,9 @@ import (
 	couponModel "gohub/domains/coupons/model"
 	eventModel "gohub/domains/events/model"
 	functionModel "gohub/domains/functions/model"
+	notificationModel "gohub/domains/notifications/model"
 	paymentModel "gohub/domains/payments/model"
+	payoutModel "gohub/domains/payouts/model"
 	permissionModel "gohub/domains/permissions/model"
 	reviewModel "gohub/domains/reviews/model"
 	roleModel "gohub/domains/roles/model"
@@ -21,6 +23,10 @@ import (
 )
 
 func AutoMigrateOptimize(db *database.Database) error {
+	if err := PreMigrate(db); err != nil {
+		return err
+	}
+
 	tables := []interface{}{
 		&permissionModel.Permission{},
 		&commandModel.Command{},
@@ -32,23 +38,43 @@ func AutoMigrateOptimize(db *database.Database) error {
 		&conversationModel.Conversation{},
 		&conversationModel.Message{},
 		&conversationModel.MessageAttachment{},
+		&conversationModel.ConversationParticipant{},
 		&eventModel.Event{},
 		&eventModel.EventSubImage{},
 		&eventModel.Reason{},
 		&eventModel.TicketType{},
+		&eventModel.EventOccurrence{},
+		&eventModel.OccurrenceInventory{},
+		&eventModel.EventModeration{},
+		&eventModel.EventReschedule{},
 		&reviewModel.Review{},
 		&couponModel.Coupon{},
+		&couponModel.CouponTicketType{},
+		&couponModel.CouponCode{},
+		&couponModel.CouponRedemption{},
 		&ticketModel.Ticket{},
+		&ticketModel.TicketScan{},
+		&ticketModel.TicketTransfer{},
 		&paymentModel.Payment{},
 		&paymentModel.PaymentLine{},
 		&paymentModel.PaymentMethod{},
+		&paymentModel.Refund{},
+		&paymentModel.RescheduleResponse{},
+		&paymentModel.RefundPolicy{},
+		&paymentModel.PricingPolicy{},
+		&payoutModel.LedgerEntry{},
+		&payoutModel.Payout{},
 		&commandModel.CommandInFunction{},
 		&eventModel.EventCategory{},
 		&eventModel.EventCoupons{},
 		&eventModel.EventFavourite{},
 		&eventModel.Invitation{},
+		&eventModel.EventStaff{},
 		&userModel.UserPayment{},
 		&userModel.UserRole{},
+		&notificationModel.Notification{},
+		&notificationModel.ReminderDelivery{},
+		&notificationModel.Announcement{},
 	}
 
 	for _, table := range tables {
@@ -64,5 +90,5 @@ func AutoMigrateOptimize(db *database.Database) error {
 		}
 	}
 
-	return nil
+	return RawMigrate(db)
 }
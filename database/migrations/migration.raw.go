@@ -0,0 +1,217 @@
+package migrations
+
+import (
+	"fmt"
+	"gohub/database"
+
+	"gohub/internal/libs/logger"
+)
+
+// rawMigrations holds the schema changes AutoMigrate cannot express. Every statement must be idempotent.
+var rawMigrations = []string{
+	// Full-text search over messages, the simple configuration does not stem so it works for Vietnamese too
+	`ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('simple', coalesce(content, ''))) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector)`,
+	// Client message ids are unique within a conversation, the index over the sender alone is replaced
+	`DROP INDEX IF EXISTS idx_messages_sender_client_message_id`,
+	// Events sell in one currency, everything sold before currencies existed was in dong
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'VND'`,
+	// An organizer has at most one payout in flight per currency, concurrent schedulers cannot pay the same balance twice
+	`DROP INDEX IF EXISTS idx_payouts_pending_user`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_payouts_pending_user_currency ON payouts (user_id, amount_currency) WHERE status = 'Pending'`,
+	// Coupons are typed in by code now, the ones created before get a code made from their id
+	`ALTER TABLE coupons ADD COLUMN IF NOT EXISTS code varchar(32) NOT NULL DEFAULT ''`,
+	`UPDATE coupons SET code = UPPER(SUBSTRING(REPLACE(id, '-', '') FROM 1 FOR 8)) WHERE code = ''`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_coupons_user_code ON coupons (user_id, code) WHERE deleted_at IS NULL`,
+	// Checkouts carry their own discount now, coupons are no longer mirrored at the provider
+	`ALTER TABLE coupons DROP COLUMN IF EXISTS coupon_id`,
+	// The expiry date string becomes the end of the validity window, a coupon stays valid for its whole last day
+	`ALTER TABLE coupons ADD COLUMN IF NOT EXISTS ends_at timestamptz`,
+	`DO $$
+		BEGIN
+			IF EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = 'coupons' AND column_name = 'expire_date'
+			) THEN
+				UPDATE coupons SET ends_at = (SUBSTRING(expire_date FROM 1 FOR 10)::date + 1)::timestamptz
+				WHERE ends_at IS NULL AND expire_date ~ '^\d{4}-\d{2}-\d{2}';
+				ALTER TABLE coupons DROP COLUMN expire_date;
+			END IF;
+		END
+	$$`,
+	// Events repeat with a recurrence rule now, every event is expanded into its occurrences. Events created
+	// before are single events whose one occurrence gets the seats they sold.
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS recurrence_rule text NOT NULL DEFAULT ''`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS excluded_dates text NOT NULL DEFAULT ''`,
+	`ALTER TABLE payments ADD COLUMN IF NOT EXISTS occurrence_id text`,
+	`ALTER TABLE tickets ADD COLUMN IF NOT EXISTS occurrence_id text`,
+	`INSERT INTO event_occurrences (id, event_id, slot_time, start_time, end_time, status, is_modified, created_at, updated_at)
+		SELECT gen_random_uuid()::text, events.id, events.start_time, events.start_time, events.end_time,
+			'Scheduled', false, NOW(), NOW()
+		FROM events
+		WHERE events.recurrence_rule = ''
+			AND NOT EXISTS (SELECT 1 FROM event_occurrences WHERE event_occurrences.event_id = events.id)`,
+	`INSERT INTO occurrence_ticket_inventories (occurrence_id, ticket_type_id, quantity, sale)
+		SELECT event_occurrences.id, ticket_types.id, ticket_types.quantity, ticket_types.sale
+		FROM event_occurrences
+		INNER JOIN events ON events.id = event_occurrences.event_id AND events.recurrence_rule = ''
+		INNER JOIN ticket_types ON ticket_types.event_id = events.id AND ticket_types.deleted_at IS NULL
+		ON CONFLICT DO NOTHING`,
+	// The occurrence of a single event has its times, they were first copied while event times were read as UTC
+	`UPDATE event_occurrences SET start_time = events.start_time, end_time = events.end_time
+		FROM events
+		WHERE events.id = event_occurrences.event_id AND events.recurrence_rule = ''
+			AND (event_occurrences.start_time <> events.start_time OR event_occurrences.end_time <> events.end_time)`,
+	`UPDATE payments SET occurrence_id = event_occurrences.id
+		FROM event_occurrences
+		INNER JOIN events ON events.id = event_occurrences.event_id AND events.recurrence_rule = ''
+		WHERE payments.event_id = event_occurrences.event_id AND payments.occurrence_id IS NULL`,
+	`UPDATE tickets SET occurrence_id = payments.occurrence_id
+		FROM payments
+		WHERE payments.id = tickets.payment_id AND tickets.occurrence_id IS NULL AND payments.occurrence_id IS NOT NULL`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS publish_at timestamptz`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS published_at timestamptz`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS approved_at timestamptz`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS cancelled_at timestamptz`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS cancellation_reason text`,
+	`CREATE INDEX IF NOT EXISTS idx_events_state ON events (state)`,
+	`UPDATE events SET published_at = created_at WHERE state = 'Published' AND published_at IS NULL`,
+	`ALTER TABLE sub_images ADD COLUMN IF NOT EXISTS image_public_id text NOT NULL DEFAULT ''`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS cover_image_public_id text NOT NULL DEFAULT ''`,
+	`ALTER TABLE sub_images ADD COLUMN IF NOT EXISTS sort_index bigint NOT NULL DEFAULT 0`,
+	// Refunds of orders paid without the provider wait for the organizer to pay them back by hand
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS settled_by_id text`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS settled_at timestamptz`,
+	// Occurrences of a recurring event are rescheduled one by one
+	`ALTER TABLE event_reschedules ADD COLUMN IF NOT EXISTS occurrence_id text`,
+	// Seats set on an occurrence by hand no longer follow the quantity of their ticket type
+	`ALTER TABLE occurrence_ticket_inventories ADD COLUMN IF NOT EXISTS is_overridden boolean NOT NULL DEFAULT false`,
+	// Occurrences cancelled by hand are refunded and notified on their own
+	`ALTER TABLE event_occurrences ADD COLUMN IF NOT EXISTS cancelled_at timestamptz`,
+	`ALTER TABLE event_occurrences ADD COLUMN IF NOT EXISTS cancellation_reason text`,
+	`ALTER TABLE notifications ADD COLUMN IF NOT EXISTS occurrence_id text`,
+	// Reminders are sent for every occurrence, the ones sent before go to the occurrence they were sent for
+	`ALTER TABLE reminder_deliveries ADD COLUMN IF NOT EXISTS occurrence_id text`,
+	`DROP INDEX IF EXISTS idx_reminder_deliveries_event_user_offset`,
+	`UPDATE reminder_deliveries SET occurrence_id = (
+			SELECT event_occurrences.id FROM event_occurrences
+			WHERE event_occurrences.event_id = reminder_deliveries.event_id AND event_occurrences.start_time > reminder_deliveries.created_at
+			ORDER BY event_occurrences.start_time LIMIT 1
+		)
+		WHERE occurrence_id IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_sub_images_event_id ON sub_images (event_id)`,
+	// Galleries from before the sort index keep the order they were uploaded in
+	`UPDATE sub_images SET sort_index = ordered.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY event_id ORDER BY created_at, id) - 1 AS position
+			FROM sub_images
+			WHERE event_id IN (SELECT event_id FROM sub_images GROUP BY event_id HAVING COUNT(*) > 1 AND MAX(sort_index) = 0)
+		) ordered
+		WHERE sub_images.id = ordered.id`,
+}
+
+// preMigrations run before AutoMigrate, for the changes of a column type it cannot cast by itself. Every
+// statement must be idempotent and cope with tables that do not exist yet.
+var preMigrations = []string{
+	// Event times were free text, they become instants read in the timezone of the event, the one the
+	// organizers entered them in. Times that cannot be read fall back to the creation of the event, an end
+	// before the start to the start.
+	`CREATE OR REPLACE FUNCTION parse_event_time(value text, zone text) RETURNS timestamptz AS $$
+		BEGIN
+			IF value ~ '(Z|[+-]\d{2}(:?\d{2})?)$' THEN
+				RETURN value::timestamptz;
+			END IF;
+			RETURN value::timestamp AT TIME ZONE zone;
+		EXCEPTION WHEN OTHERS THEN
+			RETURN NULL;
+		END
+	$$ LANGUAGE plpgsql`,
+	`DO $$
+		BEGIN
+			IF EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = 'events' AND column_name = 'start_time'
+					AND data_type IN ('text', 'character varying')
+			) THEN
+				ALTER TABLE events ADD COLUMN IF NOT EXISTS timezone varchar(64) NOT NULL DEFAULT 'Asia/Ho_Chi_Minh';
+				ALTER TABLE events ALTER COLUMN start_time TYPE timestamptz
+					USING COALESCE(parse_event_time(start_time, timezone), created_at, NOW());
+				ALTER TABLE events ALTER COLUMN end_time TYPE timestamptz
+					USING GREATEST(COALESCE(parse_event_time(end_time, timezone), start_time), start_time);
+			END IF;
+		END
+	$$`,
+	`DROP FUNCTION IF EXISTS parse_event_time(text, text)`,
+	// Events go through review before they are published now, the ones created before were live already
+	`ALTER TABLE IF EXISTS events ADD COLUMN IF NOT EXISTS state varchar(16) NOT NULL DEFAULT 'Published'`,
+}
+
+// moneyColumns are the float columns replaced by a money.Money, stored as <column>_amount in minor units
+// and <column>_currency
+var moneyColumns = []struct {
+	table  string
+	column string
+}{
+	{"ticket_types", "price"},
+	{"coupons", "min_price"},
+	{"payments", "total_price"},
+	{"payments", "discount_price"},
+	{"payments", "final_price"},
+	{"payments", "refunded_amount"},
+	{"payment_lines", "price"},
+	{"refunds", "amount"},
+	{"ledger_entries", "debit"},
+	{"ledger_entries", "credit"},
+	{"payouts", "amount"},
+	{"expenses", "total"},
+	{"sub_expenses", "price"},
+}
+
+// moneyMigrations copies the old float amounts into the money columns and drops them. They were all in
+// dong, which has no minor unit, so the amount only needs rounding.
+func moneyMigrations() []string {
+	var statements []string
+	for _, money := range moneyColumns {
+		statements = append(statements,
+			fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s_amount numeric(20,0) NOT NULL DEFAULT 0`, money.table, money.column),
+			fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s_currency varchar(3) NOT NULL DEFAULT 'VND'`, money.table, money.column),
+			fmt.Sprintf(`DO $$
+				BEGIN
+					IF EXISTS (
+						SELECT 1 FROM information_schema.columns
+						WHERE table_schema = current_schema() AND table_name = '%[1]s' AND column_name = '%[2]s'
+					) THEN
+						UPDATE %[1]s SET %[2]s_amount = ROUND(COALESCE(%[2]s, 0)), %[2]s_currency = 'VND';
+						ALTER TABLE %[1]s DROP COLUMN %[2]s;
+					END IF;
+				END
+			$$`, money.table, money.column),
+		)
+	}
+
+	return statements
+}
+
+// PreMigrate prepares the schema for AutoMigrate
+func PreMigrate(db *database.Database) error {
+	for _, statement := range preMigrations {
+		if err := db.GetDB().Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func RawMigrate(db *database.Database) error {
+	// The money columns come first, the payout index below is built on one of them
+	for _, statement := range append(moneyMigrations(), rawMigrations...) {
+		if err := db.GetDB().Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Raw migrations applied successfully")
+	return nil
+}
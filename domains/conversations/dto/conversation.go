@@ -3,34 +3,79 @@ package dto
 import (
 	"gohub/pkg/paging"
 	"gorm.io/gorm"
+	"mime/multipart"
 	"time"
 )
 
 type Conversation struct {
-	ID          string  `json:"id"`
-	User        User    `json:"user"`
-	Organizer   User    `json:"organizer"`
-	LastMessage Message `json:"lastMessage"`
-	CreatedAt   string  `json:"createdAt"`
-	UpdatedAt   string  `json:"updatedAt"`
+	ID                 string         `json:"id"`
+	EventId            string         `json:"eventId"`
+	Type               string         `json:"type"`
+	IsAnnouncementOnly bool           `json:"isAnnouncementOnly"`
+	User               User           `json:"user"`
+	Organizer          User           `json:"organizer"`
+	LastMessage        Message        `json:"lastMessage"`
+	Participants       []*Participant `json:"participants"`
+	UnreadCount        int64          `json:"unreadCount"`
+	IsUnanswered       bool           `json:"isUnanswered"`
+	CreatedAt          string         `json:"createdAt"`
+	UpdatedAt          string         `json:"updatedAt"`
 }
 
 type ConversationByOrganizer struct {
-	ID          string  `json:"id"`
-	User        User    `json:"user"`
-	LastMessage Message `json:"lastMessage"`
-	Event       Event   `json:"event"`
-	CreatedAt   string  `json:"createdAt"`
-	UpdatedAt   string  `json:"updatedAt"`
+	ID           string         `json:"id"`
+	User         User           `json:"user"`
+	LastMessage  Message        `json:"lastMessage"`
+	Event        Event          `json:"event"`
+	Participants []*Participant `json:"participants"`
+	UnreadCount  int64          `json:"unreadCount"`
+	IsUnanswered bool           `json:"isUnanswered"`
+	CreatedAt    string         `json:"createdAt"`
+	UpdatedAt    string         `json:"updatedAt"`
 }
 
 type ConversationByUser struct {
-	ID          string  `json:"id"`
-	Organizer   User    `json:"organizer"`
-	LastMessage Message `json:"lastMessage"`
-	Event       Event   `json:"event"`
-	CreatedAt   string  `json:"createdAt"`
-	UpdatedAt   string  `json:"updatedAt"`
+	ID           string         `json:"id"`
+	Organizer    User           `json:"organizer"`
+	LastMessage  Message        `json:"lastMessage"`
+	Event        Event          `json:"event"`
+	Participants []*Participant `json:"participants"`
+	UnreadCount  int64          `json:"unreadCount"`
+	IsUnanswered bool           `json:"isUnanswered"`
+	CreatedAt    string         `json:"createdAt"`
+	UpdatedAt    string         `json:"updatedAt"`
+}
+
+type GroupConversation struct {
+	ID                 string  `json:"id"`
+	Type               string  `json:"type"`
+	IsAnnouncementOnly bool    `json:"isAnnouncementOnly"`
+	Organizer          User    `json:"organizer"`
+	Event              Event   `json:"event"`
+	LastMessage        Message `json:"lastMessage"`
+	UnreadCount        int64   `json:"unreadCount"`
+	CreatedAt          string  `json:"createdAt"`
+	UpdatedAt          string  `json:"updatedAt"`
+}
+
+type GroupParticipant struct {
+	UserId     string `json:"userId"`
+	User       User   `json:"user"`
+	Role       string `json:"role"`
+	IsSilenced bool   `json:"isSilenced"`
+	IsBanned   bool   `json:"isBanned"`
+}
+
+type Participant struct {
+	UserId            string     `json:"userId"`
+	Role              string     `json:"role"`
+	IsSilenced        bool       `json:"isSilenced"`
+	IsBanned          bool       `json:"isBanned"`
+	IsArchived        bool       `json:"isArchived"`
+	IsMuted           bool       `json:"isMuted"`
+	LastReadMessageId string     `json:"lastReadMessageId"`
+	LastReadAt        *time.Time `json:"lastReadAt"`
+	LastDeliveredAt   *time.Time `json:"lastDeliveredAt"`
 }
 
 type User struct {
@@ -42,13 +87,27 @@ type User struct {
 }
 
 type Message struct {
-	Id         string         `json:"id"`
-	SenderId   string         `json:"senderId"`
-	ReceiverId string         `json:"receiverId"`
-	Content    string         `json:"content"`
-	CreatedAt  time.Time      `json:"createdAt"`
-	UpdatedAt  time.Time      `json:"updatedAt"`
-	DeletedAt  gorm.DeletedAt `json:"deletedAt"`
+	Id                 string               `json:"id"`
+	ConversationId     string               `json:"conversationId"`
+	SenderId           string               `json:"senderId"`
+	ReceiverId         string               `json:"receiverId"`
+	Content            string               `json:"content"`
+	ClientMessageId    string               `json:"clientMessageId"`
+	Status             string               `json:"status,omitempty"`
+	MessageAttachments []*MessageAttachment `json:"messageAttachments"`
+	CreatedAt          time.Time            `json:"createdAt"`
+	UpdatedAt          time.Time            `json:"updatedAt"`
+	DeletedAt          gorm.DeletedAt       `json:"deletedAt"`
+}
+
+type MessageAttachment struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	MessageUrl      string `json:"messageUrl"`
+	MessageFileName string `json:"messageFileName"`
+	ThumbnailUrl    string `json:"thumbnailUrl"`
+	ContentType     string `json:"contentType"`
+	Size            int64  `json:"size"`
 }
 
 type Event struct {
@@ -58,12 +117,14 @@ type Event struct {
 }
 
 type ListConversationReq struct {
-	Search    string `json:"-" form:"search"`
-	Page      int64  `json:"-" form:"page"`
-	Limit     int64  `json:"-" form:"pageSize"`
-	OrderBy   string `json:"-" form:"order_by"`
-	OrderDesc bool   `json:"-" form:"order_desc"`
-	TakeAll   bool   `json:"-" form:"take_all"`
+	Search       string `json:"-" form:"search"`
+	IsArchived   bool   `json:"-" form:"archived"`
+	IsUnanswered bool   `json:"-" form:"unanswered"`
+	Page         int64  `json:"-" form:"page"`
+	Limit        int64  `json:"-" form:"pageSize"`
+	OrderBy      string `json:"-" form:"order_by"`
+	OrderDesc    bool   `json:"-" form:"order_desc"`
+	TakeAll      bool   `json:"-" form:"take_all"`
 }
 
 type ListConversationByOrganizerRes struct {
@@ -76,6 +137,22 @@ type ListConversationByUserRes struct {
 	Pagination   *paging.Pagination    `json:"metadata"`
 }
 
+type ListGroupConversationRes struct {
+	Conversations []*GroupConversation `json:"items"`
+	Pagination    *paging.Pagination   `json:"metadata"`
+}
+
+type ListParticipantReq struct {
+	Page    int64 `json:"-" form:"page"`
+	Limit   int64 `json:"-" form:"pageSize"`
+	TakeAll bool  `json:"-" form:"take_all"`
+}
+
+type ListParticipantRes struct {
+	Participants []*GroupParticipant `json:"items"`
+	Pagination   *paging.Pagination  `json:"metadata"`
+}
+
 type ListMessageReq struct {
 	Page      int64  `json:"-" form:"page"`
 	Limit     int64  `json:"-" form:"pageSize"`
@@ -89,17 +166,99 @@ type ListMessageRes struct {
 	Pagination *paging.Pagination `json:"metadata"`
 }
 
+type SearchMessageReq struct {
+	Query    string    `json:"-" form:"q" validate:"required,min=2"`
+	EventId  string    `json:"-" form:"eventId"`
+	SenderId string    `json:"-" form:"senderId"`
+	From     time.Time `json:"-" form:"from" time_format:"2006-01-02"`
+	To       time.Time `json:"-" form:"to" time_format:"2006-01-02"`
+	Page     int64     `json:"-" form:"page"`
+	Limit    int64     `json:"-" form:"pageSize"`
+	TakeAll  bool      `json:"-" form:"take_all"`
+}
+
+type SearchMessage struct {
+	ID               string    `json:"id"`
+	ConversationId   string    `json:"conversationId"`
+	ConversationType string    `json:"conversationType"`
+	EventId          string    `json:"eventId"`
+	EventName        string    `json:"eventName"`
+	SenderId         string    `json:"senderId"`
+	SenderUserName   string    `json:"senderUserName"`
+	SenderFullName   string    `json:"senderFullName"`
+	SenderAvatarUrl  string    `json:"senderAvatarUrl"`
+	Content          string    `json:"content"`
+	Highlight        string    `json:"highlight"`
+	Rank             float64   `json:"rank"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type SearchMessageRes struct {
+	Messages   []*SearchMessage   `json:"items"`
+	Pagination *paging.Pagination `json:"metadata"`
+}
+
 type CreateMessageReq struct {
-	ConversationId string `form:"conversationId" validate:"required"`
-	SenderId       string `form:"senderId" validate:"required"`
-	ReceiverId     string `form:"receiverId" validate:"required"`
-	Content        string `form:"content" validate:"required"`
+	ConversationId  string                  `form:"conversationId" validate:"required"`
+	SenderId        string                  `form:"-" validate:"required"`
+	ClientMessageId string                  `form:"clientMessageId"`
+	Content         string                  `form:"content" validate:"required_without=Attachments"`
+	Attachments     []*multipart.FileHeader `form:"attachments"`
+}
+
+type SendMessageReq struct {
+	ConversationId  string `json:"conversation_id"`
+	ClientMessageId string `json:"client_message_id"`
+	Message         string `json:"message"`
+}
+
+type ConversationEventReq struct {
+	ConversationId string `json:"conversation_id"`
+}
+
+type TypingReq struct {
+	ConversationId string `json:"conversation_id"`
+	IsTyping       bool   `json:"is_typing"`
+}
+
+type TypingRes struct {
+	ConversationId string `json:"conversation_id"`
+	UserId         string `json:"user_id"`
+	IsTyping       bool   `json:"is_typing"`
+}
+
+type ReadReceiptRes struct {
+	ConversationId    string     `json:"conversation_id"`
+	UserId            string     `json:"user_id"`
+	LastReadMessageId string     `json:"last_read_message_id"`
+	LastReadAt        *time.Time `json:"last_read_at"`
+	LastDeliveredAt   *time.Time `json:"last_delivered_at"`
+}
+
+type SendMessageErrorRes struct {
+	ConversationId  string `json:"conversation_id"`
+	ClientMessageId string `json:"client_message_id"`
+	Error           string `json:"error"`
 }
 
 type UpdateMessageReq struct {
-	ID             string `form:"id" validate:"required"`
-	ConversationId string `form:"conversationId" validate:"required"`
-	SenderId       string `form:"senderId" validate:"required"`
-	ReceiverId     string `form:"receiverId" validate:"required"`
-	Content        string `form:"content" validate:"required"`
+	Content string `form:"content" validate:"required"`
+}
+
+type ArchiveConversationReq struct {
+	IsArchived bool `json:"isArchived"`
+}
+
+type MuteConversationReq struct {
+	IsMuted bool `json:"isMuted"`
+}
+
+type GroupSettingsReq struct {
+	IsAnnouncementOnly bool `json:"isAnnouncementOnly"`
+}
+
+type ModerateParticipantReq struct {
+	Role       *string `json:"role" validate:"omitempty,oneof=Moderator Member"`
+	IsSilenced *bool   `json:"isSilenced"`
+	IsBanned   *bool   `json:"isBanned"`
 }
@@ -9,19 +9,29 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	ConversationTypeDirect = "Direct"
+	ConversationTypeGroup  = "Group"
+)
+
 type Conversation struct {
-	ID            string            `json:"id" gorm:"unique;not null;index;primary_key"`
-	EventId       string            `json:"eventId"`
-	Event         *modelEvent.Event `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	UserId        string            `json:"userId" gorm:"not null"`
-	User          *modelUser.User   `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	OrganizerId   string            `json:"organizerId" gorm:"not null"`
-	Organizer     *modelUser.User   `json:"organizer" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	LastMessageId string            `json:"lastMessageId"`
-	LastMessage   *Message          `json:"lastMessage" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	CreatedAt     time.Time         `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt     time.Time         `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt     gorm.DeletedAt    `json:"deletedAt" gorm:"index"`
+	ID                 string                     `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventId            string                     `json:"eventId" gorm:"uniqueIndex:idx_conversations_event_user"`
+	Event              *modelEvent.Event          `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	UserId             string                     `json:"userId" gorm:"not null;uniqueIndex:idx_conversations_event_user"`
+	User               *modelUser.User            `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OrganizerId        string                     `json:"organizerId" gorm:"not null"`
+	Organizer          *modelUser.User            `json:"organizer" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Type               string                     `json:"type" gorm:"not null;default:'Direct'"`
+	IsAnnouncementOnly bool                       `json:"isAnnouncementOnly" gorm:"default:false"`
+	LastMessageId      *string                    `json:"lastMessageId"`
+	LastMessage        *Message                   `json:"lastMessage" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Participants       []*ConversationParticipant `json:"participants"`
+	UnreadCount        int64                      `json:"unreadCount" gorm:"-"`
+	IsUnanswered       bool                       `json:"isUnanswered" gorm:"-"`
+	CreatedAt          time.Time                  `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time                  `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt          gorm.DeletedAt             `json:"deletedAt" gorm:"index"`
 }
 
 func (c *Conversation) BeforeCreate(tx *gorm.DB) error {
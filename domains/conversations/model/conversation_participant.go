@@ -0,0 +1,47 @@
+package model
+
+import (
+	modelUser "gohub/domains/users/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ParticipantRoleOwner     = "Owner"
+	ParticipantRoleModerator = "Moderator"
+	ParticipantRoleMember    = "Member"
+)
+
+type ConversationParticipant struct {
+	ID                string          `json:"id" gorm:"unique;not null;index;primary_key"`
+	ConversationId    string          `json:"conversationId" gorm:"not null;uniqueIndex:idx_conversation_participants_conversation_user"`
+	Conversation      *Conversation   `json:"conversation" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserId            string          `json:"userId" gorm:"not null;uniqueIndex:idx_conversation_participants_conversation_user"`
+	User              *modelUser.User `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Role              string          `json:"role" gorm:"not null;default:'Member'"`
+	IsSilenced        bool            `json:"isSilenced" gorm:"default:false"`
+	IsBanned          bool            `json:"isBanned" gorm:"default:false"`
+	IsArchived        bool            `json:"isArchived" gorm:"default:false"`
+	IsMuted           bool            `json:"isMuted" gorm:"default:false"`
+	LastReadMessageId *string         `json:"lastReadMessageId"`
+	LastReadAt        *time.Time      `json:"lastReadAt"`
+	LastDeliveredAt   *time.Time      `json:"lastDeliveredAt"`
+	CreatedAt         time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time       `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+func (c *ConversationParticipant) BeforeCreate(tx *gorm.DB) error {
+	c.ID = uuid.New().String()
+	return nil
+}
+
+func (ConversationParticipant) TableName() string {
+	return "conversation_participants"
+}
+
+// CanModerate reports whether the participant may moderate a group conversation
+func (c *ConversationParticipant) CanModerate() bool {
+	return c.Role == ParticipantRoleOwner || c.Role == ParticipantRoleModerator
+}
@@ -8,16 +8,24 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	MessageStatusSent      = "Sent"
+	MessageStatusDelivered = "Delivered"
+	MessageStatusRead      = "Read"
+)
+
 type Message struct {
 	ID                 string               `json:"id" gorm:"unique;not null;index;primary_key"`
-	ConversationId     string               `json:"conversationId" gorm:"not null"`
+	ConversationId     string               `json:"conversationId" gorm:"not null;uniqueIndex:idx_messages_conversation_sender_client_message_id"`
 	Conversation       *Conversation        `json:"conversation" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	SenderId           string               `json:"senderId" gorm:"not null"`
+	SenderId           string               `json:"senderId" gorm:"not null;uniqueIndex:idx_messages_conversation_sender_client_message_id"`
 	Sender             *modelUser.User      `json:"sender" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	ReceiverId         string               `json:"receiverId" gorm:"not null"`
+	ReceiverId         *string              `json:"receiverId"`
 	Receiver           *modelUser.User      `json:"receiver" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Content            string               `json:"content"`
+	ClientMessageId    *string              `json:"clientMessageId" gorm:"uniqueIndex:idx_messages_conversation_sender_client_message_id"`
 	MessageAttachments []*MessageAttachment `json:"messageAttachments"`
+	Status             string               `json:"status" gorm:"-"`
 	CreatedAt          time.Time            `json:"createdAt" gorm:"autoCreateTime"`
 	UpdatedAt          time.Time            `json:"updatedAt" gorm:"autoUpdateTime"`
 	DeletedAt          gorm.DeletedAt       `json:"deletedAt" gorm:"index"`
@@ -6,6 +6,11 @@ import (
 	"time"
 )
 
+const (
+	MessageAttachmentTypeImage = "Image"
+	MessageAttachmentTypeFile  = "File"
+)
+
 type MessageAttachment struct {
 	ID              string         `json:"id" gorm:"unique;not null;index;primary_key"`
 	MessageID       string         `json:"messageId" gorm:"not null"`
@@ -13,6 +18,9 @@ type MessageAttachment struct {
 	MessageType     string         `json:"type" gorm:"not null"`
 	MessageUrl      string         `json:"messageUrl" gorm:"not null"`
 	MessageFileName string         `json:"messageFileName" gorm:"not null"`
+	ThumbnailUrl    string         `json:"thumbnailUrl"`
+	ContentType     string         `json:"contentType"`
+	Size            int64          `json:"size"`
 	CreatedAt       time.Time      `json:"createdAt" gorm:"autoCreateTime"`
 	UpdatedAt       time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
 	DeletedAt       gorm.DeletedAt `json:"deletedAt" gorm:"index"`
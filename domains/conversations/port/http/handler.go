@@ -4,6 +4,7 @@ import (
 	"gohub/domains/conversations/dto"
 	"gohub/domains/conversations/service"
 	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
 	"gohub/pkg/response"
 	"gohub/pkg/utils"
 	"net/http"
@@ -21,6 +22,148 @@ func NewConversationHandler(service service.IConversationService) *ConversationH
 	}
 }
 
+//		@Summary	 Contact the organizer of an event
+//	 @Description Returns the conversation between the authenticated user and the organizer of the event, creating it on first contact.
+//		@Tags		 Conversations
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the conversation"
+//		@Failure	 400	{object}	response.Response	"BadRequest - The organizer cannot contact themselves"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/events/{eventId} [post]
+func (h *ConversationHandler) ContactOrganizer(c *gin.Context) {
+	conversation, err := h.service.ContactOrganizer(c, c.GetString("userId"), c.Param("eventId"))
+	if err != nil {
+		logger.Error("Failed to contact organizer: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.CannotContactYourself:
+			response.Error(c, http.StatusBadRequest, err, messages.CannotContactYourself)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Conversation
+	utils.MapStruct(&res, &conversation)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Archive or unarchive a conversation
+//	 @Description Archives the conversation for the authenticated participant only, archived conversations are listed with archived=true.
+//		@Tags		 Conversations
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Conversation ID"
+//		@Param		 params	body	dto.ArchiveConversationReq	true	"Archive state"
+//		@Success	 200	{object}	response.Response	"Conversation updated successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not a participant of the conversation"
+//		@Failure	 404	{object}	response.Response	"Not Found - Conversation with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/{id}/archive [patch]
+func (h *ConversationHandler) ArchiveConversation(c *gin.Context) {
+	var req dto.ArchiveConversationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	participant, err := h.service.ArchiveConversation(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to archive conversation: ", err)
+		switch err.Error() {
+		case messages.ConversationNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.ConversationNotFound)
+		case messages.NotConversationMember:
+			response.Error(c, http.StatusForbidden, err, messages.NotConversationMember)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Participant
+	utils.MapStruct(&res, &participant)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Mute or unmute a conversation
+//	 @Description Stops real-time message notifications of the conversation for the authenticated participant only.
+//		@Tags		 Conversations
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Conversation ID"
+//		@Param		 params	body	dto.MuteConversationReq	true	"Mute state"
+//		@Success	 200	{object}	response.Response	"Conversation updated successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not a participant of the conversation"
+//		@Failure	 404	{object}	response.Response	"Not Found - Conversation with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/{id}/mute [patch]
+func (h *ConversationHandler) MuteConversation(c *gin.Context) {
+	var req dto.MuteConversationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	participant, err := h.service.MuteConversation(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to mute conversation: ", err)
+		switch err.Error() {
+		case messages.ConversationNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.ConversationNotFound)
+		case messages.NotConversationMember:
+			response.Error(c, http.StatusForbidden, err, messages.NotConversationMember)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Participant
+	utils.MapStruct(&res, &participant)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Mark a conversation as read
+//	 @Description Moves the read cursor of the authenticated participant to the last message and notifies the conversation.
+//		@Tags		 Conversations
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Conversation ID"
+//		@Success	 200	{object}	response.Response	"Conversation marked as read"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not a participant of the conversation"
+//		@Failure	 404	{object}	response.Response	"Not Found - Conversation with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/{id}/read [patch]
+func (h *ConversationHandler) MarkAsRead(c *gin.Context) {
+	participant, err := h.service.MarkAsRead(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to mark conversation as read: ", err)
+		switch err.Error() {
+		case messages.ConversationNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.ConversationNotFound)
+		case messages.NotConversationMember:
+			response.Error(c, http.StatusForbidden, err, messages.NotConversationMember)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Participant
+	utils.MapStruct(&res, &participant)
+	response.JSON(c, http.StatusOK, res)
+}
+
 //		@Summary	 Retrieves a list of conversations by the event
 //	 @Description Fetches a paginated list of conversations created by the event, based on the provided pagination filter.
 //		@Tags		 Conversations
@@ -40,10 +183,15 @@ func (h *ConversationHandler) GetConversationsByOrganizer(c *gin.Context) {
 	}
 
 	organizerId := c.Param("organizerId")
-	conversations, pagination, err := h.service.GetConversationsOrganizer(c, organizerId, req)
+	conversations, pagination, err := h.service.GetConversationsOrganizer(c, c.GetString("userId"), organizerId, req)
 	if err != nil {
 		logger.Error("Failed to get conversations: ", err)
-		response.Error(c, http.StatusNotFound, err, "Not found")
+		switch err.Error() {
+		case messages.NotConversationOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotConversationOwner)
+		default:
+			response.Error(c, http.StatusNotFound, err, "Not found")
+		}
 		return
 	}
 
@@ -72,10 +220,15 @@ func (h *ConversationHandler) GetConversationsByUser(c *gin.Context) {
 	}
 
 	userId := c.Param("userId")
-	conversations, pagination, err := h.service.GetConversationsByUser(c, userId, req)
+	conversations, pagination, err := h.service.GetConversationsByUser(c, c.GetString("userId"), userId, req)
 	if err != nil {
 		logger.Error("Failed to get conversations: ", err)
-		response.Error(c, http.StatusNotFound, err, "Not found")
+		switch err.Error() {
+		case messages.NotConversationOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotConversationOwner)
+		default:
+			response.Error(c, http.StatusNotFound, err, "Not found")
+		}
 		return
 	}
 
@@ -104,24 +257,30 @@ func (h *ConversationHandler) GetMessagesByConversation(c *gin.Context) {
 	}
 
 	conversationId := c.Param("id")
-	messages, pagination, err := h.service.GetMessagesByConversation(c, conversationId, req)
+	conversationMessages, pagination, err := h.service.GetMessagesByConversation(c, c.GetString("userId"), conversationId, req)
 	if err != nil {
 		logger.Error("Failed to get messages: ", err)
-		response.Error(c, http.StatusNotFound, err, "Not found")
+		switch err.Error() {
+		case messages.NotConversationMember:
+			response.Error(c, http.StatusForbidden, err, messages.NotConversationMember)
+		default:
+			response.Error(c, http.StatusNotFound, err, "Not found")
+		}
 		return
 	}
 
 	var res dto.ListMessageRes
-	utils.MapStruct(&res.Messages, messages)
+	utils.MapStruct(&res.Messages, conversationMessages)
 	res.Pagination = pagination
 	response.JSON(c, http.StatusOK, res)
 }
 
 //		@Summary	 Create message in conversation
-//	 @Description Fetches a paginated list of messages created by the conversation, based on the provided pagination filter.
+//	 @Description Sends a message as the authenticated participant and pushes it to the conversation in real time. The request must include multipart form data, images (JPEG, PNG, GIF, WebP) and PDF files up to 10MB can be attached. Retries with the same clientMessageId return the stored message.
 //		@Tags		 Conversations
+//		@Accept		 multipart/form-data
 //		@Produce	 json
-//		@Success	 200	{object}	response.Response	"Successfully retrieved the list of messages"
+//		@Success	 200	{object}	response.Response	"Message created successfully"
 //		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
 //		@Failure	 403	{object}	response.Response	"Forbidden - User does not have the required permissions"
 //		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
@@ -134,10 +293,21 @@ func (h *ConversationHandler) CreateMessage(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
 		return
 	}
+	req.ConversationId = c.Param("id")
+	req.SenderId = c.GetString("userId")
+
 	message, err := h.service.CreateMessage(c, &req)
 	if err != nil {
 		logger.Error("Failed to create message ", err.Error())
 		switch err.Error() {
+		case messages.ConversationNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.ConversationNotFound)
+		case messages.NotConversationMember:
+			response.Error(c, http.StatusForbidden, err, messages.NotConversationMember)
+		case messages.TooManyAttachments, messages.AttachmentTooLarge, messages.AttachmentNotAllowed:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.ParticipantSilenced, messages.AnnouncementOnly:
+			response.Error(c, http.StatusForbidden, err, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to create message")
 		}
@@ -168,10 +338,14 @@ func (h *ConversationHandler) UpdateMessage(c *gin.Context) {
 		return
 	}
 
-	message, err := h.service.UpdateMessage(c, messageId, &req)
+	message, err := h.service.UpdateMessage(c, c.GetString("userId"), c.Param("id"), messageId, &req)
 	if err != nil {
 		logger.Error("Failed to update message", err.Error())
 		switch err.Error() {
+		case messages.ConversationNotFound, messages.MessageNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.NotConversationMember, messages.NotMessageSender:
+			response.Error(c, http.StatusForbidden, err, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to update category")
 		}
@@ -196,11 +370,16 @@ func (h *ConversationHandler) UpdateMessage(c *gin.Context) {
 func (h *ConversationHandler) DeleteMessage(c *gin.Context) {
 	messageId := c.Param("messageId")
 
-	message, err := h.service.DeleteMessage(c, messageId)
+	message, err := h.service.DeleteMessage(c, c.GetString("userId"), c.Param("id"), messageId)
 
 	if err != nil {
 		logger.Error("Failed to delete message: ", err)
-		response.Error(c, http.StatusNotFound, err, "Not found")
+		switch err.Error() {
+		case messages.NotConversationMember, messages.NotMessageSender:
+			response.Error(c, http.StatusForbidden, err, err.Error())
+		default:
+			response.Error(c, http.StatusNotFound, err, "Not found")
+		}
 		return
 	}
 
@@ -208,3 +387,241 @@ func (h *ConversationHandler) DeleteMessage(c *gin.Context) {
 	utils.MapStruct(&res, &message)
 	response.JSON(c, http.StatusOK, res)
 }
+
+//		@Summary	 Open the attendee group of an event
+//	 @Description Creates the group conversation of the event, every ticket holder joins it automatically. Only the organizer can open it, calling it again returns the existing group.
+//		@Tags		 Conversations
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 params	body	dto.GroupSettingsReq	true	"Group settings"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the group"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/events/{eventId}/group [post]
+func (h *ConversationHandler) CreateGroup(c *gin.Context) {
+	var req dto.GroupSettingsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	conversation, err := h.service.CreateGroup(c, c.GetString("userId"), c.Param("eventId"), &req)
+	if err != nil {
+		logger.Error("Failed to create group: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.GroupConversation
+	utils.MapStruct(&res, &conversation)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieves the groups of the current user
+//	 @Description Fetches a paginated list of the event groups the authenticated user belongs to.
+//		@Tags		 Conversations
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the list of groups"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/groups [get]
+func (h *ConversationHandler) GetGroups(c *gin.Context) {
+	var req dto.ListConversationReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	conversations, pagination, err := h.service.GetGroupsByUser(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to get groups: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListGroupConversationRes
+	utils.MapStruct(&res.Conversations, &conversations)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Search messages
+//	 @Description Full-text search over the messages of the conversations the authenticated user takes part in, matches are wrapped in <mark> tags.
+//		@Tags		 Conversations
+//		@Produce	 json
+//		@Param		 q	query	string	true	"Search terms"
+//		@Param		 eventId	query	string	false	"Event ID"
+//		@Param		 senderId	query	string	false	"Sender ID"
+//		@Param		 from	query	string	false	"From date (YYYY-MM-DD)"
+//		@Param		 to	query	string	false	"To date (YYYY-MM-DD), inclusive"
+//		@Param		 page	query	int	false	"Page number"
+//		@Param		 pageSize	query	int	false	"Page size"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the matching messages"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid parameters, search too short or invalid date range"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/search [get]
+func (h *ConversationHandler) SearchMessages(c *gin.Context) {
+	var req dto.SearchMessageReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	results, pagination, err := h.service.SearchMessages(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to search messages: ", err)
+		switch err.Error() {
+		case messages.InvalidSearchQuery, messages.InvalidDateRange:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.SearchMessageRes
+	res.Messages = results
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Update the settings of a group
+//	 @Description Turns the announcement mode of the group on or off, in announcement mode only moderators can post.
+//		@Tags		 Conversations
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Conversation ID"
+//		@Param		 params	body	dto.GroupSettingsReq	true	"Group settings"
+//		@Success	 200	{object}	response.Response	"Group updated successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User does not moderate the group"
+//		@Failure	 404	{object}	response.Response	"Not Found - Conversation with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/{id}/settings [patch]
+func (h *ConversationHandler) UpdateGroupSettings(c *gin.Context) {
+	var req dto.GroupSettingsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	conversation, err := h.service.UpdateGroupSettings(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to update group: ", err)
+		switch err.Error() {
+		case messages.ConversationNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.ConversationNotFound)
+		case messages.NotConversationMember, messages.NotGroupModerator:
+			response.Error(c, http.StatusForbidden, err, err.Error())
+		case messages.NotGroupConversation:
+			response.Error(c, http.StatusBadRequest, err, messages.NotGroupConversation)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Conversation
+	utils.MapStruct(&res, &conversation)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieves the participants of a group
+//	 @Description Fetches a paginated list of the participants of the group with their role and moderation state.
+//		@Tags		 Conversations
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Conversation ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the list of participants"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not a participant of the group"
+//		@Failure	 404	{object}	response.Response	"Not Found - Conversation with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/{id}/participants [get]
+func (h *ConversationHandler) GetParticipants(c *gin.Context) {
+	var req dto.ListParticipantReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	participants, pagination, err := h.service.GetParticipants(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to get participants: ", err)
+		switch err.Error() {
+		case messages.ConversationNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.ConversationNotFound)
+		case messages.NotConversationMember:
+			response.Error(c, http.StatusForbidden, err, messages.NotConversationMember)
+		case messages.NotGroupConversation:
+			response.Error(c, http.StatusBadRequest, err, messages.NotGroupConversation)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.ListParticipantRes
+	utils.MapStruct(&res.Participants, &participants)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Moderate a participant of a group
+//	 @Description Mutes, bans or changes the role of a participant. Only the owner changes roles or moderates moderators.
+//		@Tags		 Conversations
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Conversation ID"
+//		@Param		 userId	path	string	true	"User ID of the participant"
+//		@Param		 params	body	dto.ModerateParticipantReq	true	"Moderation"
+//		@Success	 200	{object}	response.Response	"Participant updated successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User does not moderate the group"
+//		@Failure	 404	{object}	response.Response	"Not Found - Conversation or participant not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/conversations/{id}/participants/{userId} [patch]
+func (h *ConversationHandler) ModerateParticipant(c *gin.Context) {
+	var req dto.ModerateParticipantReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	participant, err := h.service.ModerateParticipant(c, c.GetString("userId"), c.Param("id"), c.Param("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to moderate participant: ", err)
+		switch err.Error() {
+		case messages.ConversationNotFound, messages.ParticipantNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.NotConversationMember, messages.NotGroupModerator, messages.CannotModerateOwner:
+			response.Error(c, http.StatusForbidden, err, err.Error())
+		case messages.NotGroupConversation:
+			response.Error(c, http.StatusBadRequest, err, messages.NotGroupConversation)
+		default:
+			response.Error(c, http.StatusBadRequest, err, "Failed to moderate participant")
+		}
+		return
+	}
+
+	var res dto.GroupParticipant
+	utils.MapStruct(&res, &participant)
+	response.JSON(c, http.StatusOK, res)
+}
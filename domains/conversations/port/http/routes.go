@@ -4,20 +4,31 @@ import (
 	"gohub/database"
 	"gohub/domains/conversations/repository"
 	"gohub/domains/conversations/service"
+	socketio "gohub/internal/libs/websocket"
 	middleware "gohub/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 	"gohub/internal/libs/validation"
 )
 
-func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.Validation) {
+func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.Validation, notifier socketio.Notifier) {
 	conversationRepository := repository.NewConversationRepository(sqlDB)
-	conversationService := service.NewConversationService(validator, conversationRepository)
+	conversationService := service.NewConversationService(validator, conversationRepository, notifier)
 	conversationHandler := NewConversationHandler(conversationService)
 
 	authMiddleware := middleware.JWTAuth()
 	conversationRoute := r.Group("/conversations").Use(authMiddleware)
 	{
+		conversationRoute.POST("/events/:eventId", conversationHandler.ContactOrganizer)
+		conversationRoute.POST("/events/:eventId/group", conversationHandler.CreateGroup)
+		conversationRoute.GET("/groups", conversationHandler.GetGroups)
+		conversationRoute.GET("/search", conversationHandler.SearchMessages)
+		conversationRoute.PATCH("/:id/settings", conversationHandler.UpdateGroupSettings)
+		conversationRoute.GET("/:id/participants", conversationHandler.GetParticipants)
+		conversationRoute.PATCH("/:id/participants/:userId", conversationHandler.ModerateParticipant)
+		conversationRoute.PATCH("/:id/archive", conversationHandler.ArchiveConversation)
+		conversationRoute.PATCH("/:id/mute", conversationHandler.MuteConversation)
+		conversationRoute.PATCH("/:id/read", conversationHandler.MarkAsRead)
 		conversationRoute.GET("/get-by-organizer/:organizerId", conversationHandler.GetConversationsByOrganizer)
 		conversationRoute.GET("/get-by-user/:userId", conversationHandler.GetConversationsByUser)
 		conversationRoute.GET("/:id/messages", conversationHandler.GetMessagesByConversation)
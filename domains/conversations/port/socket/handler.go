@@ -0,0 +1,90 @@
+package socket
+
+import (
+	"context"
+	"gohub/domains/conversations/dto"
+	"gohub/domains/conversations/service"
+	"gohub/internal/libs/logger"
+	socketio "gohub/internal/libs/websocket"
+	"gohub/pkg/messages"
+	"gohub/pkg/utils"
+
+	gosocketio "github.com/googollee/go-socket.io"
+)
+
+type ConversationHandler struct {
+	service service.IConversationService
+}
+
+func NewConversationHandler(service service.IConversationService) *ConversationHandler {
+	return &ConversationHandler{
+		service: service,
+	}
+}
+
+// SendMessage persists the message through the conversation service, which fans it out to the room.
+// The sender is taken from the connection, the result is acknowledged with message_sent or message_error.
+func (h *ConversationHandler) SendMessage(s gosocketio.Conn, data dto.SendMessageReq) {
+	senderId := socketio.UserId(s)
+	if senderId == "" {
+		s.Emit("message_error", dto.SendMessageErrorRes{
+			ConversationId:  data.ConversationId,
+			ClientMessageId: data.ClientMessageId,
+			Error:           messages.NotConversationMember,
+		})
+		return
+	}
+
+	message, err := h.service.CreateMessage(context.Background(), &dto.CreateMessageReq{
+		ConversationId:  data.ConversationId,
+		SenderId:        senderId,
+		ClientMessageId: data.ClientMessageId,
+		Content:         data.Message,
+	})
+	if err != nil {
+		logger.Error("Failed to send message: ", err)
+		s.Emit("message_error", dto.SendMessageErrorRes{
+			ConversationId:  data.ConversationId,
+			ClientMessageId: data.ClientMessageId,
+			Error:           err.Error(),
+		})
+		return
+	}
+
+	var res dto.Message
+	utils.MapStruct(&res, &message)
+	s.Emit("message_sent", res)
+}
+
+// MarkAsRead moves the read cursor of the connected user, the receipt is broadcast by the service
+func (h *ConversationHandler) MarkAsRead(s gosocketio.Conn, data dto.ConversationEventReq) {
+	if _, err := h.service.MarkAsRead(context.Background(), socketio.UserId(s), data.ConversationId); err != nil {
+		logger.Error("Failed to mark conversation as read: ", err)
+	}
+}
+
+// MarkAsDelivered is sent by clients once they received the messages of a conversation
+func (h *ConversationHandler) MarkAsDelivered(s gosocketio.Conn, data dto.ConversationEventReq) {
+	if _, err := h.service.MarkAsDelivered(context.Background(), socketio.UserId(s), data.ConversationId); err != nil {
+		logger.Error("Failed to mark conversation as delivered: ", err)
+	}
+}
+
+// Typing relays the typing state of the connected user to the conversation
+func (h *ConversationHandler) Typing(s gosocketio.Conn, data dto.TypingReq) {
+	if err := h.service.Typing(context.Background(), socketio.UserId(s), data.ConversationId, data.IsTyping); err != nil {
+		logger.Error("Failed to relay typing: ", err)
+	}
+}
+
+// JoinConversation subscribes the connection to the conversation once membership is verified
+func (h *ConversationHandler) JoinConversation(s gosocketio.Conn, conversationId string) {
+	if err := h.service.JoinConversation(context.Background(), socketio.UserId(s), conversationId); err != nil {
+		logger.Error("Failed to join conversation: ", err)
+		s.Emit("join_error", dto.SendMessageErrorRes{ConversationId: conversationId, Error: err.Error()})
+		return
+	}
+
+	logger.Info("Client joined conversation:", conversationId)
+	s.Join(conversationId)
+}
@@ -0,0 +1,21 @@
+package socket
+
+import (
+	"gohub/database"
+	"gohub/domains/conversations/repository"
+	"gohub/domains/conversations/service"
+	"gohub/internal/libs/validation"
+	socketio "gohub/internal/libs/websocket"
+)
+
+func Routes(server *socketio.Server, sqlDB database.IDatabase, validator validation.Validation) {
+	conversationRepository := repository.NewConversationRepository(sqlDB)
+	conversationService := service.NewConversationService(validator, conversationRepository, server)
+	conversationHandler := NewConversationHandler(conversationService)
+
+	server.OnEvent("join_conversation", conversationHandler.JoinConversation)
+	server.OnEvent("send_message", conversationHandler.SendMessage)
+	server.OnEvent("mark_read", conversationHandler.MarkAsRead)
+	server.OnEvent("mark_delivered", conversationHandler.MarkAsDelivered)
+	server.OnEvent("typing", conversationHandler.Typing)
+}
@@ -6,11 +6,23 @@ import (
 	"gohub/database"
 	"gohub/domains/conversations/dto"
 	"gohub/domains/conversations/model"
+	modelEvent "gohub/domains/events/model"
 	"gohub/pkg/paging"
+
+	"gorm.io/gorm"
 )
 
 type IConversationRepository interface {
 	CreateMessage(ctx context.Context, message *model.Message) error
+	GetConversationById(ctx context.Context, id string) (*model.Conversation, error)
+	GetConversationByEventAndUser(ctx context.Context, eventId string, userId string) (*model.Conversation, error)
+	CreateConversation(ctx context.Context, conversation *model.Conversation) error
+	GetEventById(ctx context.Context, id string) (*modelEvent.Event, error)
+	GetParticipant(ctx context.Context, conversationId string, userId string) (*model.ConversationParticipant, error)
+	CreateParticipant(ctx context.Context, participant *model.ConversationParticipant) error
+	UpdateParticipant(ctx context.Context, participant *model.ConversationParticipant) error
+	CountUnreadMessages(ctx context.Context, userId string, conversationIds []string) (map[string]int64, error)
+	GetMessageByClientId(ctx context.Context, conversationId string, senderId string, clientMessageId string) (*model.Message, error)
 	UpdateMessage(ctx context.Context, message *model.Message) error
 	DeleteMessage(ctx context.Context, messageId string) error
 	GetConversationByOrganizer(ctx context.Context, organizerId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error)
@@ -18,6 +30,14 @@ type IConversationRepository interface {
 	GetMessageByConversation(ctx context.Context, conservationId string, req *dto.ListMessageReq) ([]*model.Message, *paging.Pagination, error)
 	GetMessageById(ctx context.Context, messageId string) (*model.Message, error)
 	GetMessageDeleteById(ctx context.Context, messageId string) (*model.Message, error)
+	GetGroupByEvent(ctx context.Context, eventId string) (*model.Conversation, error)
+	JoinTicketHolders(ctx context.Context, conversationId string, eventId string) error
+	HasTicket(ctx context.Context, eventId string, userId string) (bool, error)
+	GetGroupsByUser(ctx context.Context, userId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error)
+	GetParticipants(ctx context.Context, conversationId string, req *dto.ListParticipantReq) ([]*model.ConversationParticipant, *paging.Pagination, error)
+	GetNotifiableParticipantIds(ctx context.Context, conversationId string, senderId string) ([]string, error)
+	UpdateConversation(ctx context.Context, conversation *model.Conversation) error
+	SearchMessages(ctx context.Context, userId string, req *dto.SearchMessageReq) ([]*dto.SearchMessage, *paging.Pagination, error)
 }
 
 type ConversationRepo struct {
@@ -28,8 +48,126 @@ func NewConversationRepository(db database.IDatabase) *ConversationRepo {
 	return &ConversationRepo{db: db}
 }
 
+// CreateMessage stores the message as the last one of its conversation. Sends to the same conversation are
+// serialized so the last message is always the newest one.
 func (c *ConversationRepo) CreateMessage(ctx context.Context, message *model.Message) error {
-	return c.db.Create(ctx, message)
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return c.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT 1 FROM conversations WHERE id = ? FOR UPDATE", message.ConversationId).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Conversation{}).
+			Where("id = ?", message.ConversationId).
+			Update("last_message_id", message.ID).Error
+	})
+}
+
+func (c *ConversationRepo) GetConversationById(ctx context.Context, id string) (*model.Conversation, error) {
+	var conversation model.Conversation
+	if err := c.db.FindById(ctx, id, &conversation); err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+func (c *ConversationRepo) GetConversationByEventAndUser(ctx context.Context, eventId string, userId string) (*model.Conversation, error) {
+	var conversation model.Conversation
+	query := database.NewQuery("event_id = ? AND user_id = ? AND type = ?", eventId, userId, model.ConversationTypeDirect)
+	if err := c.db.FindOne(
+		ctx,
+		&conversation,
+		database.WithQuery(query),
+		database.WithPreload([]string{"User", "Organizer", "LastMessage", "Participants"}),
+	); err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+// CreateConversation creates the conversation along with its participants
+func (c *ConversationRepo) CreateConversation(ctx context.Context, conversation *model.Conversation) error {
+	return c.db.Create(ctx, conversation)
+}
+
+func (c *ConversationRepo) GetEventById(ctx context.Context, id string) (*modelEvent.Event, error) {
+	var event modelEvent.Event
+	if err := c.db.FindById(ctx, id, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (c *ConversationRepo) GetParticipant(ctx context.Context, conversationId string, userId string) (*model.ConversationParticipant, error) {
+	var participant model.ConversationParticipant
+	query := database.NewQuery("conversation_id = ? AND user_id = ?", conversationId, userId)
+	if err := c.db.FindOne(ctx, &participant, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return &participant, nil
+}
+
+func (c *ConversationRepo) CreateParticipant(ctx context.Context, participant *model.ConversationParticipant) error {
+	return c.db.Create(ctx, participant)
+}
+
+func (c *ConversationRepo) UpdateParticipant(ctx context.Context, participant *model.ConversationParticipant) error {
+	return c.db.Update(ctx, participant)
+}
+
+// CountUnreadMessages counts, per conversation, the messages received by the user after their read cursor
+func (c *ConversationRepo) CountUnreadMessages(ctx context.Context, userId string, conversationIds []string) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	if len(conversationIds) == 0 {
+		return counts, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var rows []struct {
+		ConversationId string
+		Total          int64
+	}
+	err := c.db.GetDB().WithContext(ctx).Raw(`
+		SELECT messages.conversation_id, COUNT(*) AS total FROM messages
+		LEFT JOIN conversation_participants ON conversation_participants.conversation_id = messages.conversation_id
+			AND conversation_participants.user_id = @userId
+		WHERE messages.conversation_id IN @conversationIds
+			AND messages.sender_id <> @userId
+			AND messages.deleted_at IS NULL
+			AND (conversation_participants.last_read_at IS NULL OR messages.created_at > conversation_participants.last_read_at)
+		GROUP BY messages.conversation_id
+	`, map[string]interface{}{"userId": userId, "conversationIds": conversationIds}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.ConversationId] = row.Total
+	}
+
+	return counts, nil
+}
+
+func (c *ConversationRepo) GetMessageByClientId(ctx context.Context, conversationId string, senderId string, clientMessageId string) (*model.Message, error) {
+	var message model.Message
+	query := database.NewQuery("conversation_id = ? AND sender_id = ? AND client_message_id = ?", conversationId, senderId, clientMessageId)
+	if err := c.db.FindOne(ctx, &message, database.WithQuery(query), database.WithPreload([]string{"MessageAttachments"})); err != nil {
+		return nil, err
+	}
+
+	return &message, nil
 }
 
 func (c *ConversationRepo) UpdateMessage(ctx context.Context, message *model.Message) error {
@@ -52,15 +190,23 @@ func (c *ConversationRepo) GetConversationByOrganizer(ctx context.Context, organ
 	query := make([]database.Query, 0)
 	args := make([]interface{}, 0)
 
-	queryString := "organizer_id = ?"
+	queryString := "organizer_id = ? AND conversations.type = ?"
+	args = append(args, organizerId, model.ConversationTypeDirect)
+
+	queryString += " AND " + archivedFilter(req.IsArchived)
 	args = append(args, organizerId)
 
+	if req.IsUnanswered {
+		queryString += " AND conversations.last_message_id IN (SELECT id FROM messages WHERE sender_id <> ?)"
+		args = append(args, organizerId)
+	}
+
 	if req.Search != "" {
-		queryString += " AND users.user_name LIKE ? OR users.full_name LIKE ?"
+		queryString += " AND (users.user_name LIKE ? OR users.full_name LIKE ?)"
 		args = append(args, "%"+req.Search+"%", "%"+req.Search+"%")
 	}
 
-	order := "created_at DESC"
+	order := "conversations.created_at DESC"
 	if req.OrderBy != "" {
 		order = req.OrderBy
 		if req.OrderDesc {
@@ -100,7 +246,7 @@ func (c *ConversationRepo) GetConversationByOrganizer(ctx context.Context, organ
 		database.WithJoin(`
 			INNER JOIN users ON conversations.user_id = users.id
     	`),
-		database.WithPreload([]string{"User", "LastMessage", "Event"}),
+		database.WithPreload([]string{"User", "LastMessage", "Event", "Participants"}),
 	); err != nil {
 		return nil, nil, err
 	}
@@ -115,7 +261,10 @@ func (c *ConversationRepo) GetConversationByUser(ctx context.Context, userId str
 	query := make([]database.Query, 0)
 	args := make([]interface{}, 0)
 
-	queryString := "conversations.user_id = ?"
+	queryString := "conversations.user_id = ? AND conversations.type = ?"
+	args = append(args, userId, model.ConversationTypeDirect)
+
+	queryString += " AND " + archivedFilter(req.IsArchived)
 	args = append(args, userId)
 
 	if req.Search != "" {
@@ -125,7 +274,7 @@ func (c *ConversationRepo) GetConversationByUser(ctx context.Context, userId str
 
 	query = append(query, database.NewQuery(queryString, args...))
 
-	order := "created_at DESC"
+	order := "conversations.created_at DESC"
 	if req.OrderBy != "" {
 		order = req.OrderBy
 		if req.OrderDesc {
@@ -163,7 +312,7 @@ func (c *ConversationRepo) GetConversationByUser(ctx context.Context, userId str
 		database.WithJoin(`
 			INNER JOIN events ON conversations.event_id = events.id
     	`),
-		database.WithPreload([]string{"Organizer", "LastMessage", "Event"}),
+		database.WithPreload([]string{"Organizer", "LastMessage", "Event", "Participants"}),
 	); err != nil {
 		return nil, nil, err
 	}
@@ -184,22 +333,35 @@ func (c *ConversationRepo) GetMessageByConversation(ctx context.Context, conserv
 	}
 
 	pagination := paging.NewPagination(req.Page, req.Limit, total)
-	pagination.Skip = total - pagination.PageSize
 
 	if req.TakeAll {
 		pagination.PageSize = total
 	}
 
+	// The first page holds the latest messages, each page is returned oldest first
+	offset := total - pagination.CurrentPage*pagination.PageSize
+	limit := pagination.PageSize
+	if offset < 0 {
+		limit += offset
+		offset = 0
+	}
+	pagination.Skip = offset
+
 	order := "created_at ASC"
 
 	var messages []*model.Message
+	if limit <= 0 {
+		return messages, pagination, nil
+	}
+
 	if err := c.db.Find(
 		ctx,
 		&messages,
 		database.WithQuery(query...),
-		database.WithLimit(int(pagination.PageSize)),
-		database.WithOffset(int(total-pagination.PageSize)),
+		database.WithLimit(int(limit)),
+		database.WithOffset(int(offset)),
 		database.WithOrder(order),
+		database.WithPreload([]string{"MessageAttachments"}),
 	); err != nil {
 		return nil, nil, err
 	}
@@ -224,3 +386,13 @@ func (c *ConversationRepo) GetMessageDeleteById(ctx context.Context, messageId s
 
 	return &message, nil
 }
+
+// archivedFilter keeps the conversations archived by the participant bound to the placeholder, or hides them
+func archivedFilter(isArchived bool) string {
+	subQuery := "conversations.id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ? AND is_archived = true)"
+	if isArchived {
+		return subQuery
+	}
+
+	return "NOT " + subQuery
+}
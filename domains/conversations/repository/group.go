@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"gohub/configs"
+	"gohub/database"
+	"gohub/domains/conversations/dto"
+	"gohub/domains/conversations/model"
+	modelTicket "gohub/domains/tickets/model"
+	"gohub/pkg/paging"
+
+	"gorm.io/gorm/clause"
+)
+
+func (c *ConversationRepo) GetGroupByEvent(ctx context.Context, eventId string) (*model.Conversation, error) {
+	var conversation model.Conversation
+	query := database.NewQuery("event_id = ? AND type = ?", eventId, model.ConversationTypeGroup)
+	if err := c.db.FindOne(ctx, &conversation, database.WithQuery(query), database.WithPreload([]string{"Event", "Organizer"})); err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+// JoinTicketHolders adds every current ticket holder of the event to the group, existing participants are kept as they are
+func (c *ConversationRepo) JoinTicketHolders(ctx context.Context, conversationId string, eventId string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return c.db.GetDB().WithContext(ctx).Exec(`
+		INSERT INTO conversation_participants (id, conversation_id, user_id, role, created_at, updated_at)
+		SELECT gen_random_uuid()::text, @conversationId, holders.user_id, @role, NOW(), NOW()
+		FROM (SELECT DISTINCT user_id FROM tickets WHERE event_id = @eventId AND deleted_at IS NULL) AS holders
+		ON CONFLICT (conversation_id, user_id) DO NOTHING
+	`, map[string]interface{}{
+		"conversationId": conversationId,
+		"eventId":        eventId,
+		"role":           model.ParticipantRoleMember,
+	}).Error
+}
+
+func (c *ConversationRepo) HasTicket(ctx context.Context, eventId string, userId string) (bool, error) {
+	var total int64
+	query := database.NewQuery("event_id = ? AND user_id = ?", eventId, userId)
+	if err := c.db.Count(ctx, &modelTicket.Ticket{}, &total, database.WithQuery(query)); err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+// GetGroupsByUser lists the groups the user joined or can join through a ticket, except those they are banned from
+func (c *ConversationRepo) GetGroupsByUser(ctx context.Context, userId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	queryString := `conversations.type = ? AND (
+		conversations.id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ? AND is_banned = false)
+		OR (
+			conversations.event_id IN (SELECT event_id FROM tickets WHERE user_id = ? AND deleted_at IS NULL)
+			AND conversations.id NOT IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ?)
+		)
+	)`
+	args := []interface{}{model.ConversationTypeGroup, userId, userId, userId}
+
+	if req.Search != "" {
+		queryString += " AND events.name LIKE ?"
+		args = append(args, "%"+req.Search+"%")
+	}
+
+	query := []database.Query{database.NewQuery(queryString, args...)}
+	join := database.WithJoin(`
+			INNER JOIN events ON conversations.event_id = events.id
+    	`)
+
+	var total int64
+	if err := c.db.Count(ctx, &model.Conversation{}, &total, database.WithQuery(query...), join); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	if req.TakeAll {
+		pagination.PageSize = total
+	}
+
+	var conversations []*model.Conversation
+	if err := c.db.Find(
+		ctx,
+		&conversations,
+		database.WithQuery(query...),
+		database.WithLimit(int(pagination.PageSize)),
+		database.WithOffset(int(pagination.Skip)),
+		database.WithOrder("conversations.updated_at DESC"),
+		join,
+		database.WithPreload([]string{"Organizer", "LastMessage", "Event"}),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return conversations, pagination, nil
+}
+
+func (c *ConversationRepo) GetParticipants(ctx context.Context, conversationId string, req *dto.ListParticipantReq) ([]*model.ConversationParticipant, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	query := database.NewQuery("conversation_id = ?", conversationId)
+
+	var total int64
+	if err := c.db.Count(ctx, &model.ConversationParticipant{}, &total, database.WithQuery(query)); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	if req.TakeAll {
+		pagination.PageSize = total
+	}
+
+	var participants []*model.ConversationParticipant
+	if err := c.db.Find(
+		ctx,
+		&participants,
+		database.WithQuery(query),
+		database.WithLimit(int(pagination.PageSize)),
+		database.WithOffset(int(pagination.Skip)),
+		database.WithOrder("created_at ASC"),
+		database.WithPreload([]string{"User"}),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return participants, pagination, nil
+}
+
+// GetNotifiableParticipantIds returns the participants that should be notified of a new message
+func (c *ConversationRepo) GetNotifiableParticipantIds(ctx context.Context, conversationId string, senderId string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var ids []string
+	err := c.db.GetDB().WithContext(ctx).
+		Model(&model.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id <> ? AND is_banned = false AND is_muted = false", conversationId, senderId).
+		Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (c *ConversationRepo) UpdateConversation(ctx context.Context, conversation *model.Conversation) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return c.db.GetDB().WithContext(ctx).Omit(clause.Associations).Save(conversation).Error
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"gohub/configs"
+	"gohub/domains/conversations/dto"
+	"gohub/domains/conversations/model"
+	"gohub/pkg/paging"
+	"strings"
+)
+
+// escapedContent is the content of a message with the HTML special characters escaped, the ampersand first
+const escapedContent = `REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(messages.content,
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+// SearchMessages runs a full-text search over the messages of the conversations the user takes part in,
+// best matches first
+func (c *ConversationRepo) SearchMessages(ctx context.Context, userId string, req *dto.SearchMessageReq) ([]*dto.SearchMessage, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	conditions := []string{
+		"messages.search_vector @@ websearch_to_tsquery('simple', @query)",
+		"messages.deleted_at IS NULL",
+		`(
+			(conversations.type = @direct AND (conversations.user_id = @userId OR conversations.organizer_id = @userId))
+			OR conversations.id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = @userId AND is_banned = false)
+		)`,
+	}
+	args := map[string]interface{}{
+		"query":  req.Query,
+		"userId": userId,
+		"direct": model.ConversationTypeDirect,
+	}
+
+	if req.EventId != "" {
+		conditions = append(conditions, "conversations.event_id = @eventId")
+		args["eventId"] = req.EventId
+	}
+
+	if req.SenderId != "" {
+		conditions = append(conditions, "messages.sender_id = @senderId")
+		args["senderId"] = req.SenderId
+	}
+
+	if !req.From.IsZero() {
+		conditions = append(conditions, "messages.created_at >= @from")
+		args["from"] = req.From
+	}
+
+	if !req.To.IsZero() {
+		conditions = append(conditions, "messages.created_at < @to")
+		args["to"] = req.To
+	}
+
+	from := `
+		FROM messages
+		INNER JOIN conversations ON conversations.id = messages.conversation_id AND conversations.deleted_at IS NULL
+		INNER JOIN events ON events.id = conversations.event_id
+		INNER JOIN users ON users.id = messages.sender_id
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := c.db.GetDB().WithContext(ctx).Raw("SELECT COUNT(*)"+from, args).Scan(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	if req.TakeAll {
+		pagination.PageSize = total
+	}
+
+	args["limit"] = pagination.PageSize
+	args["offset"] = pagination.Skip
+
+	// The highlight is HTML with the matches in <mark>, the content is escaped before so a message cannot inject markup
+	var messages []*dto.SearchMessage
+	err := c.db.GetDB().WithContext(ctx).Raw(`
+		SELECT
+			messages.id,
+			messages.conversation_id,
+			conversations.type AS conversation_type,
+			conversations.event_id,
+			events.name AS event_name,
+			messages.sender_id,
+			users.user_name AS sender_user_name,
+			users.full_name AS sender_full_name,
+			users.avatar_url AS sender_avatar_url,
+			messages.content,
+			ts_headline('simple', `+escapedContent+`, websearch_to_tsquery('simple', @query),
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=3, FragmentDelimiter=" … "') AS highlight,
+			ts_rank(messages.search_vector, websearch_to_tsquery('simple', @query)) AS rank,
+			messages.created_at`+from+`
+		ORDER BY rank DESC, messages.created_at DESC
+		LIMIT @limit OFFSET @offset
+	`, args).Scan(&messages).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return messages, pagination, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/conversations/model"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/utils"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+const attachmentFolder = "/eventhub/messages"
+
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":      model.MessageAttachmentTypeImage,
+	"image/png":       model.MessageAttachmentTypeImage,
+	"image/gif":       model.MessageAttachmentTypeImage,
+	"image/webp":      model.MessageAttachmentTypeImage,
+	"application/pdf": model.MessageAttachmentTypeFile,
+}
+
+type attachmentFile struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+// readAttachments checks the count, size and sniffed content type of every file before anything is uploaded
+func readAttachments(fileHeaders []*multipart.FileHeader) ([]*attachmentFile, error) {
+	if len(fileHeaders) > configs.MaxAttachmentsPerMessage {
+		return nil, errors.New(messages.TooManyAttachments)
+	}
+
+	files := make([]*attachmentFile, 0, len(fileHeaders))
+	for _, fileHeader := range fileHeaders {
+		if fileHeader.Size > configs.MaxAttachmentSize {
+			return nil, errors.New(messages.AttachmentTooLarge)
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(file, configs.MaxAttachmentSize+1))
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > configs.MaxAttachmentSize {
+			return nil, errors.New(messages.AttachmentTooLarge)
+		}
+
+		// The client provided header is ignored, only the content decides the type
+		contentType := http.DetectContentType(data)
+		if _, ok := allowedAttachmentTypes[contentType]; !ok {
+			return nil, errors.New(messages.AttachmentNotAllowed)
+		}
+
+		files = append(files, &attachmentFile{name: fileHeader.Filename, contentType: contentType, data: data})
+	}
+
+	return files, nil
+}
+
+// uploadAttachments stores the files and a thumbnail for every image
+func uploadAttachments(files []*attachmentFile) ([]*model.MessageAttachment, error) {
+	attachments := make([]*model.MessageAttachment, 0, len(files))
+	for _, file := range files {
+		url, err := utils.FileUpload(bytes.NewReader(file.data), attachmentFolder)
+		if err != nil {
+			return nil, err
+		}
+
+		attachment := &model.MessageAttachment{
+			MessageType:     allowedAttachmentTypes[file.contentType],
+			MessageUrl:      url,
+			MessageFileName: file.name,
+			ContentType:     file.contentType,
+			Size:            int64(len(file.data)),
+		}
+
+		if attachment.MessageType == model.MessageAttachmentTypeImage {
+			thumbnail, err := utils.Thumbnail(file.data, configs.ThumbnailWidth)
+			if err != nil {
+				logger.Errorf("Thumbnail fail, file: %s, error: %s", file.name, err)
+			} else if attachment.ThumbnailUrl, err = utils.FileUpload(bytes.NewReader(thumbnail), attachmentFolder+"/thumbnails"); err != nil {
+				logger.Errorf("Upload thumbnail fail, file: %s, error: %s", file.name, err)
+			}
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
@@ -2,65 +2,295 @@ package service
 
 import (
 	"context"
+	"errors"
 	"gohub/domains/conversations/dto"
 	"gohub/domains/conversations/model"
 	"gohub/domains/conversations/repository"
 	"gohub/internal/libs/logger"
+	socketio "gohub/internal/libs/websocket"
+	"gohub/pkg/messages"
 	"gohub/pkg/paging"
 	"gohub/pkg/utils"
+	"time"
 
 	"gohub/internal/libs/validation"
 )
 
 type IConversationService interface {
+	ContactOrganizer(ctx context.Context, userId string, eventId string) (*model.Conversation, error)
+	ArchiveConversation(ctx context.Context, userId string, id string, req *dto.ArchiveConversationReq) (*model.ConversationParticipant, error)
+	MuteConversation(ctx context.Context, userId string, id string, req *dto.MuteConversationReq) (*model.ConversationParticipant, error)
+	MarkAsRead(ctx context.Context, userId string, id string) (*model.ConversationParticipant, error)
+	MarkAsDelivered(ctx context.Context, userId string, id string) (*model.ConversationParticipant, error)
+	Typing(ctx context.Context, userId string, id string, isTyping bool) error
 	CreateMessage(ctx context.Context, req *dto.CreateMessageReq) (*model.Message, error)
-	UpdateMessage(ctx context.Context, id string, req *dto.UpdateMessageReq) (*model.Message, error)
-	DeleteMessage(ctx context.Context, messageId string) (*model.Message, error)
-	GetConversationsOrganizer(ctx context.Context, organizerId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error)
-	GetConversationsByUser(ctx context.Context, userId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error)
-	GetMessagesByConversation(ctx context.Context, conversationID string, req *dto.ListMessageReq) ([]*model.Message, *paging.Pagination, error)
+	UpdateMessage(ctx context.Context, userId string, conversationId string, id string, req *dto.UpdateMessageReq) (*model.Message, error)
+	DeleteMessage(ctx context.Context, userId string, conversationId string, messageId string) (*model.Message, error)
+	GetConversationsOrganizer(ctx context.Context, userId string, organizerId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error)
+	GetConversationsByUser(ctx context.Context, currentUserId string, userId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error)
+	GetMessagesByConversation(ctx context.Context, userId string, conversationID string, req *dto.ListMessageReq) ([]*model.Message, *paging.Pagination, error)
+	JoinConversation(ctx context.Context, userId string, id string) error
+	CreateGroup(ctx context.Context, userId string, eventId string, req *dto.GroupSettingsReq) (*model.Conversation, error)
+	GetGroupsByUser(ctx context.Context, userId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error)
+	UpdateGroupSettings(ctx context.Context, userId string, id string, req *dto.GroupSettingsReq) (*model.Conversation, error)
+	GetParticipants(ctx context.Context, userId string, id string, req *dto.ListParticipantReq) ([]*model.ConversationParticipant, *paging.Pagination, error)
+	ModerateParticipant(ctx context.Context, userId string, id string, participantId string, req *dto.ModerateParticipantReq) (*model.ConversationParticipant, error)
+	SearchMessages(ctx context.Context, userId string, req *dto.SearchMessageReq) ([]*dto.SearchMessage, *paging.Pagination, error)
 }
 
 type ConversationService struct {
 	validator        validation.Validation
 	repoConversation repository.IConversationRepository
+	notifier         socketio.Notifier
 }
 
-func NewConversationService(validator validation.Validation, repo repository.IConversationRepository) *ConversationService {
+func NewConversationService(validator validation.Validation, repo repository.IConversationRepository, notifier socketio.Notifier) *ConversationService {
 	return &ConversationService{
 		validator:        validator,
 		repoConversation: repo,
+		notifier:         notifier,
 	}
 }
 
+// ContactOrganizer returns the conversation between the user and the organizer of the event, creating it on first contact
+func (c *ConversationService) ContactOrganizer(ctx context.Context, userId string, eventId string) (*model.Conversation, error) {
+	if conversation, err := c.repoConversation.GetConversationByEventAndUser(ctx, eventId, userId); err == nil {
+		return conversation, nil
+	}
+
+	event, err := c.repoConversation.GetEventById(ctx, eventId)
+	if err != nil {
+		logger.Errorf("ContactOrganizer.GetEventById fail, id: %s, error: %s", eventId, err)
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId == userId {
+		return nil, errors.New(messages.CannotContactYourself)
+	}
+
+	conversation := model.Conversation{
+		EventId:     event.ID,
+		UserId:      userId,
+		OrganizerId: event.UserId,
+		Type:        model.ConversationTypeDirect,
+		Participants: []*model.ConversationParticipant{
+			{UserId: userId},
+			{UserId: event.UserId},
+		},
+	}
+	if err := c.repoConversation.CreateConversation(ctx, &conversation); err != nil {
+		// The conversation may have been created by a concurrent request
+		if existing, findErr := c.repoConversation.GetConversationByEventAndUser(ctx, eventId, userId); findErr == nil {
+			return existing, nil
+		}
+		logger.Errorf("ContactOrganizer fail, event: %s, error: %s", eventId, err)
+		return nil, err
+	}
+
+	return c.repoConversation.GetConversationByEventAndUser(ctx, eventId, userId)
+}
+
+func (c *ConversationService) ArchiveConversation(ctx context.Context, userId string, id string, req *dto.ArchiveConversationReq) (*model.ConversationParticipant, error) {
+	conversation, err := c.authorize(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := c.participant(ctx, conversation, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	participant.IsArchived = req.IsArchived
+	if err := c.repoConversation.UpdateParticipant(ctx, participant); err != nil {
+		logger.Errorf("ArchiveConversation fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+func (c *ConversationService) MuteConversation(ctx context.Context, userId string, id string, req *dto.MuteConversationReq) (*model.ConversationParticipant, error) {
+	conversation, err := c.authorize(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := c.participant(ctx, conversation, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	participant.IsMuted = req.IsMuted
+	if err := c.repoConversation.UpdateParticipant(ctx, participant); err != nil {
+		logger.Errorf("MuteConversation fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+// MarkAsRead moves the read cursor of the user to the last message of the conversation
+func (c *ConversationService) MarkAsRead(ctx context.Context, userId string, id string) (*model.ConversationParticipant, error) {
+	conversation, err := c.authorize(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := c.participant(ctx, conversation, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	participant.LastReadMessageId = conversation.LastMessageId
+	participant.LastReadAt = &now
+	participant.LastDeliveredAt = &now
+	if err := c.repoConversation.UpdateParticipant(ctx, participant); err != nil {
+		logger.Errorf("MarkAsRead fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	c.notifier.EmitToRoom(conversation.ID, "messages_read", readReceipt(participant))
+	return participant, nil
+}
+
+// MarkAsDelivered records that every message of the conversation reached a client of the user
+func (c *ConversationService) MarkAsDelivered(ctx context.Context, userId string, id string) (*model.ConversationParticipant, error) {
+	conversation, err := c.authorize(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := c.participant(ctx, conversation, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	participant.LastDeliveredAt = &now
+	if err := c.repoConversation.UpdateParticipant(ctx, participant); err != nil {
+		logger.Errorf("MarkAsDelivered fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	c.notifier.EmitToRoom(conversation.ID, "messages_delivered", readReceipt(participant))
+	return participant, nil
+}
+
+// Typing broadcasts the typing state of the user to the conversation, it is not persisted
+func (c *ConversationService) Typing(ctx context.Context, userId string, id string, isTyping bool) error {
+	if _, err := c.authorize(ctx, userId, id); err != nil {
+		return err
+	}
+
+	c.notifier.EmitToRoom(id, "typing", dto.TypingRes{
+		ConversationId: id,
+		UserId:         userId,
+		IsTyping:       isTyping,
+	})
+	return nil
+}
+
+// CreateMessage is shared by the REST and Socket.IO transports. A retry with the same client message id
+// returns the stored message instead of creating a duplicate.
 func (c *ConversationService) CreateMessage(ctx context.Context, req *dto.CreateMessageReq) (*model.Message, error) {
 	if err := c.validator.ValidateStruct(req); err != nil {
 		return nil, err
 	}
 
-	var message model.Message
-	utils.MapStruct(&message, req)
+	conversation, err := c.authorize(ctx, req.SenderId, req.ConversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	var receiverId *string
+	if conversation.Type == model.ConversationTypeGroup {
+		sender, err := c.participant(ctx, conversation, req.SenderId)
+		if err != nil {
+			return nil, err
+		}
+		if sender.IsSilenced {
+			return nil, errors.New(messages.ParticipantSilenced)
+		}
+		if conversation.IsAnnouncementOnly && !sender.CanModerate() {
+			return nil, errors.New(messages.AnnouncementOnly)
+		}
+	} else {
+		otherId := c.otherParticipantId(conversation, req.SenderId)
+		receiverId = &otherId
+	}
+
+	if req.ClientMessageId != "" {
+		if message, err := c.repoConversation.GetMessageByClientId(ctx, conversation.ID, req.SenderId, req.ClientMessageId); err == nil {
+			return message, nil
+		}
+	}
+
+	files, err := readAttachments(req.Attachments)
+	if err != nil {
+		return nil, err
+	}
 
-	err := c.repoConversation.CreateMessage(ctx, &message)
+	attachments, err := uploadAttachments(files)
 	if err != nil {
+		logger.Errorf("CreateMessage.uploadAttachments fail, error: %s", err)
+		return nil, err
+	}
+
+	message := model.Message{
+		ConversationId:     conversation.ID,
+		SenderId:           req.SenderId,
+		ReceiverId:         receiverId,
+		Content:            req.Content,
+		MessageAttachments: attachments,
+	}
+	if req.ClientMessageId != "" {
+		message.ClientMessageId = &req.ClientMessageId
+	}
+
+	if err := c.repoConversation.CreateMessage(ctx, &message); err != nil {
+		// A concurrent retry may have stored the message first
+		if req.ClientMessageId != "" {
+			if existing, findErr := c.repoConversation.GetMessageByClientId(ctx, conversation.ID, req.SenderId, req.ClientMessageId); findErr == nil {
+				return existing, nil
+			}
+		}
 		logger.Errorf("Create fail, error: %s", err)
 		return nil, err
 	}
 
+	var res dto.Message
+	utils.MapStruct(&res, &message)
+	c.notifier.EmitToRoom(message.ConversationId, "receive_message", res)
+	if receiverId != nil {
+		if receiver, err := c.repoConversation.GetParticipant(ctx, conversation.ID, *receiverId); err != nil || !receiver.IsMuted {
+			c.notifier.EmitToUser(*receiverId, "notify_message", res)
+		}
+	} else {
+		ids, err := c.repoConversation.GetNotifiableParticipantIds(ctx, conversation.ID, req.SenderId)
+		if err != nil {
+			logger.Errorf("CreateMessage.GetNotifiableParticipantIds fail, id: %s, error: %s", conversation.ID, err)
+		}
+		for _, id := range ids {
+			c.notifier.EmitToUser(id, "notify_message", res)
+		}
+	}
+
 	return &message, nil
 }
 
-func (c *ConversationService) UpdateMessage(ctx context.Context, id string, req *dto.UpdateMessageReq) (*model.Message, error) {
+func (c *ConversationService) UpdateMessage(ctx context.Context, userId string, conversationId string, id string, req *dto.UpdateMessageReq) (*model.Message, error) {
 	if err := c.validator.ValidateStruct(req); err != nil {
 		return nil, err
 	}
 
-	message, err := c.repoConversation.GetMessageById(ctx, id)
+	message, err := c.ownMessage(ctx, userId, conversationId, id, false)
 	if err != nil {
-		logger.Errorf("Update.GetCategoryByID fail, id: %s, error: %s", id, err)
+		return nil, err
 	}
 
-	utils.MapStruct(message, req)
+	message.Content = req.Content
 	err = c.repoConversation.UpdateMessage(ctx, message)
 	if err != nil {
 		logger.Errorf("Update fail, id: %s, error: %s", id, err)
@@ -70,7 +300,12 @@ func (c *ConversationService) UpdateMessage(ctx context.Context, id string, req
 	return message, nil
 }
 
-func (c *ConversationService) DeleteMessage(ctx context.Context, messageId string) (*model.Message, error) {
+func (c *ConversationService) DeleteMessage(ctx context.Context, userId string, conversationId string, messageId string) (*model.Message, error) {
+	// Moderators of a group may delete any message
+	if _, err := c.ownMessage(ctx, userId, conversationId, messageId, true); err != nil {
+		return nil, err
+	}
+
 	err := c.repoConversation.DeleteMessage(ctx, messageId)
 	if err != nil {
 		return nil, err
@@ -81,29 +316,201 @@ func (c *ConversationService) DeleteMessage(ctx context.Context, messageId strin
 		return nil, err
 	}
 
+	var res dto.Message
+	utils.MapStruct(&res, message)
+	c.notifier.EmitToRoom(conversationId, "message_deleted", res)
+
 	return message, nil
 }
 
-func (c *ConversationService) GetConversationsOrganizer(ctx context.Context, organizerId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error) {
-	reviews, pagination, err := c.repoConversation.GetConversationByOrganizer(ctx, organizerId, req)
+func (c *ConversationService) GetConversationsOrganizer(ctx context.Context, userId string, organizerId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error) {
+	if userId != organizerId {
+		return nil, nil, errors.New(messages.NotConversationOwner)
+	}
+
+	conversations, pagination, err := c.repoConversation.GetConversationByOrganizer(ctx, organizerId, req)
 	if err != nil {
 		return nil, nil, err
 	}
-	return reviews, pagination, nil
+
+	if err := c.fillReadState(ctx, userId, conversations); err != nil {
+		return nil, nil, err
+	}
+	return conversations, pagination, nil
 }
 
-func (c *ConversationService) GetConversationsByUser(ctx context.Context, userId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error) {
-	reviews, pagination, err := c.repoConversation.GetConversationByUser(ctx, userId, req)
+func (c *ConversationService) GetConversationsByUser(ctx context.Context, currentUserId string, userId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error) {
+	if currentUserId != userId {
+		return nil, nil, errors.New(messages.NotConversationOwner)
+	}
+
+	conversations, pagination, err := c.repoConversation.GetConversationByUser(ctx, userId, req)
 	if err != nil {
 		return nil, nil, err
 	}
-	return reviews, pagination, nil
+
+	if err := c.fillReadState(ctx, userId, conversations); err != nil {
+		return nil, nil, err
+	}
+	return conversations, pagination, nil
 }
 
-func (c *ConversationService) GetMessagesByConversation(ctx context.Context, conversationID string, req *dto.ListMessageReq) ([]*model.Message, *paging.Pagination, error) {
+func (c *ConversationService) GetMessagesByConversation(ctx context.Context, userId string, conversationID string, req *dto.ListMessageReq) ([]*model.Message, *paging.Pagination, error) {
+	conversation, err := c.authorize(ctx, userId, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	messages, pagination, err := c.repoConversation.GetMessageByConversation(ctx, conversationID, req)
 	if err != nil {
 		return nil, nil, err
 	}
+
+	// Delivery and read states are only tracked between the two participants of a direct conversation
+	if conversation.Type != model.ConversationTypeGroup {
+		var other *model.ConversationParticipant
+		if participant, err := c.repoConversation.GetParticipant(ctx, conversation.ID, c.otherParticipantId(conversation, userId)); err == nil {
+			other = participant
+		}
+
+		for _, message := range messages {
+			if message.SenderId == userId {
+				message.Status = messageStatus(message, other)
+			}
+		}
+	}
+
+	if _, err := c.MarkAsDelivered(ctx, userId, conversationID); err != nil {
+		logger.Errorf("GetMessagesByConversation.MarkAsDelivered fail, id: %s, error: %s", conversationID, err)
+	}
+
 	return messages, pagination, nil
 }
+
+func (c *ConversationService) JoinConversation(ctx context.Context, userId string, id string) error {
+	_, err := c.authorize(ctx, userId, id)
+	return err
+}
+
+// authorize returns the conversation when the user is one of its two participants, or a member of the group
+func (c *ConversationService) authorize(ctx context.Context, userId string, conversationId string) (*model.Conversation, error) {
+	conversation, err := c.repoConversation.GetConversationById(ctx, conversationId)
+	if err != nil {
+		logger.Errorf("GetConversationById fail, id: %s, error: %s", conversationId, err)
+		return nil, errors.New(messages.ConversationNotFound)
+	}
+
+	if userId == "" {
+		return nil, errors.New(messages.NotConversationMember)
+	}
+
+	if conversation.Type == model.ConversationTypeGroup {
+		if _, err := c.groupMember(ctx, conversation, userId); err != nil {
+			return nil, err
+		}
+		return conversation, nil
+	}
+
+	if userId != conversation.UserId && userId != conversation.OrganizerId {
+		return nil, errors.New(messages.NotConversationMember)
+	}
+
+	return conversation, nil
+}
+
+func (c *ConversationService) otherParticipantId(conversation *model.Conversation, userId string) string {
+	if userId == conversation.UserId {
+		return conversation.OrganizerId
+	}
+
+	return conversation.UserId
+}
+
+// participant returns the settings of the user in the conversation, conversations created before
+// participants were tracked get their row on first use
+func (c *ConversationService) participant(ctx context.Context, conversation *model.Conversation, userId string) (*model.ConversationParticipant, error) {
+	participant, err := c.repoConversation.GetParticipant(ctx, conversation.ID, userId)
+	if err == nil {
+		return participant, nil
+	}
+
+	participant = &model.ConversationParticipant{ConversationId: conversation.ID, UserId: userId}
+	if err := c.repoConversation.CreateParticipant(ctx, participant); err != nil {
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+// ownMessage returns the message when it belongs to the conversation and was sent by the user,
+// or when moderate is set and the user moderates the group
+func (c *ConversationService) ownMessage(ctx context.Context, userId string, conversationId string, messageId string, moderate bool) (*model.Message, error) {
+	conversation, err := c.authorize(ctx, userId, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := c.repoConversation.GetMessageById(ctx, messageId)
+	if err != nil || message.ConversationId != conversationId {
+		return nil, errors.New(messages.MessageNotFound)
+	}
+
+	if message.SenderId == userId {
+		return message, nil
+	}
+
+	if moderate && conversation.Type == model.ConversationTypeGroup {
+		if participant, err := c.participant(ctx, conversation, userId); err == nil && participant.CanModerate() {
+			return message, nil
+		}
+	}
+
+	return nil, errors.New(messages.NotMessageSender)
+}
+
+// fillReadState sets the unread count of the user and whether the organizer still has to answer
+func (c *ConversationService) fillReadState(ctx context.Context, userId string, conversations []*model.Conversation) error {
+	ids := make([]string, 0, len(conversations))
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+	}
+
+	counts, err := c.repoConversation.CountUnreadMessages(ctx, userId, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, conversation := range conversations {
+		conversation.UnreadCount = counts[conversation.ID]
+		conversation.IsUnanswered = conversation.Type != model.ConversationTypeGroup &&
+			conversation.LastMessage != nil && conversation.LastMessage.SenderId != conversation.OrganizerId
+	}
+
+	return nil
+}
+
+func messageStatus(message *model.Message, receiver *model.ConversationParticipant) string {
+	switch {
+	case receiver == nil:
+		return model.MessageStatusSent
+	case receiver.LastReadAt != nil && !message.CreatedAt.After(*receiver.LastReadAt):
+		return model.MessageStatusRead
+	case receiver.LastDeliveredAt != nil && !message.CreatedAt.After(*receiver.LastDeliveredAt):
+		return model.MessageStatusDelivered
+	default:
+		return model.MessageStatusSent
+	}
+}
+
+func readReceipt(participant *model.ConversationParticipant) dto.ReadReceiptRes {
+	var res dto.ReadReceiptRes
+	res.ConversationId = participant.ConversationId
+	res.UserId = participant.UserId
+	if participant.LastReadMessageId != nil {
+		res.LastReadMessageId = *participant.LastReadMessageId
+	}
+	res.LastReadAt = participant.LastReadAt
+	res.LastDeliveredAt = participant.LastDeliveredAt
+
+	return res
+}
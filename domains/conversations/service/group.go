@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/domains/conversations/dto"
+	"gohub/domains/conversations/model"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+	"gohub/pkg/utils"
+)
+
+// CreateGroup opens the attendee group of the event, every current ticket holder joins it.
+// Calling it again returns the existing group.
+func (c *ConversationService) CreateGroup(ctx context.Context, userId string, eventId string, req *dto.GroupSettingsReq) (*model.Conversation, error) {
+	event, err := c.repoConversation.GetEventById(ctx, eventId)
+	if err != nil {
+		logger.Errorf("CreateGroup.GetEventById fail, id: %s, error: %s", eventId, err)
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		return nil, errors.New(messages.NotEventOwner)
+	}
+
+	if group, err := c.repoConversation.GetGroupByEvent(ctx, eventId); err == nil {
+		return group, nil
+	}
+
+	group := model.Conversation{
+		EventId:            event.ID,
+		UserId:             event.UserId,
+		OrganizerId:        event.UserId,
+		Type:               model.ConversationTypeGroup,
+		IsAnnouncementOnly: req.IsAnnouncementOnly,
+		Participants: []*model.ConversationParticipant{
+			{UserId: event.UserId, Role: model.ParticipantRoleOwner},
+		},
+	}
+	if err := c.repoConversation.CreateConversation(ctx, &group); err != nil {
+		logger.Errorf("CreateGroup fail, event: %s, error: %s", eventId, err)
+		return nil, err
+	}
+
+	if err := c.repoConversation.JoinTicketHolders(ctx, group.ID, event.ID); err != nil {
+		logger.Errorf("CreateGroup.JoinTicketHolders fail, id: %s, error: %s", group.ID, err)
+		return nil, err
+	}
+
+	return c.repoConversation.GetGroupByEvent(ctx, eventId)
+}
+
+func (c *ConversationService) GetGroupsByUser(ctx context.Context, userId string, req *dto.ListConversationReq) ([]*model.Conversation, *paging.Pagination, error) {
+	conversations, pagination, err := c.repoConversation.GetGroupsByUser(ctx, userId, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := c.fillReadState(ctx, userId, conversations); err != nil {
+		return nil, nil, err
+	}
+	return conversations, pagination, nil
+}
+
+func (c *ConversationService) UpdateGroupSettings(ctx context.Context, userId string, id string, req *dto.GroupSettingsReq) (*model.Conversation, error) {
+	conversation, _, err := c.moderator(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation.IsAnnouncementOnly = req.IsAnnouncementOnly
+	if err := c.repoConversation.UpdateConversation(ctx, conversation); err != nil {
+		logger.Errorf("UpdateGroupSettings fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	var res dto.Conversation
+	utils.MapStruct(&res, conversation)
+	c.notifier.EmitToRoom(conversation.ID, "conversation_updated", res)
+
+	return conversation, nil
+}
+
+func (c *ConversationService) GetParticipants(ctx context.Context, userId string, id string, req *dto.ListParticipantReq) ([]*model.ConversationParticipant, *paging.Pagination, error) {
+	conversation, err := c.authorize(ctx, userId, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if conversation.Type != model.ConversationTypeGroup {
+		return nil, nil, errors.New(messages.NotGroupConversation)
+	}
+
+	return c.repoConversation.GetParticipants(ctx, conversation.ID, req)
+}
+
+// ModerateParticipant changes the role, mute or ban state of a participant. Only the owner changes roles
+// or moderates other moderators, and the owner cannot be moderated.
+func (c *ConversationService) ModerateParticipant(ctx context.Context, userId string, id string, participantId string, req *dto.ModerateParticipantReq) (*model.ConversationParticipant, error) {
+	if err := c.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	conversation, actor, err := c.moderator(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := c.repoConversation.GetParticipant(ctx, conversation.ID, participantId)
+	if err != nil {
+		// Ticket holders that never opened the group can still be moderated
+		hasTicket, ticketErr := c.repoConversation.HasTicket(ctx, conversation.EventId, participantId)
+		if ticketErr != nil || !hasTicket {
+			return nil, errors.New(messages.ParticipantNotFound)
+		}
+
+		target = &model.ConversationParticipant{ConversationId: conversation.ID, UserId: participantId, Role: model.ParticipantRoleMember}
+		if err := c.repoConversation.CreateParticipant(ctx, target); err != nil {
+			return nil, err
+		}
+	}
+
+	if target.Role == model.ParticipantRoleOwner {
+		return nil, errors.New(messages.CannotModerateOwner)
+	}
+
+	if actor.Role != model.ParticipantRoleOwner && (req.Role != nil || target.Role == model.ParticipantRoleModerator) {
+		return nil, errors.New(messages.NotGroupModerator)
+	}
+
+	if req.Role != nil {
+		target.Role = *req.Role
+	}
+	if req.IsSilenced != nil {
+		target.IsSilenced = *req.IsSilenced
+	}
+	if req.IsBanned != nil {
+		target.IsBanned = *req.IsBanned
+	}
+
+	if err := c.repoConversation.UpdateParticipant(ctx, target); err != nil {
+		logger.Errorf("ModerateParticipant fail, id: %s, participant: %s, error: %s", id, participantId, err)
+		return nil, err
+	}
+
+	var res dto.GroupParticipant
+	utils.MapStruct(&res, target)
+	c.notifier.EmitToRoom(conversation.ID, "participant_updated", res)
+
+	if target.IsBanned {
+		c.notifier.LeaveRoom(target.UserId, conversation.ID)
+		c.notifier.EmitToUser(target.UserId, "conversation_banned", dto.ConversationEventReq{ConversationId: conversation.ID})
+	}
+
+	return target, nil
+}
+
+// groupMember returns the participant row of the user in the group. Ticket holders join the group
+// automatically the first time they access it, banned participants are refused.
+func (c *ConversationService) groupMember(ctx context.Context, conversation *model.Conversation, userId string) (*model.ConversationParticipant, error) {
+	participant, err := c.repoConversation.GetParticipant(ctx, conversation.ID, userId)
+	if err == nil {
+		if participant.IsBanned {
+			return nil, errors.New(messages.NotConversationMember)
+		}
+		return participant, nil
+	}
+
+	hasTicket, err := c.repoConversation.HasTicket(ctx, conversation.EventId, userId)
+	if err != nil {
+		return nil, err
+	}
+	if !hasTicket {
+		return nil, errors.New(messages.NotConversationMember)
+	}
+
+	participant = &model.ConversationParticipant{ConversationId: conversation.ID, UserId: userId, Role: model.ParticipantRoleMember}
+	if err := c.repoConversation.CreateParticipant(ctx, participant); err != nil {
+		// A concurrent request may have joined the user first
+		if existing, findErr := c.repoConversation.GetParticipant(ctx, conversation.ID, userId); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+// moderator returns the group and the participant of the user when they can moderate it
+func (c *ConversationService) moderator(ctx context.Context, userId string, id string) (*model.Conversation, *model.ConversationParticipant, error) {
+	conversation, err := c.authorize(ctx, userId, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if conversation.Type != model.ConversationTypeGroup {
+		return nil, nil, errors.New(messages.NotGroupConversation)
+	}
+
+	participant, err := c.participant(ctx, conversation, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !participant.CanModerate() {
+		return nil, nil, errors.New(messages.NotGroupModerator)
+	}
+
+	return conversation, participant, nil
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/domains/conversations/dto"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+	"time"
+)
+
+// SearchMessages searches the messages of every conversation the user takes part in, the to date is inclusive
+func (c *ConversationService) SearchMessages(ctx context.Context, userId string, req *dto.SearchMessageReq) ([]*dto.SearchMessage, *paging.Pagination, error) {
+	if err := c.validator.ValidateStruct(req); err != nil {
+		return nil, nil, errors.New(messages.InvalidSearchQuery)
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		return nil, nil, errors.New(messages.InvalidDateRange)
+	}
+
+	if !req.To.IsZero() {
+		req.To = req.To.Add(24 * time.Hour)
+	}
+
+	return c.repoConversation.SearchMessages(ctx, userId, req)
+}
@@ -1,19 +1,32 @@
 package dto
 
 import (
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
 	"mime/multipart"
+	"time"
 )
 
 type Coupon struct {
-	ID              string  `json:"id"`
-	CoverImageUrl   string  `json:"coverImageUrl" gorm:"not null"`
-	Name            string  `json:"name" gorm:"not null"`
-	Description     string  `json:"description" gorm:"not null"`
-	MinQuantity     int     `json:"minQuantity"`
-	MinPrice        float64 `json:"minPrice"`
-	PercentageValue float64 `json:"percentageValue"`
-	ExpireDate      string  `json:"expireDate" gorm:"not null"`
+	ID              string              `json:"id"`
+	Code            string              `json:"code"`
+	CoverImageUrl   string              `json:"coverImageUrl" gorm:"not null"`
+	Name            string              `json:"name" gorm:"not null"`
+	Description     string              `json:"description" gorm:"not null"`
+	Type            string              `json:"type"`
+	PercentageValue float64             `json:"percentageValue"`
+	AmountOff       money.Money         `json:"amountOff"`
+	MinQuantity     int                 `json:"minQuantity"`
+	MinPrice        money.Money         `json:"minPrice"`
+	UsageLimit      int                 `json:"usageLimit"`
+	PerUserLimit    int                 `json:"perUserLimit"`
+	StartsAt        *time.Time          `json:"startsAt"`
+	EndsAt          *time.Time          `json:"endsAt"`
+	TicketTypes     []*CouponTicketType `json:"ticketTypes"`
+}
+
+type CouponTicketType struct {
+	TicketTypeId string `json:"ticketTypeId"`
 }
 
 type ListCouponReq struct {
@@ -30,25 +43,111 @@ type ListCouponRes struct {
 	Pagination *paging.Pagination `json:"metadata"`
 }
 
+// CreateCouponReq creates a coupon, a coupon without a code gets a generated one
 type CreateCouponReq struct {
-	UserId          string                `form:"userId"`
-	Image           *multipart.FileHeader `form:"image"`
-	Name            string                `form:"name" validate:"required"`
-	Description     string                `form:"description" validate:"required"`
-	MinQuantity     int                   `form:"minQuantity" validate:"required"`
-	MinPrice        int                   `form:"minPrice" validate:"required"`
-	PercentageValue float64               `form:"percentageValue" validate:"required"`
-	ExpireDate      string                `form:"expireDate" validate:"required"`
+	UserId      string                `form:"userId"`
+	Image       *multipart.FileHeader `form:"image"`
+	Name        string                `form:"name" validate:"required"`
+	Description string                `form:"description" validate:"required"`
+	CouponTerms
 }
 
 type UpdateCouponReq struct {
-	ID              string                `form:"id" validate:"required"`
-	CoverImageUrl   string                `form:"coverImageUrl" validate:"required"`
-	Image           *multipart.FileHeader `form:"image"`
-	Name            string                `form:"name" validate:"required"`
-	Description     string                `form:"description" validate:"required"`
-	MinQuantity     int                   `form:"minQuantity" validate:"required"`
-	MinPrice        int                   `form:"minPrice" validate:"required"`
-	PercentageValue float64               `form:"percentageValue" validate:"required"`
-	ExpireDate      string                `form:"expireDate" validate:"required"`
+	ID            string                `form:"id" validate:"required"`
+	CoverImageUrl string                `form:"coverImageUrl" validate:"required"`
+	Image         *multipart.FileHeader `form:"image"`
+	Name          string                `form:"name" validate:"required"`
+	Description   string                `form:"description" validate:"required"`
+	CouponTerms
+}
+
+// CouponTerms are the conditions of a coupon. Amounts are in major units of the currency, VND by default.
+// Dates are RFC 3339 or a plain date, an end date without a time is valid until the end of that day.
+type CouponTerms struct {
+	Code            string   `form:"code" validate:"omitempty,max=32"`
+	Type            string   `form:"type" validate:"omitempty,oneof=Percentage FixedAmount"`
+	PercentageValue float64  `form:"percentageValue" validate:"omitempty,gt=0,lte=100"`
+	AmountOff       string   `json:"-" form:"amountOff"`
+	MinQuantity     int      `form:"minQuantity" validate:"min=0"`
+	MinPrice        string   `json:"-" form:"minPrice"`
+	Currency        string   `json:"-" form:"currency"`
+	UsageLimit      int      `form:"usageLimit" validate:"min=0"`
+	PerUserLimit    int      `form:"perUserLimit" validate:"min=0"`
+	StartsAt        string   `json:"-" form:"startsAt"`
+	EndsAt          string   `json:"-" form:"endsAt"`
+	TicketTypeIds   []string `json:"-" form:"ticketTypeIds"`
+}
+
+// ValidateCouponReq previews what a code takes off a cart for an event, nothing is reserved
+type ValidateCouponReq struct {
+	EventId     string        `json:"eventId" validate:"required"`
+	Code        string        `json:"code" validate:"required"`
+	TicketItems []*CouponItem `json:"tickets" validate:"required,min=1,dive"`
+}
+
+type CouponItem struct {
+	TicketTypeId string `json:"ticketTypeId" validate:"required"`
+	Quantity     int    `json:"quantity" validate:"required,min=1"`
+}
+
+type CouponPreview struct {
+	CouponId string      `json:"couponId"`
+	Code     string      `json:"code"`
+	Type     string      `json:"type"`
+	Subtotal money.Money `json:"subtotal"`
+	Discount money.Money `json:"discount"`
+	Total    money.Money `json:"total"`
+}
+
+// GenerateCodesReq generates single use codes from a coupon, the prefix is put in front of the random part
+type GenerateCodesReq struct {
+	Count  int    `json:"count" validate:"required,min=1,max=1000"`
+	Prefix string `json:"prefix" validate:"omitempty,max=12"`
+	Length int    `json:"length" validate:"omitempty,min=6,max=16"`
+}
+
+type CouponCode struct {
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ListCouponCodeRes struct {
+	Codes []*CouponCode `json:"items"`
+}
+
+// CouponCodeRow is a generated code with the order that redeemed it, if any
+type CouponCodeRow struct {
+	Code       string
+	CreatedAt  time.Time
+	PaymentId  *string
+	RedeemedAt *time.Time
+}
+
+// CouponAnalyticsReq selects the redemptions the analytics cover, the last 30 days by day by default
+type CouponAnalyticsReq struct {
+	StartDate string `json:"-" form:"startDate"`
+	EndDate   string `json:"-" form:"endDate"`
+	Interval  string `json:"-" form:"interval" validate:"omitempty,oneof=day week month"`
+}
+
+// RedemptionStats sums the redemptions of settled orders in one currency, over a period or an event when
+// those are set
+type RedemptionStats struct {
+	Period      *time.Time  `json:"period,omitempty"`
+	EventId     string      `json:"eventId,omitempty"`
+	EventName   string      `json:"eventName,omitempty"`
+	Currency    string      `json:"currency"`
+	Redemptions int64       `json:"redemptions"`
+	Discount    money.Money `json:"discount"`
+	Revenue     money.Money `json:"revenue"`
+}
+
+type CouponAnalytics struct {
+	CouponId  string             `json:"couponId"`
+	StartDate time.Time          `json:"startDate"`
+	EndDate   time.Time          `json:"endDate"`
+	Interval  string             `json:"interval"`
+	Totals    []*RedemptionStats `json:"totals"`
+	Timeline  []*RedemptionStats `json:"timeline"`
+	TopEvents []*RedemptionStats `json:"topEvents"`
 }
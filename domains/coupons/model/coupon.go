@@ -1,6 +1,9 @@
 package model
 
 import (
+	"errors"
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
 	"time"
 
 	modelUser "gohub/domains/users/model"
@@ -9,22 +12,48 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	CouponTypePercentage  = "Percentage"
+	CouponTypeFixedAmount = "FixedAmount"
+)
+
+// Coupon is a discount an organizer hands out under a code. Its code is unique among the coupons of the
+// organizer, a zero limit means no limit and a missing start or end leaves the window open on that side.
 type Coupon struct {
-	ID                 string          `json:"id" gorm:"unique;not null;index;primary_key"`
-	CouponId           string          `json:"couponId"`
-	UserId             string          `json:"userId" gorm:"not null"`
-	User               *modelUser.User `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	CoverImageUrl      string          `json:"coverImageUrl" gorm:"not null"`
-	CoverImageFileName string          `json:"coverImageFileName" gorm:"not null"`
-	Name               string          `json:"name" gorm:"not null"`
-	Description        string          `json:"description" gorm:"not null"`
-	MinQuantity        int             `json:"minQuantity"`
-	MinPrice           float64         `json:"minPrice"`
-	PercentageValue    float64         `json:"percentageValue"`
-	ExpireDate         string          `json:"expireDate" gorm:"not null"`
-	CreatedAt          time.Time       `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt          time.Time       `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt          gorm.DeletedAt  `json:"deletedAt" gorm:"index"`
+	ID                 string              `json:"id" gorm:"unique;not null;index;primary_key"`
+	Code               string              `json:"code" gorm:"type:varchar(32);not null;default:''"`
+	UserId             string              `json:"userId" gorm:"not null"`
+	User               *modelUser.User     `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CoverImageUrl      string              `json:"coverImageUrl" gorm:"not null"`
+	CoverImageFileName string              `json:"coverImageFileName" gorm:"not null"`
+	Name               string              `json:"name" gorm:"not null"`
+	Description        string              `json:"description" gorm:"not null"`
+	Type               string              `json:"type" gorm:"type:varchar(20);not null;default:'Percentage'"`
+	PercentageValue    float64             `json:"percentageValue"`
+	AmountOff          money.Money         `json:"amountOff" gorm:"embedded;embeddedPrefix:amount_off_"`
+	MinQuantity        int                 `json:"minQuantity"`
+	MinPrice           money.Money         `json:"minPrice" gorm:"embedded;embeddedPrefix:min_price_"`
+	UsageLimit         int                 `json:"usageLimit" gorm:"not null;default:0"`
+	PerUserLimit       int                 `json:"perUserLimit" gorm:"not null;default:0"`
+	StartsAt           *time.Time          `json:"startsAt"`
+	EndsAt             *time.Time          `json:"endsAt"`
+	TicketTypes        []*CouponTicketType `json:"ticketTypes" gorm:"foreignKey:CouponId;constraint:OnDelete:CASCADE;"`
+	CreatedAt          time.Time           `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time           `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt          gorm.DeletedAt      `json:"deletedAt" gorm:"index"`
+}
+
+// CouponTicketType limits a coupon to a ticket type, a coupon without any applies to every ticket type
+type CouponTicketType struct {
+	CouponId     string `json:"couponId" gorm:"primaryKey"`
+	TicketTypeId string `json:"ticketTypeId" gorm:"primaryKey"`
+}
+
+// CouponLine is a line of a cart a coupon is checked against
+type CouponLine struct {
+	TicketTypeId string
+	Quantity     int
+	Total        money.Money
 }
 
 func (c *Coupon) BeforeCreate(tx *gorm.DB) error {
@@ -36,3 +65,85 @@ func (c *Coupon) BeforeCreate(tx *gorm.DB) error {
 func (Coupon) TableName() string {
 	return "coupons"
 }
+
+func (CouponTicketType) TableName() string {
+	return "coupon_ticket_types"
+}
+
+// AppliesTo reports whether the coupon takes anything off tickets of the ticket type
+func (c *Coupon) AppliesTo(ticketTypeId string) bool {
+	if len(c.TicketTypes) == 0 {
+		return true
+	}
+
+	for _, ticketType := range c.TicketTypes {
+		if ticketType.TicketTypeId == ticketTypeId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckUsage tells whether the coupon can be redeemed once more under the code, a generated code is only
+// good for one order
+func (c *Coupon) CheckUsage(code string, usage CouponUsage) error {
+	if code != c.Code && usage.ByCode > 0 {
+		return errors.New(messages.CouponCodeUsed)
+	}
+
+	if c.UsageLimit > 0 && usage.Total >= int64(c.UsageLimit) {
+		return errors.New(messages.CouponUsageLimitReached)
+	}
+
+	if c.PerUserLimit > 0 && usage.ByUser >= int64(c.PerUserLimit) {
+		return errors.New(messages.CouponUserLimitReached)
+	}
+
+	return nil
+}
+
+// Discount works out what the coupon takes off the lines it applies to. When the coupon cannot be used on
+// the cart it returns why, the usage limits are left to the caller since they need the redemptions.
+func (c *Coupon) Discount(lines []*CouponLine, now time.Time) (money.Money, error) {
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return money.Money{}, errors.New(messages.CouponNotStarted)
+	}
+
+	if c.EndsAt != nil && !now.Before(*c.EndsAt) {
+		return money.Money{}, errors.New(messages.CouponExpired)
+	}
+
+	var eligible money.Money
+	var quantity int
+	for _, line := range lines {
+		if c.AppliesTo(line.TicketTypeId) {
+			eligible = eligible.Add(line.Total)
+			quantity += line.Quantity
+		}
+	}
+
+	if quantity == 0 || quantity < c.MinQuantity {
+		return money.Money{}, errors.New(messages.CouponNotApplicable)
+	}
+
+	// A minimum price or an amount in another currency can never be met
+	if !c.MinPrice.IsZero() && (c.MinPrice.Currency != eligible.Currency || eligible.Cmp(c.MinPrice) < 0) {
+		return money.Money{}, errors.New(messages.CouponNotApplicable)
+	}
+
+	switch c.Type {
+	case CouponTypeFixedAmount:
+		if !c.AmountOff.IsPositive() || c.AmountOff.Currency != eligible.Currency {
+			return money.Money{}, errors.New(messages.CouponNotApplicable)
+		}
+
+		return c.AmountOff.Min(eligible), nil
+	default:
+		if c.PercentageValue <= 0 || c.PercentageValue > 100 {
+			return money.Money{}, errors.New(messages.CouponNotApplicable)
+		}
+
+		return eligible.Percent(c.PercentageValue), nil
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CouponCode is a single use code generated from a coupon, it redeems the coupon it was generated from.
+// Codes are unique among all the codes of the organizer, the ones of their coupons included.
+type CouponCode struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	CouponId  string    `json:"couponId" gorm:"not null;index"`
+	Coupon    *Coupon   `json:"coupon,omitempty" gorm:"constraint:OnDelete:CASCADE;"`
+	UserId    string    `json:"userId" gorm:"not null;uniqueIndex:idx_coupon_codes_user_code"`
+	Code      string    `json:"code" gorm:"type:varchar(32);not null;uniqueIndex:idx_coupon_codes_user_code"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+// CouponUsage counts the redemptions of a coupon that hold, in total, by a user and of a single code
+type CouponUsage struct {
+	Total  int64
+	ByUser int64
+	ByCode int64
+}
+
+func (c *CouponCode) BeforeCreate(tx *gorm.DB) error {
+	c.ID = uuid.New().String()
+	return nil
+}
+
+func (CouponCode) TableName() string {
+	return "coupon_codes"
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestCouponDiscount(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	lines := []*CouponLine{
+		{TicketTypeId: "general", Quantity: 2, Total: money.New(200000, "VND")},
+		{TicketTypeId: "vip", Quantity: 1, Total: money.New(500000, "VND")},
+	}
+
+	tests := []struct {
+		name    string
+		coupon  Coupon
+		lines   []*CouponLine
+		want    money.Money
+		wantErr string
+	}{
+		{
+			name:   "percentage of the whole cart",
+			coupon: Coupon{Type: CouponTypePercentage, PercentageValue: 10},
+			want:   money.New(70000, "VND"),
+		},
+		{
+			name:   "percentage rounds to a minor unit",
+			coupon: Coupon{Type: CouponTypePercentage, PercentageValue: 12.5},
+			lines:  []*CouponLine{{TicketTypeId: "general", Quantity: 1, Total: money.New(1999, "USD")}},
+			want:   money.New(250, "USD"),
+		},
+		{
+			name:   "percentage of the ticket types it applies to",
+			coupon: Coupon{Type: CouponTypePercentage, PercentageValue: 50, TicketTypes: []*CouponTicketType{{TicketTypeId: "vip"}}},
+			want:   money.New(250000, "VND"),
+		},
+		{
+			name:   "fixed amount",
+			coupon: Coupon{Type: CouponTypeFixedAmount, AmountOff: money.New(100000, "VND")},
+			want:   money.New(100000, "VND"),
+		},
+		{
+			name:   "fixed amount is capped at the eligible total",
+			coupon: Coupon{Type: CouponTypeFixedAmount, AmountOff: money.New(300000, "VND"), TicketTypes: []*CouponTicketType{{TicketTypeId: "general"}}},
+			want:   money.New(200000, "VND"),
+		},
+		{
+			name:    "fixed amount in another currency",
+			coupon:  Coupon{Type: CouponTypeFixedAmount, AmountOff: money.New(500, "USD")},
+			wantErr: messages.CouponNotApplicable,
+		},
+		{
+			name:    "fixed amount without an amount",
+			coupon:  Coupon{Type: CouponTypeFixedAmount},
+			wantErr: messages.CouponNotApplicable,
+		},
+		{
+			name:    "percentage out of range",
+			coupon:  Coupon{Type: CouponTypePercentage, PercentageValue: 120},
+			wantErr: messages.CouponNotApplicable,
+		},
+		{
+			name:    "no ticket type it applies to",
+			coupon:  Coupon{Type: CouponTypePercentage, PercentageValue: 10, TicketTypes: []*CouponTicketType{{TicketTypeId: "student"}}},
+			wantErr: messages.CouponNotApplicable,
+		},
+		{
+			name:   "minimum quantity met",
+			coupon: Coupon{Type: CouponTypePercentage, PercentageValue: 10, MinQuantity: 3},
+			want:   money.New(70000, "VND"),
+		},
+		{
+			name:    "minimum quantity counts the eligible tickets only",
+			coupon:  Coupon{Type: CouponTypePercentage, PercentageValue: 10, MinQuantity: 2, TicketTypes: []*CouponTicketType{{TicketTypeId: "vip"}}},
+			wantErr: messages.CouponNotApplicable,
+		},
+		{
+			name:   "minimum price met",
+			coupon: Coupon{Type: CouponTypePercentage, PercentageValue: 10, MinPrice: money.New(700000, "VND")},
+			want:   money.New(70000, "VND"),
+		},
+		{
+			name:    "minimum price not met",
+			coupon:  Coupon{Type: CouponTypePercentage, PercentageValue: 10, MinPrice: money.New(700001, "VND")},
+			wantErr: messages.CouponNotApplicable,
+		},
+		{
+			name:    "minimum price in another currency",
+			coupon:  Coupon{Type: CouponTypePercentage, PercentageValue: 10, MinPrice: money.New(100, "USD")},
+			wantErr: messages.CouponNotApplicable,
+		},
+		{
+			name:   "inside the window",
+			coupon: Coupon{Type: CouponTypePercentage, PercentageValue: 10, StartsAt: &before, EndsAt: &after},
+			want:   money.New(70000, "VND"),
+		},
+		{
+			name:   "starts now",
+			coupon: Coupon{Type: CouponTypePercentage, PercentageValue: 10, StartsAt: &now},
+			want:   money.New(70000, "VND"),
+		},
+		{
+			name:    "not started",
+			coupon:  Coupon{Type: CouponTypePercentage, PercentageValue: 10, StartsAt: &after},
+			wantErr: messages.CouponNotStarted,
+		},
+		{
+			name:    "ends now",
+			coupon:  Coupon{Type: CouponTypePercentage, PercentageValue: 10, EndsAt: &now},
+			wantErr: messages.CouponExpired,
+		},
+		{
+			name:    "empty cart",
+			coupon:  Coupon{Type: CouponTypePercentage, PercentageValue: 10},
+			lines:   []*CouponLine{},
+			wantErr: messages.CouponNotApplicable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := tt.lines
+			if cart == nil {
+				cart = lines
+			}
+
+			got, err := tt.coupon.Discount(cart, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Discount() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Discount() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Discount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCouponCheckUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		coupon  Coupon
+		code    string
+		usage   CouponUsage
+		wantErr string
+	}{
+		{name: "no limits", coupon: Coupon{Code: "SUMMER"}, code: "SUMMER", usage: CouponUsage{Total: 1000, ByUser: 10}},
+		{name: "below the usage limit", coupon: Coupon{Code: "SUMMER", UsageLimit: 10}, code: "SUMMER", usage: CouponUsage{Total: 9}},
+		{name: "usage limit reached", coupon: Coupon{Code: "SUMMER", UsageLimit: 10}, code: "SUMMER", usage: CouponUsage{Total: 10}, wantErr: messages.CouponUsageLimitReached},
+		{name: "below the per user limit", coupon: Coupon{Code: "SUMMER", PerUserLimit: 2}, code: "SUMMER", usage: CouponUsage{Total: 50, ByUser: 1}},
+		{name: "per user limit reached", coupon: Coupon{Code: "SUMMER", PerUserLimit: 2}, code: "SUMMER", usage: CouponUsage{Total: 50, ByUser: 2}, wantErr: messages.CouponUserLimitReached},
+		{name: "unused generated code", coupon: Coupon{Code: "SUMMER"}, code: "SUMMER-7KQ2", usage: CouponUsage{Total: 5, ByCode: 0}},
+		{name: "used generated code", coupon: Coupon{Code: "SUMMER"}, code: "SUMMER-7KQ2", usage: CouponUsage{Total: 5, ByCode: 1}, wantErr: messages.CouponCodeUsed},
+		{name: "main code is reused", coupon: Coupon{Code: "SUMMER"}, code: "SUMMER", usage: CouponUsage{Total: 5, ByCode: 5}},
+		{name: "generated code still counts toward the usage limit", coupon: Coupon{Code: "SUMMER", UsageLimit: 5}, code: "SUMMER-7KQ2", usage: CouponUsage{Total: 5}, wantErr: messages.CouponUsageLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.coupon.CheckUsage(tt.code, tt.usage)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("CheckUsage() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("CheckUsage() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"gohub/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CouponRedemption records a coupon used on an order. It is written with the order, so the usage limits
+// are counted from the redemptions of orders that are not closed.
+type CouponRedemption struct {
+	ID        string      `json:"id" gorm:"primaryKey"`
+	CouponId  string      `json:"couponId" gorm:"not null;index"`
+	Coupon    *Coupon     `json:"coupon,omitempty" gorm:"constraint:OnDelete:CASCADE;"`
+	Code      string      `json:"code" gorm:"type:varchar(32);not null;default:'';index"`
+	UserId    string      `json:"userId" gorm:"not null;index"`
+	PaymentId string      `json:"paymentId" gorm:"not null;uniqueIndex"`
+	EventId   string      `json:"eventId" gorm:"not null"`
+	Amount    money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	CreatedAt time.Time   `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+func (r *CouponRedemption) BeforeCreate(tx *gorm.DB) error {
+	r.ID = uuid.New().String()
+	return nil
+}
+
+func (CouponRedemption) TableName() string {
+	return "coupon_redemptions"
+}
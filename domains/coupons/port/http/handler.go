@@ -1,8 +1,8 @@
 package http
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"gohub/configs"
 	"gohub/domains/coupons/dto"
 	"gohub/domains/coupons/service"
 	"gohub/internal/libs/logger"
@@ -10,6 +10,7 @@ import (
 	"gohub/pkg/response"
 	"gohub/pkg/utils"
 	"net/http"
+	"time"
 )
 
 type CouponHandler struct {
@@ -129,14 +130,16 @@ func (h *CouponHandler) CreateCoupon(c *gin.Context) {
 	}
 	req.UserId = c.GetString("userId")
 
-	stripeKey := configs.GetConfig().StripeSecretKey
-
-	coupon, err := h.service.CreateCoupon(c, &req, stripeKey)
+	coupon, err := h.service.CreateCoupon(c, &req)
 	if err != nil {
 		logger.Error("Failed to create coupon ", err.Error())
 		switch err.Error() {
-		case messages.CouponNameAlreadyExists:
-			response.Error(c, http.StatusConflict, err, messages.CouponNameAlreadyExists)
+		case messages.CouponNameAlreadyExists, messages.CouponCodeAlreadyExists:
+			response.Error(c, http.StatusConflict, err, err.Error())
+		case messages.UnsupportedCurrency, messages.InvalidPrice, messages.InvalidCouponCode, messages.InvalidCouponValue, messages.InvalidCouponWindow:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.TicketTypeNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.TicketTypeNotFound)
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to create coupon")
 		}
@@ -171,8 +174,12 @@ func (h *CouponHandler) UpdateCoupon(c *gin.Context) {
 	if err != nil {
 		logger.Error("Failed to update coupon ", err.Error())
 		switch err.Error() {
-		case messages.CouponNameAlreadyExists:
-			response.Error(c, http.StatusConflict, err, messages.CouponNameAlreadyExists)
+		case messages.CouponNameAlreadyExists, messages.CouponCodeAlreadyExists:
+			response.Error(c, http.StatusConflict, err, err.Error())
+		case messages.UnsupportedCurrency, messages.InvalidPrice, messages.InvalidCouponCode, messages.InvalidCouponValue, messages.InvalidCouponWindow:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.TicketTypeNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.TicketTypeNotFound)
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to update coupon")
 		}
@@ -207,3 +214,148 @@ func (h *CouponHandler) DeleteCoupon(c *gin.Context) {
 
 	response.JSON(c, http.StatusOK, "Delete coupon successfully")
 }
+
+//		@Summary	 Validate a coupon code
+//	 @Description Previews what a coupon code takes off a cart for an event. The code must belong to a coupon the organizer applied to the event, be within its validity window, meet its minimum quantity and price and be under its usage limits. Nothing is reserved, the coupon is redeemed with the order.
+//		@Tags		 Coupons
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 params	body	dto.ValidateCouponReq	true	"Event, code and ticket quantities"
+//		@Success	 200	{object}	response.Response	"Discount the coupon gives on the cart"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The coupon is not valid for the cart"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Coupon or ticket type not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The coupon reached its usage limit"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/coupons/validate [post]
+func (h *CouponHandler) ValidateCoupon(c *gin.Context) {
+	var req dto.ValidateCouponReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	preview, err := h.service.ValidateCoupon(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to validate coupon ", err.Error())
+		switch err.Error() {
+		case messages.CouponNotFound, messages.TicketTypeNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.CouponNotStarted, messages.CouponExpired, messages.CouponNotApplicable:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.CouponUsageLimitReached, messages.CouponUserLimitReached, messages.CouponCodeUsed:
+			response.Error(c, http.StatusConflict, err, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Failed to validate coupon")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, preview)
+}
+
+//		@Summary	 Generate coupon codes
+//	 @Description Generates single use codes that redeem a coupon, for campaigns that hand out one code per customer. Codes are the prefix followed by random letters and digits and never match another code of the organizer. Only the owner of the coupon can generate codes.
+//		@Tags		 Coupons
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Coupon ID"
+//		@Param		 params	body	dto.GenerateCodesReq	true	"Number of codes, prefix and length of the random part"
+//		@Success	 200	{object}	response.Response	"Generated codes"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid prefix"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the owner of the coupon"
+//		@Failure	 404	{object}	response.Response	"Not Found - Coupon not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/coupons/{id}/codes [post]
+func (h *CouponHandler) GenerateCodes(c *gin.Context) {
+	var req dto.GenerateCodesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	codes, err := h.service.GenerateCodes(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to generate coupon codes ", err.Error())
+		couponError(c, err)
+		return
+	}
+
+	var res dto.ListCouponCodeRes
+	utils.MapStruct(&res.Codes, &codes)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Export coupon codes
+//	 @Description Downloads the generated codes of a coupon as CSV, with the order that redeemed each of them. Only the owner of the coupon can export its codes.
+//		@Tags		 Coupons
+//		@Produce	 text/csv
+//		@Param		 id	path	string	true	"Coupon ID"
+//		@Success	 200	{file}	file	"CSV of the codes"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the owner of the coupon"
+//		@Failure	 404	{object}	response.Response	"Not Found - Coupon not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/coupons/{id}/codes/export [get]
+func (h *CouponHandler) ExportCodes(c *gin.Context) {
+	data, err := h.service.ExportCodes(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to export coupon codes ", err.Error())
+		couponError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=coupon-codes-%s.csv", time.Now().Format("20060102")))
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+//		@Summary	 Coupon analytics
+//	 @Description Reports the redemptions of a coupon on paid orders between two dates, the last 30 days by default: the totals per currency, a timeline by day, week or month and the events it was redeemed on most. Revenue is what the orders were paid minus their refunds. Only the owner of the coupon can see its analytics.
+//		@Tags		 Coupons
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Coupon ID"
+//		@Param		 startDate	query	string	false	"First day, YYYY-MM-DD"
+//		@Param		 endDate	query	string	false	"Last day, YYYY-MM-DD"
+//		@Param		 interval	query	string	false	"day, week or month"
+//		@Success	 200	{object}	response.Response	"Analytics of the coupon"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid date range"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the owner of the coupon"
+//		@Failure	 404	{object}	response.Response	"Not Found - Coupon not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/coupons/{id}/analytics [get]
+func (h *CouponHandler) GetAnalytics(c *gin.Context) {
+	var req dto.CouponAnalyticsReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	analytics, err := h.service.GetAnalytics(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to get coupon analytics ", err.Error())
+		couponError(c, err)
+		return
+	}
+
+	response.JSON(c, http.StatusOK, analytics)
+}
+
+func couponError(c *gin.Context, err error) {
+	switch err.Error() {
+	case messages.CouponNotFound:
+		response.Error(c, http.StatusNotFound, err, messages.CouponNotFound)
+	case messages.NotCouponOwner:
+		response.Error(c, http.StatusForbidden, err, messages.NotCouponOwner)
+	case messages.InvalidCodePrefix, messages.InvalidDateRange:
+		response.Error(c, http.StatusBadRequest, err, err.Error())
+	case messages.CouponCodesExhausted:
+		response.Error(c, http.StatusConflict, err, messages.CouponCodesExhausted)
+	default:
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+	}
+}
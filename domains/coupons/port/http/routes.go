@@ -21,7 +21,11 @@ func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.V
 	{
 		categoryRoute.GET("/", CouponHandler.GetCoupons)
 		categoryRoute.GET("/get-created-coupons", CouponHandler.GetCreatedCoupons)
+		categoryRoute.POST("/validate", CouponHandler.ValidateCoupon)
 		categoryRoute.GET("/:id", CouponHandler.GetCouponById)
+		categoryRoute.POST("/:id/codes", CouponHandler.GenerateCodes)
+		categoryRoute.GET("/:id/codes/export", CouponHandler.ExportCodes)
+		categoryRoute.GET("/:id/analytics", CouponHandler.GetAnalytics)
 		categoryRoute.POST("/", CouponHandler.CreateCoupon)
 		categoryRoute.PUT("/:id", CouponHandler.UpdateCoupon)
 		categoryRoute.DELETE("/:id", CouponHandler.DeleteCoupon)
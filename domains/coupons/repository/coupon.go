@@ -2,11 +2,23 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"gohub/configs"
 	"gohub/database"
 	"gohub/domains/coupons/dto"
 	"gohub/domains/coupons/model"
+	modelEvent "gohub/domains/events/model"
+	modelPayment "gohub/domains/payments/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ICouponRepository interface {
@@ -17,8 +29,21 @@ type ICouponRepository interface {
 	Create(ctx context.Context, coupon *model.Coupon) error
 	Update(ctx context.Context, coupon *model.Coupon) error
 	Delete(ctx context.Context, id string) error
+	GetEventCouponByCode(ctx context.Context, eventId string, code string) (*model.Coupon, error)
+	GetTicketTypesByIds(ctx context.Context, eventId string, ids []string) ([]*modelEvent.TicketType, error)
+	CountOwnedTicketTypes(ctx context.Context, userId string, ids []string) (int64, error)
+	GetUsage(ctx context.Context, couponId string, userId string, code string) (*model.CouponUsage, error)
+	IsCodeGenerated(ctx context.Context, userId string, code string) (bool, error)
+	CreateCodes(ctx context.Context, coupon *model.Coupon, count int, generate func() string) ([]*model.CouponCode, error)
+	GetCodes(ctx context.Context, couponId string) ([]*dto.CouponCodeRow, error)
+	GetRedemptionTotals(ctx context.Context, couponId string, from time.Time, to time.Time) ([]*dto.RedemptionStats, error)
+	GetRedemptionTimeline(ctx context.Context, couponId string, from time.Time, to time.Time, interval string) ([]*dto.RedemptionStats, error)
+	GetTopEvents(ctx context.Context, couponId string, from time.Time, to time.Time, limit int) ([]*dto.RedemptionStats, error)
 }
 
+// maxCodeAttempts bounds the rounds spent replacing generated codes that are taken
+const maxCodeAttempts = 10
+
 type CouponRepository struct {
 	db database.IDatabase
 }
@@ -110,8 +135,11 @@ func (c *CouponRepository) GetCreatedCoupons(ctx context.Context, userId string,
 }
 
 func (c *CouponRepository) GetCouponById(ctx context.Context, id string) (*model.Coupon, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
 	var coupon model.Coupon
-	if err := c.db.FindById(ctx, id, &coupon); err != nil {
+	if err := c.db.GetDB().WithContext(ctx).Preload("TicketTypes").Where("id = ?", id).First(&coupon).Error; err != nil {
 		return nil, err
 	}
 
@@ -132,8 +160,30 @@ func (c *CouponRepository) Create(ctx context.Context, coupon *model.Coupon) err
 	return c.db.Create(ctx, coupon)
 }
 
+// Update saves the coupon and replaces the ticket types it is limited to
 func (c *CouponRepository) Update(ctx context.Context, coupon *model.Coupon) error {
-	return c.db.Update(ctx, coupon)
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return c.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(coupon).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("coupon_id = ?", coupon.ID).Delete(&model.CouponTicketType{}).Error; err != nil {
+			return err
+		}
+
+		if len(coupon.TicketTypes) == 0 {
+			return nil
+		}
+
+		for _, ticketType := range coupon.TicketTypes {
+			ticketType.CouponId = coupon.ID
+		}
+
+		return tx.Create(&coupon.TicketTypes).Error
+	})
 }
 
 func (c *CouponRepository) Delete(ctx context.Context, id string) error {
@@ -143,3 +193,280 @@ func (c *CouponRepository) Delete(ctx context.Context, id string) error {
 	}
 	return c.db.Delete(ctx, coupon)
 }
+
+// GetEventCouponByCode finds a coupon of the organizer of the event by its code or one of its generated
+// codes, the organizer must have applied it to the event
+func (c *CouponRepository) GetEventCouponByCode(ctx context.Context, eventId string, code string) (*model.Coupon, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var coupon model.Coupon
+	if err := c.db.GetDB().WithContext(ctx).Scopes(EventCode(eventId, code)).First(&coupon).Error; err != nil {
+		return nil, err
+	}
+
+	return &coupon, nil
+}
+
+func (c *CouponRepository) GetTicketTypesByIds(ctx context.Context, eventId string, ids []string) ([]*modelEvent.TicketType, error) {
+	var ticketTypes []*modelEvent.TicketType
+	query := database.NewQuery("event_id = ? AND id IN ?", eventId, ids)
+	if err := c.db.Find(ctx, &ticketTypes, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return ticketTypes, nil
+}
+
+// CountOwnedTicketTypes counts the ticket types among ids that belong to events of the user
+func (c *CouponRepository) CountOwnedTicketTypes(ctx context.Context, userId string, ids []string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var total int64
+	err := c.db.GetDB().WithContext(ctx).
+		Model(&modelEvent.TicketType{}).
+		Joins("INNER JOIN events ON events.id = ticket_types.event_id").
+		Where("ticket_types.id IN ? AND events.user_id = ?", ids, userId).
+		Count(&total).Error
+
+	return total, err
+}
+
+// IsCodeGenerated reports whether the organizer already has a generated code equal to code
+func (c *CouponRepository) IsCodeGenerated(ctx context.Context, userId string, code string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var total int64
+	err := c.db.GetDB().WithContext(ctx).
+		Model(&model.CouponCode{}).
+		Where("user_id = ? AND code = ?", userId, code).
+		Count(&total).Error
+
+	return total > 0, err
+}
+
+func (c *CouponRepository) GetUsage(ctx context.Context, couponId string, userId string, code string) (*model.CouponUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return CouponUsage(c.db.GetDB().WithContext(ctx), couponId, userId, code)
+}
+
+// CreateCodes generates count codes for the coupon and stores them. The codes of the organizer are locked
+// meanwhile, so a generated code never matches one they have, on a coupon or generated before.
+func (c *CouponRepository) CreateCodes(ctx context.Context, coupon *model.Coupon, count int, generate func() string) ([]*model.CouponCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var codes []*model.CouponCode
+	err := c.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "coupon-codes:"+coupon.UserId).Error; err != nil {
+			return err
+		}
+
+		pending := make(map[string]bool)
+		for attempt := 0; len(pending) < count; attempt++ {
+			if attempt == maxCodeAttempts {
+				return errors.New(messages.CouponCodesExhausted)
+			}
+
+			var candidates []string
+			for len(pending)+len(candidates) < count {
+				code := generate()
+				if !pending[code] && !slices.Contains(candidates, code) {
+					candidates = append(candidates, code)
+				}
+			}
+
+			var taken []string
+			if err := tx.Raw(`
+				SELECT code FROM coupons WHERE user_id = ? AND code IN ? AND deleted_at IS NULL
+				UNION
+				SELECT code FROM coupon_codes WHERE user_id = ? AND code IN ?`,
+				coupon.UserId, candidates, coupon.UserId, candidates,
+			).Scan(&taken).Error; err != nil {
+				return err
+			}
+
+			for _, code := range candidates {
+				if !slices.Contains(taken, code) {
+					pending[code] = true
+				}
+			}
+		}
+
+		for code := range pending {
+			codes = append(codes, &model.CouponCode{CouponId: coupon.ID, UserId: coupon.UserId, Code: code})
+		}
+		sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+
+		return tx.CreateInBatches(&codes, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// GetCodes lists the generated codes of a coupon with the order that redeemed each of them, if any
+func (c *CouponRepository) GetCodes(ctx context.Context, couponId string) ([]*dto.CouponCodeRow, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var codes []*dto.CouponCodeRow
+	if err := c.db.GetDB().WithContext(ctx).Raw(`
+		SELECT coupon_codes.code, coupon_codes.created_at, redemption.payment_id, redemption.created_at AS redeemed_at
+		FROM coupon_codes
+		LEFT JOIN LATERAL (
+			SELECT coupon_redemptions.payment_id, coupon_redemptions.created_at
+			FROM coupon_redemptions
+			INNER JOIN payments ON payments.id = coupon_redemptions.payment_id
+			WHERE coupon_redemptions.coupon_id = coupon_codes.coupon_id AND coupon_redemptions.code = coupon_codes.code
+				AND payments.status NOT IN ?
+			ORDER BY coupon_redemptions.created_at
+			LIMIT 1
+		) redemption ON true
+		WHERE coupon_codes.coupon_id = ?
+		ORDER BY coupon_codes.created_at, coupon_codes.code`,
+		closedPaymentStatuses, couponId,
+	).Scan(&codes).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// GetRedemptionTotals sums the redemptions of settled orders between from and to, per currency. Revenue is
+// what the orders were paid minus what was refunded.
+func (c *CouponRepository) GetRedemptionTotals(ctx context.Context, couponId string, from time.Time, to time.Time) ([]*dto.RedemptionStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var rows []*redemptionRow
+	if err := c.db.GetDB().WithContext(ctx).
+		Scopes(settledRedemptions(couponId, from, to)).
+		Select(redemptionSums + ", payments.final_price_currency AS currency").
+		Group("payments.final_price_currency").
+		Order("currency").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return toRedemptionStats(rows), nil
+}
+
+// GetRedemptionTimeline sums the redemptions of settled orders by day, week or month
+func (c *CouponRepository) GetRedemptionTimeline(ctx context.Context, couponId string, from time.Time, to time.Time, interval string) ([]*dto.RedemptionStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var rows []*redemptionRow
+	if err := c.db.GetDB().WithContext(ctx).
+		Scopes(settledRedemptions(couponId, from, to)).
+		Select(redemptionSums+", payments.final_price_currency AS currency, date_trunc(?, coupon_redemptions.created_at) AS period", interval).
+		Group("period, payments.final_price_currency").
+		Order("period, currency").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return toRedemptionStats(rows), nil
+}
+
+// GetTopEvents ranks the events by the settled orders the coupon was redeemed on
+func (c *CouponRepository) GetTopEvents(ctx context.Context, couponId string, from time.Time, to time.Time, limit int) ([]*dto.RedemptionStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var rows []*redemptionRow
+	if err := c.db.GetDB().WithContext(ctx).
+		Scopes(settledRedemptions(couponId, from, to)).
+		Select(redemptionSums + ", payments.final_price_currency AS currency, events.id AS event_id, events.name AS event_name").
+		Joins("INNER JOIN events ON events.id = coupon_redemptions.event_id").
+		Group("events.id, events.name, payments.final_price_currency").
+		Order("redemptions DESC, revenue DESC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return toRedemptionStats(rows), nil
+}
+
+// redemptionRow is a line of the coupon analytics, amounts are in minor units of the currency
+type redemptionRow struct {
+	Period      *time.Time
+	EventId     string
+	EventName   string
+	Currency    string
+	Redemptions int64
+	Discount    int64
+	Revenue     int64
+}
+
+func toRedemptionStats(rows []*redemptionRow) []*dto.RedemptionStats {
+	stats := make([]*dto.RedemptionStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, &dto.RedemptionStats{
+			Period:      row.Period,
+			EventId:     row.EventId,
+			EventName:   row.EventName,
+			Currency:    row.Currency,
+			Redemptions: row.Redemptions,
+			Discount:    money.New(row.Discount, row.Currency),
+			Revenue:     money.New(row.Revenue, row.Currency),
+		})
+	}
+
+	return stats
+}
+
+// redemptionSums are the aggregates of the analytics queries, amounts in minor units of the currency
+const redemptionSums = `COUNT(*) AS redemptions,
+	COALESCE(SUM(coupon_redemptions.amount_amount), 0) AS discount,
+	COALESCE(SUM(payments.final_price_amount - payments.refunded_amount_amount), 0) AS revenue`
+
+// settledRedemptions selects the redemptions of a coupon on orders that were paid, between from and to
+func settledRedemptions(couponId string, from time.Time, to time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Table("coupon_redemptions").
+			Joins("INNER JOIN payments ON payments.id = coupon_redemptions.payment_id").
+			Where("coupon_redemptions.coupon_id = ? AND payments.status IN ?", couponId, modelPayment.SettledPaymentStatuses).
+			Where("coupon_redemptions.created_at >= ? AND coupon_redemptions.created_at < ?", from, to)
+	}
+}
+
+// EventCode selects the coupon an organizer applied to an event by its code or one of its generated codes
+func EventCode(eventId string, code string) func(db *gorm.DB) *gorm.DB {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload("TicketTypes").
+			Joins("INNER JOIN event_coupons ON event_coupons.coupon_id = coupons.id AND event_coupons.deleted_at IS NULL").
+			Joins("INNER JOIN events ON events.id = event_coupons.event_id AND events.user_id = coupons.user_id").
+			Where("event_coupons.event_id = ?", eventId).
+			Where("coupons.code = ? OR EXISTS (SELECT 1 FROM coupon_codes WHERE coupon_codes.coupon_id = coupons.id AND coupon_codes.code = ?)", code, code)
+	}
+}
+
+// closedPaymentStatuses are the statuses of orders that give their coupon redemption back
+var closedPaymentStatuses = []string{modelPayment.PaymentStatusFailed, modelPayment.PaymentStatusExpired, modelPayment.PaymentStatusRejected}
+
+// CouponUsage counts the redemptions of a coupon that hold, in total, by the user and of the code. It takes
+// a transaction so the payments can count under a lock.
+func CouponUsage(tx *gorm.DB, couponId string, userId string, code string) (*model.CouponUsage, error) {
+	var usage model.CouponUsage
+	err := tx.Raw(`
+		SELECT COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE coupon_redemptions.user_id = ?) AS by_user,
+			COUNT(*) FILTER (WHERE coupon_redemptions.code = ?) AS by_code
+		FROM coupon_redemptions
+		INNER JOIN payments ON payments.id = coupon_redemptions.payment_id
+		WHERE coupon_redemptions.coupon_id = ? AND payments.status NOT IN ?`,
+		userId, strings.ToUpper(code), couponId, closedPaymentStatuses,
+	).Scan(&usage).Error
+
+	return &usage, err
+}
@@ -1,31 +1,50 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/stripe/stripe-go/v81"
-	couponStripe "github.com/stripe/stripe-go/v81/coupon"
 	"gohub/domains/coupons/dto"
 	"gohub/domains/coupons/model"
 	"gohub/domains/coupons/repository"
 	"gohub/internal/libs/logger"
 	"gohub/internal/libs/validation"
 	"gohub/pkg/messages"
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
 	"gohub/pkg/utils"
-	"log"
+	"regexp"
+	"strings"
+	"time"
 )
 
 type ICouponService interface {
-	CreateCoupon(ctx context.Context, req *dto.CreateCouponReq, stripeKey string) (*model.Coupon, error)
+	CreateCoupon(ctx context.Context, req *dto.CreateCouponReq) (*model.Coupon, error)
 	GetCoupons(ctx context.Context, req *dto.ListCouponReq) ([]*model.Coupon, *paging.Pagination, error)
 	GetCreatedCoupons(ctx context.Context, userId string, req *dto.ListCouponReq) ([]*model.Coupon, *paging.Pagination, error)
 	GetCouponById(ctx context.Context, id string) (*model.Coupon, error)
 	DeleteCoupon(ctx context.Context, id string) error
 	UpdateCoupon(ctx context.Context, id string, req *dto.UpdateCouponReq) (*model.Coupon, error)
+	ValidateCoupon(ctx context.Context, userId string, req *dto.ValidateCouponReq) (*dto.CouponPreview, error)
+	GenerateCodes(ctx context.Context, userId string, id string, req *dto.GenerateCodesReq) ([]*model.CouponCode, error)
+	ExportCodes(ctx context.Context, userId string, id string) ([]byte, error)
+	GetAnalytics(ctx context.Context, userId string, id string, req *dto.CouponAnalyticsReq) (*dto.CouponAnalytics, error)
 }
 
+const (
+	// codeLength is the length of the random part of generated coupon codes
+	codeLength = 8
+	// topEventsLimit is the number of events the analytics rank
+	topEventsLimit = 10
+)
+
+var (
+	codePattern   = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+	prefixPattern = regexp.MustCompile(`^[A-Z0-9_-]{0,12}$`)
+)
+
 type CouponService struct {
 	validator  validation.Validation
 	repoCoupon repository.ICouponRepository
@@ -38,28 +57,17 @@ func NewCouponService(validator validation.Validation, repoCoupon repository.ICo
 	}
 }
 
-func (s *CouponService) CreateCoupon(ctx context.Context, req *dto.CreateCouponReq, stripeKey string) (*model.Coupon, error) {
+func (s *CouponService) CreateCoupon(ctx context.Context, req *dto.CreateCouponReq) (*model.Coupon, error) {
 	if err := s.validator.ValidateStruct(req); err != nil {
 		return nil, err
 	}
 
 	var coupon model.Coupon
 	utils.MapStruct(&coupon, req)
-
-	stripe.Key = stripeKey
-	params := &stripe.CouponParams{
-		PercentOff: stripe.Float64(req.PercentageValue),
-		Duration:   stripe.String("once"),
-		Name:       stripe.String(req.Name),
-	}
-
-	newCoupon, err := couponStripe.New(params)
-	if err != nil {
-		log.Fatalf("Error creating coupon: %v", err)
+	if err := s.applyTerms(ctx, &coupon, &req.CouponTerms); err != nil {
+		return nil, err
 	}
 
-	coupon.CouponId = newCoupon.ID
-
 	if req.Image.Header != nil && req.Image.Filename != "" {
 		uploadUrl, err := utils.ImageUpload(req.Image, "/eventhub/conpons")
 		if err != nil {
@@ -70,11 +78,11 @@ func (s *CouponService) CreateCoupon(ctx context.Context, req *dto.CreateCouponR
 		coupon.CoverImageUrl = uploadUrl
 	}
 
-	err = s.repoCoupon.Create(ctx, &coupon)
+	err := s.repoCoupon.Create(ctx, &coupon)
 	if err != nil {
 		logger.Errorf("Create fail, error: %s", err)
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
-			return nil, errors.New(messages.CouponNameAlreadyExists)
+			return nil, conflictError(pgErr)
 		}
 		return nil, errors.New("some thing went wrong")
 	}
@@ -129,7 +137,21 @@ func (s *CouponService) UpdateCoupon(ctx context.Context, id string, req *dto.Up
 		return nil, errors.New(messages.CategoryNotFound)
 	}
 
+	// Leaving out the code, the type or the currency keeps the ones the coupon has
+	if req.Code == "" {
+		req.Code = coupon.Code
+	}
+	if req.Type == "" {
+		req.Type = coupon.Type
+	}
+	if req.Currency == "" {
+		req.Currency = coupon.MinPrice.Currency
+	}
+
 	utils.MapStruct(coupon, req)
+	if err := s.applyTerms(ctx, coupon, &req.CouponTerms); err != nil {
+		return nil, err
+	}
 	if req.Image.Header != nil && req.Image.Filename != "" {
 		logger.Info("vao day")
 		uploadUrl, err := utils.ImageUpload(req.Image, "/eventhub/conpons")
@@ -145,10 +167,361 @@ func (s *CouponService) UpdateCoupon(ctx context.Context, id string, req *dto.Up
 	if err != nil {
 		logger.Errorf("Create fail, error: %s", err)
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
-			return nil, errors.New(messages.CouponNameAlreadyExists)
+			return nil, conflictError(pgErr)
 		}
 		return nil, errors.New("some thing went wrong")
 	}
 
 	return coupon, nil
 }
+
+// ValidateCoupon previews what a code takes off a cart, the coupon is only redeemed with the order
+func (s *CouponService) ValidateCoupon(ctx context.Context, userId string, req *dto.ValidateCouponReq) (*dto.CouponPreview, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	coupon, err := s.repoCoupon.GetEventCouponByCode(ctx, req.EventId, req.Code)
+	if err != nil {
+		return nil, errors.New(messages.CouponNotFound)
+	}
+
+	quantities := make(map[string]int)
+	var ticketTypeIds []string
+	for _, item := range req.TicketItems {
+		if _, ok := quantities[item.TicketTypeId]; !ok {
+			ticketTypeIds = append(ticketTypeIds, item.TicketTypeId)
+		}
+		quantities[item.TicketTypeId] += item.Quantity
+	}
+
+	ticketTypes, err := s.repoCoupon.GetTicketTypesByIds(ctx, req.EventId, ticketTypeIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ticketTypes) != len(ticketTypeIds) {
+		return nil, errors.New(messages.TicketTypeNotFound)
+	}
+
+	var subtotal money.Money
+	lines := make([]*model.CouponLine, 0, len(ticketTypes))
+	for _, ticketType := range ticketTypes {
+		total := ticketType.Price.Mul(int64(quantities[ticketType.ID]))
+		lines = append(lines, &model.CouponLine{
+			TicketTypeId: ticketType.ID,
+			Quantity:     quantities[ticketType.ID],
+			Total:        total,
+		})
+		subtotal = subtotal.Add(total)
+	}
+
+	discount, err := coupon.Discount(lines, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	usage, err := s.repoCoupon.GetUsage(ctx, coupon.ID, userId, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := coupon.CheckUsage(code, *usage); err != nil {
+		return nil, err
+	}
+
+	return &dto.CouponPreview{
+		CouponId: coupon.ID,
+		Code:     code,
+		Type:     coupon.Type,
+		Subtotal: subtotal,
+		Discount: discount,
+		Total:    subtotal.Sub(discount),
+	}, nil
+}
+
+// GenerateCodes generates single use codes that redeem the coupon, they share its terms and events
+func (s *CouponService) GenerateCodes(ctx context.Context, userId string, id string, req *dto.GenerateCodesReq) ([]*model.CouponCode, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	coupon, err := s.ownedCoupon(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.ToUpper(strings.TrimSpace(req.Prefix))
+	if !prefixPattern.MatchString(prefix) {
+		return nil, errors.New(messages.InvalidCodePrefix)
+	}
+
+	length := req.Length
+	if length == 0 {
+		length = codeLength
+	}
+
+	// Codes are at most 32 characters long, the prefix eats into the random part
+	length = min(length, 32-len(prefix))
+
+	return s.repoCoupon.CreateCodes(ctx, coupon, req.Count, func() string {
+		return prefix + utils.RandomCode(length)
+	})
+}
+
+// ExportCodes writes the generated codes of the coupon as CSV, with the order that redeemed each of them
+func (s *CouponService) ExportCodes(ctx context.Context, userId string, id string) ([]byte, error) {
+	coupon, err := s.ownedCoupon(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.repoCoupon.GetCodes(ctx, coupon.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"Code", "Coupon", "Status", "Created At", "Redeemed At", "Payment ID"})
+	for _, code := range codes {
+		status, redeemedAt, paymentId := "Available", "", ""
+		if code.RedeemedAt != nil && code.PaymentId != nil {
+			status, redeemedAt, paymentId = "Redeemed", code.RedeemedAt.Format(time.RFC3339), *code.PaymentId
+		}
+
+		_ = writer.Write([]string{code.Code, coupon.Name, status, code.CreatedAt.Format(time.RFC3339), redeemedAt, paymentId})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GetAnalytics reports the redemptions of the coupon on paid orders, the discount they got, the revenue
+// they brought and the events they were made on
+func (s *CouponService) GetAnalytics(ctx context.Context, userId string, id string, req *dto.CouponAnalyticsReq) (*dto.CouponAnalytics, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	coupon, err := s.ownedCoupon(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	to := now
+	if req.EndDate != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, req.EndDate, now.Location()); err != nil {
+			return nil, errors.New(messages.InvalidDateRange)
+		}
+	}
+	// The end date is inclusive
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -30)
+	if req.StartDate != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, req.StartDate, now.Location()); err != nil {
+			return nil, errors.New(messages.InvalidDateRange)
+		}
+	}
+	if !from.Before(to) {
+		return nil, errors.New(messages.InvalidDateRange)
+	}
+
+	interval := req.Interval
+	if interval == "" {
+		interval = "day"
+	}
+
+	analytics := &dto.CouponAnalytics{CouponId: coupon.ID, StartDate: from, EndDate: to, Interval: interval}
+	if analytics.Totals, err = s.repoCoupon.GetRedemptionTotals(ctx, coupon.ID, from, to); err != nil {
+		return nil, err
+	}
+
+	if analytics.Timeline, err = s.repoCoupon.GetRedemptionTimeline(ctx, coupon.ID, from, to, interval); err != nil {
+		return nil, err
+	}
+
+	if analytics.TopEvents, err = s.repoCoupon.GetTopEvents(ctx, coupon.ID, from, to, topEventsLimit); err != nil {
+		return nil, err
+	}
+
+	return analytics, nil
+}
+
+func (s *CouponService) ownedCoupon(ctx context.Context, userId string, id string) (*model.Coupon, error) {
+	coupon, err := s.repoCoupon.GetCouponById(ctx, id)
+	if err != nil {
+		return nil, errors.New(messages.CouponNotFound)
+	}
+
+	if coupon.UserId != userId {
+		return nil, errors.New(messages.NotCouponOwner)
+	}
+
+	return coupon, nil
+}
+
+// applyTerms checks the terms of a request and sets them on the coupon
+func (s *CouponService) applyTerms(ctx context.Context, coupon *model.Coupon, terms *dto.CouponTerms) error {
+	currency := terms.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	if !money.IsSupported(currency) {
+		return errors.New(messages.UnsupportedCurrency)
+	}
+
+	coupon.Code = strings.ToUpper(strings.TrimSpace(terms.Code))
+	if coupon.Code == "" {
+		coupon.Code = utils.RandomCode(codeLength)
+	}
+
+	if !codePattern.MatchString(coupon.Code) {
+		return errors.New(messages.InvalidCouponCode)
+	}
+
+	// The index on coupons only covers their own codes, the generated ones are checked here
+	generated, err := s.repoCoupon.IsCodeGenerated(ctx, coupon.UserId, coupon.Code)
+	if err != nil {
+		return err
+	}
+
+	if generated {
+		return errors.New(messages.CouponCodeAlreadyExists)
+	}
+
+	minPrice, err := parseAmount(terms.MinPrice, currency)
+	if err != nil {
+		return err
+	}
+
+	amountOff, err := parseAmount(terms.AmountOff, currency)
+	if err != nil {
+		return err
+	}
+
+	coupon.MinPrice = minPrice
+	coupon.AmountOff = money.Zero(currency)
+	switch terms.Type {
+	case model.CouponTypeFixedAmount:
+		if !amountOff.IsPositive() {
+			return errors.New(messages.InvalidCouponValue)
+		}
+
+		coupon.Type = model.CouponTypeFixedAmount
+		coupon.PercentageValue = 0
+		coupon.AmountOff = amountOff
+	default:
+		if terms.PercentageValue <= 0 || terms.PercentageValue > 100 {
+			return errors.New(messages.InvalidCouponValue)
+		}
+
+		coupon.Type = model.CouponTypePercentage
+	}
+
+	coupon.StartsAt, coupon.EndsAt, err = parseWindow(terms.StartsAt, terms.EndsAt)
+	if err != nil {
+		return err
+	}
+
+	var ticketTypeIds []string
+	seen := make(map[string]bool)
+	for _, id := range terms.TicketTypeIds {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ticketTypeIds = append(ticketTypeIds, id)
+		}
+	}
+
+	coupon.TicketTypes = nil
+	if len(ticketTypeIds) == 0 {
+		return nil
+	}
+
+	// A coupon can only be limited to ticket types of events of its organizer
+	owned, err := s.repoCoupon.CountOwnedTicketTypes(ctx, coupon.UserId, ticketTypeIds)
+	if err != nil {
+		return err
+	}
+
+	if owned != int64(len(ticketTypeIds)) {
+		return errors.New(messages.TicketTypeNotFound)
+	}
+
+	for _, id := range ticketTypeIds {
+		coupon.TicketTypes = append(coupon.TicketTypes, &model.CouponTicketType{CouponId: coupon.ID, TicketTypeId: id})
+	}
+
+	return nil
+}
+
+// parseAmount reads an amount of a coupon in major units of its currency, a missing amount counts as zero
+func parseAmount(value string, currency string) (money.Money, error) {
+	if strings.TrimSpace(value) == "" {
+		return money.Zero(currency), nil
+	}
+
+	amount, err := money.Parse(value, currency)
+	if err != nil || amount.IsNegative() {
+		return money.Money{}, errors.New(messages.InvalidPrice)
+	}
+
+	return amount, nil
+}
+
+// parseWindow reads when a coupon starts and ends, a date without a time starts at the beginning of that
+// day and ends at its end
+func parseWindow(start string, end string) (*time.Time, *time.Time, error) {
+	startsAt, err := parseDate(start, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	endsAt, err := parseDate(end, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return nil, nil, errors.New(messages.InvalidCouponWindow)
+	}
+
+	return startsAt, endsAt, nil
+}
+
+func parseDate(value string, endOfDay bool) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return &date, nil
+	}
+
+	date, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return nil, errors.New(messages.InvalidCouponWindow)
+	}
+
+	if endOfDay {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return &date, nil
+}
+
+// conflictError tells a taken code from a taken name
+func conflictError(pgErr *pgconn.PgError) error {
+	if pgErr.ConstraintName == "idx_coupons_user_code" {
+		return errors.New(messages.CouponCodeAlreadyExists)
+	}
+
+	return errors.New(messages.CouponNameAlreadyExists)
+}
@@ -1,15 +1,21 @@
 package dto
 
-import "time"
+import (
+	"gohub/pkg/money"
+	"time"
+)
 
 type Coupon struct {
-	ID              string    `json:"id"`
-	CouponId        string    `json:"couponId"`
-	Name            string    `json:"name"`
-	CoverImageUrl   string    `json:"coverImageUrl"`
-	Description     string    `json:"description"`
-	MinQuantity     int       `json:"minQuantity"`
-	MinPrice        float64   `json:"minPrice"`
-	PercentageValue float64   `json:"percentageValue"`
-	ExpireDate      time.Time `json:"expireDate"`
+	ID              string      `json:"id"`
+	Code            string      `json:"code"`
+	Name            string      `json:"name"`
+	CoverImageUrl   string      `json:"coverImageUrl"`
+	Description     string      `json:"description"`
+	MinQuantity     int         `json:"minQuantity"`
+	MinPrice        money.Money `json:"minPrice"`
+	Type            string      `json:"type"`
+	PercentageValue float64     `json:"percentageValue"`
+	AmountOff       money.Money `json:"amountOff"`
+	StartsAt        *time.Time  `json:"startsAt"`
+	EndsAt          *time.Time  `json:"endsAt"`
 }
@@ -1,29 +1,42 @@
 package dto
 
 import (
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
 	"mime/multipart"
+	"time"
 )
 
 type Event struct {
-	ID               string        `json:"id"`
-	User             *User         `json:"creator"`
-	Name             string        `json:"name"`
-	Description      string        `json:"description"`
-	CoverImageUrl    string        `json:"coverImageUrl"`
-	StartTime        string        `json:"startTime"`
-	EndTime          string        `json:"endTime"`
-	Location         string        `json:"location"`
-	PathLocation     string        `json:"pathLocation"`
-	EventCycleType   string        `json:"eventCycleType"`
-	EventPaymentType string        `json:"eventPaymentType"`
-	IsPrivate        bool          `json:"isPrivate"`
-	SubImage         []*SubImage   `json:"subImages"`
-	Categories       []*Category   `json:"categories"`
-	Reasons          []*Reason     `json:"reasons"`
-	TicketTypes      []*TicketType `json:"ticketTypes"`
-	Coupons          []*Coupon     `json:"coupons"`
-	AverageRate      float32       `json:"averageRate"`
+	ID                 string        `json:"id"`
+	User               *User         `json:"creator"`
+	Name               string        `json:"name"`
+	Description        string        `json:"description"`
+	CoverImageUrl      string        `json:"coverImageUrl"`
+	StartTime          time.Time     `json:"startTime"`
+	EndTime            time.Time     `json:"endTime"`
+	Timezone           string        `json:"timezone"`
+	Location           string        `json:"location"`
+	PathLocation       string        `json:"pathLocation"`
+	EventCycleType     string        `json:"eventCycleType"`
+	RecurrenceRule     string        `json:"recurrenceRule"`
+	ExcludedDates      []string      `json:"excludedDates"`
+	EventPaymentType   string        `json:"eventPaymentType"`
+	IsPrivate          bool          `json:"isPrivate"`
+	State              string        `json:"state"`
+	PublishAt          *time.Time    `json:"publishAt"`
+	PublishedAt        *time.Time    `json:"publishedAt"`
+	CancelledAt        *time.Time    `json:"cancelledAt"`
+	CancellationReason string        `json:"cancellationReason"`
+	RequiresApproval   bool          `json:"requiresApproval"`
+	MaxTicketsPerUser  int           `json:"maxTicketsPerUser"`
+	Currency           string        `json:"currency"`
+	SubImage           []*SubImage   `json:"subImages"`
+	Categories         []*Category   `json:"categories"`
+	Reasons            []*Reason     `json:"reasons"`
+	TicketTypes        []*TicketType `json:"ticketTypes"`
+	Coupons            []*Coupon     `json:"coupons"`
+	AverageRate        float32       `json:"averageRate"`
 }
 
 type Events struct {
@@ -32,32 +45,40 @@ type Events struct {
 	Description        string        `json:"description"`
 	CoverImageUrl      string        `json:"coverImageUrl"`
 	CoverImageFileName string        `json:"coverImageFileName"`
-	StartTime          string        `json:"startTime"`
-	EndTime            string        `json:"endTime"`
+	StartTime          time.Time     `json:"startTime"`
+	EndTime            time.Time     `json:"endTime"`
+	Timezone           string        `json:"timezone"`
 	Location           string        `json:"location"`
 	PathLocation       string        `json:"pathLocation"`
 	EventCycleType     string        `json:"eventCycleType"`
+	RecurrenceRule     string        `json:"recurrenceRule"`
 	EventPaymentType   string        `json:"eventPaymentType"`
 	IsPrivate          bool          `json:"isPrivate"`
+	State              string        `json:"state"`
+	Currency           string        `json:"currency"`
 	AverageRate        float32       `json:"averageRate"`
 	Categories         []*Category   `json:"categories"`
 	TicketTypes        []*TicketType `json:"ticketTypes"`
+	Occurrences        []*Occurrence `json:"occurrences"`
 }
 
 type MyEvent struct {
-	ID            string    `json:"id"`
-	Name          string    `json:"name"`
-	CoverImageUrl string    `json:"coverImageUrl"`
-	StartTime     string    `json:"startTime"`
-	Location      string    `json:"location"`
-	IsPrivate     bool      `json:"isPrivate"`
-	DeletedAt     string    `json:"deletedAt"`
-	Coupons       []*Coupon `json:"coupons"`
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	CoverImageUrl string     `json:"coverImageUrl"`
+	StartTime     time.Time  `json:"startTime"`
+	Timezone      string     `json:"timezone"`
+	Location      string     `json:"location"`
+	IsPrivate     bool       `json:"isPrivate"`
+	State         string     `json:"state"`
+	PublishAt     *time.Time `json:"publishAt"`
+	DeletedAt     string     `json:"deletedAt"`
+	Coupons       []*Coupon  `json:"coupons"`
 }
 
 type Expense struct {
-	ID    string  `json:"id"`
-	Total float64 `json:"total"`
+	ID    string      `json:"id"`
+	Total money.Money `json:"total"`
 }
 
 type MyEventAnalysis struct {
@@ -74,47 +95,58 @@ type EventFavourite struct {
 	ID            string      `json:"id"`
 	Name          string      `json:"name"`
 	CoverImageUrl string      `json:"coverImageUrl"`
-	StartTime     string      `json:"startTime"`
+	StartTime     time.Time   `json:"startTime"`
+	Timezone      string      `json:"timezone"`
+	State         string      `json:"state"`
 	AverageRate   float32     `json:"averageRate"`
 	Categories    []*Category `json:"categories"`
 }
 
 type CreateEventReq struct {
-	UserId           string                  `form:"userId"`
-	Name             string                  `form:"name"`
-	Description      string                  `form:"description"`
-	CoverImage       *multipart.FileHeader   `form:"coverImage"`
-	SubImageItems    []*multipart.FileHeader `form:"subImageItems"`
-	StartTime        string                  `form:"startTime"`
-	EndTime          string                  `form:"endTime"`
-	Location         string                  `form:"location"`
-	EventCycleType   string                  `form:"eventCycleType"`
-	EventPaymentType string                  `form:"eventPaymentType"`
-	IsPrivate        bool                    `form:"isPrivate"`
-	CategoryIds      []string                `form:"categoryIds"`
-	TicketTypeItems  []*CreateTicketType     `form:"ticketTypeItems"`
-	ReasonItems      []string                `form:"reasonItems"`
+	UserId            string                  `form:"userId"`
+	Name              string                  `form:"name"`
+	Description       string                  `form:"description"`
+	CoverImage        *multipart.FileHeader   `form:"coverImage"`
+	SubImageItems     []*multipart.FileHeader `form:"subImageItems"`
+	StartTime         string                  `json:"-" form:"startTime"`
+	EndTime           string                  `json:"-" form:"endTime"`
+	Timezone          string                  `json:"-" form:"timezone"`
+	Location          string                  `form:"location"`
+	EventCycleType    string                  `form:"eventCycleType"`
+	RecurrenceRule    string                  `form:"recurrenceRule"`
+	ExcludedDates     []string                `form:"excludedDates"`
+	EventPaymentType  string                  `form:"eventPaymentType"`
+	IsPrivate         bool                    `form:"isPrivate"`
+	RequiresApproval  bool                    `form:"requiresApproval"`
+	MaxTicketsPerUser int                     `form:"maxTicketsPerUser" validate:"min=0"`
+	Currency          string                  `form:"currency"`
+	CategoryIds       []string                `form:"categoryIds"`
+	TicketTypeItems   []*CreateTicketType     `form:"ticketTypeItems"`
+	ReasonItems       []string                `form:"reasonItems"`
 }
 
 type UpdateEventReq struct {
-	ID                 string                `form:"id"`
-	UserId             string                `form:"userId"`
-	Name               string                `form:"name"`
-	Description        string                `form:"description"`
-	CoverImageUrl      string                `form:"coverImageUrl"`
-	CoverImageFileName string                `form:"coverImageFileName"`
-	CoverImage         *multipart.FileHeader `form:"coverImage"`
-	SubImageItems      []string              `form:"subImageItems"`
-	StartTime          string                `form:"startTime"`
-	EndTime            string                `form:"endTime"`
-	Location           string                `form:"location"`
-	PathLocation       string                `form:"pathLocation"`
-	EventCycleType     string                `form:"eventCycleType"`
-	EventPaymentType   string                `form:"eventPaymentType"`
-	IsPrivate          bool                  `form:"isPrivate"`
-	CategoryIds        []string              `form:"categoryIds"`
-	TicketTypeItems    []*CreateTicketType   `form:"ticketTypeItems"`
-	ReasonItems        []string              `form:"reasonItems"`
+	ID                string                `form:"id"`
+	UserId            string                `form:"userId"`
+	Name              string                `form:"name"`
+	Description       string                `form:"description"`
+	CoverImage        *multipart.FileHeader `form:"coverImage"`
+	StartTime         string                `json:"-" form:"startTime"`
+	EndTime           string                `json:"-" form:"endTime"`
+	Timezone          string                `json:"-" form:"timezone"`
+	Location          string                `form:"location"`
+	PathLocation      string                `form:"pathLocation"`
+	EventCycleType    string                `form:"eventCycleType"`
+	RecurrenceRule    string                `form:"recurrenceRule"`
+	ExcludedDates     []string              `form:"excludedDates"`
+	EventPaymentType  string                `form:"eventPaymentType"`
+	IsPrivate         bool                  `form:"isPrivate"`
+	RequiresApproval  bool                  `form:"requiresApproval"`
+	MaxTicketsPerUser int                   `form:"maxTicketsPerUser" validate:"min=0"`
+	Currency          string                `form:"currency"`
+	CategoryIds       []string              `form:"categoryIds"`
+	TicketTypeItems   []*CreateTicketType   `form:"ticketTypeItems"`
+	ReasonItems       []string              `form:"reasonItems"`
 }
 
 type ListEventReq struct {
@@ -129,6 +161,7 @@ type ListEventReq struct {
 	TakeAll        bool     `json:"-" form:"take_all"`
 	IsPrivate      bool     `json:"-" form:"is_private"`
 	Visibility     string   `json:"-" form:"visibility"`
+	State          string   `json:"-" form:"state"`
 	PaymentType    string   `json:"-" form:"paymentType"`
 	StartTimeRange string   `json:"-" form:"startTimeRange"`
 	EndTimeRange   string   `json:"-" form:"endTimeRange"`
@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"gohub/pkg/paging"
+	"time"
+)
+
+// SubmitEventReq sends a draft to review. The event is published when an admin approves it, or at PublishAt
+// when that is still ahead then.
+type SubmitEventReq struct {
+	UserId    string `json:"-"`
+	PublishAt string `json:"publishAt"`
+}
+
+type ReviewEventReq struct {
+	UserId   string `json:"-"`
+	Decision string `json:"decision" validate:"required,oneof=Approved Rejected"`
+	Note     string `json:"note" validate:"required_if=Decision Rejected,max=1000"`
+}
+
+type CancelEventReq struct {
+	UserId string `json:"-"`
+	Reason string `json:"reason" validate:"max=1000"`
+}
+
+type ListPendingEventReq struct {
+	Page  int64 `form:"page"`
+	Limit int64 `form:"pageSize"`
+}
+
+type PendingEvent struct {
+	ID            string      `json:"id"`
+	User          *User       `json:"creator"`
+	Name          string      `json:"name"`
+	CoverImageUrl string      `json:"coverImageUrl"`
+	StartTime     time.Time   `json:"startTime"`
+	Timezone      string      `json:"timezone"`
+	Location      string      `json:"location"`
+	PublishAt     *time.Time  `json:"publishAt"`
+	Categories    []*Category `json:"categories"`
+	UpdatedAt     time.Time   `json:"updatedAt"`
+}
+
+type ListPendingEventRes struct {
+	Events     []*PendingEvent    `json:"items"`
+	Pagination *paging.Pagination `json:"metadata"`
+}
+
+type EventModeration struct {
+	ID        string    `json:"id"`
+	Moderator *User     `json:"moderator"`
+	Decision  string    `json:"decision"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RescheduleEventReq moves an event to new times, times without an offset are read in the timezone of the
+// event, or in Timezone when it changes too
+type RescheduleEventReq struct {
+	UserId    string `json:"-"`
+	StartTime string `json:"startTime" validate:"required"`
+	EndTime   string `json:"endTime" validate:"required"`
+	Timezone  string `json:"timezone"`
+	Reason    string `json:"reason" validate:"max=1000"`
+}
+
+type EventReschedule struct {
+	ID                string    `json:"id"`
+	OccurrenceId      *string   `json:"occurrenceId"`
+	PreviousStartTime time.Time `json:"previousStartTime"`
+	PreviousEndTime   time.Time `json:"previousEndTime"`
+	PreviousTimezone  string    `json:"previousTimezone"`
+	StartTime         time.Time `json:"startTime"`
+	EndTime           time.Time `json:"endTime"`
+	Timezone          string    `json:"timezone"`
+	Reason            string    `json:"reason"`
+	RespondBy         time.Time `json:"respondBy"`
+	CreatedAt         time.Time `json:"createdAt"`
+}
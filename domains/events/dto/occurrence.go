@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"gohub/pkg/paging"
+	"time"
+)
+
+// The scope of an edit or a cancellation, the occurrence alone or it and every later one of the series
+const (
+	OccurrenceScopeThis   = "this"
+	OccurrenceScopeFuture = "future"
+)
+
+type Occurrence struct {
+	ID                 string                 `json:"id"`
+	EventId            string                 `json:"eventId"`
+	StartTime          time.Time              `json:"startTime"`
+	EndTime            time.Time              `json:"endTime"`
+	Status             string                 `json:"status"`
+	IsModified         bool                   `json:"isModified"`
+	CancelledAt        *time.Time             `json:"cancelledAt"`
+	CancellationReason string                 `json:"cancellationReason"`
+	Inventories        []*OccurrenceInventory `json:"inventories,omitempty"`
+}
+
+type OccurrenceInventory struct {
+	TicketTypeId string `json:"ticketTypeId"`
+	Quantity     int    `json:"quantity"`
+	Sale         int    `json:"sale"`
+	IsOverridden bool   `json:"isOverridden"`
+}
+
+type ListOccurrenceReq struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Status string `form:"status" validate:"omitempty,oneof=Scheduled Cancelled"`
+	Page   int64  `form:"page"`
+	Limit  int64  `form:"pageSize"`
+}
+
+type ListOccurrenceRes struct {
+	Occurrences []*Occurrence      `json:"items"`
+	Pagination  *paging.Pagination `json:"metadata"`
+}
+
+// UpdateOccurrenceReq moves an occurrence to new times, with the future scope every later occurrence moves
+// by the same shift. Tickets changes the seats of ticket types at the moved occurrences. Reason is told to
+// the ticket holders of the moved occurrences.
+type UpdateOccurrenceReq struct {
+	UserId    string                 `json:"-"`
+	Scope     string                 `json:"scope" validate:"omitempty,oneof=this future"`
+	StartTime string                 `json:"startTime" validate:"required"`
+	EndTime   string                 `json:"endTime" validate:"required"`
+	Reason    string                 `json:"reason" validate:"max=1000"`
+	Tickets   []*OccurrenceTicketReq `json:"tickets" validate:"omitempty,dive"`
+}
+
+type OccurrenceTicketReq struct {
+	TicketTypeId string `json:"ticketTypeId" validate:"required"`
+	Quantity     int    `json:"quantity" validate:"min=0"`
+}
+
+type CancelOccurrenceReq struct {
+	UserId string `json:"-"`
+	Scope  string `json:"scope" validate:"omitempty,oneof=this future"`
+	Reason string `json:"reason" validate:"max=1000"`
+}
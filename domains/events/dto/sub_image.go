@@ -1,6 +1,20 @@
 package dto
 
+import "mime/multipart"
+
 type SubImage struct {
-	ID       string `json:"id"`
-	ImageUrl string `json:"imageUrl"`
+	ID        string `json:"id"`
+	ImageUrl  string `json:"imageUrl"`
+	SortIndex int    `json:"sortIndex"`
+}
+
+type AddGalleryImageReq struct {
+	UserId string                  `json:"-" form:"-"`
+	Images []*multipart.FileHeader `form:"images"`
+}
+
+// ReorderGalleryImageReq lists every image of the gallery in its new order
+type ReorderGalleryImageReq struct {
+	UserId   string   `json:"-"`
+	ImageIds []string `json:"imageIds" validate:"required,unique,dive,required"`
 }
@@ -1,16 +1,25 @@
 package dto
 
+import (
+	"encoding/json"
+	"gohub/pkg/money"
+)
+
 type TicketType struct {
-	ID       string  `json:"id"`
-	Name     string  `json:"name"`
-	Quantity int     `json:"quantity"`
-	Sale     int     `json:"sale"`
-	Price    float64 `json:"price"`
+	ID                 string      `json:"id"`
+	Name               string      `json:"name"`
+	Quantity           int         `json:"quantity"`
+	Sale               int         `json:"sale"`
+	Price              money.Money `json:"price"`
+	IsTransferDisabled bool        `json:"isTransferDisabled"`
 }
 
+// CreateTicketType takes the price in major units of the event currency, the service parses it into UnitPrice
 type CreateTicketType struct {
-	Name     string  `json:"name"`
-	Quantity int     `json:"quantity"`
-	Sale     int     `json:"sale"`
-	Price    float64 `json:"price"`
+	Name               string      `json:"name"`
+	Quantity           int         `json:"quantity"`
+	Sale               int         `json:"sale"`
+	Price              json.Number `json:"price"`
+	UnitPrice          money.Money `json:"-"`
+	IsTransferDisabled bool        `json:"isTransferDisabled"`
 }
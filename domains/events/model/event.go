@@ -1,16 +1,77 @@
 package model
 
 import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	modelCategory "gohub/domains/categories/model"
 	modelCoupon "gohub/domains/coupons/model"
 	modelExpense "gohub/domains/expense/model"
 	modelUser "gohub/domains/users/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/rrule"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	EventPaymentTypeFree = "Free"
+	EventPaymentTypePaid = "Paid"
+)
+
+// An event is drafted, reviewed by an admin and published, the review can set it back to draft. Published
+// events complete once their last occurrence ended, any event that did not end can be cancelled.
+const (
+	EventStateDraft         = "Draft"
+	EventStatePendingReview = "PendingReview"
+	EventStatePublished     = "Published"
+	EventStateCancelled     = "Cancelled"
+	EventStateCompleted     = "Completed"
+)
+
+// eventTransitions are the states each state can move to
+var eventTransitions = map[string][]string{
+	EventStateDraft:         {EventStatePendingReview, EventStateCancelled},
+	EventStatePendingReview: {EventStateDraft, EventStatePublished, EventStateCancelled},
+	EventStatePublished:     {EventStateCancelled, EventStateCompleted},
+}
+
+// The cycle type follows the recurrence rule of the event, an event without a rule happens once
+const (
+	EventCycleTypeOnce    = "Once"
+	EventCycleTypeDaily   = "Daily"
+	EventCycleTypeWeekly  = "Weekly"
+	EventCycleTypeMonthly = "Monthly"
+)
+
+// DefaultTimezone is the timezone of events created without one, the one organizers entered times in
+// before events had a timezone
+const DefaultTimezone = "Asia/Ho_Chi_Minh"
+
+// DisplayTimeLayout is how event times are written for people, in mails and on tickets
+const DisplayTimeLayout = "Mon, 02 Jan 2006 15:04 MST"
+
+var cycleTypes = map[string]string{
+	rrule.Daily:   EventCycleTypeDaily,
+	rrule.Weekly:  EventCycleTypeWeekly,
+	rrule.Monthly: EventCycleTypeMonthly,
+}
+
+// eventTimeLayouts are the formats start and end times are entered in, times without an offset are in the
+// timezone of the event
+var eventTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	time.DateTime,
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
 type Event struct {
 	ID                 string                    `json:"id" gorm:"unique;not null;index;primary_key"`
 	UserId             string                    `json:"userId" gorm:"not null"`
@@ -19,19 +80,33 @@ type Event struct {
 	Description        string                    `json:"description"`
 	CoverImageUrl      string                    `json:"coverImageUrl" gorm:"not null"`
 	CoverImageFileName string                    `json:"coverImageFileName" gorm:"not null"`
-	StartTime          string                    `json:"startTime" gorm:"not null"`
-	EndTime            string                    `json:"endTime" gorm:"not null"`
+	CoverImagePublicId string                    `json:"-" gorm:"not null;default:''"`
+	StartTime          time.Time                 `json:"startTime" gorm:"type:timestamptz;not null"`
+	EndTime            time.Time                 `json:"endTime" gorm:"type:timestamptz;not null"`
+	Timezone           string                    `json:"timezone" gorm:"type:varchar(64);not null;default:'Asia/Ho_Chi_Minh'"`
 	Location           string                    `json:"location" gorm:"not null"`
 	PathLocation       string                    `json:"pathLocation" gorm:"not null"`
 	EventCycleType     string                    `json:"eventCycleType" gorm:"not null"`
+	RecurrenceRule     string                    `json:"recurrenceRule" gorm:"not null;default:''"`
+	ExcludedDates      DateList                  `json:"excludedDates" gorm:"type:text;not null;default:''"`
 	EventPaymentType   string                    `json:"eventPaymentType" gorm:"not null"`
 	IsPrivate          bool                      `json:"isPrivate" gorm:"default:0"`
+	State              string                    `json:"state" gorm:"type:varchar(16);not null;default:'Draft';index"`
+	PublishAt          *time.Time                `json:"publishAt"`
+	PublishedAt        *time.Time                `json:"publishedAt"`
+	ApprovedAt         *time.Time                `json:"approvedAt"`
+	CancelledAt        *time.Time                `json:"cancelledAt"`
+	CancellationReason string                    `json:"cancellationReason"`
+	RequiresApproval   bool                      `json:"requiresApproval" gorm:"not null;default:false"`
+	MaxTicketsPerUser  int                       `json:"maxTicketsPerUser" gorm:"not null;default:0"`
+	Currency           string                    `json:"currency" gorm:"type:varchar(3);not null;default:'VND'"`
 	SubImages          []*EventSubImage          `json:"subImages"`
 	Categories         []*modelCategory.Category `json:"categories" gorm:"many2many:event_categories;"`
 	Reasons            []*Reason                 `json:"reasons"`
 	Coupons            []*modelCoupon.Coupon     `json:"coupons" gorm:"many2many:event_coupons;"`
 	Expenses           []*modelExpense.Expense   `json:"expenses"`
 	TicketTypes        []*TicketType             `json:"ticketTypes"`
+	Occurrences        []*EventOccurrence        `json:"occurrences"`
 	Reviews            []*Review                 `json:"reviews"`
 	UserFavourite      []*modelUser.User         `json:"userFavourite" gorm:"many2many:event_favourites;"`
 	AverageRate        float32                   `json:"averageRate"`
@@ -58,6 +133,216 @@ func (e *Event) BeforeCreate(db *gorm.DB) (err error) {
 	return nil
 }
 
+// AfterFind shows the times of the event and its occurrences at the offset of its timezone, and its gallery
+// in the order the organizer gave it
+func (e *Event) AfterFind(db *gorm.DB) (err error) {
+	location := e.TimeLocation()
+	e.StartTime = e.StartTime.In(location)
+	e.EndTime = e.EndTime.In(location)
+	for _, occurrence := range e.Occurrences {
+		occurrence.In(location)
+	}
+	sort.SliceStable(e.SubImages, func(i, j int) bool {
+		return e.SubImages[i].SortIndex < e.SubImages[j].SortIndex
+	})
+	return nil
+}
+
 func (Event) TableName() string {
 	return "events"
 }
+
+// CanMoveTo reports whether the event can go from its state to the given one
+func (e *Event) CanMoveTo(state string) bool {
+	for _, next := range eventTransitions[e.State] {
+		if next == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsListed reports whether anyone may see the event, drafts and events in review are only shown to their
+// organizer and to admins
+func (e *Event) IsListed() bool {
+	return e.State != EventStateDraft && e.State != EventStatePendingReview
+}
+
+// StatesBefore lists the states an event can move to the given state from
+func StatesBefore(state string) []string {
+	var states []string
+	for from, nexts := range eventTransitions {
+		for _, next := range nexts {
+			if next == state {
+				states = append(states, from)
+			}
+		}
+	}
+
+	sort.Strings(states)
+	return states
+}
+
+// TimeLocation is the timezone of the event, UTC when it is not a known one
+func (e *Event) TimeLocation() *time.Location {
+	return loadLocation(e.Timezone)
+}
+
+func loadLocation(timezone string) *time.Location {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// SetTimes sets the timezone of the event and reads its start and end times in it. Empty values keep what
+// the event had, the times it kept move to the new timezone at the same wall clock.
+func (e *Event) SetTimes(timezone string, start string, end string) error {
+	if timezone = strings.TrimSpace(timezone); timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil || strings.EqualFold(timezone, "Local") {
+			return errors.New(messages.InvalidTimezone)
+		}
+
+		e.StartTime = wallClock(e.StartTime, location)
+		e.EndTime = wallClock(e.EndTime, location)
+		e.Timezone = location.String()
+	}
+	if e.Timezone == "" {
+		e.Timezone = DefaultTimezone
+	}
+
+	location := e.TimeLocation()
+	if start != "" {
+		parsed, err := ParseEventTime(start, location)
+		if err != nil {
+			return err
+		}
+		e.StartTime = parsed
+	}
+
+	if end != "" {
+		parsed, err := ParseEventTime(end, location)
+		if err != nil {
+			return err
+		}
+		e.EndTime = parsed
+	}
+
+	return nil
+}
+
+// Expand checks the times and the recurrence of the event and lists the start of each of its occurrences
+// with their duration, a single event has one. The rule is written back normalized and the cycle type
+// follows it.
+func (e *Event) Expand() ([]time.Time, time.Duration, error) {
+	location := e.TimeLocation()
+	start := e.StartTime.In(location)
+	end := e.EndTime.In(location)
+	if start.IsZero() || end.Before(start) {
+		return nil, 0, errors.New(messages.InvalidEventTime)
+	}
+
+	if strings.TrimSpace(e.RecurrenceRule) == "" {
+		e.RecurrenceRule = ""
+		e.ExcludedDates = nil
+		e.EventCycleType = EventCycleTypeOnce
+		return []time.Time{start}, end.Sub(start), nil
+	}
+
+	rule, err := rrule.Parse(e.RecurrenceRule)
+	if err != nil {
+		return nil, 0, errors.New(messages.InvalidRecurrenceRule)
+	}
+
+	excluded, err := e.ExcludedDates.Times()
+	if err != nil {
+		return nil, 0, errors.New(messages.InvalidExcludedDate)
+	}
+
+	starts, err := rule.Expand(start, excluded)
+	if errors.Is(err, rrule.ErrTooManyOccurrences) {
+		return nil, 0, errors.New(messages.TooManyOccurrences)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	e.RecurrenceRule = rule.String()
+	e.EventCycleType = cycleTypes[rule.Freq]
+	return starts, end.Sub(start), nil
+}
+
+// ParseEventTime reads a start or end time in any of the formats events are created with, a time without
+// an offset is read in the location. The time is returned in the location either way.
+func ParseEventTime(value string, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range eventTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed.In(location), nil
+		}
+	}
+
+	return time.Time{}, errors.New(messages.InvalidEventTime)
+}
+
+// wallClock keeps the date and time of day of t in another location
+func wallClock(t time.Time, location *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), location)
+}
+
+// DateList is a list of calendar dates like 2024-12-25, stored as one comma separated column
+type DateList []string
+
+func (d DateList) Value() (driver.Value, error) {
+	return strings.Join(d, ","), nil
+}
+
+func (d *DateList) Scan(value interface{}) error {
+	var text string
+	switch value := value.(type) {
+	case string:
+		text = value
+	case []byte:
+		text = string(value)
+	case nil:
+	default:
+		return fmt.Errorf("date list: cannot scan %T", value)
+	}
+
+	*d = nil
+	for _, date := range strings.Split(text, ",") {
+		if date = strings.TrimSpace(date); date != "" {
+			*d = append(*d, date)
+		}
+	}
+
+	return nil
+}
+
+// Times parses the dates, sorted and without duplicates
+func (d DateList) Times() ([]time.Time, error) {
+	seen := make(map[string]bool, len(d))
+	times := make([]time.Time, 0, len(d))
+	for _, date := range d {
+		parsed, err := time.Parse(time.DateOnly, strings.TrimSpace(date))
+		if err != nil {
+			return nil, err
+		}
+
+		if key := parsed.Format(time.DateOnly); !seen[key] {
+			seen[key] = true
+			times = append(times, parsed)
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times, nil
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	modelUser "gohub/domains/users/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ModerationApproved = "Approved"
+	ModerationRejected = "Rejected"
+)
+
+// EventModeration records the decision of an admin on an event submitted for review
+type EventModeration struct {
+	ID          string          `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventId     string          `json:"eventId" gorm:"not null;index"`
+	Event       *Event          `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ModeratorId string          `json:"moderatorId" gorm:"not null"`
+	Moderator   *modelUser.User `json:"moderator" gorm:"foreignKey:ModeratorId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Decision    string          `json:"decision" gorm:"not null"`
+	Note        string          `json:"note"`
+	CreatedAt   time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+func (m *EventModeration) BeforeCreate(tx *gorm.DB) error {
+	m.ID = uuid.New().String()
+
+	return nil
+}
+
+func (EventModeration) TableName() string {
+	return "event_moderations"
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	OccurrenceStatusScheduled = "Scheduled"
+	OccurrenceStatusCancelled = "Cancelled"
+)
+
+// EventOccurrence is one date of an event, a single event has exactly one. SlotTime is the start the
+// recurrence rule gave it and never changes, so expanding the rule again after an edit recognizes the
+// occurrences that were moved or cancelled by hand.
+type EventOccurrence struct {
+	ID                 string                 `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventId            string                 `json:"eventId" gorm:"not null;uniqueIndex:idx_event_occurrences_event_slot;index:idx_event_occurrences_event_start"`
+	Event              *Event                 `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SlotTime           time.Time              `json:"slotTime" gorm:"not null;uniqueIndex:idx_event_occurrences_event_slot"`
+	StartTime          time.Time              `json:"startTime" gorm:"not null;index:idx_event_occurrences_event_start"`
+	EndTime            time.Time              `json:"endTime" gorm:"not null"`
+	Status             string                 `json:"status" gorm:"not null;default:'Scheduled'"`
+	IsModified         bool                   `json:"isModified" gorm:"not null;default:false"`
+	CancelledAt        *time.Time             `json:"cancelledAt"`
+	CancellationReason string                 `json:"cancellationReason"`
+	Inventories        []*OccurrenceInventory `json:"inventories" gorm:"foreignKey:OccurrenceId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt          time.Time              `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time              `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// OccurrenceInventory holds the seats of a ticket type at one occurrence, every occurrence starts with the
+// quantity of the ticket type and follows it until the seats of the occurrence are set by hand
+type OccurrenceInventory struct {
+	OccurrenceId string      `json:"occurrenceId" gorm:"primaryKey"`
+	TicketTypeId string      `json:"ticketTypeId" gorm:"primaryKey"`
+	TicketType   *TicketType `json:"ticketType" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Quantity     int         `json:"quantity" gorm:"not null"`
+	Sale         int         `json:"sale" gorm:"not null;default:0"`
+	IsOverridden bool        `json:"isOverridden" gorm:"not null;default:false"`
+}
+
+func (o *EventOccurrence) BeforeCreate(tx *gorm.DB) error {
+	o.ID = uuid.New().String()
+
+	return nil
+}
+
+// In shows the times of the occurrence in the location, the timezone of its event
+func (o *EventOccurrence) In(location *time.Location) {
+	o.SlotTime = o.SlotTime.In(location)
+	o.StartTime = o.StartTime.In(location)
+	o.EndTime = o.EndTime.In(location)
+}
+
+func (EventOccurrence) TableName() string {
+	return "event_occurrences"
+}
+
+func (OccurrenceInventory) TableName() string {
+	return "occurrence_ticket_inventories"
+}
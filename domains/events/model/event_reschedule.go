@@ -0,0 +1,57 @@
+package model
+
+import (
+	modelUser "gohub/domains/users/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventReschedule records a move of an event to new times. The ticket holders who ordered before it can
+// keep their tickets or get them refunded until RespondBy, not answering keeps the tickets. A move of one
+// occurrence of a recurring event has its OccurrenceId, only the orders of that occurrence answer it.
+type EventReschedule struct {
+	ID                string          `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventId           string          `json:"eventId" gorm:"not null;index"`
+	Event             *Event          `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OccurrenceId      *string         `json:"occurrenceId" gorm:"index"`
+	UserId            string          `json:"userId" gorm:"not null"`
+	User              *modelUser.User `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PreviousStartTime time.Time       `json:"previousStartTime" gorm:"type:timestamptz;not null"`
+	PreviousEndTime   time.Time       `json:"previousEndTime" gorm:"type:timestamptz;not null"`
+	PreviousTimezone  string          `json:"previousTimezone" gorm:"type:varchar(64);not null"`
+	StartTime         time.Time       `json:"startTime" gorm:"type:timestamptz;not null"`
+	EndTime           time.Time       `json:"endTime" gorm:"type:timestamptz;not null"`
+	Timezone          string          `json:"timezone" gorm:"type:varchar(64);not null"`
+	Reason            string          `json:"reason"`
+	RespondBy         time.Time       `json:"respondBy" gorm:"type:timestamptz;not null"`
+	NotifiedAt        *time.Time      `json:"notifiedAt"`
+	CreatedAt         time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+func (r *EventReschedule) BeforeCreate(tx *gorm.DB) error {
+	r.ID = uuid.New().String()
+
+	return nil
+}
+
+// AfterFind shows the times at the offset of the timezone they were planned in
+func (r *EventReschedule) AfterFind(tx *gorm.DB) error {
+	r.PreviousStartTime = r.PreviousStartTime.In(loadLocation(r.PreviousTimezone))
+	r.PreviousEndTime = r.PreviousEndTime.In(loadLocation(r.PreviousTimezone))
+	r.StartTime = r.StartTime.In(loadLocation(r.Timezone))
+	r.EndTime = r.EndTime.In(loadLocation(r.Timezone))
+	r.RespondBy = r.RespondBy.In(loadLocation(r.Timezone))
+
+	return nil
+}
+
+// IsOpen tells whether the ticket holders can still answer the reschedule
+func (r *EventReschedule) IsOpen(now time.Time) bool {
+	return now.Before(r.RespondBy)
+}
+
+func (EventReschedule) TableName() string {
+	return "event_reschedules"
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	modelUser "gohub/domains/users/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// EventStaff lets a user check in tickets at the door of an event on behalf of its organizer
+type EventStaff struct {
+	ID        string          `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventId   string          `json:"eventId" gorm:"not null;uniqueIndex:idx_event_staffs_event_user"`
+	Event     *Event          `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserId    string          `json:"userId" gorm:"not null;uniqueIndex:idx_event_staffs_event_user"`
+	User      *modelUser.User `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt time.Time       `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+func (e *EventStaff) BeforeCreate(tx *gorm.DB) error {
+	e.ID = uuid.New().String()
+
+	return nil
+}
+
+func (EventStaff) TableName() string {
+	return "event_staffs"
+}
@@ -8,10 +8,12 @@ import (
 
 type EventSubImage struct {
 	ID            string         `json:"id" gorm:"unique;not null;index;primary_key"`
-	EventId       string         `json:"eventId" gorm:"not null"`
+	EventId       string         `json:"eventId" gorm:"not null;index"`
 	Event         *Event         `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	ImageUrl      string         `json:"imageUrl" gorm:"not null"`
 	ImageFileName string         `json:"imageFileName" gorm:"not null"`
+	ImagePublicId string         `json:"-" gorm:"not null;default:''"`
+	SortIndex     int            `json:"sortIndex" gorm:"not null;default:0"`
 	CreatedAt     time.Time      `json:"createdAt" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
 	DeletedAt     gorm.DeletedAt `json:"deletedAt" gorm:"index"`
@@ -72,6 +72,10 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		switch err.Error() {
 		case messages.EventNameAlreadyExists:
 			response.Error(c, http.StatusConflict, err, messages.EventNameAlreadyExists)
+		case messages.UnsupportedCurrency, messages.InvalidPrice, messages.InvalidEventTime, messages.InvalidRecurrenceRule,
+			messages.InvalidExcludedDate, messages.TooManyOccurrences, messages.InvalidTimezone,
+			messages.GalleryFull, messages.GalleryImageTooLarge, messages.GalleryImageNotAllowed:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to create event")
 		}
@@ -95,10 +99,11 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 //		@Router		 /api/v1/events/{eventId} [get]
 func (h *EventHandler) GetEvent(c *gin.Context) {
 	eventId := c.Param("id")
-	event, err := h.service.GetEventById(c, eventId)
+	event, err := h.service.GetEventById(c, eventId, c.GetString("userId"))
 	if err != nil {
 		logger.Error("Failed to get event: ", err)
 		response.Error(c, http.StatusNotFound, err, "Not found")
+		return
 	}
 
 	var res dto.Event
@@ -133,6 +138,11 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		switch err.Error() {
 		case messages.CategoryNameExists:
 			response.Error(c, http.StatusConflict, err, messages.CategoryNameExists)
+		case messages.UnsupportedCurrency, messages.InvalidPrice, messages.InvalidEventTime, messages.InvalidRecurrenceRule,
+			messages.InvalidExcludedDate, messages.TooManyOccurrences, messages.InvalidTimezone:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.CurrencyLocked, messages.EventNotEditable, messages.RescheduleRequired, messages.ReviewedContentLocked:
+			response.Error(c, http.StatusConflict, err, err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to update event")
 		}
@@ -161,6 +171,10 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 
 	if err != nil {
 		logger.Error("Failed to delete event: ", err)
+		if err.Error() == messages.EventHasValidTickets {
+			response.Error(c, http.StatusConflict, err, messages.EventHasValidTickets)
+			return
+		}
 		response.Error(c, http.StatusNotFound, err, "Not found")
 		return
 	}
@@ -190,6 +204,10 @@ func (h *EventHandler) DeleteMultipleEvent(c *gin.Context) {
 
 	if err != nil {
 		logger.Error("Failed to delete events: ", err)
+		if err.Error() == messages.EventHasValidTickets {
+			response.Error(c, http.StatusConflict, err, messages.EventHasValidTickets)
+			return
+		}
 		response.Error(c, http.StatusNotFound, err, "Not found")
 		return
 	}
@@ -526,3 +544,513 @@ func (h *EventHandler) CheckFavourite(c *gin.Context) {
 
 	response.JSON(c, http.StatusOK, result)
 }
+
+//		@Summary	 List the occurrences of an event
+//	 @Description Lists the dates of an event in order, a recurring event has one per date of its recurrence rule and a single event has one. Each occurrence comes with the seats of every ticket type at that date.
+//		@Tags		 Events
+//		@Produce	 json
+//		@Param		 from		query		string	false	"Occurrences ending at or after this time"
+//		@Param		 to			query		string	false	"Occurrences starting at or before this time"
+//		@Param		 status		query		string	false	"Scheduled or Cancelled"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the occurrences"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/occurrences [get]
+func (h *EventHandler) ListOccurrences(c *gin.Context) {
+	var req dto.ListOccurrenceReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	occurrences, pagination, err := h.service.ListOccurrences(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to list occurrences: ", err)
+		occurrenceError(c, err)
+		return
+	}
+
+	var res dto.ListOccurrenceRes
+	utils.MapStruct(&res.Occurrences, &occurrences)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Edit an occurrence of an event
+//	 @Description Moves an occurrence to new times and sets its seats per ticket type. With the future scope every later occurrence of the series moves by the same shift and gets the same seats. Seats cannot go below what was sold. The ticket holders of a moved occurrence are notified and can ask a refund like for a reschedule, a single event with tickets must be rescheduled instead.
+//		@Tags		 Events
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 request	body		dto.UpdateOccurrenceReq	true	"New times, scope and seats"
+//		@Success	 200	{object}	response.Response	"Occurrence updated successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event or occurrence not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Occurrence cancelled, seats below sales or single event with tickets"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/occurrences/{occurrenceId} [put]
+func (h *EventHandler) UpdateOccurrence(c *gin.Context) {
+	var req dto.UpdateOccurrenceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+	req.UserId = c.GetString("userId")
+
+	occurrence, err := h.service.UpdateOccurrence(c, c.Param("id"), c.Param("occurrenceId"), &req)
+	if err != nil {
+		logger.Error("Failed to update occurrence ", err.Error())
+		occurrenceError(c, err)
+		return
+	}
+
+	var res dto.Occurrence
+	utils.MapStruct(&res, &occurrence)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Cancel an occurrence of an event
+//	 @Description Cancels an occurrence so no more tickets are sold for it, its tickets are refunded and their holders notified. With the future scope every later occurrence is cancelled too and the recurrence rule ends before the occurrence.
+//		@Tags		 Events
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 request	body		dto.CancelOccurrenceReq	false	"Scope and reason of the cancellation"
+//		@Success	 200	{object}	response.Response	"Occurrence cancelled successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event or occurrence not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Occurrence already cancelled"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/occurrences/{occurrenceId}/cancel [patch]
+func (h *EventHandler) CancelOccurrence(c *gin.Context) {
+	var req dto.CancelOccurrenceReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error("Failed to get body", err)
+			response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+			return
+		}
+	}
+	req.UserId = c.GetString("userId")
+
+	occurrence, err := h.service.CancelOccurrence(c, c.Param("id"), c.Param("occurrenceId"), &req)
+	if err != nil {
+		logger.Error("Failed to cancel occurrence ", err.Error())
+		occurrenceError(c, err)
+		return
+	}
+
+	var res dto.Occurrence
+	utils.MapStruct(&res, &occurrence)
+	response.JSON(c, http.StatusOK, res)
+}
+
+func occurrenceError(c *gin.Context, err error) {
+	switch err.Error() {
+	case messages.EventNotFound, messages.OccurrenceNotFound, messages.TicketTypeNotFound:
+		response.Error(c, http.StatusNotFound, err, err.Error())
+	case messages.NotEventOwner:
+		response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+	case messages.OccurrenceCancelled, messages.QuantityBelowSale, messages.RescheduleRequired:
+		response.Error(c, http.StatusConflict, err, err.Error())
+	case messages.InvalidEventTime:
+		response.Error(c, http.StatusBadRequest, err, messages.InvalidEventTime)
+	default:
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+	}
+}
+
+//		@Summary	 Submit an event for review
+//	 @Description Sends a draft event to the admins for review. With a publish time the approved event goes live at that time, otherwise as soon as it is approved.
+//		@Tags		 Events
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 request	body		dto.SubmitEventReq	false	"Scheduled publish time"
+//		@Success	 200	{object}	response.Response	"Event submitted successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event is not a draft"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/submit [patch]
+func (h *EventHandler) SubmitEvent(c *gin.Context) {
+	var req dto.SubmitEventReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error("Failed to get body", err)
+			response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+			return
+		}
+	}
+	req.UserId = c.GetString("userId")
+
+	event, err := h.service.SubmitEvent(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to submit event ", err.Error())
+		eventStateError(c, err)
+		return
+	}
+
+	var res dto.Event
+	utils.MapStruct(&res, &event)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Withdraw an event from review
+//	 @Description Takes an event waiting for review back to draft.
+//		@Tags		 Events
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Event withdrawn successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event is not in review"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/withdraw [patch]
+func (h *EventHandler) WithdrawEvent(c *gin.Context) {
+	event, err := h.service.WithdrawEvent(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to withdraw event ", err.Error())
+		eventStateError(c, err)
+		return
+	}
+
+	var res dto.Event
+	utils.MapStruct(&res, &event)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Review an event
+//	 @Description Approves or rejects an event waiting for review. Only admins can review, a rejection needs a note for the organizer.
+//		@Tags		 Events
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 request	body		dto.ReviewEventReq	true	"Decision and note"
+//		@Success	 200	{object}	response.Response	"Event reviewed successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not an admin"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event is not waiting for review"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/review [patch]
+func (h *EventHandler) ReviewEvent(c *gin.Context) {
+	var req dto.ReviewEventReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+	req.UserId = c.GetString("userId")
+
+	event, err := h.service.ReviewEvent(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to review event ", err.Error())
+		eventStateError(c, err)
+		return
+	}
+
+	var res dto.Event
+	utils.MapStruct(&res, &event)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Cancel an event
+//	 @Description Cancels an event for its organizer or an admin. Sales stop, paid tickets are refunded and their holders notified.
+//		@Tags		 Events
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 request	body		dto.CancelEventReq	false	"Reason told to the ticket holders"
+//		@Success	 200	{object}	response.Response	"Event cancelled successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event already cancelled or completed"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/cancel [patch]
+func (h *EventHandler) CancelEvent(c *gin.Context) {
+	var req dto.CancelEventReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			logger.Error("Failed to get body", err)
+			response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+			return
+		}
+	}
+	req.UserId = c.GetString("userId")
+
+	event, err := h.service.CancelEvent(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to cancel event ", err.Error())
+		eventStateError(c, err)
+		return
+	}
+
+	var res dto.Event
+	utils.MapStruct(&res, &event)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 List the events waiting for review
+//	 @Description Lists the submitted events not reviewed yet, the longest waiting first. Only admins can see them.
+//		@Tags		 Events
+//		@Produce	 json
+//		@Param		 page		query		int	false	"Page number"
+//		@Param		 pageSize	query		int	false	"Page size"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the events"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not an admin"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/pending-review [get]
+func (h *EventHandler) ListPendingEvents(c *gin.Context) {
+	var req dto.ListPendingEventReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	events, pagination, err := h.service.ListPendingEvents(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to list pending events: ", err)
+		eventStateError(c, err)
+		return
+	}
+
+	var res dto.ListPendingEventRes
+	utils.MapStruct(&res.Events, &events)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 List the reviews of an event
+//	 @Description Lists the decisions the admins took on an event, for its organizer and the admins.
+//		@Tags		 Events
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the reviews"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/moderations [get]
+func (h *EventHandler) ListModerations(c *gin.Context) {
+	moderations, err := h.service.ListModerations(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to list moderations: ", err)
+		eventStateError(c, err)
+		return
+	}
+
+	var res []*dto.EventModeration
+	utils.MapStruct(&res, &moderations)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Reschedule an event
+//	 @Description Moves a single event to new times and keeps the previous ones in its history. Ticket holders are notified and can keep their tickets or get a refund until the response window closes.
+//		@Tags		 Events
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 request	body		dto.RescheduleEventReq	true	"New times and reason"
+//		@Success	 200	{object}	response.Response	"Event rescheduled successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event cancelled, completed or recurring"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/reschedule [patch]
+func (h *EventHandler) RescheduleEvent(c *gin.Context) {
+	var req dto.RescheduleEventReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+	req.UserId = c.GetString("userId")
+
+	event, err := h.service.RescheduleEvent(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to reschedule event ", err.Error())
+		eventStateError(c, err)
+		return
+	}
+
+	var res dto.Event
+	utils.MapStruct(&res, &event)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 List the reschedules of an event
+//	 @Description Lists the times an event was moved from and to, the latest first.
+//		@Tags		 Events
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the reschedules"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/reschedules [get]
+func (h *EventHandler) ListReschedules(c *gin.Context) {
+	reschedules, err := h.service.ListReschedules(c, c.Param("id"), c.GetString("userId"))
+	if err != nil {
+		logger.Error("Failed to list reschedules: ", err)
+		eventStateError(c, err)
+		return
+	}
+
+	var res []*dto.EventReschedule
+	utils.MapStruct(&res, &reschedules)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Add images to the gallery of an event
+//	 @Description Uploads one or more JPEG, PNG, GIF or WebP images of at most 10MB each to the end of the gallery. A gallery holds at most 20 images.
+//		@Tags		 Events
+//		@Accept		 multipart/form-data
+//		@Produce	 json
+//		@Param		 images	formData	file	true	"Images to add"
+//		@Success	 200	{object}	response.Response	"Images added successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Missing, too large or unsupported images, or gallery full"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event cancelled or completed"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/images [post]
+func (h *EventHandler) AddGalleryImages(c *gin.Context) {
+	var req dto.AddGalleryImageReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+	req.UserId = c.GetString("userId")
+
+	images, err := h.service.AddGalleryImages(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to add gallery images ", err.Error())
+		galleryError(c, err)
+		return
+	}
+
+	var res []*dto.SubImage
+	utils.MapStruct(&res, &images)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Delete an image of the gallery
+//	 @Description Removes an image from the gallery of an event and from the storage.
+//		@Tags		 Events
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Image deleted successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event or image not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event cancelled or completed"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/images/{imageId} [delete]
+func (h *EventHandler) DeleteGalleryImage(c *gin.Context) {
+	if err := h.service.DeleteGalleryImage(c, c.GetString("userId"), c.Param("id"), c.Param("imageId")); err != nil {
+		logger.Error("Failed to delete gallery image ", err.Error())
+		galleryError(c, err)
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Delete image successfully")
+}
+
+//		@Summary	 Reorder the gallery of an event
+//	 @Description Sorts the gallery in the order of the given image IDs, which must list every image of the gallery once.
+//		@Tags		 Events
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 request	body		dto.ReorderGalleryImageReq	true	"Image IDs in their new order"
+//		@Success	 200	{object}	response.Response	"Gallery reordered successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event cancelled or completed"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/images/order [put]
+func (h *EventHandler) ReorderGalleryImages(c *gin.Context) {
+	var req dto.ReorderGalleryImageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+	req.UserId = c.GetString("userId")
+
+	images, err := h.service.ReorderGalleryImages(c, c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to reorder gallery images ", err.Error())
+		galleryError(c, err)
+		return
+	}
+
+	var res []*dto.SubImage
+	utils.MapStruct(&res, &images)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Set an image of the gallery as cover
+//	 @Description Makes an image of the gallery the cover of the event, the previous cover takes its place in the gallery.
+//		@Tags		 Events
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Cover set successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event or image not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Event cancelled or completed"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/events/{eventId}/images/{imageId}/cover [patch]
+func (h *EventHandler) SetGalleryCover(c *gin.Context) {
+	event, err := h.service.SetGalleryCover(c, c.GetString("userId"), c.Param("id"), c.Param("imageId"))
+	if err != nil {
+		logger.Error("Failed to set gallery cover ", err.Error())
+		galleryError(c, err)
+		return
+	}
+
+	var res dto.Event
+	utils.MapStruct(&res, &event)
+	response.JSON(c, http.StatusOK, res)
+}
+
+func galleryError(c *gin.Context, err error) {
+	switch err.Error() {
+	case messages.EventNotFound, messages.SubImageNotFound:
+		response.Error(c, http.StatusNotFound, err, err.Error())
+	case messages.NotEventOwner:
+		response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+	case messages.EventNotEditable:
+		response.Error(c, http.StatusConflict, err, messages.EventNotEditable)
+	case messages.NoGalleryImages, messages.GalleryFull, messages.GalleryImageTooLarge, messages.GalleryImageNotAllowed,
+		messages.InvalidImageOrder:
+		response.Error(c, http.StatusBadRequest, err, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+	}
+}
+
+func eventStateError(c *gin.Context, err error) {
+	switch err.Error() {
+	case messages.EventNotFound:
+		response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+	case messages.NotEventOwner, messages.NotEventModerator:
+		response.Error(c, http.StatusForbidden, err, err.Error())
+	case messages.InvalidEventTransition, messages.EventNotEditable, messages.RecurringEventReschedule:
+		response.Error(c, http.StatusConflict, err, err.Error())
+	case messages.InvalidPublishTime, messages.InvalidEventTime, messages.InvalidTimezone:
+		response.Error(c, http.StatusBadRequest, err, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+	}
+}
@@ -20,7 +20,8 @@ func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.V
 	{
 		eventRoute.GET("/", eventHandler.GetEvents)
 		eventRoute.POST("/", authMiddleware, eventHandler.CreateEvent)
-		eventRoute.GET("/:id", eventHandler.GetEvent)
+		eventRoute.GET("/pending-review", authMiddleware, eventHandler.ListPendingEvents)
+		eventRoute.GET("/:id", middleware.JWTOptional(), eventHandler.GetEvent)
 		eventRoute.PUT("/:id", authMiddleware, eventHandler.UpdateEvent)
 		eventRoute.DELETE("/:id", authMiddleware, eventHandler.DeleteEvent)
 		eventRoute.DELETE("/", authMiddleware, eventHandler.DeleteMultipleEvent)
@@ -35,5 +36,19 @@ func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.V
 		eventRoute.PATCH("/make-events-public", authMiddleware, eventHandler.MakeEventPublic)
 		eventRoute.PATCH("/apply-coupons/:id", authMiddleware, eventHandler.ApplyCoupons)
 		eventRoute.GET("/check-favourite/:id", authMiddleware, eventHandler.CheckFavourite)
+		eventRoute.GET("/:id/occurrences", eventHandler.ListOccurrences)
+		eventRoute.PUT("/:id/occurrences/:occurrenceId", authMiddleware, eventHandler.UpdateOccurrence)
+		eventRoute.PATCH("/:id/occurrences/:occurrenceId/cancel", authMiddleware, eventHandler.CancelOccurrence)
+		eventRoute.PATCH("/:id/submit", authMiddleware, eventHandler.SubmitEvent)
+		eventRoute.PATCH("/:id/withdraw", authMiddleware, eventHandler.WithdrawEvent)
+		eventRoute.PATCH("/:id/review", authMiddleware, eventHandler.ReviewEvent)
+		eventRoute.PATCH("/:id/cancel", authMiddleware, eventHandler.CancelEvent)
+		eventRoute.GET("/:id/moderations", authMiddleware, eventHandler.ListModerations)
+		eventRoute.PATCH("/:id/reschedule", authMiddleware, eventHandler.RescheduleEvent)
+		eventRoute.GET("/:id/reschedules", middleware.JWTOptional(), eventHandler.ListReschedules)
+		eventRoute.POST("/:id/images", authMiddleware, eventHandler.AddGalleryImages)
+		eventRoute.PUT("/:id/images/order", authMiddleware, eventHandler.ReorderGalleryImages)
+		eventRoute.DELETE("/:id/images/:imageId", authMiddleware, eventHandler.DeleteGalleryImage)
+		eventRoute.PATCH("/:id/images/:imageId/cover", authMiddleware, eventHandler.SetGalleryCover)
 	}
 }
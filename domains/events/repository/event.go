@@ -9,6 +9,7 @@ import (
 	"gohub/pkg/paging"
 	"gohub/pkg/utils"
 	"gorm.io/gorm"
+	"time"
 )
 
 type IEventRepository interface {
@@ -30,6 +31,26 @@ type IEventRepository interface {
 	MakeEventPublicOrPrivate(ctx context.Context, req *dto.MakeEventPublicOrPrivateReq, isPrivate bool) error
 	ApplyCoupons(ctx context.Context, eventId string, req *dto.ApplyCouponReq) error
 	CheckFavourite(ctx context.Context, req *dto.UserFavouriteEvent) (bool, error)
+	HasPayments(ctx context.Context, eventId string) (bool, error)
+	SyncOccurrences(ctx context.Context, event *model.Event, starts []time.Time, duration time.Duration) error
+	ListOccurrences(ctx context.Context, eventId string, req *dto.ListOccurrenceReq) ([]*model.EventOccurrence, *paging.Pagination, error)
+	GetOccurrence(ctx context.Context, eventId string, id string) (*model.EventOccurrence, error)
+	UpdateOccurrences(ctx context.Context, event *model.Event, occurrence *model.EventOccurrence, future bool, start time.Time, end time.Time, tickets []*dto.OccurrenceTicketReq, reschedule *model.EventReschedule) error
+	CancelOccurrences(ctx context.Context, occurrence *model.EventOccurrence, future bool, rule string, reason string) error
+	IsAdmin(ctx context.Context, userId string) (bool, error)
+	MoveEvent(ctx context.Context, eventId string, from []string, changes map[string]interface{}, moderation *model.EventModeration) error
+	ListPendingEvents(ctx context.Context, req *dto.ListPendingEventReq) ([]*model.Event, *paging.Pagination, error)
+	ListModerations(ctx context.Context, eventId string) ([]*model.EventModeration, error)
+	PublishDueEvents(ctx context.Context) (int64, error)
+	CompleteEndedEvents(ctx context.Context) (int64, error)
+	HasValidTickets(ctx context.Context, eventIds []string) (bool, error)
+	RescheduleEvent(ctx context.Context, event *model.Event, reschedule *model.EventReschedule, starts []time.Time, duration time.Duration) error
+	ListReschedules(ctx context.Context, eventId string) ([]*model.EventReschedule, error)
+	ListSubImages(ctx context.Context, eventId string) ([]*model.EventSubImage, error)
+	AddSubImages(ctx context.Context, eventId string, images []*model.EventSubImage) error
+	DeleteSubImage(ctx context.Context, eventId string, imageId string) (*model.EventSubImage, error)
+	ReorderSubImages(ctx context.Context, eventId string, imageIds []string) error
+	SwapCoverImage(ctx context.Context, eventId string, imageId string) error
 }
 
 type EventRepo struct {
@@ -43,13 +64,14 @@ func NewEventRepository(db database.IDatabase) *EventRepo {
 func (e *EventRepo) CreateEvent(ctx context.Context, event *model.Event, req *dto.CreateEventReq) error {
 	handler := func() error {
 		if req.CoverImage.Header != nil && req.CoverImage.Filename != "" {
-			uploadUrl, err := utils.ImageUpload(req.CoverImage, "/eventhub/events")
+			stored, err := utils.ImageStore(req.CoverImage, "/eventhub/events")
 			if err != nil {
 				return err
 			}
 
 			event.CoverImageFileName = req.CoverImage.Filename
-			event.CoverImageUrl = uploadUrl
+			event.CoverImageUrl = stored.Url
+			event.CoverImagePublicId = stored.PublicId
 		}
 
 		if err := e.db.Create(ctx, event); err != nil {
@@ -64,21 +86,6 @@ func (e *EventRepo) CreateEvent(ctx context.Context, event *model.Event, req *dt
 			return err
 		}
 
-		var subImages []*model.EventSubImage
-		for _, subImage := range req.SubImageItems {
-			if subImage.Header != nil && subImage.Filename != "" {
-				uploadUrl, err := utils.ImageUpload(subImage, "/eventhub/events")
-				if err != nil {
-					return err
-				}
-
-				subImages = append(subImages, &model.EventSubImage{EventId: event.ID, ImageUrl: uploadUrl, ImageFileName: subImage.Filename})
-			}
-		}
-		if err := e.db.CreateInBatches(ctx, &subImages, len(subImages)); err != nil {
-			return err
-		}
-
 		var reasons []*model.Reason
 		for _, reason := range req.ReasonItems {
 			reasons = append(reasons, &model.Reason{EventId: event.ID, Content: reason})
@@ -90,7 +97,7 @@ func (e *EventRepo) CreateEvent(ctx context.Context, event *model.Event, req *dt
 		var ticketTypes []*model.TicketType
 		for _, ticketItem := range req.TicketTypeItems {
 			ticketTypes = append(ticketTypes,
-				&model.TicketType{EventId: event.ID, Name: ticketItem.Name, Quantity: ticketItem.Quantity, Price: ticketItem.Price},
+				&model.TicketType{EventId: event.ID, Name: ticketItem.Name, Quantity: ticketItem.Quantity, Price: ticketItem.UnitPrice, IsTransferDisabled: ticketItem.IsTransferDisabled},
 			)
 		}
 		if err := e.db.CreateInBatches(ctx, &ticketTypes, len(ticketTypes)); err != nil {
@@ -112,16 +119,18 @@ func (e *EventRepo) CreateEvent(ctx context.Context, event *model.Event, req *dt
 func (e *EventRepo) UpdateEvent(ctx context.Context, event *model.Event, req *dto.UpdateEventReq) error {
 	handler := func() error {
 		if req.CoverImage.Header != nil && req.CoverImage.Filename != "" {
-			uploadUrl, err := utils.ImageUpload(req.CoverImage, "/eventhub/events")
+			stored, err := utils.ImageStore(req.CoverImage, "/eventhub/events")
 			if err != nil {
 				return err
 			}
 
 			event.CoverImageFileName = req.CoverImage.Filename
-			event.CoverImageUrl = uploadUrl
+			event.CoverImageUrl = stored.Url
+			event.CoverImagePublicId = stored.PublicId
 		}
 
-		if err := e.db.Update(ctx, event); err != nil {
+		// The state only moves through its transitions, an edit never writes it back
+		if err := e.db.GetDB().WithContext(ctx).Omit(lifecycleColumns...).Save(event).Error; err != nil {
 			return err
 		}
 
@@ -136,24 +145,6 @@ func (e *EventRepo) UpdateEvent(ctx context.Context, event *model.Event, req *dt
 			return err
 		}
 
-		//if err := e.db.ForceDelete(ctx, model.EventSubImage{}, database.WithQuery(database.NewQuery("event_id = ?", req.ID))); err != nil {
-		//	return err
-		//}
-		//var subImages []*model.EventSubImage
-		//for _, subImage := range req.SubImageItems {
-		//	if subImage.Header != nil && subImage.Filename != "" {
-		//		uploadUrl, err := utils.ImageUpload(subImage, "/eventhub/events")
-		//		if err != nil {
-		//			return err
-		//		}
-		//
-		//		subImages = append(subImages, &model.EventSubImage{EventId: event.ID, ImageUrl: uploadUrl, ImageFileName: subImage.Filename})
-		//	}
-		//}
-		//if err := e.db.CreateInBatches(ctx, &subImages, len(subImages)); err != nil {
-		//	return err
-		//}
-
 		if err := e.db.ForceDelete(ctx, model.Reason{}, database.WithQuery(database.NewQuery("event_id = ?", req.ID))); err != nil {
 			return err
 		}
@@ -171,7 +162,7 @@ func (e *EventRepo) UpdateEvent(ctx context.Context, event *model.Event, req *dt
 		var ticketTypes []*model.TicketType
 		for _, ticketItem := range req.TicketTypeItems {
 			ticketTypes = append(ticketTypes,
-				&model.TicketType{EventId: event.ID, Name: ticketItem.Name, Quantity: ticketItem.Quantity, Price: ticketItem.Price},
+				&model.TicketType{EventId: event.ID, Name: ticketItem.Name, Quantity: ticketItem.Quantity, Price: ticketItem.UnitPrice, IsTransferDisabled: ticketItem.IsTransferDisabled},
 			)
 		}
 		if err := e.db.CreateInBatches(ctx, &ticketTypes, len(ticketTypes)); err != nil {
@@ -215,8 +206,8 @@ func (e *EventRepo) ListEvents(ctx context.Context, req *dto.ListEventReq) ([]*m
 	query := make([]database.Query, 0)
 	args := make([]interface{}, 0)
 
-	queryString := "is_private = ?"
-	args = append(args, false)
+	queryString := "is_private = ? AND events.state IN ?"
+	args = append(args, false, listedStates)
 
 	if req.Search != "" {
 		queryString += " AND name LIKE ?"
@@ -228,21 +219,22 @@ func (e *EventRepo) ListEvents(ctx context.Context, req *dto.ListEventReq) ([]*m
 		args = append(args, req.CategoryIds)
 	}
 
+	// A recurring event is upcoming while one of its occurrences is ahead and closes after the last one ends
 	if req.Status != "All" {
 		switch req.Status {
 		case "Upcoming":
-			queryString += " AND start_time::date > NOW()::date"
+			queryString += " AND EXISTS (" + scheduledOccurrences + " AND event_occurrences.start_time > NOW())"
 		case "Opening":
-			queryString += " AND NOW()::date BETWEEN start_time::date AND end_time::date"
+			queryString += " AND EXISTS (" + scheduledOccurrences + " AND NOW() BETWEEN event_occurrences.start_time AND event_occurrences.end_time)"
 		case "Close":
-			queryString += " AND end_time::date < NOW()::date"
+			queryString += " AND NOT EXISTS (" + scheduledOccurrences + " AND event_occurrences.end_time >= NOW())"
 		default:
 			queryString += ""
 		}
 	}
 
 	if req.StartTimeRange != "" && req.EndTimeRange != "" {
-		queryString += " AND start_time BETWEEN ? AND ?"
+		queryString += " AND EXISTS (" + scheduledOccurrences + " AND event_occurrences.start_time BETWEEN ? AND ?)"
 		args = append(args, req.StartTimeRange, req.EndTimeRange)
 	}
 
@@ -315,6 +307,10 @@ func (e *EventRepo) ListEvents(ctx context.Context, req *dto.ListEventReq) ([]*m
 		return nil, nil, err
 	}
 
+	if err := e.upcomingOccurrences(ctx, events, req.StartTimeRange, req.EndTimeRange); err != nil {
+		return nil, nil, err
+	}
+
 	return events, pagination, nil
 }
 
@@ -355,15 +351,20 @@ func (e *EventRepo) ListCreatedEvents(ctx context.Context, userId string, req *d
 
 	switch req.Status {
 	case "Upcoming":
-		queryString += " AND start_time::date > NOW()::date"
+		queryString += " AND EXISTS (" + scheduledOccurrences + " AND event_occurrences.start_time > NOW())"
 	case "Opening":
-		queryString += " AND NOW()::date BETWEEN start_time::date AND end_time::date"
+		queryString += " AND EXISTS (" + scheduledOccurrences + " AND NOW() BETWEEN event_occurrences.start_time AND event_occurrences.end_time)"
 	case "Close":
-		queryString += " AND end_time::date < NOW()::date"
+		queryString += " AND NOT EXISTS (" + scheduledOccurrences + " AND event_occurrences.end_time >= NOW())"
 	default:
 		queryString += ""
 	}
 
+	if req.State != "" {
+		queryString += " AND state = ?"
+		args = append(args, req.State)
+	}
+
 	switch req.PaymentType {
 	case "Free":
 		queryString += " AND event_payment_type = ?"
@@ -416,7 +417,7 @@ func (e *EventRepo) ListCreatedEvents(ctx context.Context, userId string, req *d
 		database.WithLimit(int(pagination.PageSize)),
 		database.WithOffset(int(pagination.Skip)),
 		database.WithOrder(order),
-		database.WithSelect("events.id, events.name, events.cover_image_url, events.start_time, events.location, events.is_private, events.deleted_at"),
+		database.WithSelect("events.id, events.name, events.cover_image_url, events.start_time, events.timezone, events.location, events.is_private, events.state, events.publish_at, events.deleted_at"),
 		database.WithJoin(`
 			INNER JOIN event_categories ON event_categories.event_id = events.id
 		`),
@@ -569,8 +570,8 @@ func (e *EventRepo) ListFavouriteEvents(ctx context.Context, userId string, req
 	query := make([]database.Query, 0)
 	args := make([]interface{}, 0)
 
-	queryString := "event_favourites.user_id = ?"
-	args = append(args, userId)
+	queryString := "event_favourites.user_id = ? AND events.state IN ?"
+	args = append(args, userId, favouriteStates)
 
 	if req.Search != "" {
 		queryString += " AND name LIKE ?"
@@ -723,3 +724,19 @@ func (e *EventRepo) CheckFavourite(ctx context.Context, req *dto.UserFavouriteEv
 	}
 	return true, nil
 }
+
+// HasPayments reports whether anyone ordered tickets of the event, whatever became of the order
+func (e *EventRepo) HasPayments(ctx context.Context, eventId string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var total int64
+	if err := e.db.GetDB().WithContext(ctx).
+		Table("payments").
+		Where("event_id = ?", eventId).
+		Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/events/model"
+	"gohub/pkg/messages"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (e *EventRepo) ListSubImages(ctx context.Context, eventId string) ([]*model.EventSubImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var images []*model.EventSubImage
+	if err := e.db.GetDB().WithContext(ctx).
+		Where("event_id = ?", eventId).
+		Order("sort_index ASC, created_at ASC").
+		Find(&images).Error; err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// AddSubImages appends the images to the end of the gallery, as long as it has room for all of them
+func (e *EventRepo) AddSubImages(ctx context.Context, eventId string, images []*model.EventSubImage) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return e.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockEvent(tx, eventId); err != nil {
+			return err
+		}
+
+		var gallery struct {
+			Total int
+			Next  int
+		}
+		if err := tx.Model(&model.EventSubImage{}).
+			Select("COUNT(*) AS total, COALESCE(MAX(sort_index) + 1, 0) AS next").
+			Where("event_id = ?", eventId).
+			Scan(&gallery).Error; err != nil {
+			return err
+		}
+
+		if gallery.Total+len(images) > configs.MaxGalleryImages {
+			return errors.New(messages.GalleryFull)
+		}
+
+		for i, image := range images {
+			image.EventId = eventId
+			image.SortIndex = gallery.Next + i
+		}
+
+		return tx.Create(&images).Error
+	})
+}
+
+// DeleteSubImage removes the image from the gallery and returns it, so its stored object can be removed too
+func (e *EventRepo) DeleteSubImage(ctx context.Context, eventId string, imageId string) (*model.EventSubImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var image model.EventSubImage
+	result := e.db.GetDB().WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND event_id = ?", imageId, eventId).
+		Unscoped().
+		Delete(&image)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, errors.New(messages.SubImageNotFound)
+	}
+
+	return &image, nil
+}
+
+// ReorderSubImages sorts the gallery in the order of the ids, which must list each of its images once
+func (e *EventRepo) ReorderSubImages(ctx context.Context, eventId string, imageIds []string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return e.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockEvent(tx, eventId); err != nil {
+			return err
+		}
+
+		var total int64
+		if err := tx.Model(&model.EventSubImage{}).
+			Where("event_id = ? AND id IN ?", eventId, imageIds).
+			Count(&total).Error; err != nil {
+			return err
+		}
+
+		var all int64
+		if err := tx.Model(&model.EventSubImage{}).Where("event_id = ?", eventId).Count(&all).Error; err != nil {
+			return err
+		}
+
+		if total != int64(len(imageIds)) || total != all {
+			return errors.New(messages.InvalidImageOrder)
+		}
+
+		for index, imageId := range imageIds {
+			if err := tx.Model(&model.EventSubImage{}).
+				Where("id = ?", imageId).
+				Update("sort_index", index).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// SwapCoverImage makes the image the cover of the event. The previous cover takes its place in the gallery,
+// an event without a cover simply loses the image from its gallery.
+func (e *EventRepo) SwapCoverImage(ctx context.Context, eventId string, imageId string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return e.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event model.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "cover_image_url", "cover_image_file_name", "cover_image_public_id").
+			Where("id = ?", eventId).
+			Take(&event).Error; err != nil {
+			return err
+		}
+
+		var image model.EventSubImage
+		if err := tx.Where("id = ? AND event_id = ?", imageId, eventId).Take(&image).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(messages.SubImageNotFound)
+			}
+			return err
+		}
+
+		if err := tx.Model(&model.Event{}).Where("id = ?", eventId).Updates(map[string]interface{}{
+			"cover_image_url":       image.ImageUrl,
+			"cover_image_file_name": image.ImageFileName,
+			"cover_image_public_id": image.ImagePublicId,
+		}).Error; err != nil {
+			return err
+		}
+
+		if event.CoverImageUrl == "" {
+			return tx.Unscoped().Delete(&image).Error
+		}
+
+		return tx.Model(&image).Updates(map[string]interface{}{
+			"image_url":       event.CoverImageUrl,
+			"image_file_name": event.CoverImageFileName,
+			"image_public_id": event.CoverImagePublicId,
+		}).Error
+	})
+}
+
+// lockEvent serializes the changes to the gallery of an event
+func lockEvent(tx *gorm.DB, eventId string) error {
+	var event model.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", eventId).Take(&event).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(messages.EventNotFound)
+		}
+		return err
+	}
+
+	return nil
+}
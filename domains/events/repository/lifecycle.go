@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/events/dto"
+	"gohub/domains/events/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+
+	"gorm.io/gorm"
+)
+
+// listedStates are the states of the events the public listings show
+var listedStates = []string{model.EventStatePublished, model.EventStateCompleted}
+
+// favouriteStates are the states of the favourite events a user is shown, a cancelled favourite stays so its
+// cancellation does not go unnoticed
+var favouriteStates = []string{model.EventStatePublished, model.EventStateCancelled, model.EventStateCompleted}
+
+// lifecycleColumns are only written by state transitions
+var lifecycleColumns = []string{"state", "publish_at", "published_at", "approved_at", "cancelled_at", "cancellation_reason"}
+
+func (e *EventRepo) IsAdmin(ctx context.Context, userId string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var total int64
+	if err := e.db.GetDB().WithContext(ctx).
+		Table("user_roles").
+		Joins("INNER JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND roles.name = ? AND user_roles.deleted_at IS NULL", userId, configs.AdminRoleName).
+		Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+// MoveEvent applies the changes to the event when it is still in one of the from states, so two transitions
+// racing each other cannot both apply. The moderation is recorded along, a cancelled event cancels its
+// scheduled occurrences.
+func (e *EventRepo) MoveEvent(ctx context.Context, eventId string, from []string, changes map[string]interface{}, moderation *model.EventModeration) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return e.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Event{}).Where("id = ? AND state IN ?", eventId, from).Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New(messages.InvalidEventTransition)
+		}
+
+		if moderation != nil {
+			if err := tx.Create(moderation).Error; err != nil {
+				return err
+			}
+		}
+
+		if changes["state"] != model.EventStateCancelled {
+			return nil
+		}
+
+		return tx.Model(&model.EventOccurrence{}).
+			Where("event_id = ? AND status = ?", eventId, model.OccurrenceStatusScheduled).
+			Update("status", model.OccurrenceStatusCancelled).Error
+	})
+}
+
+// ListPendingEvents lists the events waiting for a decision of an admin, the longest waiting first
+func (e *EventRepo) ListPendingEvents(ctx context.Context, req *dto.ListPendingEventReq) ([]*model.Event, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	pending := func(db *gorm.DB) *gorm.DB {
+		return db.Where("state = ? AND approved_at IS NULL", model.EventStatePendingReview)
+	}
+
+	var total int64
+	if err := e.db.GetDB().WithContext(ctx).Model(&model.Event{}).Scopes(pending).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	var events []*model.Event
+	if err := e.db.GetDB().WithContext(ctx).
+		Scopes(pending).
+		Preload("User").
+		Preload("Categories").
+		Order("updated_at ASC").
+		Limit(int(pagination.PageSize)).
+		Offset(int(pagination.Skip)).
+		Find(&events).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return events, pagination, nil
+}
+
+func (e *EventRepo) ListModerations(ctx context.Context, eventId string) ([]*model.EventModeration, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var moderations []*model.EventModeration
+	if err := e.db.GetDB().WithContext(ctx).
+		Preload("Moderator").
+		Where("event_id = ?", eventId).
+		Order("created_at DESC").
+		Find(&moderations).Error; err != nil {
+		return nil, err
+	}
+
+	return moderations, nil
+}
+
+// PublishDueEvents publishes the approved events whose publish time came
+func (e *EventRepo) PublishDueEvents(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	result := e.db.GetDB().WithContext(ctx).
+		Model(&model.Event{}).
+		Where("state = ? AND approved_at IS NOT NULL AND publish_at <= NOW()", model.EventStatePendingReview).
+		Updates(map[string]interface{}{
+			"state":        model.EventStatePublished,
+			"published_at": gorm.Expr("NOW()"),
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+// CompleteEndedEvents completes the published events that have nothing scheduled anymore
+func (e *EventRepo) CompleteEndedEvents(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	result := e.db.GetDB().WithContext(ctx).
+		Model(&model.Event{}).
+		Where("state = ? AND end_time < NOW() AND NOT EXISTS ("+scheduledOccurrences+" AND event_occurrences.end_time >= NOW())", model.EventStatePublished).
+		Update("state", model.EventStateCompleted)
+
+	return result.RowsAffected, result.Error
+}
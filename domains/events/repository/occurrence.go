@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/events/dto"
+	"gohub/domains/events/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// listedOccurrences is how many upcoming occurrences each event of a listing comes with
+const listedOccurrences = 5
+
+// scheduledOccurrences selects the scheduled occurrences of the event of the outer query
+const scheduledOccurrences = "SELECT 1 FROM event_occurrences WHERE event_occurrences.event_id = events.id AND event_occurrences.status = 'Scheduled'"
+
+// unbooked keeps the occurrences nobody ordered tickets for, whatever became of the order
+const unbooked = "NOT EXISTS (SELECT 1 FROM payments WHERE payments.occurrence_id = event_occurrences.id)"
+
+// SyncOccurrences makes the occurrences of the event match the starts its schedule expands to. Occurrences
+// that were moved or cancelled by hand keep their changes, the ones the schedule dropped are deleted unless
+// tickets were ordered for them. Every occurrence gets the seats of the ticket types it has none of yet, and
+// the scheduled ones follow the quantity of their ticket types unless their seats were set by hand. The seats
+// never go below the ones already sold.
+func (e *EventRepo) SyncOccurrences(ctx context.Context, event *model.Event, starts []time.Time, duration time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return e.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return syncOccurrences(tx, event, starts, duration)
+	})
+}
+
+// syncOccurrences is SyncOccurrences within the transaction of the caller
+func syncOccurrences(tx *gorm.DB, event *model.Event, starts []time.Time, duration time.Duration) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "occurrences:"+event.ID).Error; err != nil {
+		return err
+	}
+
+	var existing []*model.EventOccurrence
+	if err := tx.Where("event_id = ?", event.ID).Order("slot_time ASC").Find(&existing).Error; err != nil {
+		return err
+	}
+
+	// A single event has one occurrence whatever its times, moving the event moves it. The one already at
+	// the new start is kept when the event used to repeat.
+	if event.RecurrenceRule == "" && len(existing) > 0 {
+		kept := 0
+		for i, occurrence := range existing {
+			if occurrence.SlotTime.Equal(starts[0]) {
+				kept = i
+			}
+		}
+
+		first := existing[kept]
+		if err := tx.Model(first).Updates(map[string]interface{}{
+			"slot_time":   starts[0],
+			"start_time":  starts[0],
+			"end_time":    starts[0].Add(duration),
+			"is_modified": false,
+		}).Error; err != nil {
+			return err
+		}
+
+		existing = append(existing[:kept], existing[kept+1:]...)
+		starts = nil
+	}
+
+	slots := make(map[int64]time.Time, len(starts))
+	for _, start := range starts {
+		slots[start.Unix()] = start
+	}
+
+	var dropped []string
+	for _, occurrence := range existing {
+		start, planned := slots[occurrence.SlotTime.Unix()]
+		delete(slots, occurrence.SlotTime.Unix())
+
+		switch {
+		case occurrence.IsModified || occurrence.Status != model.OccurrenceStatusScheduled:
+		case planned:
+			if err := tx.Model(occurrence).Updates(map[string]interface{}{
+				"start_time": start,
+				"end_time":   start.Add(duration),
+			}).Error; err != nil {
+				return err
+			}
+		default:
+			dropped = append(dropped, occurrence.ID)
+		}
+	}
+
+	if len(dropped) > 0 {
+		if err := tx.Where("id IN ? AND "+unbooked, dropped).Delete(&model.EventOccurrence{}).Error; err != nil {
+			return err
+		}
+	}
+
+	occurrences := make([]*model.EventOccurrence, 0, len(slots))
+	for _, start := range starts {
+		if _, ok := slots[start.Unix()]; !ok {
+			continue
+		}
+
+		occurrences = append(occurrences, &model.EventOccurrence{
+			EventId:   event.ID,
+			SlotTime:  start,
+			StartTime: start,
+			EndTime:   start.Add(duration),
+			Status:    model.OccurrenceStatusScheduled,
+		})
+	}
+	if len(occurrences) > 0 {
+		if err := tx.Omit(clause.Associations).CreateInBatches(&occurrences, 100).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Exec(`
+		INSERT INTO occurrence_ticket_inventories (occurrence_id, ticket_type_id, quantity, sale)
+		SELECT event_occurrences.id, ticket_types.id, ticket_types.quantity, 0
+		FROM event_occurrences
+		INNER JOIN ticket_types ON ticket_types.event_id = event_occurrences.event_id AND ticket_types.deleted_at IS NULL
+		WHERE event_occurrences.event_id = ?
+		ON CONFLICT DO NOTHING
+	`, event.ID).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		UPDATE occurrence_ticket_inventories
+		SET quantity = GREATEST(ticket_types.quantity, occurrence_ticket_inventories.sale)
+		FROM event_occurrences, ticket_types
+		WHERE event_occurrences.id = occurrence_ticket_inventories.occurrence_id
+			AND event_occurrences.event_id = ? AND event_occurrences.status = ?
+			AND ticket_types.id = occurrence_ticket_inventories.ticket_type_id AND ticket_types.deleted_at IS NULL
+			AND NOT occurrence_ticket_inventories.is_overridden
+			AND occurrence_ticket_inventories.quantity <> GREATEST(ticket_types.quantity, occurrence_ticket_inventories.sale)
+	`, event.ID, model.OccurrenceStatusScheduled).Error
+}
+
+func (e *EventRepo) ListOccurrences(ctx context.Context, eventId string, req *dto.ListOccurrenceReq) ([]*model.EventOccurrence, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("event_id = ?", eventId)
+		if req.From != "" {
+			db = db.Where("end_time >= ?", req.From)
+		}
+		if req.To != "" {
+			db = db.Where("start_time <= ?", req.To)
+		}
+		if req.Status != "" {
+			db = db.Where("status = ?", req.Status)
+		}
+
+		return db
+	}
+
+	var total int64
+	if err := e.db.GetDB().WithContext(ctx).Model(&model.EventOccurrence{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	var occurrences []*model.EventOccurrence
+	if err := e.db.GetDB().WithContext(ctx).
+		Scopes(filter).
+		Preload("Inventories").
+		Order("start_time ASC").
+		Limit(int(pagination.PageSize)).
+		Offset(int(pagination.Skip)).
+		Find(&occurrences).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return occurrences, pagination, nil
+}
+
+func (e *EventRepo) GetOccurrence(ctx context.Context, eventId string, id string) (*model.EventOccurrence, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var occurrence model.EventOccurrence
+	if err := e.db.GetDB().WithContext(ctx).
+		Preload("Inventories").
+		Where("id = ? AND event_id = ?", id, eventId).
+		First(&occurrence).Error; err != nil {
+		return nil, err
+	}
+
+	return &occurrence, nil
+}
+
+// UpdateOccurrences moves the occurrence, or with future it and every later scheduled occurrence of the
+// series, by the shift of its start and gives them the new duration. Their seats change to the given
+// quantities, never below the seats already sold. Moving the occurrence of a single event moves the event.
+// Every moved occurrence with tickets held gets a reschedule by the user of the given one, for its reason and
+// answered until its RespondBy or the new start if sooner.
+func (e *EventRepo) UpdateOccurrences(ctx context.Context, event *model.Event, occurrence *model.EventOccurrence, future bool, start time.Time, end time.Time, tickets []*dto.OccurrenceTicketReq, reschedule *model.EventReschedule) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return e.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "occurrences:"+event.ID).Error; err != nil {
+			return err
+		}
+
+		targets := occurrenceScope(tx, occurrence, future)
+		shift := start.Sub(occurrence.StartTime)
+		duration := end.Sub(start)
+
+		// The holders of the moved occurrences answer a reschedule of their own occurrence
+		var held []*model.EventOccurrence
+		if err := tx.Where("id IN (?)", targets).
+			Where("EXISTS (SELECT 1 FROM tickets WHERE tickets.occurrence_id = event_occurrences.id AND tickets.deleted_at IS NULL)").
+			Find(&held).Error; err != nil {
+			return err
+		}
+
+		for _, moved := range held {
+			movedStart := moved.StartTime.Add(shift)
+			movedEnd := movedStart.Add(duration)
+			if movedStart.Equal(moved.StartTime) && movedEnd.Equal(moved.EndTime) {
+				continue
+			}
+
+			respondBy := reschedule.RespondBy
+			if movedStart.Before(respondBy) {
+				respondBy = movedStart
+			}
+
+			occurrenceId := moved.ID
+			if err := tx.Create(&model.EventReschedule{
+				EventId:           event.ID,
+				OccurrenceId:      &occurrenceId,
+				UserId:            reschedule.UserId,
+				PreviousStartTime: moved.StartTime,
+				PreviousEndTime:   moved.EndTime,
+				PreviousTimezone:  event.Timezone,
+				StartTime:         movedStart,
+				EndTime:           movedEnd,
+				Timezone:          event.Timezone,
+				Reason:            reschedule.Reason,
+				RespondBy:         respondBy,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.EventOccurrence{}).
+			Where("id IN (?)", targets).
+			Updates(map[string]interface{}{
+				"start_time":  gorm.Expr("start_time + ? * INTERVAL '1 second'", shift.Seconds()),
+				"end_time":    gorm.Expr("start_time + ? * INTERVAL '1 second'", (shift + duration).Seconds()),
+				"is_modified": true,
+			}).Error; err != nil {
+			return err
+		}
+
+		for _, ticket := range tickets {
+			var oversold int64
+			if err := tx.Model(&model.OccurrenceInventory{}).
+				Where("ticket_type_id = ? AND occurrence_id IN (?) AND sale > ?", ticket.TicketTypeId, targets, ticket.Quantity).
+				Count(&oversold).Error; err != nil {
+				return err
+			}
+
+			if oversold > 0 {
+				return errors.New(messages.QuantityBelowSale)
+			}
+
+			result := tx.Model(&model.OccurrenceInventory{}).
+				Where("ticket_type_id = ? AND occurrence_id IN (?)", ticket.TicketTypeId, targets).
+				Updates(map[string]interface{}{"quantity": ticket.Quantity, "is_overridden": true})
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return errors.New(messages.TicketTypeNotFound)
+			}
+		}
+
+		if event.RecurrenceRule != "" {
+			return nil
+		}
+
+		return tx.Model(&model.Event{}).
+			Where("id = ?", event.ID).
+			Updates(map[string]interface{}{
+				"start_time": start,
+				"end_time":   end,
+			}).Error
+	})
+}
+
+// CancelOccurrences cancels the occurrence, or with future it and every later occurrence of the series. A
+// series cancelled from an occurrence on ends before it, rule is the recurrence rule cut there.
+func (e *EventRepo) CancelOccurrences(ctx context.Context, occurrence *model.EventOccurrence, future bool, rule string, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return e.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "occurrences:"+occurrence.EventId).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.EventOccurrence{}).
+			Where("id IN (?)", occurrenceScope(tx, occurrence, future)).
+			Updates(map[string]interface{}{
+				"status":              model.OccurrenceStatusCancelled,
+				"cancelled_at":        time.Now(),
+				"cancellation_reason": reason,
+			}).Error; err != nil {
+			return err
+		}
+
+		if rule == "" {
+			return nil
+		}
+
+		return tx.Model(&model.Event{}).Where("id = ?", occurrence.EventId).Update("recurrence_rule", rule).Error
+	})
+}
+
+// occurrenceScope selects the ids of the scheduled occurrences an edit of the occurrence applies to
+func occurrenceScope(tx *gorm.DB, occurrence *model.EventOccurrence, future bool) *gorm.DB {
+	query := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&model.EventOccurrence{}).
+		Select("id").
+		Where("event_id = ? AND status = ?", occurrence.EventId, model.OccurrenceStatusScheduled)
+	if future {
+		return query.Where("slot_time >= ?", occurrence.SlotTime)
+	}
+
+	return query.Where("id = ?", occurrence.ID)
+}
+
+// upcomingOccurrences loads the next scheduled occurrences of every listed event, within the range when
+// the listing asked for one
+func (e *EventRepo) upcomingOccurrences(ctx context.Context, events []*model.Event, from string, to string) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(events))
+	byId := make(map[string]*model.Event, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+		byId[event.ID] = event
+	}
+
+	query := e.db.GetDB().WithContext(ctx).
+		Model(&model.EventOccurrence{}).
+		Select("event_occurrences.*, ROW_NUMBER() OVER (PARTITION BY event_id ORDER BY start_time) AS position").
+		Where("event_id IN ? AND status = ? AND end_time >= NOW()", ids, model.OccurrenceStatusScheduled)
+	if from != "" && to != "" {
+		query = query.Where("start_time BETWEEN ? AND ?", from, to)
+	}
+
+	var occurrences []*model.EventOccurrence
+	if err := e.db.GetDB().WithContext(ctx).
+		Table("(?) AS event_occurrences", query).
+		Where("position <= ?", listedOccurrences).
+		Order("start_time ASC").
+		Find(&occurrences).Error; err != nil {
+		return err
+	}
+
+	for _, occurrence := range occurrences {
+		event := byId[occurrence.EventId]
+		occurrence.In(event.TimeLocation())
+		event.Occurrences = append(event.Occurrences, occurrence)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/events/model"
+	"gohub/pkg/messages"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HasValidTickets tells whether tickets of the events are still held, refunded tickets are deleted
+func (e *EventRepo) HasValidTickets(ctx context.Context, eventIds []string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var exists bool
+	if err := e.db.GetDB().WithContext(ctx).
+		Raw("SELECT EXISTS (SELECT 1 FROM tickets WHERE event_id IN ? AND deleted_at IS NULL)", eventIds).
+		Scan(&exists).Error; err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// RescheduleEvent moves the event to the times of the reschedule, records it and moves its occurrence in one
+// transaction, unless the event was cancelled or completed meanwhile
+func (e *EventRepo) RescheduleEvent(ctx context.Context, event *model.Event, reschedule *model.EventReschedule, starts []time.Time, duration time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return e.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Event{}).
+			Where("id = ? AND state IN ?", reschedule.EventId, model.StatesBefore(model.EventStateCancelled)).
+			Updates(map[string]interface{}{
+				"start_time": reschedule.StartTime,
+				"end_time":   reschedule.EndTime,
+				"timezone":   reschedule.Timezone,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New(messages.EventNotEditable)
+		}
+
+		if err := tx.Create(reschedule).Error; err != nil {
+			return err
+		}
+
+		return syncOccurrences(tx, event, starts, duration)
+	})
+}
+
+func (e *EventRepo) ListReschedules(ctx context.Context, eventId string) ([]*model.EventReschedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var reschedules []*model.EventReschedule
+	if err := e.db.GetDB().WithContext(ctx).
+		Where("event_id = ?", eventId).
+		Order("created_at DESC").
+		Find(&reschedules).Error; err != nil {
+		return nil, err
+	}
+
+	return reschedules, nil
+}
@@ -10,15 +10,17 @@ import (
 	"gohub/internal/libs/logger"
 	"gohub/internal/libs/validation"
 	"gohub/pkg/messages"
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
 	"gohub/pkg/utils"
 	"gorm.io/gorm"
+	"strings"
 )
 
 type IEventService interface {
 	GetEvents(ctx context.Context, req *dto.ListEventReq) ([]*model.Event, *paging.Pagination, error)
 	CreateEvent(ctx context.Context, req *dto.CreateEventReq) (*model.Event, error)
-	GetEventById(ctx context.Context, id string) (*model.Event, error)
+	GetEventById(ctx context.Context, id string, userId string) (*model.Event, error)
 	UpdateEvent(ctx context.Context, id string, req *dto.UpdateEventReq) (*model.Event, error)
 	DeleteEvent(ctx context.Context, id string) error
 	DeleteEvents(ctx context.Context, ids *dto.DeleteRequest) error
@@ -33,6 +35,22 @@ type IEventService interface {
 	MakeEventPublic(ctx context.Context, req *dto.MakeEventPublicOrPrivateReq) error
 	ApplyCoupons(ctx context.Context, eventId string, req *dto.ApplyCouponReq) error
 	CheckFavourite(ctx context.Context, req *dto.UserFavouriteEvent) (bool, error)
+	ListOccurrences(ctx context.Context, eventId string, req *dto.ListOccurrenceReq) ([]*model.EventOccurrence, *paging.Pagination, error)
+	UpdateOccurrence(ctx context.Context, eventId string, occurrenceId string, req *dto.UpdateOccurrenceReq) (*model.EventOccurrence, error)
+	CancelOccurrence(ctx context.Context, eventId string, occurrenceId string, req *dto.CancelOccurrenceReq) (*model.EventOccurrence, error)
+	RescheduleEvent(ctx context.Context, eventId string, req *dto.RescheduleEventReq) (*model.Event, error)
+	AddGalleryImages(ctx context.Context, eventId string, req *dto.AddGalleryImageReq) ([]*model.EventSubImage, error)
+	DeleteGalleryImage(ctx context.Context, userId string, eventId string, imageId string) error
+	ReorderGalleryImages(ctx context.Context, eventId string, req *dto.ReorderGalleryImageReq) ([]*model.EventSubImage, error)
+	SetGalleryCover(ctx context.Context, userId string, eventId string, imageId string) (*model.Event, error)
+	ListReschedules(ctx context.Context, eventId string, userId string) ([]*model.EventReschedule, error)
+	SubmitEvent(ctx context.Context, eventId string, req *dto.SubmitEventReq) (*model.Event, error)
+	WithdrawEvent(ctx context.Context, userId string, eventId string) (*model.Event, error)
+	ReviewEvent(ctx context.Context, eventId string, req *dto.ReviewEventReq) (*model.Event, error)
+	CancelEvent(ctx context.Context, eventId string, req *dto.CancelEventReq) (*model.Event, error)
+	ListPendingEvents(ctx context.Context, userId string, req *dto.ListPendingEventReq) ([]*model.Event, *paging.Pagination, error)
+	ListModerations(ctx context.Context, userId string, eventId string) ([]*model.EventModeration, error)
+	AdvanceEventStates(ctx context.Context) error
 }
 
 type EventService struct {
@@ -60,27 +78,67 @@ func (e *EventService) GetEvents(ctx context.Context, req *dto.ListEventReq) ([]
 }
 
 func (e *EventService) CreateEvent(ctx context.Context, req *dto.CreateEventReq) (*model.Event, error) {
+	currency, err := ticketPrices(req.Currency, req.TicketTypeItems)
+	if err != nil {
+		return nil, err
+	}
+
 	var event model.Event
 	utils.MapStruct(&event, req)
+	event.Currency = currency
+	event.State = model.EventStateDraft
+	if err := event.SetTimes(req.Timezone, req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+
+	starts, duration, err := event.Expand()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := readGalleryImages(req.SubImageItems)
+	if err != nil {
+		return nil, err
+	}
+
+	// The gallery is saved along with the event
+	event.SubImages, err = uploadGalleryImages(files)
+	if err != nil {
+		logger.Errorf("Create.UploadGallery fail, error: %s", err)
+		return nil, err
+	}
 
-	err := e.eventRepo.CreateEvent(ctx, &event, req)
+	err = e.eventRepo.CreateEvent(ctx, &event, req)
 	if err != nil {
 		logger.Errorf("Create fail, error: %s", err)
+		removeGalleryImages(event.SubImages)
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
 			return nil, errors.New(messages.TitleExpenseAlreadyExists)
 		}
 		return nil, err
 	}
 
+	if err := e.eventRepo.SyncOccurrences(ctx, &event, starts, duration); err != nil {
+		logger.Errorf("Create.SyncOccurrences fail, id: %s, error: %s", event.ID, err)
+		return nil, err
+	}
+
 	return &event, nil
 }
 
-func (e *EventService) GetEventById(ctx context.Context, id string) (*model.Event, error) {
+func (e *EventService) GetEventById(ctx context.Context, id string, userId string) (*model.Event, error) {
 	event, err := e.eventRepo.GetEventById(ctx, id, true)
 	if err != nil {
 		return nil, err
 	}
 
+	// an event not published yet is only shown to its organizer and the admins reviewing it
+	if !event.IsListed() && event.UserId != userId {
+		if userId == "" || e.requireAdmin(ctx, userId) != nil {
+			return nil, errors.New(messages.EventNotFound)
+		}
+	}
+
 	var totalRate float32
 	for _, review := range event.Reviews {
 		totalRate += review.Rate
@@ -105,17 +163,113 @@ func (e *EventService) UpdateEvent(ctx context.Context, id string, req *dto.Upda
 		return nil, errors.New(messages.CategoryNotFound)
 	}
 
+	if event.State == model.EventStateCancelled || event.State == model.EventStateCompleted {
+		return nil, errors.New(messages.EventNotEditable)
+	}
+
+	if event.State == model.EventStatePublished {
+		if err := e.checkReviewedContent(ctx, event, req); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Currency == "" {
+		req.Currency = event.Currency
+	}
+	currency, err := ticketPrices(req.Currency, req.TicketTypeItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if currency != event.Currency {
+		ordered, err := e.eventRepo.HasPayments(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ordered {
+			return nil, errors.New(messages.CurrencyLocked)
+		}
+	}
+
+	start, end, timezone := event.StartTime, event.EndTime, event.Timezone
 	utils.MapStruct(event, req)
+	event.Currency = currency
+	if err := event.SetTimes(req.Timezone, req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+
+	// ticket holders are told about new times and may get a refund, which only a reschedule does
+	if timesChanged(event, start, end, timezone) {
+		held, err := e.eventRepo.HasValidTickets(ctx, []string{id})
+		if err != nil {
+			return nil, err
+		}
+		if held {
+			return nil, errors.New(messages.RescheduleRequired)
+		}
+	}
+
+	starts, duration, err := event.Expand()
+	if err != nil {
+		return nil, err
+	}
+
 	err = e.eventRepo.UpdateEvent(ctx, event, req)
 	if err != nil {
 		logger.Errorf("Update fail, id: %s, error: %s", id, err)
 		return nil, err
 	}
 
+	if err := e.eventRepo.SyncOccurrences(ctx, event, starts, duration); err != nil {
+		logger.Errorf("Update.SyncOccurrences fail, id: %s, error: %s", id, err)
+		return nil, err
+	}
+
+	if err := e.resetApproval(ctx, event); err != nil {
+		return nil, err
+	}
+
 	return event, nil
 }
 
+// checkReviewedContent refuses an edit of a published event that changes what the admins reviewed: its name,
+// description, cover image, location and categories. The schedule, the tickets and the sale settings stay editable,
+// new times still go through a reschedule when tickets are held.
+func (e *EventService) checkReviewedContent(ctx context.Context, event *model.Event, req *dto.UpdateEventReq) error {
+	if req.Name != event.Name || req.Description != event.Description || req.Location != event.Location ||
+		req.PathLocation != event.PathLocation || (req.CoverImage != nil && req.CoverImage.Filename != "") {
+		return errors.New(messages.ReviewedContentLocked)
+	}
+
+	reviewed, err := e.eventRepo.GetEventById(ctx, event.ID, true)
+	if err != nil {
+		return err
+	}
+
+	categoryIds := make(map[string]bool, len(reviewed.Categories))
+	for _, category := range reviewed.Categories {
+		categoryIds[category.ID] = true
+	}
+
+	requested := make(map[string]bool, len(req.CategoryIds))
+	for _, categoryId := range req.CategoryIds {
+		if !categoryIds[categoryId] {
+			return errors.New(messages.ReviewedContentLocked)
+		}
+		requested[categoryId] = true
+	}
+	if len(requested) != len(categoryIds) {
+		return errors.New(messages.ReviewedContentLocked)
+	}
+
+	return nil
+}
+
 func (e *EventService) DeleteEvent(ctx context.Context, id string) error {
+	if err := e.refuseHeldTickets(ctx, []string{id}); err != nil {
+		return err
+	}
+
 	err := e.eventRepo.Delete(ctx, id)
 	if err != nil {
 		return err
@@ -125,6 +279,10 @@ func (e *EventService) DeleteEvent(ctx context.Context, id string) error {
 }
 
 func (e *EventService) DeleteEvents(ctx context.Context, ids *dto.DeleteRequest) error {
+	if err := e.refuseHeldTickets(ctx, ids.Ids); err != nil {
+		return err
+	}
+
 	var err error
 	if len(ids.Ids) == 1 {
 		err = e.eventRepo.Delete(ctx, ids.Ids[0])
@@ -139,6 +297,21 @@ func (e *EventService) DeleteEvents(ctx context.Context, ids *dto.DeleteRequest)
 	return nil
 }
 
+// refuseHeldTickets keeps events with valid tickets from being deleted, they are cancelled so the tickets
+// get refunded
+func (e *EventService) refuseHeldTickets(ctx context.Context, ids []string) error {
+	held, err := e.eventRepo.HasValidTickets(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	if held {
+		return errors.New(messages.EventHasValidTickets)
+	}
+
+	return nil
+}
+
 func (e *EventService) GetCreatedEvent(ctx context.Context, userId string, req *dto.ListEventReq, statistic *dto.StatisticMyEvent) ([]*model.Event, *paging.Pagination, error) {
 	events, pagination, err := e.eventRepo.ListCreatedEvents(ctx, userId, req, statistic)
 	if err != nil {
@@ -271,3 +444,29 @@ func (e *EventService) CheckFavourite(ctx context.Context, req *dto.UserFavourit
 	}
 	return result, nil
 }
+
+// ticketPrices parses the price of every ticket type in the currency of the event, VND when none is given
+func ticketPrices(currency string, items []*dto.CreateTicketType) (string, error) {
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	currency = strings.ToUpper(currency)
+	if !money.IsSupported(currency) {
+		return "", errors.New(messages.UnsupportedCurrency)
+	}
+
+	for _, item := range items {
+		value := item.Price.String()
+		if value == "" {
+			value = "0"
+		}
+
+		price, err := money.Parse(value, currency)
+		if err != nil || price.IsNegative() {
+			return "", errors.New(messages.InvalidPrice)
+		}
+		item.UnitPrice = price
+	}
+
+	return currency, nil
+}
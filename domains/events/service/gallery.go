@@ -0,0 +1,228 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/events/dto"
+	"gohub/domains/events/model"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/utils"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sync"
+)
+
+const galleryFolder = "/eventhub/events"
+
+var galleryImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+type galleryFile struct {
+	name string
+	data []byte
+}
+
+// AddGalleryImages uploads the images at the end of the gallery of the event
+func (e *EventService) AddGalleryImages(ctx context.Context, eventId string, req *dto.AddGalleryImageReq) ([]*model.EventSubImage, error) {
+	event, err := e.editableEvent(ctx, req.UserId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := readGalleryImages(req.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New(messages.NoGalleryImages)
+	}
+
+	// Checked again when the images are saved, this only spares the uploads of a gallery already full
+	gallery, err := e.eventRepo.ListSubImages(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gallery)+len(files) > configs.MaxGalleryImages {
+		return nil, errors.New(messages.GalleryFull)
+	}
+
+	images, err := uploadGalleryImages(files)
+	if err != nil {
+		logger.Errorf("AddGalleryImages.Upload fail, id: %s, error: %s", event.ID, err)
+		return nil, err
+	}
+
+	if err := e.eventRepo.AddSubImages(ctx, event.ID, images); err != nil {
+		logger.Errorf("AddSubImages fail, id: %s, error: %s", event.ID, err)
+		removeGalleryImages(images)
+		return nil, err
+	}
+
+	return e.eventRepo.ListSubImages(ctx, event.ID)
+}
+
+// DeleteGalleryImage removes the image from the gallery and from the storage
+func (e *EventService) DeleteGalleryImage(ctx context.Context, userId string, eventId string, imageId string) error {
+	event, err := e.editableEvent(ctx, userId, eventId)
+	if err != nil {
+		return err
+	}
+
+	image, err := e.eventRepo.DeleteSubImage(ctx, event.ID, imageId)
+	if err != nil {
+		return err
+	}
+
+	removeGalleryImages([]*model.EventSubImage{image})
+	return nil
+}
+
+func (e *EventService) ReorderGalleryImages(ctx context.Context, eventId string, req *dto.ReorderGalleryImageReq) ([]*model.EventSubImage, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := e.editableEvent(ctx, req.UserId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.eventRepo.ReorderSubImages(ctx, event.ID, req.ImageIds); err != nil {
+		return nil, err
+	}
+
+	return e.eventRepo.ListSubImages(ctx, event.ID)
+}
+
+// SetGalleryCover makes an image of the gallery the cover of the event, the previous cover takes its place
+func (e *EventService) SetGalleryCover(ctx context.Context, userId string, eventId string, imageId string) (*model.Event, error) {
+	event, err := e.editableEvent(ctx, userId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.eventRepo.SwapCoverImage(ctx, event.ID, imageId); err != nil {
+		return nil, err
+	}
+
+	return e.eventRepo.GetEventById(ctx, event.ID, true)
+}
+
+func (e *EventService) editableEvent(ctx context.Context, userId string, eventId string) (*model.Event, error) {
+	event, err := e.ownedEvent(ctx, userId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.State == model.EventStateCancelled || event.State == model.EventStateCompleted {
+		return nil, errors.New(messages.EventNotEditable)
+	}
+
+	return event, nil
+}
+
+// readGalleryImages checks the size and sniffed content type of every image before anything is uploaded,
+// empty file fields are skipped
+func readGalleryImages(fileHeaders []*multipart.FileHeader) ([]*galleryFile, error) {
+	files := make([]*galleryFile, 0, len(fileHeaders))
+	for _, fileHeader := range fileHeaders {
+		if fileHeader == nil || fileHeader.Filename == "" {
+			continue
+		}
+
+		if fileHeader.Size > configs.MaxGalleryImageSize {
+			return nil, errors.New(messages.GalleryImageTooLarge)
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(file, configs.MaxGalleryImageSize+1))
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > configs.MaxGalleryImageSize {
+			return nil, errors.New(messages.GalleryImageTooLarge)
+		}
+
+		// The client provided header is ignored, only the content decides the type
+		if !galleryImageTypes[http.DetectContentType(data)] {
+			return nil, errors.New(messages.GalleryImageNotAllowed)
+		}
+
+		files = append(files, &galleryFile{name: fileHeader.Filename, data: data})
+	}
+
+	if len(files) > configs.MaxGalleryImages {
+		return nil, errors.New(messages.GalleryFull)
+	}
+
+	return files, nil
+}
+
+// uploadGalleryImages stores the images a few at a time, in the order they were given. When one of them
+// fails the ones already stored are removed again.
+func uploadGalleryImages(files []*galleryFile) ([]*model.EventSubImage, error) {
+	images := make([]*model.EventSubImage, len(files))
+	errs := make([]error, len(files))
+	slots := make(chan struct{}, configs.GalleryUploadConcurrency)
+
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, file *galleryFile) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			stored, err := utils.StoreFile(bytes.NewReader(file.data), galleryFolder)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			images[i] = &model.EventSubImage{
+				ImageUrl:      stored.Url,
+				ImageFileName: file.name,
+				ImagePublicId: stored.PublicId,
+				SortIndex:     i,
+			}
+		}(i, file)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		removeGalleryImages(images)
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// removeGalleryImages deletes the stored objects of the images, a failure only leaves an unused object behind.
+// Images uploaded before their public id was kept are left in the storage, their URL is not trusted to name one.
+func removeGalleryImages(images []*model.EventSubImage) {
+	for _, image := range images {
+		if image == nil {
+			continue
+		}
+
+		if image.ImagePublicId != "" {
+			if err := utils.FileDelete(image.ImagePublicId); err != nil {
+				logger.Errorf("Delete gallery image fail, url: %s, error: %s", image.ImageUrl, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/domains/events/dto"
+	"gohub/domains/events/model"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+	"time"
+)
+
+// SubmitEvent sends a draft to the admins for review, with the time it should be published at if not right away
+func (e *EventService) SubmitEvent(ctx context.Context, eventId string, req *dto.SubmitEventReq) (*model.Event, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := e.ownedEvent(ctx, req.UserId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	var publishAt *time.Time
+	if req.PublishAt != "" {
+		parsed, err := model.ParseEventTime(req.PublishAt, event.TimeLocation())
+		if err != nil || !parsed.After(time.Now()) {
+			return nil, errors.New(messages.InvalidPublishTime)
+		}
+		publishAt = &parsed
+	}
+
+	return e.moveEvent(ctx, event, model.EventStatePendingReview, map[string]interface{}{
+		"publish_at":  publishAt,
+		"approved_at": nil,
+	}, nil)
+}
+
+// WithdrawEvent takes an event out of review, back to draft
+func (e *EventService) WithdrawEvent(ctx context.Context, userId string, eventId string) (*model.Event, error) {
+	event, err := e.ownedEvent(ctx, userId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.moveEvent(ctx, event, model.EventStateDraft, map[string]interface{}{"approved_at": nil}, nil)
+}
+
+// ReviewEvent records the decision of an admin. An approved event is published, or stays in review until its
+// publish time when that is ahead. A rejected one goes back to draft with the note telling what to change.
+func (e *EventService) ReviewEvent(ctx context.Context, eventId string, req *dto.ReviewEventReq) (*model.Event, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if err := e.requireAdmin(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	event, err := e.eventRepo.GetEventById(ctx, eventId, false)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.State != model.EventStatePendingReview || event.ApprovedAt != nil {
+		return nil, errors.New(messages.InvalidEventTransition)
+	}
+
+	moderation := &model.EventModeration{
+		EventId:     event.ID,
+		ModeratorId: req.UserId,
+		Decision:    req.Decision,
+		Note:        req.Note,
+	}
+
+	if req.Decision == model.ModerationRejected {
+		return e.moveEvent(ctx, event, model.EventStateDraft, map[string]interface{}{"approved_at": nil}, moderation)
+	}
+
+	now := time.Now()
+	if event.PublishAt != nil && event.PublishAt.After(now) {
+		return e.moveEvent(ctx, event, model.EventStatePendingReview, map[string]interface{}{"approved_at": now}, moderation)
+	}
+
+	return e.moveEvent(ctx, event, model.EventStatePublished, map[string]interface{}{
+		"approved_at":  now,
+		"published_at": now,
+	}, moderation)
+}
+
+// CancelEvent cancels an event for its organizer or an admin. Its occurrences are cancelled with it and sales
+// stop, the worker then refunds the tickets sold and notifies their holders.
+func (e *EventService) CancelEvent(ctx context.Context, eventId string, req *dto.CancelEventReq) (*model.Event, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := e.eventRepo.GetEventById(ctx, eventId, false)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != req.UserId {
+		if err := e.requireAdmin(ctx, req.UserId); err != nil {
+			return nil, errors.New(messages.NotEventOwner)
+		}
+	}
+
+	return e.moveEvent(ctx, event, model.EventStateCancelled, map[string]interface{}{
+		"cancelled_at":        time.Now(),
+		"cancellation_reason": req.Reason,
+	}, nil)
+}
+
+func (e *EventService) ListPendingEvents(ctx context.Context, userId string, req *dto.ListPendingEventReq) ([]*model.Event, *paging.Pagination, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, nil, err
+	}
+
+	if err := e.requireAdmin(ctx, userId); err != nil {
+		return nil, nil, err
+	}
+
+	return e.eventRepo.ListPendingEvents(ctx, req)
+}
+
+// ListModerations shows the review history of an event to its organizer and to the admins
+func (e *EventService) ListModerations(ctx context.Context, userId string, eventId string) ([]*model.EventModeration, error) {
+	event, err := e.eventRepo.GetEventById(ctx, eventId, false)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		if err := e.requireAdmin(ctx, userId); err != nil {
+			return nil, errors.New(messages.NotEventOwner)
+		}
+	}
+
+	return e.eventRepo.ListModerations(ctx, eventId)
+}
+
+// AdvanceEventStates publishes the approved events whose publish time came and completes the events that ended
+func (e *EventService) AdvanceEventStates(ctx context.Context) error {
+	published, err := e.eventRepo.PublishDueEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	completed, err := e.eventRepo.CompleteEndedEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	if published > 0 || completed > 0 {
+		logger.Infof("AdvanceEventStates published %d and completed %d events", published, completed)
+	}
+
+	return nil
+}
+
+// moveEvent moves the event to the state with the other changes when its state allows it
+func (e *EventService) moveEvent(ctx context.Context, event *model.Event, state string, changes map[string]interface{}, moderation *model.EventModeration) (*model.Event, error) {
+	from := []string{event.State}
+	if state != event.State {
+		if !event.CanMoveTo(state) {
+			return nil, errors.New(messages.InvalidEventTransition)
+		}
+		changes["state"] = state
+	}
+
+	if err := e.eventRepo.MoveEvent(ctx, event.ID, from, changes, moderation); err != nil {
+		logger.Errorf("MoveEvent fail, id: %s, state: %s, error: %s", event.ID, state, err)
+		return nil, err
+	}
+
+	return e.eventRepo.GetEventById(ctx, event.ID, false)
+}
+
+func (e *EventService) ownedEvent(ctx context.Context, userId string, eventId string) (*model.Event, error) {
+	event, err := e.eventRepo.GetEventById(ctx, eventId, false)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		return nil, errors.New(messages.NotEventOwner)
+	}
+
+	return event, nil
+}
+
+func (e *EventService) requireAdmin(ctx context.Context, userId string) error {
+	isAdmin, err := e.eventRepo.IsAdmin(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return errors.New(messages.NotEventModerator)
+	}
+
+	return nil
+}
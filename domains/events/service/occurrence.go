@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/events/dto"
+	"gohub/domains/events/model"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+	"gohub/pkg/rrule"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (e *EventService) ListOccurrences(ctx context.Context, eventId string, req *dto.ListOccurrenceReq) ([]*model.EventOccurrence, *paging.Pagination, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, nil, err
+	}
+
+	event, err := e.eventRepo.GetEventById(ctx, eventId, false)
+	if err != nil {
+		return nil, nil, errors.New(messages.EventNotFound)
+	}
+
+	occurrences, pagination, err := e.eventRepo.ListOccurrences(ctx, eventId, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, occurrence := range occurrences {
+		occurrence.In(event.TimeLocation())
+	}
+
+	return occurrences, pagination, nil
+}
+
+// UpdateOccurrence moves an occurrence, or every occurrence from it on, to new times. The moved occurrences
+// are marked as modified so later edits of the event schedule leave them where they are. The holders of a
+// moved occurrence of a recurring event are offered a refund like for a reschedule, a single event with
+// tickets must be moved with RescheduleEvent.
+func (e *EventService) UpdateOccurrence(ctx context.Context, eventId string, occurrenceId string, req *dto.UpdateOccurrenceReq) (*model.EventOccurrence, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, occurrence, err := e.ownedOccurrence(ctx, req.UserId, eventId, occurrenceId)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := model.ParseEventTime(req.StartTime, event.TimeLocation())
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := model.ParseEventTime(req.EndTime, event.TimeLocation())
+	if err != nil || end.Before(start) {
+		return nil, errors.New(messages.InvalidEventTime)
+	}
+
+	if event.RecurrenceRule == "" && (!start.Equal(occurrence.StartTime) || !end.Equal(occurrence.EndTime)) {
+		held, err := e.eventRepo.HasValidTickets(ctx, []string{event.ID})
+		if err != nil {
+			return nil, err
+		}
+		if held {
+			return nil, errors.New(messages.RescheduleRequired)
+		}
+	}
+
+	reschedule := &model.EventReschedule{
+		UserId:    req.UserId,
+		Reason:    req.Reason,
+		RespondBy: time.Now().Add(configs.RescheduleResponseWindow),
+	}
+
+	future := req.Scope == dto.OccurrenceScopeFuture
+	if err := e.eventRepo.UpdateOccurrences(ctx, event, occurrence, future, start, end, req.Tickets, reschedule); err != nil {
+		logger.Errorf("UpdateOccurrence fail, id: %s, error: %s", occurrenceId, err)
+		return nil, err
+	}
+
+	return e.localOccurrence(ctx, event, occurrenceId)
+}
+
+// CancelOccurrence cancels an occurrence, or every occurrence from it on. Cancelling the future of a
+// recurring event also ends its rule before the occurrence, so the series does not grow back when the
+// event is edited. The worker then refunds the tickets of the cancelled occurrences and notifies their holders.
+func (e *EventService) CancelOccurrence(ctx context.Context, eventId string, occurrenceId string, req *dto.CancelOccurrenceReq) (*model.EventOccurrence, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, occurrence, err := e.ownedOccurrence(ctx, req.UserId, eventId, occurrenceId)
+	if err != nil {
+		return nil, err
+	}
+
+	future := req.Scope == dto.OccurrenceScopeFuture
+	rule := ""
+	if future && event.RecurrenceRule != "" {
+		parsed, err := rrule.Parse(event.RecurrenceRule)
+		if err != nil {
+			return nil, errors.New(messages.InvalidRecurrenceRule)
+		}
+
+		parsed.SetUntil(occurrence.SlotTime.Add(-time.Second))
+		rule = parsed.String()
+	}
+
+	if err := e.eventRepo.CancelOccurrences(ctx, occurrence, future, rule, req.Reason); err != nil {
+		logger.Errorf("CancelOccurrence fail, id: %s, error: %s", occurrenceId, err)
+		return nil, err
+	}
+
+	return e.localOccurrence(ctx, event, occurrenceId)
+}
+
+// localOccurrence reloads an occurrence of the event with its times in the timezone of the event
+func (e *EventService) localOccurrence(ctx context.Context, event *model.Event, occurrenceId string) (*model.EventOccurrence, error) {
+	occurrence, err := e.eventRepo.GetOccurrence(ctx, event.ID, occurrenceId)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence.In(event.TimeLocation())
+	return occurrence, nil
+}
+
+// ownedOccurrence loads a scheduled occurrence of an event of the organizer
+func (e *EventService) ownedOccurrence(ctx context.Context, userId string, eventId string, occurrenceId string) (*model.Event, *model.EventOccurrence, error) {
+	event, err := e.eventRepo.GetEventById(ctx, eventId, false)
+	if err != nil {
+		return nil, nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		return nil, nil, errors.New(messages.NotEventOwner)
+	}
+
+	occurrence, err := e.eventRepo.GetOccurrence(ctx, eventId, occurrenceId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errors.New(messages.OccurrenceNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if occurrence.Status == model.OccurrenceStatusCancelled {
+		return nil, nil, errors.New(messages.OccurrenceCancelled)
+	}
+
+	return event, occurrence, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/events/dto"
+	"gohub/domains/events/model"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"time"
+)
+
+// RescheduleEvent moves a single event to new times and records the move. The ticket holders are notified
+// by the worker and can ask a refund until the response window closes, or the event starts if sooner.
+func (e *EventService) RescheduleEvent(ctx context.Context, eventId string, req *dto.RescheduleEventReq) (*model.Event, error) {
+	if err := e.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := e.ownedEvent(ctx, req.UserId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.State == model.EventStateCancelled || event.State == model.EventStateCompleted {
+		return nil, errors.New(messages.EventNotEditable)
+	}
+
+	if event.RecurrenceRule != "" {
+		return nil, errors.New(messages.RecurringEventReschedule)
+	}
+
+	reschedule := &model.EventReschedule{
+		EventId:           event.ID,
+		UserId:            req.UserId,
+		PreviousStartTime: event.StartTime,
+		PreviousEndTime:   event.EndTime,
+		PreviousTimezone:  event.Timezone,
+		Reason:            req.Reason,
+	}
+
+	if err := event.SetTimes(req.Timezone, req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+
+	starts, duration, err := event.Expand()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !event.StartTime.After(now) || !timesChanged(event, reschedule.PreviousStartTime, reschedule.PreviousEndTime, reschedule.PreviousTimezone) {
+		return nil, errors.New(messages.InvalidEventTime)
+	}
+
+	reschedule.StartTime = event.StartTime
+	reschedule.EndTime = event.EndTime
+	reschedule.Timezone = event.Timezone
+	reschedule.RespondBy = now.Add(configs.RescheduleResponseWindow)
+	if event.StartTime.Before(reschedule.RespondBy) {
+		reschedule.RespondBy = event.StartTime
+	}
+
+	if err := e.eventRepo.RescheduleEvent(ctx, event, reschedule, starts, duration); err != nil {
+		logger.Errorf("RescheduleEvent fail, id: %s, error: %s", event.ID, err)
+		return nil, err
+	}
+
+	if err := e.resetApproval(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return e.eventRepo.GetEventById(ctx, event.ID, false)
+}
+
+// ListReschedules shows the times an event was moved from, to whoever can see the event
+func (e *EventService) ListReschedules(ctx context.Context, eventId string, userId string) ([]*model.EventReschedule, error) {
+	if _, err := e.GetEventById(ctx, eventId, userId); err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	return e.eventRepo.ListReschedules(ctx, eventId)
+}
+
+// resetApproval withdraws the approval of an event still waiting for its publish time, an approval covers
+// what the admin saw and a change made after it needs a new review
+func (e *EventService) resetApproval(ctx context.Context, event *model.Event) error {
+	if event.State != model.EventStatePendingReview || event.ApprovedAt == nil {
+		return nil
+	}
+
+	if err := e.eventRepo.MoveEvent(ctx, event.ID, []string{event.State}, map[string]interface{}{"approved_at": nil}, nil); err != nil {
+		logger.Errorf("resetApproval fail, id: %s, error: %s", event.ID, err)
+		return err
+	}
+
+	event.ApprovedAt = nil
+	return nil
+}
+
+// timesChanged tells whether the event no longer starts, ends or is planned in the timezone it was
+func timesChanged(event *model.Event, start time.Time, end time.Time, timezone string) bool {
+	return !event.StartTime.Equal(start) || !event.EndTime.Equal(end) || event.Timezone != timezone
+}
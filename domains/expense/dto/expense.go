@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"encoding/json"
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
 )
 
@@ -8,7 +10,7 @@ type Expense struct {
 	ID          string        `json:"id"`
 	EventId     string        `json:"eventId"`
 	Title       string        `json:"title"`
-	Total       float64       `json:"total"`
+	Total       money.Money   `json:"total"`
 	SubExpenses *[]SubExpense `json:"subExpenses"`
 	CreatedAt   string        `json:"createdAt"`
 	UpdatedAt   string        `json:"updatedAt"`
@@ -28,15 +30,16 @@ type ListExpenseRes struct {
 	Pagination *paging.Pagination `json:"metadata"`
 }
 
+// CreatedExpenseReq takes the total in major units of the event currency, like 12.50 for an event in USD
 type CreatedExpenseReq struct {
-	Title   string  `json:"title"`
-	EventId string  `json:"eventId"`
-	Total   float64 `json:"total"`
+	Title   string      `json:"title"`
+	EventId string      `json:"eventId"`
+	Total   json.Number `json:"total"`
 }
 
 type UpdatedExpenseReq struct {
-	ID      string  `json:"id"`
-	EventId string  `json:"eventId"`
-	Title   string  `json:"title"`
-	Total   float64 `json:"total"`
+	ID      string      `json:"id"`
+	EventId string      `json:"eventId"`
+	Title   string      `json:"title"`
+	Total   json.Number `json:"total"`
 }
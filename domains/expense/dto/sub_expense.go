@@ -1,21 +1,27 @@
 package dto
 
+import (
+	"encoding/json"
+	"gohub/pkg/money"
+)
+
 type SubExpense struct {
-	ID        string  `json:"id"`
-	ExpenseId string  `json:"expenseId"`
-	Name      string  `json:"name"`
-	Price     float32 `json:"price"`
+	ID        string      `json:"id"`
+	ExpenseId string      `json:"expenseId"`
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"`
 }
 
+// CreatedSubExpenseReq takes the price in major units of the currency of the expense
 type CreatedSubExpenseReq struct {
-	ExpenseId string  `json:"expenseId"`
-	Name      string  `json:"name"`
-	Price     float32 `json:"price"`
+	ExpenseId string      `json:"expenseId"`
+	Name      string      `json:"name"`
+	Price     json.Number `json:"price"`
 }
 
 type UpdateSubExpenseReq struct {
-	ID        string  `json:"id"`
-	ExpenseId string  `json:"expenseId"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
+	ID        string      `json:"id"`
+	ExpenseId string      `json:"expenseId"`
+	Name      string      `json:"name"`
+	Price     json.Number `json:"price"`
 }
@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/google/uuid"
+	"gohub/pkg/money"
 	"gorm.io/gorm"
 	"time"
 )
@@ -10,7 +11,7 @@ type Expense struct {
 	ID          string         `json:"id" gorm:"unique;not null;index;primary_key"`
 	EventId     string         `json:"eventId" gorm:"not null"`
 	Title       string         `json:"title" gorm:"not null"`
-	Total       float64        `json:"total" gorm:"not null default:0"`
+	Total       money.Money    `json:"total" gorm:"embedded;embeddedPrefix:total_"`
 	SubExpenses []*SubExpense  `json:"subExpenses" gorm:"foreignKey:ExpenseId;references:ID"`
 	CreatedAt   time.Time      `json:"createdAt" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/google/uuid"
+	"gohub/pkg/money"
 	"gorm.io/gorm"
 	"time"
 )
@@ -10,7 +11,7 @@ type SubExpense struct {
 	ID        string         `json:"id" gorm:"unique;not null;index;primary_key"`
 	ExpenseId string         `json:"expenseId" gorm:"not null"`
 	Name      string         `json:"name" gorm:"not null"`
-	Price     float64        `json:"price" gorm:"not null"`
+	Price     money.Money    `json:"price" gorm:"embedded;embeddedPrefix:price_"`
 	CreatedAt time.Time      `json:"createdAt" gorm:"autoCreateTime"`
 	UpdatedAt time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"`
@@ -101,6 +101,8 @@ func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 		switch err.Error() {
 		case messages.TitleExpenseAlreadyExists:
 			response.Error(c, http.StatusConflict, err, messages.TitleExpenseAlreadyExists)
+		case messages.InvalidPrice:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidPrice)
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to create expense")
 		}
@@ -134,6 +136,8 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 	if err != nil {
 		logger.Error("Failed to update expense ", err.Error())
 		switch err.Error() {
+		case messages.InvalidPrice:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidPrice)
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to update expense")
 		}
@@ -191,6 +195,8 @@ func (h *ExpenseHandler) CreateSubExpense(c *gin.Context) {
 		switch err.Error() {
 		case messages.NameSubExpenseAlreadyExists:
 			response.Error(c, http.StatusConflict, err, messages.NameSubExpenseAlreadyExists)
+		case messages.InvalidPrice:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidPrice)
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to create expense")
 		}
@@ -224,6 +230,8 @@ func (h *ExpenseHandler) UpdateSubExpense(c *gin.Context) {
 	if err != nil {
 		logger.Error("Failed to update sub expense ", err.Error())
 		switch err.Error() {
+		case messages.InvalidPrice:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidPrice)
 		default:
 			response.Error(c, http.StatusInternalServerError, err, "Failed to update expense")
 		}
@@ -6,8 +6,8 @@ import (
 	"gohub/database"
 	"gohub/domains/expense/dto"
 	"gohub/domains/expense/model"
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
-	"gohub/pkg/utils"
 )
 
 type IExpenseRepository interface {
@@ -18,7 +18,8 @@ type IExpenseRepository interface {
 	Delete(ctx context.Context, id string) error
 	GetSubExpenseById(ctx context.Context, subExpenseId string) (*model.SubExpense, error)
 	CreateSubExpense(ctx context.Context, subExpense *model.SubExpense) error
-	UpdateSubExpense(ctx context.Context, subExpenseId string, req *dto.UpdateSubExpenseReq) error
+	UpdateSubExpense(ctx context.Context, subExpenseId string, name string, price money.Money) error
+	GetEventCurrency(ctx context.Context, eventId string) (string, error)
 	DeleteSubExpense(ctx context.Context, subExpenseId string) error
 }
 
@@ -106,12 +107,12 @@ func (e *ExpenseRepository) GetSubExpenseById(ctx context.Context, id string) (*
 
 func (e *ExpenseRepository) CreateSubExpense(ctx context.Context, subExpense *model.SubExpense) error {
 	handler := func() error {
-		if subExpense.Price > 0 {
+		if subExpense.Price.IsPositive() {
 			var expense model.Expense
 			if err := e.db.FindById(ctx, subExpense.ExpenseId, &expense); err != nil {
 				return err
 			}
-			expense.Total += subExpense.Price
+			expense.Total = expense.Total.Add(subExpense.Price)
 			if err := e.db.Update(ctx, expense); err != nil {
 				return err
 			}
@@ -129,25 +130,26 @@ func (e *ExpenseRepository) CreateSubExpense(ctx context.Context, subExpense *mo
 	return nil
 }
 
-func (e *ExpenseRepository) UpdateSubExpense(ctx context.Context, subExpenseId string, req *dto.UpdateSubExpenseReq) error {
+func (e *ExpenseRepository) UpdateSubExpense(ctx context.Context, subExpenseId string, name string, price money.Money) error {
 	handler := func() error {
 		subExpense, err := e.GetSubExpenseById(ctx, subExpenseId)
 		if err != nil {
 			return err
 		}
 
-		if subExpense.Price != req.Price {
+		if subExpense.Price != price {
 			var expense model.Expense
 			if err := e.db.FindById(ctx, subExpense.ExpenseId, &expense); err != nil {
 				return err
 			}
-			expense.Total = expense.Total - subExpense.Price + req.Price
+			expense.Total = expense.Total.Sub(subExpense.Price).Add(price)
 			if err := e.db.Update(ctx, expense); err != nil {
 				return err
 			}
 		}
 
-		utils.MapStruct(subExpense, req)
+		subExpense.Name = name
+		subExpense.Price = price
 		if err := e.db.Update(ctx, subExpense); err != nil {
 			return err
 		}
@@ -167,12 +169,12 @@ func (e *ExpenseRepository) DeleteSubExpense(ctx context.Context, subExpenseId s
 			return err
 		}
 
-		if subExpense.Price != 0 {
+		if !subExpense.Price.IsZero() {
 			var expense model.Expense
 			if err := e.db.FindById(ctx, subExpense.ExpenseId, &expense); err != nil {
 				return err
 			}
-			expense.Total -= subExpense.Price
+			expense.Total = expense.Total.Sub(subExpense.Price)
 			if err := e.db.Update(ctx, expense); err != nil {
 				return err
 			}
@@ -190,3 +192,15 @@ func (e *ExpenseRepository) DeleteSubExpense(ctx context.Context, subExpenseId s
 
 	return nil
 }
+
+func (e *ExpenseRepository) GetEventCurrency(ctx context.Context, eventId string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var currency string
+	if err := e.db.GetDB().WithContext(ctx).Table("events").Select("currency").Where("id = ?", eventId).Scan(&currency).Error; err != nil {
+		return "", err
+	}
+
+	return currency, nil
+}
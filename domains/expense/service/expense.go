@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/jackc/pgx/v5/pgconn"
 	"gohub/domains/expense/dto"
@@ -10,8 +11,8 @@ import (
 	"gohub/internal/libs/logger"
 	"gohub/internal/libs/validation"
 	"gohub/pkg/messages"
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
-	"gohub/pkg/utils"
 )
 
 type IExpenseService interface {
@@ -60,10 +61,24 @@ func (e *ExpenseService) CreateExpense(ctx context.Context, req *dto.CreatedExpe
 		return nil, err
 	}
 
-	var expense model.Expense
-	utils.MapStruct(&expense, req)
+	currency, err := e.repoExpense.GetEventCurrency(ctx, req.EventId)
+	if err != nil {
+		logger.Errorf("Create.GetEventCurrency fail, eventId: %s, error: %s", req.EventId, err)
+		return nil, err
+	}
+
+	total, err := parseAmount(req.Total, currency)
+	if err != nil {
+		return nil, err
+	}
 
-	err := e.repoExpense.Create(ctx, &expense)
+	expense := model.Expense{
+		EventId: req.EventId,
+		Title:   req.Title,
+		Total:   total,
+	}
+
+	err = e.repoExpense.Create(ctx, &expense)
 	if err != nil {
 		logger.Errorf("Create fail, error: %s", err)
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
@@ -86,7 +101,13 @@ func (e *ExpenseService) UpdateExpense(ctx context.Context, id string, req *dto.
 		return nil, errors.New(messages.ExpenseNotFound)
 	}
 
-	utils.MapStruct(expense, req)
+	total, err := parseAmount(req.Total, expense.Total.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	expense.Title = req.Title
+	expense.Total = total
 	err = e.repoExpense.Update(ctx, expense)
 	if err != nil {
 		logger.Errorf("Update fail, id: %s, error: %s", id, err)
@@ -111,10 +132,23 @@ func (e *ExpenseService) CreateSubExpense(ctx context.Context, req *dto.CreatedS
 		return nil, err
 	}
 
-	var subExpense model.SubExpense
-	utils.MapStruct(&subExpense, req)
+	expense, err := e.repoExpense.GetExpenseById(ctx, req.ExpenseId)
+	if err != nil {
+		return nil, errors.New(messages.ExpenseNotFound)
+	}
 
-	err := e.repoExpense.CreateSubExpense(ctx, &subExpense)
+	price, err := parseAmount(req.Price, expense.Total.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	subExpense := model.SubExpense{
+		ExpenseId: req.ExpenseId,
+		Name:      req.Name,
+		Price:     price,
+	}
+
+	err = e.repoExpense.CreateSubExpense(ctx, &subExpense)
 	if err != nil {
 		logger.Errorf("Create fail, error: %s", err)
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
@@ -131,7 +165,17 @@ func (e *ExpenseService) UpdateSubExpense(ctx context.Context, subExpenseId stri
 		return err
 	}
 
-	err := e.repoExpense.UpdateSubExpense(ctx, subExpenseId, req)
+	subExpense, err := e.repoExpense.GetSubExpenseById(ctx, subExpenseId)
+	if err != nil {
+		return errors.New(messages.ExpenseNotFound)
+	}
+
+	price, err := parseAmount(req.Price, subExpense.Price.Currency)
+	if err != nil {
+		return err
+	}
+
+	err = e.repoExpense.UpdateSubExpense(ctx, subExpenseId, req.Name, price)
 	if err != nil {
 		logger.Errorf("Update fail, id: %s, error: %s", subExpenseId, err)
 		return err
@@ -147,3 +191,21 @@ func (e *ExpenseService) DeleteSubExpense(ctx context.Context, subExpenseId stri
 
 	return nil
 }
+
+// parseAmount reads an amount in major units of the currency, a missing amount counts as zero
+func parseAmount(value json.Number, currency string) (money.Money, error) {
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	if value == "" {
+		return money.Zero(currency), nil
+	}
+
+	amount, err := money.Parse(value.String(), currency)
+	if err != nil || amount.IsNegative() {
+		return money.Money{}, errors.New(messages.InvalidPrice)
+	}
+
+	return amount, nil
+}
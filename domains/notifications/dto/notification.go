@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"gohub/pkg/paging"
+	"time"
+)
+
+type Notification struct {
+	ID        string     `json:"id"`
+	Event     Event      `json:"event"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	IsRead    bool       `json:"isRead"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type Event struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	CoverImageUrl string `json:"coverImageUrl"`
+	StartTime     string `json:"startTime"`
+}
+
+type Announcement struct {
+	ID             string    `json:"id"`
+	EventId        string    `json:"eventId"`
+	SenderId       string    `json:"senderId"`
+	Title          string    `json:"title"`
+	Content        string    `json:"content"`
+	RecipientCount int       `json:"recipientCount"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type Recipient struct {
+	ID       string
+	Email    string
+	FullName string
+	// PaidByCard and PaidManually tell how the orders of the tickets held were paid, only cancellations use them
+	PaidByCard   bool
+	PaidManually bool
+	// CanRefund tells whether the holder bought tickets they still hold and can ask their refund, only
+	// reschedules use it
+	CanRefund bool
+}
+
+type ListNotificationReq struct {
+	IsRead  *bool `json:"-" form:"isRead"`
+	Page    int64 `json:"-" form:"page"`
+	Limit   int64 `json:"-" form:"pageSize"`
+	TakeAll bool  `json:"-" form:"take_all"`
+}
+
+type ListNotificationRes struct {
+	Notifications []*Notification    `json:"items"`
+	Pagination    *paging.Pagination `json:"metadata"`
+}
+
+type ListAnnouncementReq struct {
+	Page    int64 `json:"-" form:"page"`
+	Limit   int64 `json:"-" form:"pageSize"`
+	TakeAll bool  `json:"-" form:"take_all"`
+}
+
+type ListAnnouncementRes struct {
+	Announcements []*Announcement    `json:"items"`
+	Pagination    *paging.Pagination `json:"metadata"`
+}
+
+type CreateAnnouncementReq struct {
+	EventId  string `json:"-"`
+	SenderId string `json:"-"`
+	Title    string `json:"title" validate:"required"`
+	Content  string `json:"content" validate:"required"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	modelEvent "gohub/domains/events/model"
+	modelUser "gohub/domains/users/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Announcement struct {
+	ID             string            `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventId        string            `json:"eventId" gorm:"not null;index"`
+	Event          *modelEvent.Event `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SenderId       string            `json:"senderId" gorm:"not null"`
+	Sender         *modelUser.User   `json:"sender" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Title          string            `json:"title" gorm:"not null"`
+	Content        string            `json:"content" gorm:"not null"`
+	RecipientCount int               `json:"recipientCount"`
+	CreatedAt      time.Time         `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time         `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt    `json:"deletedAt" gorm:"index"`
+}
+
+func (a *Announcement) BeforeCreate(tx *gorm.DB) error {
+	a.ID = uuid.New().String()
+
+	return nil
+}
+
+func (Announcement) TableName() string {
+	return "announcements"
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	modelEvent "gohub/domains/events/model"
+	modelUser "gohub/domains/users/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	NotificationTypeReminder     = "Reminder"
+	NotificationTypeAnnouncement = "Announcement"
+	NotificationTypeCancellation = "Cancellation"
+	NotificationTypeReschedule   = "Reschedule"
+)
+
+type Notification struct {
+	ID           string            `json:"id" gorm:"unique;not null;index;primary_key"`
+	UserId       string            `json:"userId" gorm:"not null;index"`
+	User         *modelUser.User   `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	EventId      string            `json:"eventId" gorm:"not null"`
+	Event        *modelEvent.Event `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OccurrenceId *string           `json:"occurrenceId"`
+	Type         string            `json:"type" gorm:"not null"`
+	Title        string            `json:"title" gorm:"not null"`
+	Content      string            `json:"content"`
+	IsRead       bool              `json:"isRead" gorm:"default:false"`
+	ReadAt       *time.Time        `json:"readAt"`
+	CreatedAt    time.Time         `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time         `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt    `json:"deletedAt" gorm:"index"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	n.ID = uuid.New().String()
+
+	return nil
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
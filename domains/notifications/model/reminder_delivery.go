@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReminderDelivery records that a reminder was sent, the unique index guarantees each user gets each reminder
+// of an occurrence once
+type ReminderDelivery struct {
+	ID            string    `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventId       string    `json:"eventId" gorm:"not null;index"`
+	OccurrenceId  *string   `json:"occurrenceId" gorm:"uniqueIndex:idx_reminder_deliveries_occurrence_user_offset"`
+	UserId        string    `json:"userId" gorm:"not null;uniqueIndex:idx_reminder_deliveries_occurrence_user_offset"`
+	OffsetMinutes int       `json:"offsetMinutes" gorm:"not null;uniqueIndex:idx_reminder_deliveries_occurrence_user_offset"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+func (r *ReminderDelivery) BeforeCreate(tx *gorm.DB) error {
+	r.ID = uuid.New().String()
+
+	return nil
+}
+
+func (ReminderDelivery) TableName() string {
+	return "reminder_deliveries"
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"gohub/domains/notifications/dto"
+	"gohub/domains/notifications/service"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/response"
+	"gohub/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	service service.INotificationService
+}
+
+func NewNotificationHandler(service service.INotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+	}
+}
+
+//		@Summary	 Retrieves the notifications of the current user
+//	 @Description Fetches a paginated list of reminders and announcements sent to the authenticated user.
+//		@Tags		 Notifications
+//		@Produce	 json
+//		@Param		 isRead	query	bool	false	"Filter by read state"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the list of notifications"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	var req dto.ListNotificationReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	userId := c.GetString("userId")
+	notifications, pagination, err := h.service.ListNotifications(c, userId, &req)
+	if err != nil {
+		logger.Error("Failed to get notifications: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListNotificationRes
+	utils.MapStruct(&res.Notifications, &notifications)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Mark a notification as read
+//	 @Description Marks the notification with the specified ID as read for the authenticated user.
+//		@Tags		 Notifications
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Notification ID"
+//		@Success	 200	{object}	response.Response	"Notification marked as read"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Notification with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/notifications/{id}/read [patch]
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	userId := c.GetString("userId")
+	if err := h.service.MarkAsRead(c, userId, c.Param("id")); err != nil {
+		logger.Error("Failed to mark notification as read: ", err)
+		switch err.Error() {
+		case messages.NotificationNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.NotificationNotFound)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, true)
+}
+
+//		@Summary	 Mark all notifications as read
+//	 @Description Marks every unread notification of the authenticated user as read.
+//		@Tags		 Notifications
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Notifications marked as read"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/notifications/read-all [patch]
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	userId := c.GetString("userId")
+	if err := h.service.MarkAllAsRead(c, userId); err != nil {
+		logger.Error("Failed to mark notifications as read: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	response.JSON(c, http.StatusOK, true)
+}
+
+//		@Summary	 Send an announcement to ticket holders
+//	 @Description Allows the organizer of an event to send an announcement to every ticket holder by email and in real time. Announcements are rate limited per event.
+//		@Tags		 Notifications
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 params	body	dto.CreateAnnouncementReq	true	"Announcement"
+//		@Success	 200	{object}	response.Response	"Announcement sent successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 429	{object}	response.Response	"Too Many Requests - An announcement was sent recently"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/notifications/events/{eventId}/announcements [post]
+func (h *NotificationHandler) CreateAnnouncement(c *gin.Context) {
+	var req dto.CreateAnnouncementReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+	req.EventId = c.Param("eventId")
+	req.SenderId = c.GetString("userId")
+
+	announcement, err := h.service.CreateAnnouncement(c, &req)
+	if err != nil {
+		logger.Error("Failed to create announcement: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		case messages.AnnouncementRateLimited:
+			response.Error(c, http.StatusTooManyRequests, err, messages.AnnouncementRateLimited)
+		default:
+			response.Error(c, http.StatusBadRequest, err, "Failed to create announcement")
+		}
+		return
+	}
+
+	var res dto.Announcement
+	utils.MapStruct(&res, &announcement)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieves the announcements of an event
+//	 @Description Fetches a paginated list of announcements sent by the organizer of the event.
+//		@Tags		 Notifications
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the list of announcements"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/notifications/events/{eventId}/announcements [get]
+func (h *NotificationHandler) ListAnnouncements(c *gin.Context) {
+	var req dto.ListAnnouncementReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	userId := c.GetString("userId")
+	announcements, pagination, err := h.service.ListAnnouncements(c, userId, c.Param("eventId"), &req)
+	if err != nil {
+		logger.Error("Failed to get announcements: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.ListAnnouncementRes
+	utils.MapStruct(&res.Announcements, &announcements)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
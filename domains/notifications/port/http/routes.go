@@ -0,0 +1,29 @@
+package http
+
+import (
+	"gohub/database"
+	"gohub/domains/notifications/repository"
+	"gohub/domains/notifications/service"
+	"gohub/internal/libs/mailer"
+	"gohub/internal/libs/validation"
+	socketio "gohub/internal/libs/websocket"
+	middleware "gohub/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.Validation, notifier socketio.Notifier, mailer mailer.Mailer) {
+	notificationRepository := repository.NewNotificationRepository(sqlDB)
+	notificationService := service.NewNotificationService(validator, notificationRepository, notifier, mailer)
+	notificationHandler := NewNotificationHandler(notificationService)
+
+	authMiddleware := middleware.JWTAuth()
+	notificationRoute := r.Group("/notifications").Use(authMiddleware)
+	{
+		notificationRoute.GET("/", notificationHandler.ListNotifications)
+		notificationRoute.PATCH("/read-all", notificationHandler.MarkAllAsRead)
+		notificationRoute.PATCH("/:id/read", notificationHandler.MarkAsRead)
+		notificationRoute.POST("/events/:eventId/announcements", notificationHandler.CreateAnnouncement)
+		notificationRoute.GET("/events/:eventId/announcements", notificationHandler.ListAnnouncements)
+	}
+}
@@ -0,0 +1,452 @@
+package repository
+
+import (
+	"context"
+	"gohub/configs"
+	"gohub/database"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/notifications/dto"
+	"gohub/domains/notifications/model"
+	modelPayment "gohub/domains/payments/model"
+	"gohub/pkg/paging"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type INotificationRepository interface {
+	ListNotifications(ctx context.Context, userId string, req *dto.ListNotificationReq) ([]*model.Notification, *paging.Pagination, error)
+	CreateNotifications(ctx context.Context, notifications []*model.Notification) error
+	MarkAsRead(ctx context.Context, userId string, id string) error
+	MarkAllAsRead(ctx context.Context, userId string) error
+	GetEventById(ctx context.Context, id string) (*modelEvent.Event, error)
+	ListOccurrencesStartingBetween(ctx context.Context, fromMinutes int, toMinutes int) ([]*modelEvent.EventOccurrence, error)
+	ListReminderRecipients(ctx context.Context, occurrence *modelEvent.EventOccurrence, offsetMinutes int) ([]*dto.Recipient, error)
+	ListTicketHolders(ctx context.Context, eventId string) ([]*dto.Recipient, error)
+	ListRescheduleRecipients(ctx context.Context, reschedule *modelEvent.EventReschedule) ([]*dto.Recipient, error)
+	ListCancelledEventsToNotify(ctx context.Context) ([]*modelEvent.Event, error)
+	ListCancelledOccurrencesToNotify(ctx context.Context) ([]*modelEvent.EventOccurrence, error)
+	ListCancellationRecipients(ctx context.Context, eventId string, occurrenceId *string) ([]*dto.Recipient, error)
+	ListReschedulesToNotify(ctx context.Context) ([]*modelEvent.EventReschedule, error)
+	ClaimReschedule(ctx context.Context, rescheduleId string, notifications []*model.Notification) (bool, error)
+	CreateReminderDelivery(ctx context.Context, delivery *model.ReminderDelivery) (bool, error)
+	CreateAnnouncement(ctx context.Context, announcement *model.Announcement, cooldown time.Duration) (bool, error)
+	ListAnnouncements(ctx context.Context, eventId string, req *dto.ListAnnouncementReq) ([]*model.Announcement, *paging.Pagination, error)
+}
+
+// unnoticedCancellation keeps the tickets of the event that were held when it was cancelled, refunded ones
+// included, whose holder was not told about the cancellation yet
+const unnoticedCancellation = `(tickets.deleted_at IS NULL OR tickets.deleted_at >= events.cancelled_at)
+	AND NOT EXISTS (
+		SELECT 1 FROM notifications
+		WHERE notifications.event_id = events.id AND notifications.user_id = tickets.user_id
+			AND notifications.type = '` + model.NotificationTypeCancellation + `' AND notifications.occurrence_id IS NULL
+	)`
+
+// unnoticedOccurrenceCancellation is unnoticedCancellation for the tickets of an occurrence cancelled by hand
+const unnoticedOccurrenceCancellation = `(tickets.deleted_at IS NULL OR tickets.deleted_at >= event_occurrences.cancelled_at)
+	AND NOT EXISTS (
+		SELECT 1 FROM notifications
+		WHERE notifications.occurrence_id = event_occurrences.id AND notifications.user_id = tickets.user_id
+			AND notifications.type = '` + model.NotificationTypeCancellation + `'
+	)`
+
+type NotificationRepo struct {
+	db database.IDatabase
+}
+
+func NewNotificationRepository(db database.IDatabase) *NotificationRepo {
+	return &NotificationRepo{db: db}
+}
+
+func (n *NotificationRepo) ListNotifications(ctx context.Context, userId string, req *dto.ListNotificationReq) ([]*model.Notification, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	query := make([]database.Query, 0)
+	args := make([]interface{}, 0)
+
+	queryString := "user_id = ?"
+	args = append(args, userId)
+
+	if req.IsRead != nil {
+		queryString += " AND is_read = ?"
+		args = append(args, *req.IsRead)
+	}
+
+	query = append(query, database.NewQuery(queryString, args...))
+
+	var total int64
+	if err := n.db.Count(ctx, &model.Notification{}, &total, database.WithQuery(query...)); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	if req.TakeAll {
+		pagination.PageSize = total
+	}
+
+	var notifications []*model.Notification
+	if err := n.db.Find(
+		ctx,
+		&notifications,
+		database.WithQuery(query...),
+		database.WithLimit(int(pagination.PageSize)),
+		database.WithOffset(int(pagination.Skip)),
+		database.WithOrder("created_at DESC"),
+		database.WithPreload([]string{"Event"}),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return notifications, pagination, nil
+}
+
+func (n *NotificationRepo) CreateNotifications(ctx context.Context, notifications []*model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	return n.db.CreateInBatches(ctx, &notifications, len(notifications))
+}
+
+func (n *NotificationRepo) MarkAsRead(ctx context.Context, userId string, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	result := n.db.GetDB().WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", id, userId).
+		Updates(map[string]interface{}{"is_read": true, "read_at": gorm.Expr("NOW()")})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (n *NotificationRepo) MarkAllAsRead(ctx context.Context, userId string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return n.db.GetDB().WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userId, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": gorm.Expr("NOW()")}).Error
+}
+
+func (n *NotificationRepo) GetEventById(ctx context.Context, id string) (*modelEvent.Event, error) {
+	var event modelEvent.Event
+	if err := n.db.FindById(ctx, id, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// ListOccurrencesStartingBetween returns the scheduled occurrences of published events starting in the window
+// (NOW + fromMinutes, NOW + toMinutes], with their event
+func (n *NotificationRepo) ListOccurrencesStartingBetween(ctx context.Context, fromMinutes int, toMinutes int) ([]*modelEvent.EventOccurrence, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var occurrences []*modelEvent.EventOccurrence
+	if err := n.db.GetDB().WithContext(ctx).
+		Select("event_occurrences.*").
+		Preload("Event").
+		Joins("INNER JOIN events ON events.id = event_occurrences.event_id AND events.state = ?", modelEvent.EventStatePublished).
+		Where("event_occurrences.status = ?", modelEvent.OccurrenceStatusScheduled).
+		Where("event_occurrences.start_time > NOW() + ? * INTERVAL '1 minute'", fromMinutes).
+		Where("event_occurrences.start_time <= NOW() + ? * INTERVAL '1 minute'", toMinutes).
+		Find(&occurrences).Error; err != nil {
+		return nil, err
+	}
+
+	return occurrences, nil
+}
+
+// ListReminderRecipients returns the holders of tickets for the occurrence and the users following its event
+// who were not sent the reminder of the offset yet
+func (n *NotificationRepo) ListReminderRecipients(ctx context.Context, occurrence *modelEvent.EventOccurrence, offsetMinutes int) ([]*dto.Recipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var recipients []*dto.Recipient
+	err := n.db.GetDB().WithContext(ctx).Raw(`
+		SELECT users.id, users.email, users.full_name FROM users
+		WHERE users.deleted_at IS NULL AND users.id IN (
+			SELECT tickets.user_id FROM tickets WHERE tickets.occurrence_id = @occurrenceId AND tickets.deleted_at IS NULL
+			UNION
+			SELECT event_favourites.user_id FROM event_favourites WHERE event_favourites.event_id = @eventId AND event_favourites.deleted_at IS NULL
+		) AND NOT EXISTS (
+			SELECT 1 FROM reminder_deliveries
+			WHERE reminder_deliveries.occurrence_id = @occurrenceId AND reminder_deliveries.user_id = users.id
+				AND reminder_deliveries.offset_minutes = @offsetMinutes
+		)
+	`, map[string]interface{}{
+		"eventId":       occurrence.EventId,
+		"occurrenceId":  occurrence.ID,
+		"offsetMinutes": offsetMinutes,
+	}).Scan(&recipients).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+func (n *NotificationRepo) ListTicketHolders(ctx context.Context, eventId string) ([]*dto.Recipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var recipients []*dto.Recipient
+	err := n.db.GetDB().WithContext(ctx).Raw(`
+		SELECT users.id, users.email, users.full_name FROM users
+		WHERE users.deleted_at IS NULL AND users.id IN (
+			SELECT tickets.user_id FROM tickets WHERE tickets.event_id = ? AND tickets.deleted_at IS NULL
+		)
+	`, eventId).Scan(&recipients).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+// ListRescheduleRecipients returns the holders of the tickets the reschedule moved, of its occurrence when it
+// moved one occurrence. Only the buyers still holding tickets of a paid order made before the reschedule can
+// ask its refund, the holders of transferred tickets are told about the new times only.
+func (n *NotificationRepo) ListRescheduleRecipients(ctx context.Context, reschedule *modelEvent.EventReschedule) ([]*dto.Recipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var recipients []*dto.Recipient
+	err := n.db.GetDB().WithContext(ctx).Raw(`
+		SELECT users.id, users.email, users.full_name,
+			COALESCE(BOOL_OR(
+				payments.user_id = tickets.user_id AND payments.created_at < @rescheduledAt AND payments.status IN @refundable
+			), false) AS can_refund
+		FROM tickets
+		INNER JOIN users ON users.id = tickets.user_id AND users.deleted_at IS NULL
+		LEFT JOIN payments ON payments.id = tickets.payment_id
+		WHERE tickets.event_id = @eventId AND tickets.deleted_at IS NULL
+			AND (CAST(@occurrenceId AS text) IS NULL OR tickets.occurrence_id = @occurrenceId)
+		GROUP BY users.id, users.email, users.full_name
+	`, map[string]interface{}{
+		"eventId":       reschedule.EventId,
+		"occurrenceId":  reschedule.OccurrenceId,
+		"rescheduledAt": reschedule.CreatedAt,
+		"refundable":    []string{modelPayment.PaymentStatusSuccess, modelPayment.PaymentStatusPartiallyRefunded},
+	}).Scan(&recipients).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+// ListCancelledEventsToNotify returns the cancelled events with ticket holders who were not notified yet
+func (n *NotificationRepo) ListCancelledEventsToNotify(ctx context.Context) ([]*modelEvent.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var events []*modelEvent.Event
+	if err := n.db.GetDB().WithContext(ctx).
+		Where("state = ? AND EXISTS (SELECT 1 FROM tickets WHERE tickets.event_id = events.id AND "+unnoticedCancellation+")",
+			modelEvent.EventStateCancelled).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ListCancelledOccurrencesToNotify returns the occurrences cancelled by hand with ticket holders who were not
+// notified yet, the occurrences of a cancelled event are told with the event
+func (n *NotificationRepo) ListCancelledOccurrencesToNotify(ctx context.Context) ([]*modelEvent.EventOccurrence, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var occurrences []*modelEvent.EventOccurrence
+	if err := n.db.GetDB().WithContext(ctx).
+		Select("event_occurrences.*").
+		Preload("Event").
+		Joins("INNER JOIN events ON events.id = event_occurrences.event_id AND events.state <> ?", modelEvent.EventStateCancelled).
+		Where("event_occurrences.status = ? AND event_occurrences.cancelled_at IS NOT NULL", modelEvent.OccurrenceStatusCancelled).
+		Where("EXISTS (SELECT 1 FROM tickets WHERE tickets.occurrence_id = event_occurrences.id AND " + unnoticedOccurrenceCancellation + ")").
+		Find(&occurrences).Error; err != nil {
+		return nil, err
+	}
+
+	return occurrences, nil
+}
+
+// ListCancellationRecipients returns the holders to tell about the cancellation of the event, or of its
+// occurrence when one is given
+func (n *NotificationRepo) ListCancellationRecipients(ctx context.Context, eventId string, occurrenceId *string) ([]*dto.Recipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	scope, id := `INNER JOIN events ON events.id = tickets.event_id
+		WHERE events.id = ? AND `+unnoticedCancellation, eventId
+	if occurrenceId != nil {
+		scope, id = `INNER JOIN event_occurrences ON event_occurrences.id = tickets.occurrence_id
+		WHERE event_occurrences.id = ? AND `+unnoticedOccurrenceCancellation, *occurrenceId
+	}
+
+	// Orders paid through the provider are refunded by it, the others wait for the organizer to pay back by hand
+	var recipients []*dto.Recipient
+	err := n.db.GetDB().WithContext(ctx).Raw(`
+		SELECT users.id, users.email, users.full_name,
+			COALESCE(BOOL_OR(payments.payment_session_id <> '' AND payments.final_price_amount > 0), false) AS paid_by_card,
+			COALESCE(BOOL_OR(payments.payment_session_id = '' AND payments.final_price_amount > 0), false) AS paid_manually
+		FROM tickets
+		INNER JOIN users ON users.id = tickets.user_id AND users.deleted_at IS NULL
+		LEFT JOIN payments ON payments.id = tickets.payment_id
+		`+scope+`
+		GROUP BY users.id, users.email, users.full_name
+	`, id).Scan(&recipients).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+// ListReschedulesToNotify returns the reschedules whose ticket holders were not notified yet
+func (n *NotificationRepo) ListReschedulesToNotify(ctx context.Context) ([]*modelEvent.EventReschedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var reschedules []*modelEvent.EventReschedule
+	if err := n.db.GetDB().WithContext(ctx).
+		Preload("Event").
+		Where("notified_at IS NULL").
+		Order("created_at ASC").
+		Find(&reschedules).Error; err != nil {
+		return nil, err
+	}
+
+	return reschedules, nil
+}
+
+// ClaimReschedule marks the reschedule notified and saves its notifications together, it reports false when
+// another run already did and saves nothing then
+func (n *NotificationRepo) ClaimReschedule(ctx context.Context, rescheduleId string, notifications []*model.Notification) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	claimed := false
+	err := n.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&modelEvent.EventReschedule{}).
+			Where("id = ? AND notified_at IS NULL", rescheduleId).
+			Update("notified_at", gorm.Expr("NOW()"))
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		claimed = true
+		if len(notifications) == 0 {
+			return nil
+		}
+
+		return tx.CreateInBatches(&notifications, len(notifications)).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return claimed, nil
+}
+
+// CreateReminderDelivery reports false when the reminder was already delivered to the user
+func (n *NotificationRepo) CreateReminderDelivery(ctx context.Context, delivery *model.ReminderDelivery) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	result := n.db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// CreateAnnouncement records the announcement unless the event made one within the cooldown, it reports false
+// then. Announcements of the same event are serialized so two concurrent ones cannot both pass the cooldown.
+func (n *NotificationRepo) CreateAnnouncement(ctx context.Context, announcement *model.Announcement, cooldown time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	created := false
+	err := n.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "announcement:"+announcement.EventId).Error; err != nil {
+			return err
+		}
+
+		var recent int64
+		if err := tx.Model(&model.Announcement{}).
+			Where("event_id = ? AND created_at > NOW() - ? * INTERVAL '1 second'", announcement.EventId, cooldown.Seconds()).
+			Count(&recent).Error; err != nil {
+			return err
+		}
+
+		if recent > 0 {
+			return nil
+		}
+
+		if err := tx.Omit(clause.Associations).Create(announcement).Error; err != nil {
+			return err
+		}
+
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+func (n *NotificationRepo) ListAnnouncements(ctx context.Context, eventId string, req *dto.ListAnnouncementReq) ([]*model.Announcement, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	query := database.NewQuery("event_id = ?", eventId)
+
+	var total int64
+	if err := n.db.Count(ctx, &model.Announcement{}, &total, database.WithQuery(query)); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	if req.TakeAll {
+		pagination.PageSize = total
+	}
+
+	var announcements []*model.Announcement
+	if err := n.db.Find(
+		ctx,
+		&announcements,
+		database.WithQuery(query),
+		database.WithLimit(int(pagination.PageSize)),
+		database.WithOffset(int(pagination.Skip)),
+		database.WithOrder("created_at DESC"),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return announcements, pagination, nil
+}
@@ -0,0 +1,537 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohub/configs"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/notifications/dto"
+	"gohub/domains/notifications/model"
+	"gohub/domains/notifications/repository"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/mailer"
+	"gohub/internal/libs/validation"
+	socketio "gohub/internal/libs/websocket"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+	"gohub/pkg/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type INotificationService interface {
+	ListNotifications(ctx context.Context, userId string, req *dto.ListNotificationReq) ([]*model.Notification, *paging.Pagination, error)
+	MarkAsRead(ctx context.Context, userId string, id string) error
+	MarkAllAsRead(ctx context.Context, userId string) error
+	CreateAnnouncement(ctx context.Context, req *dto.CreateAnnouncementReq) (*model.Announcement, error)
+	ListAnnouncements(ctx context.Context, userId string, eventId string, req *dto.ListAnnouncementReq) ([]*model.Announcement, *paging.Pagination, error)
+	SendDueReminders(ctx context.Context) error
+	NotifyCancelledEvents(ctx context.Context) error
+	NotifyRescheduledEvents(ctx context.Context) error
+}
+
+type NotificationService struct {
+	validator            validation.Validation
+	repoNotification     repository.INotificationRepository
+	notifier             socketio.Notifier
+	mailer               mailer.Mailer
+	reminderOffsets      []time.Duration
+	announcementCooldown time.Duration
+}
+
+func NewNotificationService(
+	validator validation.Validation,
+	repoNotification repository.INotificationRepository,
+	notifier socketio.Notifier,
+	mailer mailer.Mailer,
+) *NotificationService {
+	cfg := configs.GetConfig()
+
+	return &NotificationService{
+		validator:            validator,
+		repoNotification:     repoNotification,
+		notifier:             notifier,
+		mailer:               mailer,
+		reminderOffsets:      parseReminderOffsets(cfg.ReminderOffsets),
+		announcementCooldown: parseDuration(cfg.AnnouncementCooldown, configs.DefaultAnnouncementCooldown),
+	}
+}
+
+func (s *NotificationService) ListNotifications(ctx context.Context, userId string, req *dto.ListNotificationReq) ([]*model.Notification, *paging.Pagination, error) {
+	notifications, pagination, err := s.repoNotification.ListNotifications(ctx, userId, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return notifications, pagination, nil
+}
+
+func (s *NotificationService) MarkAsRead(ctx context.Context, userId string, id string) error {
+	if err := s.repoNotification.MarkAsRead(ctx, userId, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(messages.NotificationNotFound)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *NotificationService) MarkAllAsRead(ctx context.Context, userId string) error {
+	return s.repoNotification.MarkAllAsRead(ctx, userId)
+}
+
+func (s *NotificationService) CreateAnnouncement(ctx context.Context, req *dto.CreateAnnouncementReq) (*model.Announcement, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.repoNotification.GetEventById(ctx, req.EventId)
+	if err != nil {
+		logger.Errorf("CreateAnnouncement.GetEventById fail, id: %s, error: %s", req.EventId, err)
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != req.SenderId {
+		return nil, errors.New(messages.NotEventOwner)
+	}
+
+	recipients, err := s.repoNotification.ListTicketHolders(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	announcement := model.Announcement{
+		EventId:        event.ID,
+		SenderId:       req.SenderId,
+		Title:          req.Title,
+		Content:        req.Content,
+		RecipientCount: len(recipients),
+	}
+	created, err := s.repoNotification.CreateAnnouncement(ctx, &announcement, s.announcementCooldown)
+	if err != nil {
+		logger.Errorf("CreateAnnouncement fail, event: %s, error: %s", event.ID, err)
+		return nil, err
+	}
+
+	if !created {
+		return nil, errors.New(messages.AnnouncementRateLimited)
+	}
+
+	go s.deliverAnnouncement(context.Background(), event, &announcement, recipients)
+
+	return &announcement, nil
+}
+
+func (s *NotificationService) ListAnnouncements(ctx context.Context, userId string, eventId string, req *dto.ListAnnouncementReq) ([]*model.Announcement, *paging.Pagination, error) {
+	event, err := s.repoNotification.GetEventById(ctx, eventId)
+	if err != nil {
+		return nil, nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		return nil, nil, errors.New(messages.NotEventOwner)
+	}
+
+	announcements, pagination, err := s.repoNotification.ListAnnouncements(ctx, eventId, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return announcements, pagination, nil
+}
+
+// SendDueReminders sends every reminder whose window is open. Offsets are sorted from the largest,
+// the window of an offset ends where the next smaller one starts, so a late run never sends a stale reminder.
+func (s *NotificationService) SendDueReminders(ctx context.Context) error {
+	for i, offset := range s.reminderOffsets {
+		var lower time.Duration
+		if i+1 < len(s.reminderOffsets) {
+			lower = s.reminderOffsets[i+1]
+		}
+
+		occurrences, err := s.repoNotification.ListOccurrencesStartingBetween(ctx, int(lower.Minutes()), int(offset.Minutes()))
+		if err != nil {
+			return err
+		}
+
+		for _, occurrence := range occurrences {
+			if occurrence.Event == nil {
+				continue
+			}
+
+			if err := s.sendEventReminder(ctx, occurrence.Event, occurrence, offset); err != nil {
+				logger.Errorf("SendDueReminders fail, occurrence: %s, error: %s", occurrence.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NotifyCancelledEvents tells the ticket holders of cancelled events and of occurrences cancelled by hand,
+// each of them once
+func (s *NotificationService) NotifyCancelledEvents(ctx context.Context) error {
+	events, err := s.repoNotification.ListCancelledEventsToNotify(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if err := s.sendCancellation(ctx, event, nil); err != nil {
+			logger.Errorf("NotifyCancelledEvents fail, event: %s, error: %s", event.ID, err)
+		}
+	}
+
+	occurrences, err := s.repoNotification.ListCancelledOccurrencesToNotify(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, occurrence := range occurrences {
+		if occurrence.Event == nil {
+			continue
+		}
+
+		if err := s.sendCancellation(ctx, occurrence.Event, occurrence); err != nil {
+			logger.Errorf("NotifyCancelledEvents fail, occurrence: %s, error: %s", occurrence.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// sendCancellation tells the holders about the cancellation of the event, or of the occurrence when one is given
+func (s *NotificationService) sendCancellation(ctx context.Context, event *modelEvent.Event, occurrence *modelEvent.EventOccurrence) error {
+	var occurrenceId *string
+	startTime, reason := event.StartTime, event.CancellationReason
+	if occurrence != nil {
+		occurrenceId = &occurrence.ID
+		startTime, reason = occurrence.StartTime.In(event.TimeLocation()), occurrence.CancellationReason
+	}
+
+	recipients, err := s.repoNotification.ListCancellationRecipients(ctx, event.ID, occurrenceId)
+	if err != nil {
+		return err
+	}
+
+	notifications := make([]*model.Notification, 0, len(recipients))
+	for _, recipient := range recipients {
+		content := fmt.Sprintf("%s planned on %s was cancelled", event.Name, startTime.Format(modelEvent.DisplayTimeLayout))
+		if recipient.PaidByCard {
+			content += ", tickets paid online are refunded automatically"
+		}
+		if recipient.PaidManually {
+			content += ", the organizer refunds tickets paid by bank transfer by hand"
+		}
+		if reason != "" {
+			content += ". " + reason
+		}
+
+		title := fmt.Sprintf("%s is cancelled", event.Name)
+		if occurrence != nil {
+			title = fmt.Sprintf("%s on %s is cancelled", event.Name, startTime.Format(modelEvent.DisplayTimeLayout))
+		}
+
+		notifications = append(notifications, &model.Notification{
+			UserId:       recipient.ID,
+			EventId:      event.ID,
+			OccurrenceId: occurrenceId,
+			Type:         model.NotificationTypeCancellation,
+			Title:        title,
+			Content:      content,
+		})
+	}
+
+	// The notifications are the record of who was told, they are saved before anything is sent so a failing
+	// mail never makes the next run notify everyone again
+	if err := s.repoNotification.CreateNotifications(ctx, notifications); err != nil {
+		return err
+	}
+
+	for i, recipient := range recipients {
+		body, err := render(cancellationTemplate, cancellationData{
+			FullName:     recipient.FullName,
+			EventName:    event.Name,
+			StartTime:    startTime.Format(modelEvent.DisplayTimeLayout),
+			Reason:       reason,
+			PaidByCard:   recipient.PaidByCard,
+			PaidManually: recipient.PaidManually,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := s.mailer.Send(ctx, &mailer.Message{
+			To:      []string{recipient.Email},
+			Subject: notifications[i].Title,
+			Body:    body,
+		}); err != nil {
+			logger.Errorf("Send cancellation email fail, user: %s, error: %s", recipient.ID, err)
+		}
+
+		notifications[i].Event = event
+		s.emit(recipient.ID, "notify_cancellation", notifications[i])
+	}
+
+	return nil
+}
+
+// NotifyRescheduledEvents tells the ticket holders of rescheduled events about the new times and until when
+// they can ask a refund
+func (s *NotificationService) NotifyRescheduledEvents(ctx context.Context) error {
+	reschedules, err := s.repoNotification.ListReschedulesToNotify(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, reschedule := range reschedules {
+		if err := s.sendReschedule(ctx, reschedule); err != nil {
+			logger.Errorf("NotifyRescheduledEvents fail, reschedule: %s, error: %s", reschedule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *NotificationService) sendReschedule(ctx context.Context, reschedule *modelEvent.EventReschedule) error {
+	// The event was deleted since, nobody is left to tell
+	if reschedule.Event == nil {
+		_, err := s.repoNotification.ClaimReschedule(ctx, reschedule.ID, nil)
+		return err
+	}
+
+	event := reschedule.Event
+	recipients, err := s.repoNotification.ListRescheduleRecipients(ctx, reschedule)
+	if err != nil {
+		return err
+	}
+
+	data := rescheduleData{
+		EventName:         event.Name,
+		PreviousStartTime: reschedule.PreviousStartTime.Format(modelEvent.DisplayTimeLayout),
+		StartTime:         reschedule.StartTime.Format(modelEvent.DisplayTimeLayout),
+		Reason:            reschedule.Reason,
+		RespondBy:         reschedule.RespondBy.Format(modelEvent.DisplayTimeLayout),
+	}
+
+	notifications := make([]*model.Notification, 0, len(recipients))
+	for _, recipient := range recipients {
+		content := fmt.Sprintf("%s planned on %s was moved to %s", event.Name, data.PreviousStartTime, data.StartTime)
+		if recipient.CanRefund {
+			content += ", you can ask a refund until " + data.RespondBy
+		}
+		if reschedule.Reason != "" {
+			content += ". " + reschedule.Reason
+		}
+
+		notifications = append(notifications, &model.Notification{
+			UserId:  recipient.ID,
+			EventId: event.ID,
+			Type:    model.NotificationTypeReschedule,
+			Title:   fmt.Sprintf("%s is rescheduled", event.Name),
+			Content: content,
+		})
+	}
+
+	// The reschedule is claimed with its notifications before anything is sent, so a failing mail never makes
+	// the next run notify everyone again and a failing claim leaves nobody told
+	claimed, err := s.repoNotification.ClaimReschedule(ctx, reschedule.ID, notifications)
+	if err != nil || !claimed {
+		return err
+	}
+
+	for i, recipient := range recipients {
+		data.FullName = recipient.FullName
+		data.CanRefund = recipient.CanRefund
+		body, err := render(rescheduleTemplate, data)
+		if err != nil {
+			return err
+		}
+
+		if err := s.mailer.Send(ctx, &mailer.Message{
+			To:      []string{recipient.Email},
+			Subject: notifications[i].Title,
+			Body:    body,
+		}); err != nil {
+			logger.Errorf("Send reschedule email fail, user: %s, error: %s", recipient.ID, err)
+		}
+
+		notifications[i].Event = event
+		s.emit(recipient.ID, "notify_reschedule", notifications[i])
+	}
+
+	return nil
+}
+
+func (s *NotificationService) sendEventReminder(ctx context.Context, event *modelEvent.Event, occurrence *modelEvent.EventOccurrence, offset time.Duration) error {
+	recipients, err := s.repoNotification.ListReminderRecipients(ctx, occurrence, int(offset.Minutes()))
+	if err != nil {
+		return err
+	}
+
+	startTime := occurrence.StartTime.In(event.TimeLocation())
+
+	var notifications []*model.Notification
+	for _, recipient := range recipients {
+		// A run picked up late tells the time actually left, not the offset of the reminder
+		startsIn := humanizeDuration(time.Until(startTime))
+		notification := &model.Notification{
+			UserId:       recipient.ID,
+			EventId:      event.ID,
+			OccurrenceId: &occurrence.ID,
+			Type:         model.NotificationTypeReminder,
+			Title:        fmt.Sprintf("%s starts in %s", event.Name, startsIn),
+			Content:      fmt.Sprintf("%s starts at %s, %s", event.Name, startTime.Format(modelEvent.DisplayTimeLayout), event.Location),
+		}
+
+		body, err := render(reminderTemplate, reminderData{
+			FullName:  recipient.FullName,
+			EventName: event.Name,
+			StartsIn:  startsIn,
+			StartTime: startTime.Format(modelEvent.DisplayTimeLayout),
+			Location:  event.Location,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The delivery is only recorded once the email went out, a failed one is retried while the window is open
+		if err := s.mailer.Send(ctx, &mailer.Message{
+			To:      []string{recipient.Email},
+			Subject: notification.Title,
+			Body:    body,
+		}); err != nil {
+			logger.Errorf("Send reminder email fail, user: %s, error: %s", recipient.ID, err)
+			continue
+		}
+
+		delivered, err := s.repoNotification.CreateReminderDelivery(ctx, &model.ReminderDelivery{
+			EventId:       event.ID,
+			OccurrenceId:  &occurrence.ID,
+			UserId:        recipient.ID,
+			OffsetMinutes: int(offset.Minutes()),
+		})
+		if err != nil {
+			return err
+		}
+		if delivered {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	if err := s.repoNotification.CreateNotifications(ctx, notifications); err != nil {
+		return err
+	}
+
+	for _, notification := range notifications {
+		notification.Event = event
+		s.emit(notification.UserId, "notify_reminder", notification)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) deliverAnnouncement(ctx context.Context, event *modelEvent.Event, announcement *model.Announcement, recipients []*dto.Recipient) {
+	var notifications []*model.Notification
+	for _, recipient := range recipients {
+		notifications = append(notifications, &model.Notification{
+			UserId:  recipient.ID,
+			EventId: event.ID,
+			Type:    model.NotificationTypeAnnouncement,
+			Title:   announcement.Title,
+			Content: announcement.Content,
+		})
+
+		body, err := render(announcementTemplate, announcementData{
+			FullName:  recipient.FullName,
+			EventName: event.Name,
+			Title:     announcement.Title,
+			Content:   announcement.Content,
+		})
+		if err != nil {
+			logger.Errorf("Render announcement fail, announcement: %s, user: %s, error: %s", announcement.ID, recipient.ID, err)
+			continue
+		}
+
+		if err := s.mailer.Send(ctx, &mailer.Message{
+			To:      []string{recipient.Email},
+			Subject: fmt.Sprintf("[%s] %s", event.Name, announcement.Title),
+			Body:    body,
+		}); err != nil {
+			logger.Errorf("Send announcement email fail, user: %s, error: %s", recipient.ID, err)
+		}
+	}
+
+	if err := s.repoNotification.CreateNotifications(ctx, notifications); err != nil {
+		logger.Errorf("Create announcement notifications fail, announcement: %s, error: %s", announcement.ID, err)
+		return
+	}
+
+	for _, notification := range notifications {
+		notification.Event = event
+		s.emit(notification.UserId, "notify_announcement", notification)
+	}
+}
+
+func (s *NotificationService) emit(userId string, event string, notification *model.Notification) {
+	var data dto.Notification
+	utils.MapStruct(&data, notification)
+	s.notifier.EmitToUser(userId, event, data)
+}
+
+func parseReminderOffsets(raw string) []time.Duration {
+	if strings.TrimSpace(raw) == "" {
+		raw = configs.DefaultReminderOffsets
+	}
+
+	var offsets []time.Duration
+	for _, item := range strings.Split(raw, ",") {
+		offset, err := time.ParseDuration(strings.TrimSpace(item))
+		if err != nil || offset <= 0 {
+			logger.Warnf("Ignore invalid reminder offset: %q", item)
+			continue
+		}
+		offsets = append(offsets, offset)
+	}
+
+	sort.Slice(offsets, func(i, j int) bool {
+		return offsets[i] > offsets[j]
+	})
+
+	return offsets
+}
+
+func parseDuration(raw string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+
+	return duration
+}
+
+// humanizeDuration rounds the duration to the largest unit it reaches, never below a minute
+func humanizeDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d >= 24*time.Hour:
+		return plural(int(d.Round(24*time.Hour)/(24*time.Hour)), "day")
+	case d >= time.Hour:
+		return plural(int(d.Round(time.Hour)/time.Hour), "hour")
+	default:
+		return plural(max(int(d/time.Minute), 1), "minute")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
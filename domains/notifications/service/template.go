@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var reminderTemplate = template.Must(template.New("reminder").Parse(`
+<p>Hi {{.FullName}},</p>
+<p><b>{{.EventName}}</b> starts in {{.StartsIn}} ({{.StartTime}}) at {{.Location}}.</p>
+<p>See you there!</p>
+<p>EventHub</p>
+`))
+
+var announcementTemplate = template.Must(template.New("announcement").Parse(`
+<p>Hi {{.FullName}},</p>
+<p>The organizer of <b>{{.EventName}}</b> sent an announcement:</p>
+<h3>{{.Title}}</h3>
+<p>{{.Content}}</p>
+<p>EventHub</p>
+`))
+
+var cancellationTemplate = template.Must(template.New("cancellation").Parse(`
+<p>Hi {{.FullName}},</p>
+<p>We are sorry, <b>{{.EventName}}</b> planned on {{.StartTime}} was cancelled by its organizer.</p>
+{{if .Reason}}<p>{{.Reason}}</p>{{end}}
+{{if .PaidByCard}}<p>Tickets paid online are refunded automatically, the money goes back to the card or account the order was paid with.</p>{{end}}
+{{if .PaidManually}}<p>Tickets paid by bank transfer are refunded by the organizer, who sends the money back to the buyer of the order by hand. Please contact the organizer if it has not arrived within a few days.</p>{{end}}
+<p>EventHub</p>
+`))
+
+var rescheduleTemplate = template.Must(template.New("reschedule").Parse(`
+<p>Hi {{.FullName}},</p>
+<p><b>{{.EventName}}</b> planned on {{.PreviousStartTime}} was moved to {{.StartTime}} by its organizer.</p>
+{{if .Reason}}<p>{{.Reason}}</p>{{end}}
+<p>Your tickets stay valid for the new date.{{if .CanRefund}} If it does not suit you, you can ask a full refund from your order until {{.RespondBy}}.{{end}}</p>
+<p>EventHub</p>
+`))
+
+type reminderData struct {
+	FullName  string
+	EventName string
+	StartsIn  string
+	StartTime string
+	Location  string
+}
+
+type cancellationData struct {
+	FullName     string
+	EventName    string
+	StartTime    string
+	Reason       string
+	PaidByCard   bool
+	PaidManually bool
+}
+
+type rescheduleData struct {
+	FullName          string
+	EventName         string
+	PreviousStartTime string
+	StartTime         string
+	Reason            string
+	RespondBy         string
+	CanRefund         bool
+}
+
+type announcementData struct {
+	FullName  string
+	EventName string
+	Title     string
+	Content   string
+}
+
+func render(tmpl *template.Template, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
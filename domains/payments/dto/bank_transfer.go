@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"gohub/pkg/money"
+	"mime/multipart"
+)
+
+type PaymentMethod struct {
+	ID            string `json:"id"`
+	MethodName    string `json:"methodName"`
+	MethodLogoUrl string `json:"methodLogoUrl"`
+}
+
+type ListPaymentMethodRes struct {
+	PaymentMethods []*PaymentMethod `json:"items"`
+}
+
+type PaymentAccount struct {
+	ID                      string `json:"id"`
+	PaymentMethodId         string `json:"paymentMethodId"`
+	PaymentAccountNumber    string `json:"paymentAccountNumber"`
+	PaymentAccountQrCodeUrl string `json:"paymentAccountQrCodeUrl"`
+	CheckoutContent         string `json:"checkoutContent"`
+	CreatedAt               string `json:"createdAt"`
+}
+
+type ListPaymentAccountRes struct {
+	PaymentAccounts []*PaymentAccount `json:"items"`
+}
+
+type CreatePaymentAccountReq struct {
+	PaymentMethodId string                `form:"paymentMethodId" validate:"required"`
+	AccountNumber   string                `form:"accountNumber" validate:"required,max=50"`
+	CheckoutContent string                `form:"checkoutContent" validate:"max=50"`
+	QrCode          *multipart.FileHeader `form:"qrCode" validate:"required"`
+}
+
+type BankTransferReq struct {
+	EventId          string              `json:"eventId" validate:"required"`
+	OccurrenceId     string              `json:"occurrenceId"`
+	PaymentAccountId string              `json:"paymentAccountId"`
+	CustomerName     string              `json:"customerName" validate:"required"`
+	CustomerEmail    string              `json:"customerEmail" validate:"required,email"`
+	CustomerPhone    string              `json:"customerPhone"`
+	CouponCode       string              `json:"couponCode"`
+	TicketItems      []*RegistrationItem `json:"tickets" validate:"required,min=1,dive"`
+}
+
+// BankTransfer tells the attendee where to send the money and what to write in the transfer content
+type BankTransfer struct {
+	PaymentId       string      `json:"paymentId"`
+	Status          string      `json:"status"`
+	Reference       string      `json:"reference"`
+	TransferContent string      `json:"transferContent"`
+	Amount          money.Money `json:"amount"`
+	ExpiresAt       string      `json:"expiresAt"`
+	MethodName      string      `json:"methodName"`
+	AccountNumber   string      `json:"accountNumber"`
+	QrCodeUrl       string      `json:"qrCodeUrl"`
+}
+
+type ImportStatementReq struct {
+	Statement *multipart.FileHeader `form:"statement" validate:"required"`
+}
+
+type StatementMatch struct {
+	Line      int         `json:"line"`
+	Reference string      `json:"reference"`
+	PaymentId string      `json:"paymentId"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+}
+
+type ImportStatementRes struct {
+	Confirmed int               `json:"confirmed"`
+	Skipped   int               `json:"skipped"`
+	Matches   []*StatementMatch `json:"matches"`
+}
@@ -1,17 +1,22 @@
 package dto
 
-import "gohub/pkg/paging"
+import (
+	"encoding/json"
+	"gohub/pkg/money"
+	"gohub/pkg/paging"
+)
 
 type Transaction struct {
-	ID             string  `json:"id"`
-	Event          Event   `json:"event"`
-	CustomerName   string  `json:"customerName"`
-	TicketQuantity int     `json:"ticketQuantity"`
-	TotalPrice     float32 `json:"totalPrice"`
-	DiscountPrice  float32 `json:"discountPrice"`
-	FinalPrice     float32 `json:"finalPrice"`
-	Status         string  `json:"status"`
-	CreatedAt      string  `json:"createdAt"`
+	ID             string      `json:"id"`
+	Event          Event       `json:"event"`
+	CustomerName   string      `json:"customerName"`
+	TicketQuantity int         `json:"ticketQuantity"`
+	TotalPrice     money.Money `json:"totalPrice"`
+	DiscountPrice  money.Money `json:"discountPrice"`
+	FinalPrice     money.Money `json:"finalPrice"`
+	RefundedAmount money.Money `json:"refundedAmount"`
+	Status         string      `json:"status"`
+	CreatedAt      string      `json:"createdAt"`
 }
 
 type Event struct {
@@ -36,14 +41,15 @@ type ListTransactionRes struct {
 }
 
 type Order struct {
-	ID             string     `json:"id"`
-	Event          EventOrder `json:"event"`
-	TicketQuantity int        `json:"ticketQuantity"`
-	TotalPrice     float32    `json:"totalPrice"`
-	DiscountPrice  float32    `json:"discountPrice"`
-	FinalPrice     float32    `json:"finalPrice"`
-	Status         string     `json:"status"`
-	CreatedAt      string     `json:"createdAt"`
+	ID             string      `json:"id"`
+	Event          EventOrder  `json:"event"`
+	TicketQuantity int         `json:"ticketQuantity"`
+	TotalPrice     money.Money `json:"totalPrice"`
+	DiscountPrice  money.Money `json:"discountPrice"`
+	FinalPrice     money.Money `json:"finalPrice"`
+	RefundedAmount money.Money `json:"refundedAmount"`
+	Status         string      `json:"status"`
+	CreatedAt      string      `json:"createdAt"`
 }
 
 type EventOrder struct {
@@ -73,26 +79,22 @@ type ListOrderRes struct {
 	Pagination *paging.Pagination `json:"metadata"`
 }
 
+// TicketCheckoutRequest opens a checkout for a signed quote, the tickets and every amount come from the quote
 type TicketCheckoutRequest struct {
-	EventId       string       `json:"eventId"`
-	UserId        string       `json:"userId"`
-	CouponId      string       `json:"couponId"`
-	CustomerEmail string       `json:"customerEmail"`
-	CustomerName  string       `json:"customerName"`
-	CustomerPhone string       `json:"customerPhone"`
-	TicketItems   []TicketItem `json:"tickets"`
-	DiscountPrice float32      `json:"discountPrice"`
-	TotalPrice    float32      `json:"totalPrice"`
-	FinalPrice    float32      `json:"finalPrice"`
-	SessionId     string       `json:"sessionId"`
-	PaymentId     string       `json:"paymentId"`
+	QuoteToken    string `json:"quoteToken" validate:"required"`
+	UserId        string `json:"userId"`
+	CustomerEmail string `json:"customerEmail" validate:"required,email"`
+	CustomerName  string `json:"customerName" validate:"required"`
+	CustomerPhone string `json:"customerPhone"`
+	SessionId     string `json:"sessionId"`
+	PaymentId     string `json:"paymentId"`
 }
 
 type TicketItem struct {
-	TicketTypeId string  `json:"ticketTypeId"`
-	Name         string  `json:"name"`
-	Quantity     int     `json:"quantity"`
-	Price        float32 `json:"price"`
+	TicketTypeId string      `json:"ticketTypeId"`
+	Name         string      `json:"name"`
+	Quantity     int         `json:"quantity"`
+	Price        json.Number `json:"price"`
 }
 
 type TicketCheckoutResponse struct {
@@ -110,7 +112,7 @@ type ConFirmTransactionRequest struct {
 	CustomerName  string       `json:"customerName"`
 	CustomerPhone string       `json:"customerPhone"`
 	TicketItems   []TicketItem `json:"tickets"`
-	TotalPrice    float32      `json:"totalPrice"`
-	DiscountPrice float32      `json:"discountPrice"`
-	FinalPrice    float32      `json:"finalPrice"`
+	TotalPrice    json.Number  `json:"totalPrice"`
+	DiscountPrice json.Number  `json:"discountPrice"`
+	FinalPrice    json.Number  `json:"finalPrice"`
 }
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"gohub/pkg/money"
+	"time"
+)
+
+// QuoteReq asks for the price of an order, the prices always come from the ticket types of the event
+type QuoteReq struct {
+	EventId      string              `json:"eventId" validate:"required"`
+	OccurrenceId string              `json:"occurrenceId"`
+	CouponCode   string              `json:"couponCode"`
+	TicketItems  []*RegistrationItem `json:"tickets" validate:"required,min=1,dive"`
+}
+
+// Quote is a priced order. Token signs every field, the checkout session is opened from the token alone
+// so the amount charged is always the amount quoted.
+type Quote struct {
+	EventId      string       `json:"eventId"`
+	OccurrenceId string       `json:"occurrenceId,omitempty"`
+	UserId       string       `json:"userId"`
+	CouponId     string       `json:"couponId,omitempty"`
+	CouponCode   string       `json:"couponCode,omitempty"`
+	Currency     string       `json:"currency"`
+	Lines        []*QuoteLine `json:"lines"`
+	Subtotal     money.Money  `json:"subtotal"`
+	Discount     money.Money  `json:"discount"`
+	Fees         []*QuoteFee  `json:"fees"`
+	Total        money.Money  `json:"total"`
+	ExpiresAt    time.Time    `json:"expiresAt"`
+	Token        string       `json:"token,omitempty"`
+}
+
+type QuoteLine struct {
+	TicketTypeId string      `json:"ticketTypeId"`
+	Name         string      `json:"name"`
+	Quantity     int         `json:"quantity"`
+	UnitPrice    money.Money `json:"unitPrice"`
+	Total        money.Money `json:"total"`
+}
+
+type QuoteFee struct {
+	Type    string      `json:"type"`
+	Name    string      `json:"name"`
+	Percent float64     `json:"percent"`
+	Amount  money.Money `json:"amount"`
+}
+
+type PricingPolicyReq struct {
+	PlatformFeePercent   float64 `json:"platformFeePercent" validate:"min=0,max=100"`
+	ProcessingFeePercent float64 `json:"processingFeePercent" validate:"min=0,max=100"`
+	TaxName              string  `json:"taxName" validate:"max=50"`
+	TaxPercent           float64 `json:"taxPercent" validate:"min=0,max=100"`
+}
+
+type PricingPolicy struct {
+	EventId              string  `json:"eventId"`
+	PlatformFeePercent   float64 `json:"platformFeePercent"`
+	ProcessingFeePercent float64 `json:"processingFeePercent"`
+	TaxName              string  `json:"taxName"`
+	TaxPercent           float64 `json:"taxPercent"`
+}
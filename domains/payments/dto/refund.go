@@ -0,0 +1,60 @@
+package dto
+
+import "gohub/pkg/money"
+
+type RefundReq struct {
+	TicketIds      []string `json:"ticketIds" validate:"omitempty,dive,required"`
+	PaymentLineIds []string `json:"paymentLineIds" validate:"omitempty,dive,required"`
+	Reason         string   `json:"reason" validate:"max=500"`
+}
+
+type CancelTicketReq struct {
+	TicketIds []string `json:"ticketIds" validate:"omitempty,dive,required"`
+	Reason    string   `json:"reason" validate:"max=500"`
+}
+
+type Refund struct {
+	ID               string      `json:"id"`
+	PaymentId        string      `json:"paymentId"`
+	InitiatedBy      User        `json:"initiatedBy"`
+	Source           string      `json:"source"`
+	TicketQuantity   int         `json:"ticketQuantity"`
+	Amount           money.Money `json:"amount"`
+	Reason           string      `json:"reason"`
+	Status           string      `json:"status"`
+	ProviderRefundId string      `json:"providerRefundId"`
+	SettledById      string      `json:"settledById"`
+	SettledAt        string      `json:"settledAt"`
+	CreatedAt        string      `json:"createdAt"`
+}
+
+type ListRefundRes struct {
+	Refunds []*Refund `json:"items"`
+}
+
+type RefundPolicyReq struct {
+	IsEnabled     bool    `json:"isEnabled"`
+	DeadlineHours int     `json:"deadlineHours" validate:"min=0"`
+	Percentage    float32 `json:"percentage" validate:"min=0,max=100"`
+}
+
+type RefundPolicy struct {
+	EventId       string  `json:"eventId"`
+	IsEnabled     bool    `json:"isEnabled"`
+	DeadlineHours int     `json:"deadlineHours"`
+	Percentage    float32 `json:"percentage"`
+}
+
+// RescheduleResponseReq keeps the tickets of an order for the new times of the event or refunds them
+type RescheduleResponseReq struct {
+	Decision string `json:"decision" validate:"required,oneof=Accepted Refunded"`
+}
+
+type RescheduleResponse struct {
+	ID           string  `json:"id"`
+	RescheduleId string  `json:"rescheduleId"`
+	PaymentId    string  `json:"paymentId"`
+	Decision     string  `json:"decision"`
+	Refund       *Refund `json:"refund"`
+	CreatedAt    string  `json:"createdAt"`
+}
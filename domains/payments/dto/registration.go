@@ -0,0 +1,36 @@
+package dto
+
+type RegisterReq struct {
+	EventId       string              `json:"eventId" validate:"required"`
+	OccurrenceId  string              `json:"occurrenceId"`
+	CustomerName  string              `json:"customerName" validate:"required"`
+	CustomerEmail string              `json:"customerEmail" validate:"required,email"`
+	CustomerPhone string              `json:"customerPhone"`
+	TicketItems   []*RegistrationItem `json:"tickets" validate:"required,min=1,dive"`
+}
+
+type RegistrationItem struct {
+	TicketTypeId string `json:"ticketTypeId" validate:"required"`
+	Quantity     int    `json:"quantity" validate:"required,min=1"`
+}
+
+type Registration struct {
+	ID             string `json:"id"`
+	EventId        string `json:"eventId"`
+	OccurrenceId   string `json:"occurrenceId"`
+	UserId         string `json:"userId"`
+	CustomerName   string `json:"customerName"`
+	CustomerEmail  string `json:"customerEmail"`
+	CustomerPhone  string `json:"customerPhone"`
+	TicketQuantity int    `json:"ticketQuantity"`
+	Status         string `json:"status"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+type ListRegistrationReq struct {
+	Status string `form:"status" validate:"omitempty,oneof=AwaitingApproval Free Rejected"`
+}
+
+type ListRegistrationRes struct {
+	Registrations []*Registration `json:"items"`
+}
@@ -3,35 +3,73 @@ package model
 import (
 	modelEvent "gohub/domains/events/model"
 	modelUser "gohub/domains/users/model"
+	"gohub/pkg/money"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	PaymentStatusPending           = "Pending"
+	PaymentStatusSuccess           = "Success"
+	PaymentStatusFailed            = "Failed"
+	PaymentStatusExpired           = "Expired"
+	PaymentStatusPartiallyRefunded = "PartiallyRefunded"
+	PaymentStatusRefunded          = "Refunded"
+	PaymentStatusFree              = "Free"
+	PaymentStatusAwaitingApproval  = "AwaitingApproval"
+	PaymentStatusRejected          = "Rejected"
+	PaymentStatusAwaitingTransfer  = "AwaitingTransfer"
+)
+
+// HeldPaymentStatuses are the statuses of orders that hold seats before their tickets are issued
+var HeldPaymentStatuses = []string{PaymentStatusAwaitingApproval, PaymentStatusAwaitingTransfer}
+
+// SettledPaymentStatuses are the statuses of payments that were paid, the ones listed as orders and transactions
+var SettledPaymentStatuses = []string{PaymentStatusSuccess, PaymentStatusPartiallyRefunded, PaymentStatusRefunded, PaymentStatusFree}
+
 type Payment struct {
-	ID               string            `json:"id" gorm:"unique;not null;index;primary_key"`
-	EventID          string            `json:"eventId" gorm:"not null"`
-	Event            *modelEvent.Event `json:"event"`
-	CustomerName     string            `json:"customerName" gorm:"not null"`
-	CustomerEmail    string            `json:"customerEmail" gorm:"not null"`
-	CustomerPhone    string            `json:"customerPhone" gorm:"not null"`
-	UserId           string            `json:"userId" gorm:"not null"`
-	User             *modelUser.User   `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	PaymentSessionID string            `json:"paymentSessionId" gorm:"not null"`
-	TicketQuantity   int               `json:"ticketQuantity" gorm:"not null"`
-	TotalPrice       float32           `json:"totalPrice" gorm:"not null"`
-	DiscountPrice    float32           `json:"discountPrice" gorm:"not null"`
-	FinalPrice       float32           `json:"finalPrice" gorm:"not null"`
-	Status           string            `json:"status" gorm:"default:'PENDING'"`
-	CreatedAt        time.Time         `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt        time.Time         `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt        gorm.DeletedAt    `json:"deletedAt" gorm:"index"`
+	ID                string                      `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventID           string                      `json:"eventId" gorm:"not null"`
+	Event             *modelEvent.Event           `json:"event"`
+	OccurrenceId      *string                     `json:"occurrenceId" gorm:"index"`
+	Occurrence        *modelEvent.EventOccurrence `json:"occurrence" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CustomerName      string                      `json:"customerName" gorm:"not null"`
+	CustomerEmail     string                      `json:"customerEmail" gorm:"not null"`
+	CustomerPhone     string                      `json:"customerPhone" gorm:"not null"`
+	UserId            string                      `json:"userId" gorm:"not null"`
+	User              *modelUser.User             `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PaymentSessionID  string                      `json:"paymentSessionId" gorm:"not null"`
+	TicketQuantity    int                         `json:"ticketQuantity" gorm:"not null"`
+	TotalPrice        money.Money                 `json:"totalPrice" gorm:"embedded;embeddedPrefix:total_price_"`
+	DiscountPrice     money.Money                 `json:"discountPrice" gorm:"embedded;embeddedPrefix:discount_price_"`
+	FinalPrice        money.Money                 `json:"finalPrice" gorm:"embedded;embeddedPrefix:final_price_"`
+	RefundedAmount    money.Money                 `json:"refundedAmount" gorm:"embedded;embeddedPrefix:refunded_amount_"`
+	FeeAmount         money.Money                 `json:"feeAmount" gorm:"embedded;embeddedPrefix:fee_amount_"`
+	TaxAmount         money.Money                 `json:"taxAmount" gorm:"embedded;embeddedPrefix:tax_amount_"`
+	CouponId          *string                     `json:"couponId"`
+	CouponCode        string                      `json:"couponCode" gorm:"type:varchar(32);not null;default:''"`
+	Status            string                      `json:"status" gorm:"default:'PENDING'"`
+	TransferReference *string                     `json:"transferReference" gorm:"uniqueIndex"`
+	UserPaymentId     *string                     `json:"userPaymentId"`
+	UserPayment       *modelUser.UserPayment      `json:"userPayment" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ExpiresAt         *time.Time                  `json:"expiresAt"`
+	CreatedAt         time.Time                   `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time                   `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt              `json:"deletedAt" gorm:"index"`
 }
 
 func (p *Payment) BeforeCreate(tx *gorm.DB) error {
 	p.ID = uuid.New().String()
 
+	// Every amount of an order is in the currency of its total
+	for _, amount := range []*money.Money{&p.DiscountPrice, &p.FinalPrice, &p.RefundedAmount, &p.FeeAmount, &p.TaxAmount} {
+		if amount.Currency == "" {
+			*amount = money.Zero(p.TotalPrice.Currency)
+		}
+	}
+
 	return nil
 }
 
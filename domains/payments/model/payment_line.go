@@ -2,6 +2,7 @@ package model
 
 import (
 	modelEvent "gohub/domains/events/model"
+	"gohub/pkg/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,7 +20,7 @@ type PaymentLine struct {
 	TicketTypeID string                 `json:"ticketTypeId" gorm:"not null"`
 	TicketType   *modelEvent.TicketType `json:"ticketType" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	Quantity     int                    `json:"quantity"`
-	Price        float32                `json:"price"`
+	Price        money.Money            `json:"price" gorm:"embedded;embeddedPrefix:price_"`
 	CreatedAt    time.Time              `json:"createdAt" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time              `json:"updatedAt" gorm:"autoUpdateTime"`
 	DeletedAt    gorm.DeletedAt         `json:"deletedAt" gorm:"index"`
@@ -0,0 +1,40 @@
+package model
+
+import (
+	modelEvent "gohub/domains/events/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Fee lines a quote adds on top of the discounted ticket price
+const (
+	FeeTypePlatform   = "PlatformFee"
+	FeeTypeProcessing = "ProcessingFee"
+	FeeTypeTax        = "Tax"
+)
+
+// PricingPolicy holds the fees and the tax the buyers of an event pay on top of the tickets, all of them
+// are percentages of the ticket price after discount
+type PricingPolicy struct {
+	ID                   string            `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventID              string            `json:"eventId" gorm:"not null;uniqueIndex"`
+	Event                *modelEvent.Event `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PlatformFeePercent   float64           `json:"platformFeePercent" gorm:"not null;default:0"`
+	ProcessingFeePercent float64           `json:"processingFeePercent" gorm:"not null;default:0"`
+	TaxName              string            `json:"taxName" gorm:"not null;default:'VAT'"`
+	TaxPercent           float64           `json:"taxPercent" gorm:"not null;default:0"`
+	CreatedAt            time.Time         `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time         `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+func (p *PricingPolicy) BeforeCreate(tx *gorm.DB) error {
+	p.ID = uuid.New().String()
+
+	return nil
+}
+
+func (PricingPolicy) TableName() string {
+	return "pricing_policies"
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	modelUser "gohub/domains/users/model"
+	"gohub/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	RefundStatusPending   = "Pending"
+	RefundStatusSucceeded = "Succeeded"
+	RefundStatusFailed    = "Failed"
+	// RefundStatusAwaitingManual refunds have voided their tickets but the money was paid without the provider,
+	// the organizer pays it back by hand and settles the refund
+	RefundStatusAwaitingManual = "AwaitingManualRefund"
+
+	RefundSourceOrganizer = "Organizer"
+	RefundSourceAdmin     = "Admin"
+	RefundSourceAttendee  = "Attendee"
+	// RefundSourceCancellation refunds are made for the organizer when the event or an occurrence is cancelled
+	RefundSourceCancellation = "Cancellation"
+	// RefundSourceReschedule refunds are asked by attendees who do not keep their tickets for the new times
+	RefundSourceReschedule = "Reschedule"
+)
+
+type Refund struct {
+	ID               string          `json:"id" gorm:"unique;not null;index;primary_key"`
+	PaymentID        string          `json:"paymentId" gorm:"not null;index"`
+	Payment          *Payment        `json:"payment" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	InitiatedById    string          `json:"initiatedById" gorm:"not null"`
+	InitiatedBy      *modelUser.User `json:"initiatedBy" gorm:"foreignKey:InitiatedById;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Source           string          `json:"source" gorm:"not null"`
+	TicketQuantity   int             `json:"ticketQuantity" gorm:"not null"`
+	Amount           money.Money     `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	Reason           string          `json:"reason"`
+	Status           string          `json:"status" gorm:"not null;default:'Pending'"`
+	ProviderRefundId string          `json:"providerRefundId"`
+	SettledById      *string         `json:"settledById"`
+	SettledAt        *time.Time      `json:"settledAt"`
+	CreatedAt        time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time       `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	r.ID = uuid.New().String()
+
+	return nil
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	modelEvent "gohub/domains/events/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefundPolicy decides whether and how much attendees get back when they cancel their own tickets
+type RefundPolicy struct {
+	ID            string            `json:"id" gorm:"unique;not null;index;primary_key"`
+	EventID       string            `json:"eventId" gorm:"not null;uniqueIndex"`
+	Event         *modelEvent.Event `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	IsEnabled     bool              `json:"isEnabled" gorm:"not null;default:false"`
+	DeadlineHours int               `json:"deadlineHours" gorm:"not null;default:0"`
+	Percentage    float32           `json:"percentage" gorm:"not null;default:100"`
+	CreatedAt     time.Time         `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time         `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+func (r *RefundPolicy) BeforeCreate(tx *gorm.DB) error {
+	r.ID = uuid.New().String()
+
+	return nil
+}
+
+func (RefundPolicy) TableName() string {
+	return "refund_policies"
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	modelEvent "gohub/domains/events/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	RescheduleAccepted = "Accepted"
+	RescheduleRefunded = "Refunded"
+)
+
+// RescheduleResponse is the answer of an attendee to a reschedule of the event, once per order
+type RescheduleResponse struct {
+	ID           string                      `json:"id" gorm:"unique;not null;index;primary_key"`
+	RescheduleId string                      `json:"rescheduleId" gorm:"not null;uniqueIndex:idx_reschedule_responses_order"`
+	Reschedule   *modelEvent.EventReschedule `json:"reschedule" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PaymentId    string                      `json:"paymentId" gorm:"not null;uniqueIndex:idx_reschedule_responses_order"`
+	Payment      *Payment                    `json:"payment" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserId       string                      `json:"userId" gorm:"not null"`
+	Decision     string                      `json:"decision" gorm:"not null"`
+	RefundId     *string                     `json:"refundId"`
+	Refund       *Refund                     `json:"refund" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CreatedAt    time.Time                   `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+func (r *RescheduleResponse) BeforeCreate(tx *gorm.DB) error {
+	r.ID = uuid.New().String()
+
+	return nil
+}
+
+func (RescheduleResponse) TableName() string {
+	return "reschedule_responses"
+}
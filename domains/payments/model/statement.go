@@ -0,0 +1,11 @@
+package model
+
+const (
+	TransferReferencePrefix = "TT"
+
+	StatementMatchConfirmed      = "Confirmed"
+	StatementMatchAlreadyPaid    = "AlreadyPaid"
+	StatementMatchExpired        = "Expired"
+	StatementMatchAmountMismatch = "AmountMismatch"
+	StatementMatchUnmatched      = "Unmatched"
+)
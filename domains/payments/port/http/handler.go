@@ -1,11 +1,12 @@
 package http
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"gohub/configs"
 	"gohub/domains/payments/dto"
 	"gohub/domains/payments/service"
 	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
 	"gohub/pkg/response"
 	"gohub/pkg/utils"
 	"net/http"
@@ -85,6 +86,47 @@ func (h *PaymentHandler) GetOrders(c *gin.Context) {
 	response.JSON(c, http.StatusOK, res)
 }
 
+//		@Summary	 Price an order
+//	 @Description Prices the tickets of an order from the ticket types of the event, applies the coupon and adds the service and processing fees and the tax of the event. The quote comes with a signed token to open the checkout session with, it is valid for 15 minutes.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 params	body	dto.QuoteReq	true	"Event, ticket quantities and coupon code"
+//		@Success	 200	{object}	response.Response	"Successfully priced the order"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The coupon is not valid yet, has expired or does not apply to the order"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event, ticket type or coupon not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The coupon reached its usage limit"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/quote [post]
+func (h *PaymentHandler) Quote(c *gin.Context) {
+	var req dto.QuoteReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	quote, err := h.service.Quote(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to quote: ", err)
+		switch err.Error() {
+		case messages.EventNotFound, messages.TicketTypeNotFound, messages.CouponNotFound, messages.OccurrenceNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.CouponNotStarted, messages.CouponExpired, messages.CouponNotApplicable, messages.OccurrenceRequired:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.CouponUsageLimitReached, messages.CouponUserLimitReached, messages.CouponCodeUsed,
+			messages.OccurrenceCancelled, messages.OccurrenceEnded, messages.EventNotOnSale:
+			response.Error(c, http.StatusConflict, err, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, quote)
+}
+
 //		@Summary	 Checkout
 //	 @Description Fetches the details of a specific category based on the provided category ID.
 //		@Tags		 Payments
@@ -96,40 +138,51 @@ func (h *PaymentHandler) GetOrders(c *gin.Context) {
 //		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
 //		@Router		 /api/v1/payments/create-session [post]
 func (h *PaymentHandler) CreateSession(c *gin.Context) {
-	cfg := configs.GetConfig()
-
 	var req dto.TicketCheckoutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error("Failed to get body", err)
 		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
 		return
 	}
+	req.UserId = c.GetString("userId")
 
-	sessionId, sessionUrl, paymentId, err := h.service.CreateSession(c, &req, cfg.StripeSecretKey)
+	session, payment, err := h.service.CreateSession(c, &req)
 
 	if err != nil {
 		logger.Error("Failed to checkout: ", err)
-		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		switch err.Error() {
+		case messages.InvalidQuote:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidQuote)
+		case messages.QuoteExpired:
+			response.Error(c, http.StatusGone, err, messages.QuoteExpired)
+		case messages.CouponNotFound, messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.CouponUsageLimitReached, messages.CouponUserLimitReached, messages.CouponCodeUsed, messages.EventNotOnSale,
+			messages.TicketSoldOut:
+			response.Error(c, http.StatusConflict, err, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
 		return
 	}
 
 	result := dto.TicketCheckoutResponse{
-		SessionID:  sessionId,
-		SessionUrl: sessionUrl,
-		PaymentId:  paymentId,
+		SessionID:  session.Id,
+		SessionUrl: session.Url,
+		PaymentId:  payment.ID,
 		Data:       req,
 	}
 	response.JSON(c, http.StatusOK, result)
 }
 
 //		@Summary	 Checkout
-//	 @Description Fetches the details of a specific category based on the provided category ID.
+//	 @Description Confirms the payment of a checkout session once the attendee is back from the checkout page and issues the tickets. Confirming a session twice issues the tickets once.
 //		@Tags		 Payments
 //		@Produce	 json
 //		@Success	 200	{object}	response.Response	"Category created successfully"
 //		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
-//		@Failure	 403	{object}	response.Response	"Forbidden - User does not have the required permissions"
-//		@Failure	 404	{object}	response.Response	"Not Found - Category with the specified ID not found"
+//		@Failure	 402	{object}	response.Response	"Payment Required - The checkout session has not been paid"
+//		@Failure	 404	{object}	response.Response	"Not Found - No order for the checkout session"
 //		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
 //		@Router		 /api/v1/payments/checkout [post]
 func (h *PaymentHandler) Checkout(c *gin.Context) {
@@ -142,8 +195,796 @@ func (h *PaymentHandler) Checkout(c *gin.Context) {
 
 	if err := h.service.Checkout(c, &req); err != nil {
 		logger.Error("Failed to checkout: ", err)
-		response.Error(c, http.StatusInternalServerError, err, "Some thing went wrong")
+		switch err.Error() {
+		case messages.PaymentNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.PaymentNotFound)
+		case messages.PaymentNotCompleted:
+			response.Error(c, http.StatusPaymentRequired, err, messages.PaymentNotCompleted)
+		case messages.PaymentAmountMismatch:
+			response.Error(c, http.StatusConflict, err, messages.PaymentAmountMismatch)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Some thing went wrong")
+		}
+		return
 	}
 
 	response.JSON(c, http.StatusOK, true)
 }
+
+//		@Summary	 Payment provider webhook
+//	 @Description Receives the checkout outcomes of the payment provider. Paid sessions issue their tickets, failed and expired sessions close their order. The call must carry the signature of the provider.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Webhook processed"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid signature"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/webhook [post]
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	if err := h.service.HandleWebhook(c, payload, c.Request.Header); err != nil {
+		logger.Error("Failed to handle webhook: ", err)
+		switch err.Error() {
+		case messages.InvalidWebhookSignature:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidWebhookSignature)
+		case messages.PaymentNotFound:
+			// Sessions opened outside this application are acknowledged so the provider stops retrying
+			response.JSON(c, http.StatusOK, false)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, true)
+}
+
+//		@Summary	 Download the receipt of a payment
+//	 @Description Renders the receipt of a payment with its line items, discount and final price as a PDF, for the buyer or the organizer of the event.
+//		@Tags		 Payments
+//		@Produce	 application/pdf
+//		@Param		 id	path	string	true	"Payment ID"
+//		@Success	 200	{file}		binary				"Receipt PDF"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is neither the buyer nor the organizer"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/receipt [get]
+func (h *PaymentHandler) GetReceipt(c *gin.Context) {
+	receipt, err := h.service.GetReceipt(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to get receipt: ", err)
+		switch err.Error() {
+		case messages.PaymentNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.PaymentNotFound)
+		case messages.NotPaymentOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotPaymentOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=receipt-%s.pdf", c.Param("id")))
+	c.Data(http.StatusOK, "application/pdf", receipt)
+}
+
+//		@Summary	 Refund a payment
+//	 @Description Refunds the selected tickets or payment lines of a payment, or the whole payment when nothing is selected. Refunded tickets are voided and their seats released. Only the organizer of the event or an admin can refund.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payment ID"
+//		@Param		 params	body	dto.RefundReq	true	"Tickets or payment lines to refund"
+//		@Success	 200	{object}	response.Response	"Payment refunded successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The payment or tickets cannot be refunded"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is neither the organizer nor an admin"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment with the specified ID not found"
+//		@Failure	 502	{object}	response.Response	"Bad Gateway - The payment provider rejected the refund"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/refunds [post]
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	var req dto.RefundReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	refund, err := h.service.RefundPayment(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to refund payment: ", err)
+		refundError(c, err)
+		return
+	}
+
+	var res dto.Refund
+	utils.MapStruct(&res, &refund)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Cancel own tickets
+//	 @Description Cancels tickets of a payment of the authenticated user and refunds them according to the refund policy of the event. Omitting ticketIds cancels every ticket the user still holds.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payment ID"
+//		@Param		 params	body	dto.CancelTicketReq	true	"Tickets to cancel"
+//		@Success	 200	{object}	response.Response	"Tickets cancelled successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The event does not allow cancellation or the deadline has passed"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the buyer"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment with the specified ID not found"
+//		@Failure	 502	{object}	response.Response	"Bad Gateway - The payment provider rejected the refund"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/cancel [post]
+func (h *PaymentHandler) CancelTickets(c *gin.Context) {
+	var req dto.CancelTicketReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	refund, err := h.service.CancelTickets(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to cancel tickets: ", err)
+		refundError(c, err)
+		return
+	}
+
+	var res dto.Refund
+	utils.MapStruct(&res, &refund)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Answer the reschedule of an event
+//	 @Description Keeps the tickets of an order for the new times of a rescheduled event, or refunds the ones the buyer holds in full. Orders made before the reschedule answer it once, before its response window closes.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id			path		string						true	"Payment ID"
+//		@Param		 request	body		dto.RescheduleResponseReq	true	"Accepted or Refunded"
+//		@Success	 200	{object}	response.Response	"Reschedule answered successfully"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or nothing left to refund"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the buyer of the order"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment or reschedule not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Already answered or response window closed"
+//		@Failure	 502	{object}	response.Response	"Bad Gateway - The payment provider rejected the refund"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/reschedule-response [post]
+func (h *PaymentHandler) RespondToReschedule(c *gin.Context) {
+	var req dto.RescheduleResponseReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	answer, err := h.service.RespondToReschedule(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to answer reschedule: ", err)
+		refundError(c, err)
+		return
+	}
+
+	var res dto.RescheduleResponse
+	utils.MapStruct(&res, &answer)
+	response.JSON(c, http.StatusOK, res)
+}
+
+func refundError(c *gin.Context, err error) {
+	switch err.Error() {
+	case messages.PaymentNotFound:
+		response.Error(c, http.StatusNotFound, err, messages.PaymentNotFound)
+	case messages.NotPaymentOwner, messages.NotRefundManager:
+		response.Error(c, http.StatusForbidden, err, err.Error())
+	case messages.PaymentNotRefundable, messages.TicketNotRefundable, messages.NoRefundableTickets,
+		messages.RefundNotAllowed, messages.RefundDeadlinePassed, messages.TicketAlreadyCheckedIn:
+		response.Error(c, http.StatusBadRequest, err, err.Error())
+	case messages.RescheduleNotFound, messages.RefundNotFound:
+		response.Error(c, http.StatusNotFound, err, err.Error())
+	case messages.RescheduleWindowClosed, messages.RescheduleAnswered, messages.RefundNotAwaitingManual:
+		response.Error(c, http.StatusConflict, err, err.Error())
+	case messages.RefundProviderRejected:
+		response.Error(c, http.StatusBadGateway, err, messages.RefundProviderRejected)
+	default:
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+	}
+}
+
+//		@Summary	 Retrieve the refunds of a payment
+//	 @Description Fetches the refunds of a payment, newest first, for the buyer, the organizer of the event or an admin.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payment ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the refunds"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not allowed to access this payment"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/refunds [get]
+func (h *PaymentHandler) GetRefunds(c *gin.Context) {
+	refunds, err := h.service.GetRefunds(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to get refunds: ", err)
+		switch err.Error() {
+		case messages.PaymentNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.PaymentNotFound)
+		case messages.NotPaymentOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotPaymentOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.ListRefundRes
+	utils.MapStruct(&res.Refunds, &refunds)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Settle a manual refund
+//	 @Description Records that the money of a refund awaiting a manual refund, like the refund of a bank transfer order, was paid back to the attendee. Only the organizer of the event or an admin can settle it.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payment ID"
+//		@Param		 refundId	path	string	true	"Refund ID"
+//		@Success	 200	{object}	response.Response	"Refund settled successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not allowed to refund this payment"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment or refund with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The refund is not awaiting a manual refund"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/refunds/{refundId}/settle [patch]
+func (h *PaymentHandler) SettleRefund(c *gin.Context) {
+	refund, err := h.service.SettleRefund(c, c.GetString("userId"), c.Param("id"), c.Param("refundId"))
+	if err != nil {
+		logger.Error("Failed to settle refund: ", err)
+		refundError(c, err)
+		return
+	}
+
+	var res dto.Refund
+	utils.MapStruct(&res, &refund)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the refund policy of an event
+//	 @Description Fetches whether attendees can cancel their tickets, until how many hours before the start and which percentage they get back.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the refund policy"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/events/{eventId}/refund-policy [get]
+func (h *PaymentHandler) GetRefundPolicy(c *gin.Context) {
+	policy, err := h.service.GetRefundPolicy(c, c.Param("eventId"))
+	if err != nil {
+		logger.Error("Failed to get refund policy: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.RefundPolicy
+	utils.MapStruct(&res, &policy)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Update the refund policy of an event
+//	 @Description Sets whether attendees can cancel their tickets, the deadline in hours before the start of the event and the percentage refunded. Only the organizer of the event can update it.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 params	body	dto.RefundPolicyReq	true	"Refund policy"
+//		@Success	 200	{object}	response.Response	"Refund policy updated successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/events/{eventId}/refund-policy [put]
+func (h *PaymentHandler) UpdateRefundPolicy(c *gin.Context) {
+	var req dto.RefundPolicyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	policy, err := h.service.UpdateRefundPolicy(c, c.GetString("userId"), c.Param("eventId"), &req)
+	if err != nil {
+		logger.Error("Failed to update refund policy: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.RefundPolicy
+	utils.MapStruct(&res, &policy)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the pricing policy of an event
+//	 @Description Fetches the service fee, the processing fee and the tax added on top of the ticket prices of an event, in percent of the discounted price.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the pricing policy"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/events/{eventId}/pricing-policy [get]
+func (h *PaymentHandler) GetPricingPolicy(c *gin.Context) {
+	policy, err := h.service.GetPricingPolicy(c, c.Param("eventId"))
+	if err != nil {
+		logger.Error("Failed to get pricing policy: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.PricingPolicy
+	utils.MapStruct(&res, &policy)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Update the pricing policy of an event
+//	 @Description Sets the service fee, the processing fee and the tax added on top of the ticket prices of an event. Only the organizer of the event can update it, quotes already given keep their price.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 params	body	dto.PricingPolicyReq	true	"Pricing policy"
+//		@Success	 200	{object}	response.Response	"Pricing policy updated successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/events/{eventId}/pricing-policy [put]
+func (h *PaymentHandler) UpdatePricingPolicy(c *gin.Context) {
+	var req dto.PricingPolicyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	policy, err := h.service.UpdatePricingPolicy(c, c.GetString("userId"), c.Param("eventId"), &req)
+	if err != nil {
+		logger.Error("Failed to update pricing policy: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.PricingPolicy
+	utils.MapStruct(&res, &policy)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Register for free tickets
+//	 @Description Registers the authenticated user for free ticket types of an event without going through the payment provider. Capacity and the per user limit of the event are enforced. Events that require approval keep the registration awaiting approval, otherwise the tickets are issued and emailed right away.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 params	body	dto.RegisterReq	true	"Event, attendee and ticket quantities"
+//		@Success	 200	{object}	response.Response	"Registered successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - A ticket type is not free"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event or ticket type not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Sold out or ticket limit reached"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/register [post]
+func (h *PaymentHandler) Register(c *gin.Context) {
+	var req dto.RegisterReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	registration, err := h.service.Register(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to register: ", err)
+		switch err.Error() {
+		case messages.EventNotFound, messages.TicketTypeNotFound, messages.OccurrenceNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.TicketTypeNotFree, messages.OccurrenceRequired:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.TicketSoldOut, messages.TicketLimitExceeded, messages.OccurrenceCancelled, messages.OccurrenceEnded,
+			messages.EventNotOnSale:
+			response.Error(c, http.StatusConflict, err, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Registration
+	utils.MapStruct(&res, &registration)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the registrations of an event
+//	 @Description Fetches the free registrations of an event, oldest first, optionally filtered by status. Only the organizer of the event can see them.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 status	query	string	false	"AwaitingApproval, Free or Rejected"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the registrations"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/events/{eventId}/registrations [get]
+func (h *PaymentHandler) GetRegistrations(c *gin.Context) {
+	var req dto.ListRegistrationReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	registrations, err := h.service.GetRegistrations(c, c.GetString("userId"), c.Param("eventId"), &req)
+	if err != nil {
+		logger.Error("Failed to get registrations: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.ListRegistrationRes
+	utils.MapStruct(&res.Registrations, &registrations)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Approve a registration
+//	 @Description Issues and emails the tickets of a registration awaiting approval. Only the organizer of the event can approve.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payment ID of the registration"
+//		@Success	 200	{object}	response.Response	"Registration approved successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Registration not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The registration is not awaiting approval"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/approve [patch]
+func (h *PaymentHandler) ApproveRegistration(c *gin.Context) {
+	registration, err := h.service.ApproveRegistration(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to approve registration: ", err)
+		registrationError(c, err)
+		return
+	}
+
+	var res dto.Registration
+	utils.MapStruct(&res, &registration)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Reject a registration
+//	 @Description Rejects a registration awaiting approval and releases its seats. Only the organizer of the event can reject.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payment ID of the registration"
+//		@Success	 200	{object}	response.Response	"Registration rejected successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Registration not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The registration is not awaiting approval"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/reject [patch]
+func (h *PaymentHandler) RejectRegistration(c *gin.Context) {
+	registration, err := h.service.RejectRegistration(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to reject registration: ", err)
+		registrationError(c, err)
+		return
+	}
+
+	var res dto.Registration
+	utils.MapStruct(&res, &registration)
+	response.JSON(c, http.StatusOK, res)
+}
+
+func registrationError(c *gin.Context, err error) {
+	switch err.Error() {
+	case messages.PaymentNotFound:
+		response.Error(c, http.StatusNotFound, err, messages.PaymentNotFound)
+	case messages.NotEventOwner:
+		response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+	case messages.RegistrationNotPending:
+		response.Error(c, http.StatusConflict, err, messages.RegistrationNotPending)
+	default:
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+	}
+}
+
+//		@Summary	 Retrieve the payment methods
+//	 @Description Fetches the banks and wallets organizers can receive bank transfers with.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the payment methods"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/methods [get]
+func (h *PaymentHandler) GetPaymentMethods(c *gin.Context) {
+	methods, err := h.service.GetPaymentMethods(c)
+	if err != nil {
+		logger.Error("Failed to get payment methods: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListPaymentMethodRes
+	utils.MapStruct(&res.PaymentMethods, &methods)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the payment accounts of the current user
+//	 @Description Fetches the accounts the authenticated organizer receives bank transfers on.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the payment accounts"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/accounts [get]
+func (h *PaymentHandler) GetPaymentAccounts(c *gin.Context) {
+	accounts, err := h.service.GetPaymentAccounts(c, c.GetString("userId"))
+	if err != nil {
+		logger.Error("Failed to get payment accounts: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListPaymentAccountRes
+	utils.MapStruct(&res.PaymentAccounts, &accounts)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Add a payment account
+//	 @Description Adds a bank account with its QR code image that attendees can pay bank transfer orders to.
+//		@Tags		 Payments
+//		@Accept		 multipart/form-data
+//		@Produce	 json
+//		@Param		 paymentMethodId	formData	string	true	"Payment method ID"
+//		@Param		 accountNumber	formData	string	true	"Account number"
+//		@Param		 checkoutContent	formData	string	false	"Text written before the reference in the transfer content"
+//		@Param		 qrCode	formData	file	true	"QR code image of the account"
+//		@Success	 200	{object}	response.Response	"Payment account created successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid parameters"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment method not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/accounts [post]
+func (h *PaymentHandler) CreatePaymentAccount(c *gin.Context) {
+	var req dto.CreatePaymentAccountReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	account, err := h.service.CreatePaymentAccount(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to create payment account: ", err)
+		switch err.Error() {
+		case messages.PaymentMethodNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.PaymentMethodNotFound)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.PaymentAccount
+	utils.MapStruct(&res, &account)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Remove a payment account
+//	 @Description Removes a payment account of the authenticated user, orders already placed keep their transfer instructions.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 accountId	path	string	true	"Payment account ID"
+//		@Success	 200	{object}	response.Response	"Payment account removed successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment account not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/accounts/{accountId} [delete]
+func (h *PaymentHandler) DeletePaymentAccount(c *gin.Context) {
+	if err := h.service.DeletePaymentAccount(c, c.GetString("userId"), c.Param("accountId")); err != nil {
+		logger.Error("Failed to delete payment account: ", err)
+		switch err.Error() {
+		case messages.PaymentAccountNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.PaymentAccountNotFound)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Remove payment account successfully")
+}
+
+//		@Summary	 Order tickets by bank transfer
+//	 @Description Places an order paid by bank transfer to an account of the organizer and returns the QR code, amount and reference to write in the transfer content. The seats are held until the organizer confirms the transfer or the order expires.
+//		@Tags		 Payments
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 params	body	dto.BankTransferReq	true	"Event, attendee and ticket quantities"
+//		@Success	 200	{object}	response.Response	"Order placed successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The organizer has no payment account"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event, ticket type, coupon or payment account not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - Sold out, ticket or coupon limit reached"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/bank-transfer [post]
+func (h *PaymentHandler) CreateBankTransfer(c *gin.Context) {
+	var req dto.BankTransferReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	transfer, err := h.service.CreateBankTransfer(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to create bank transfer: ", err)
+		switch err.Error() {
+		case messages.EventNotFound, messages.TicketTypeNotFound, messages.PaymentAccountNotFound, messages.OccurrenceNotFound,
+			messages.CouponNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.NoPaymentAccount, messages.OccurrenceRequired, messages.CouponNotStarted, messages.CouponExpired,
+			messages.CouponNotApplicable:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.TicketSoldOut, messages.TicketLimitExceeded, messages.OccurrenceCancelled, messages.OccurrenceEnded,
+			messages.EventNotOnSale, messages.CouponUsageLimitReached, messages.CouponUserLimitReached, messages.CouponCodeUsed:
+			response.Error(c, http.StatusConflict, err, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, transfer)
+}
+
+//		@Summary	 Retrieve the bank transfer instructions of an order
+//	 @Description Fetches the account, amount and reference of a bank transfer order, for its buyer or the organizer.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payment ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the bank transfer"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The order is not paid by bank transfer"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is neither the buyer nor the organizer"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/bank-transfer [get]
+func (h *PaymentHandler) GetBankTransfer(c *gin.Context) {
+	transfer, err := h.service.GetBankTransfer(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to get bank transfer: ", err)
+		switch err.Error() {
+		case messages.PaymentNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.PaymentNotFound)
+		case messages.NotPaymentOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotPaymentOwner)
+		case messages.NotAwaitingTransfer:
+			response.Error(c, http.StatusBadRequest, err, messages.NotAwaitingTransfer)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, transfer)
+}
+
+//		@Summary	 Confirm a bank transfer
+//	 @Description Confirms the money of a bank transfer order arrived, then issues and emails its tickets. Only the organizer of the event can confirm.
+//		@Tags		 Payments
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payment ID"
+//		@Success	 200	{object}	response.Response	"Bank transfer confirmed successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payment with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The order is not awaiting a bank transfer"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/{id}/confirm-transfer [patch]
+func (h *PaymentHandler) ConfirmTransfer(c *gin.Context) {
+	payment, err := h.service.ConfirmTransfer(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to confirm bank transfer: ", err)
+		switch err.Error() {
+		case messages.PaymentNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.PaymentNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		case messages.NotAwaitingTransfer:
+			response.Error(c, http.StatusConflict, err, messages.NotAwaitingTransfer)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Order
+	utils.MapStruct(&res, &payment)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Import a bank statement
+//	 @Description Matches the lines of a bank statement CSV with the bank transfer orders of the authenticated organizer by reference, and confirms the orders paid with the exact amount.
+//		@Tags		 Payments
+//		@Accept		 multipart/form-data
+//		@Produce	 json
+//		@Param		 statement	formData	file	true	"Bank statement CSV"
+//		@Success	 200	{object}	response.Response	"Bank statement imported successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The statement could not be read"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payments/bank-statements [post]
+func (h *PaymentHandler) ImportStatement(c *gin.Context) {
+	var req dto.ImportStatementReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	res, err := h.service.ImportStatement(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to import bank statement: ", err)
+		switch err.Error() {
+		case messages.InvalidStatement:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidStatement)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, res)
+}
@@ -5,22 +5,54 @@ import (
 	"gohub/database"
 	"gohub/domains/payments/repository"
 	"gohub/domains/payments/service"
+	ticketRepository "gohub/domains/tickets/repository"
+	ticketService "gohub/domains/tickets/service"
+	"gohub/internal/libs/mailer"
+	"gohub/internal/libs/provider"
 	"gohub/internal/libs/validation"
+	socketio "gohub/internal/libs/websocket"
 	middleware "gohub/pkg/middleware"
 )
 
-func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.Validation) {
+func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.Validation, notifier socketio.Notifier, mailer mailer.Mailer, payments provider.PaymentProvider) {
+	TicketService := ticketService.NewTicketService(validator, ticketRepository.NewTicketRepository(sqlDB), notifier)
 	PaymentRepository := repository.NewPaymentRepository(sqlDB)
-	PaymentService := service.NewPaymentService(validator, PaymentRepository)
+	PaymentService := service.NewPaymentService(validator, PaymentRepository, TicketService, mailer, payments)
 	PaymentHandler := NewPaymentHandler(PaymentService)
 
 	authMiddleware := middleware.JWTAuth()
 
+	// The provider signs its webhooks, it has no user token
+	r.POST("/payments/webhook", PaymentHandler.Webhook)
+
 	expenseRoute := r.Group("/payments").Use(authMiddleware)
 	{
 		expenseRoute.GET("/get-transactions", PaymentHandler.GetTransactions)
 		expenseRoute.GET("/get-orders", PaymentHandler.GetOrders)
+		expenseRoute.POST("/quote", PaymentHandler.Quote)
 		expenseRoute.POST("/create-session", PaymentHandler.CreateSession)
 		expenseRoute.POST("/checkout", PaymentHandler.Checkout)
+		expenseRoute.POST("/register", PaymentHandler.Register)
+		expenseRoute.POST("/bank-transfer", PaymentHandler.CreateBankTransfer)
+		expenseRoute.POST("/bank-statements", PaymentHandler.ImportStatement)
+		expenseRoute.GET("/methods", PaymentHandler.GetPaymentMethods)
+		expenseRoute.GET("/accounts", PaymentHandler.GetPaymentAccounts)
+		expenseRoute.POST("/accounts", PaymentHandler.CreatePaymentAccount)
+		expenseRoute.DELETE("/accounts/:accountId", PaymentHandler.DeletePaymentAccount)
+		expenseRoute.GET("/:id/receipt", PaymentHandler.GetReceipt)
+		expenseRoute.GET("/:id/refunds", PaymentHandler.GetRefunds)
+		expenseRoute.POST("/:id/refunds", PaymentHandler.RefundPayment)
+		expenseRoute.PATCH("/:id/refunds/:refundId/settle", PaymentHandler.SettleRefund)
+		expenseRoute.POST("/:id/cancel", PaymentHandler.CancelTickets)
+		expenseRoute.POST("/:id/reschedule-response", PaymentHandler.RespondToReschedule)
+		expenseRoute.GET("/events/:eventId/refund-policy", PaymentHandler.GetRefundPolicy)
+		expenseRoute.PUT("/events/:eventId/refund-policy", PaymentHandler.UpdateRefundPolicy)
+		expenseRoute.GET("/events/:eventId/pricing-policy", PaymentHandler.GetPricingPolicy)
+		expenseRoute.PUT("/events/:eventId/pricing-policy", PaymentHandler.UpdatePricingPolicy)
+		expenseRoute.GET("/events/:eventId/registrations", PaymentHandler.GetRegistrations)
+		expenseRoute.PATCH("/:id/approve", PaymentHandler.ApproveRegistration)
+		expenseRoute.PATCH("/:id/reject", PaymentHandler.RejectRegistration)
+		expenseRoute.GET("/:id/bank-transfer", PaymentHandler.GetBankTransfer)
+		expenseRoute.PATCH("/:id/confirm-transfer", PaymentHandler.ConfirmTransfer)
 	}
 }
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"gohub/configs"
+	"gohub/database"
+	"gohub/domains/payments/model"
+	modelUser "gohub/domains/users/model"
+)
+
+func (p *PaymentRepository) GetPaymentMethods(ctx context.Context) ([]*model.PaymentMethod, error) {
+	var methods []*model.PaymentMethod
+	if err := p.db.Find(ctx, &methods, database.WithOrder("method_name ASC")); err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (p *PaymentRepository) GetPaymentMethodById(ctx context.Context, id string) (*model.PaymentMethod, error) {
+	var method model.PaymentMethod
+	query := database.NewQuery("id = ?", id)
+	if err := p.db.FindOne(ctx, &method, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return &method, nil
+}
+
+func (p *PaymentRepository) GetUserPayments(ctx context.Context, userId string) ([]*modelUser.UserPayment, error) {
+	var accounts []*modelUser.UserPayment
+	query := database.NewQuery("user_id = ?", userId)
+	if err := p.db.Find(ctx, &accounts, database.WithQuery(query), database.WithOrder("created_at ASC")); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func (p *PaymentRepository) GetUserPaymentById(ctx context.Context, id string) (*modelUser.UserPayment, error) {
+	var account modelUser.UserPayment
+	query := database.NewQuery("id = ?", id)
+	if err := p.db.FindOne(ctx, &account, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (p *PaymentRepository) CreateUserPayment(ctx context.Context, account *modelUser.UserPayment) error {
+	return p.db.Create(ctx, account)
+}
+
+func (p *PaymentRepository) DeleteUserPayment(ctx context.Context, id string) error {
+	return p.db.Delete(ctx, &modelUser.UserPayment{}, database.WithQuery(database.NewQuery("id = ?", id)))
+}
+
+func (p *PaymentRepository) GetPaymentsByReferences(ctx context.Context, references []string) ([]*model.Payment, error) {
+	var payments []*model.Payment
+	query := database.NewQuery("transfer_reference IN ?", references)
+	if err := p.db.Find(ctx, &payments, database.WithQuery(query), database.WithPreload([]string{"Event"})); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// GetExpiredTransfers returns the orders still waiting for a bank transfer after their hold ran out
+func (p *PaymentRepository) GetExpiredTransfers(ctx context.Context) ([]*model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var payments []*model.Payment
+	query := database.NewQuery("status = ? AND expires_at < NOW()", model.PaymentStatusAwaitingTransfer)
+	if err := p.db.Find(ctx, &payments, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/model"
+	modelPayout "gohub/domains/payouts/model"
+	"gohub/pkg/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// postSale credits the organizer with what the buyer paid through the provider and debits the platform fee
+// from them, the money itself lands in the platform cash account until it is paid out. The service and
+// processing fees the buyer paid on top of the tickets go straight to the platform, the tax stays with the
+// organizer who remits it.
+func postSale(tx *gorm.DB, payment *model.Payment, fee money.Money) error {
+	if !payment.FinalPrice.IsPositive() {
+		return nil
+	}
+
+	var event modelEvent.Event
+	if err := tx.Select("id", "user_id", "name").Where("id = ?", payment.EventID).First(&event).Error; err != nil {
+		return err
+	}
+
+	buyerFee := payment.FeeAmount.Min(payment.FinalPrice)
+	earned := payment.FinalPrice.Sub(buyerFee)
+	fee = fee.Min(earned)
+
+	transactionId := uuid.New().String()
+	entries := []*modelPayout.LedgerEntry{
+		{Account: modelPayout.LedgerAccountPlatformCash, Debit: payment.FinalPrice, SourceType: modelPayout.LedgerSourceSale},
+		{Account: modelPayout.LedgerAccountOrganizer, UserId: &event.UserId, Credit: earned, SourceType: modelPayout.LedgerSourceSale},
+	}
+	if buyerFee.IsPositive() {
+		entries = append(entries,
+			&modelPayout.LedgerEntry{Account: modelPayout.LedgerAccountPlatformFees, Credit: buyerFee, SourceType: modelPayout.LedgerSourcePlatformFee},
+		)
+	}
+	if fee.IsPositive() {
+		entries = append(entries,
+			&modelPayout.LedgerEntry{Account: modelPayout.LedgerAccountOrganizer, UserId: &event.UserId, Debit: fee, SourceType: modelPayout.LedgerSourcePlatformFee},
+			&modelPayout.LedgerEntry{Account: modelPayout.LedgerAccountPlatformFees, Credit: fee, SourceType: modelPayout.LedgerSourcePlatformFee},
+		)
+	}
+
+	for _, entry := range entries {
+		entry.TransactionId = transactionId
+		entry.SourceId = payment.ID
+		entry.Description = "Ticket sale of " + event.Name
+	}
+
+	return tx.Omit("User").CreateInBatches(&entries, len(entries)).Error
+}
+
+// postRefund debits the organizer with the refunded amount, the platform keeps its fee. Orders paid
+// outside the provider never reached the platform so they have nothing to reverse.
+func postRefund(tx *gorm.DB, refund *model.Refund) error {
+	if !refund.Amount.IsPositive() {
+		return nil
+	}
+
+	var payment model.Payment
+	if err := tx.Preload("Event").Where("id = ?", refund.PaymentID).First(&payment).Error; err != nil {
+		return err
+	}
+
+	if payment.PaymentSessionID == "" || payment.Event == nil {
+		return nil
+	}
+
+	transactionId := uuid.New().String()
+	entries := []*modelPayout.LedgerEntry{
+		{Account: modelPayout.LedgerAccountOrganizer, UserId: &payment.Event.UserId, Debit: refund.Amount},
+		{Account: modelPayout.LedgerAccountPlatformCash, Credit: refund.Amount},
+	}
+	for _, entry := range entries {
+		entry.TransactionId = transactionId
+		entry.SourceType = modelPayout.LedgerSourceRefund
+		entry.SourceId = refund.ID
+		entry.Description = "Refund of " + payment.Event.Name
+	}
+
+	return tx.Omit("User").CreateInBatches(&entries, len(entries)).Error
+}
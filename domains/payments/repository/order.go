@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/database"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/model"
+	"gohub/pkg/messages"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (p *PaymentRepository) GetTicketTypesByIds(ctx context.Context, eventId string, ids []string) ([]*modelEvent.TicketType, error) {
+	var ticketTypes []*modelEvent.TicketType
+	query := database.NewQuery("event_id = ? AND id IN ?", eventId, ids)
+	if err := p.db.Find(ctx, &ticketTypes, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return ticketTypes, nil
+}
+
+// GetOccurrence loads an occurrence of the event, the first one when no id is given
+func (p *PaymentRepository) GetOccurrence(ctx context.Context, eventId string, id string) (*modelEvent.EventOccurrence, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	query := p.db.GetDB().WithContext(ctx).Where("event_id = ?", eventId)
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+
+	var occurrence modelEvent.EventOccurrence
+	if err := query.Order("start_time ASC").First(&occurrence).Error; err != nil {
+		return nil, err
+	}
+
+	return &occurrence, nil
+}
+
+// CreateOrder records an order that does not go through the payment provider, takes its seats and redeems its
+// coupon in one transaction, tickets are issued right away for free orders. Orders of the same user for the same
+// event are serialized so concurrent requests cannot get past the per user limit.
+func (p *PaymentRepository) CreateOrder(ctx context.Context, payment *model.Payment, paymentLines []*model.PaymentLine, maxPerUser int) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", payment.EventID+":"+payment.UserId).Error; err != nil {
+			return err
+		}
+
+		// The shared lock waits for a cancellation in flight, an order never lands on an event that stopped selling
+		var onSale int64
+		if err := tx.Raw("SELECT COUNT(*) FROM (SELECT 1 FROM events WHERE id = ? AND state = ? FOR SHARE) AS event",
+			payment.EventID, modelEvent.EventStatePublished).Scan(&onSale).Error; err != nil {
+			return err
+		}
+
+		if onSale == 0 {
+			return errors.New(messages.EventNotOnSale)
+		}
+
+		if maxPerUser > 0 {
+			var held int
+			if err := tx.Raw(`
+				SELECT
+					(SELECT COUNT(*) FROM tickets WHERE user_id = @userId AND event_id = @eventId AND deleted_at IS NULL) +
+					(SELECT COALESCE(SUM(ticket_quantity), 0) FROM payments
+						WHERE user_id = @userId AND event_id = @eventId AND status IN @statuses AND deleted_at IS NULL)
+			`, map[string]interface{}{
+				"userId":   payment.UserId,
+				"eventId":  payment.EventID,
+				"statuses": model.HeldPaymentStatuses,
+			}).Scan(&held).Error; err != nil {
+				return err
+			}
+
+			if held+payment.TicketQuantity > maxPerUser {
+				return errors.New(messages.TicketLimitExceeded)
+			}
+		}
+
+		if err := takeSeats(tx, payment, paymentLines, true); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(payment).Error; err != nil {
+			return err
+		}
+
+		if payment.CouponId != nil {
+			if err := redeemCoupon(tx, payment); err != nil {
+				return err
+			}
+		}
+
+		for _, line := range paymentLines {
+			line.PaymentID = payment.ID
+		}
+		if err := tx.Omit(clause.Associations).CreateInBatches(&paymentLines, len(paymentLines)).Error; err != nil {
+			return err
+		}
+
+		if payment.Status != model.PaymentStatusFree {
+			return nil
+		}
+
+		return issueTickets(tx, payment, paymentLines)
+	})
+}
+
+func (p *PaymentRepository) GetRegistrations(ctx context.Context, eventId string, status string) ([]*model.Payment, error) {
+	queryString := "event_id = ? AND status IN ?"
+	statuses := []string{model.PaymentStatusAwaitingApproval, model.PaymentStatusFree, model.PaymentStatusRejected}
+	if status != "" {
+		statuses = []string{status}
+	}
+
+	var payments []*model.Payment
+	query := database.NewQuery(queryString, eventId, statuses)
+	if err := p.db.Find(ctx, &payments, database.WithQuery(query), database.WithOrder("created_at ASC")); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// ConfirmOrder issues the tickets of an order in the from status and moves it to the to status, its seats
+// were taken when the order was placed. It reports false when the order was not in the from status anymore.
+func (p *PaymentRepository) ConfirmOrder(ctx context.Context, paymentId string, from string, to string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	confirmed := false
+	err := p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Payment{}).
+			Where("id = ? AND status = ?", paymentId, from).
+			Update("status", to)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var payment model.Payment
+		if err := tx.Where("id = ?", paymentId).First(&payment).Error; err != nil {
+			return err
+		}
+
+		var paymentLines []*model.PaymentLine
+		if err := tx.Where("payment_id = ?", paymentId).Find(&paymentLines).Error; err != nil {
+			return err
+		}
+
+		confirmed = true
+		return issueTickets(tx, &payment, paymentLines)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return confirmed, nil
+}
+
+// ReleaseOrder closes an order in the from status with the to status and gives its seats back
+func (p *PaymentRepository) ReleaseOrder(ctx context.Context, paymentId string, from string, to string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	released := false
+	err := p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Payment{}).
+			Where("id = ? AND status = ?", paymentId, from).
+			Update("status", to)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := releaseSeats(tx, paymentId); err != nil {
+			return err
+		}
+
+		released = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return released, nil
+}
+
+// takeSeats books the seats of the order lines. An order for an occurrence takes them from the inventory of
+// the occurrence and ticket_types.sale counts the seats sold over every occurrence of the event. When capped
+// an order that does not fit in the seats left is refused.
+func takeSeats(tx *gorm.DB, payment *model.Payment, paymentLines []*model.PaymentLine, capped bool) error {
+	for _, line := range paymentLines {
+		seats := tx.Model(&modelEvent.TicketType{}).Where("id = ? AND event_id = ?", line.TicketTypeID, payment.EventID)
+		if payment.OccurrenceId != nil {
+			seats = tx.Model(&modelEvent.OccurrenceInventory{}).Where("occurrence_id = ? AND ticket_type_id = ?", *payment.OccurrenceId, line.TicketTypeID)
+		}
+		if capped {
+			seats = seats.Where("sale + ? <= quantity", line.Quantity)
+		}
+
+		result := seats.UpdateColumn("sale", gorm.Expr("sale + ?", line.Quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if capped && result.RowsAffected == 0 {
+			return errors.New(messages.TicketSoldOut)
+		}
+
+		if payment.OccurrenceId == nil {
+			continue
+		}
+
+		if err := tx.Model(&modelEvent.TicketType{}).
+			Where("id = ?", line.TicketTypeID).
+			UpdateColumn("sale", gorm.Expr("sale + ?", line.Quantity)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseSeats gives back the seats of every line of the order
+func releaseSeats(tx *gorm.DB, paymentId string) error {
+	if err := tx.Exec(`
+		UPDATE ticket_types
+		SET sale = GREATEST(ticket_types.sale - payment_lines.quantity, 0)
+		FROM payment_lines
+		WHERE payment_lines.ticket_type_id = ticket_types.id AND payment_lines.payment_id = ?
+	`, paymentId).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		UPDATE occurrence_ticket_inventories
+		SET sale = GREATEST(occurrence_ticket_inventories.sale - payment_lines.quantity, 0)
+		FROM payment_lines
+		INNER JOIN payments ON payments.id = payment_lines.payment_id
+		WHERE occurrence_ticket_inventories.occurrence_id = payments.occurrence_id
+			AND occurrence_ticket_inventories.ticket_type_id = payment_lines.ticket_type_id
+			AND payment_lines.payment_id = ?
+	`, paymentId).Error
+}
@@ -4,17 +4,66 @@ import (
 	"context"
 	"gohub/configs"
 	"gohub/database"
+	modelCoupon "gohub/domains/coupons/model"
+	modelEvent "gohub/domains/events/model"
 	"gohub/domains/payments/dto"
 	"gohub/domains/payments/model"
 	modelTicket "gohub/domains/tickets/model"
+	modelUser "gohub/domains/users/model"
+	"gohub/pkg/money"
 	"gohub/pkg/paging"
-	"gohub/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IPaymentRepository interface {
 	GetTransactions(ctx context.Context, userId string, req *dto.ListTransactionReq) ([]*model.Payment, *paging.Pagination, error)
 	GetOrders(ctx context.Context, userId string, req *dto.ListOrderReq) ([]*model.Payment, *paging.Pagination, error)
-	Checkout(ctx context.Context, req *dto.TicketCheckoutRequest) error
+	CreatePayment(ctx context.Context, payment *model.Payment, paymentLines []*model.PaymentLine) error
+	GetPaymentBySession(ctx context.Context, sessionId string) (*model.Payment, error)
+	CompletePayment(ctx context.Context, paymentId string, fee money.Money) (bool, error)
+	ClosePayment(ctx context.Context, paymentId string, status string) error
+	GetExpiredCheckouts(ctx context.Context) ([]*model.Payment, error)
+	GetTicketTypesByIds(ctx context.Context, eventId string, ids []string) ([]*modelEvent.TicketType, error)
+	GetOccurrence(ctx context.Context, eventId string, id string) (*modelEvent.EventOccurrence, error)
+	CreateOrder(ctx context.Context, payment *model.Payment, paymentLines []*model.PaymentLine, maxPerUser int) error
+	GetRegistrations(ctx context.Context, eventId string, status string) ([]*model.Payment, error)
+	ConfirmOrder(ctx context.Context, paymentId string, from string, to string) (bool, error)
+	ReleaseOrder(ctx context.Context, paymentId string, from string, to string) (bool, error)
+	GetPaymentMethods(ctx context.Context) ([]*model.PaymentMethod, error)
+	GetPaymentMethodById(ctx context.Context, id string) (*model.PaymentMethod, error)
+	GetUserPayments(ctx context.Context, userId string) ([]*modelUser.UserPayment, error)
+	GetUserPaymentById(ctx context.Context, id string) (*modelUser.UserPayment, error)
+	CreateUserPayment(ctx context.Context, account *modelUser.UserPayment) error
+	DeleteUserPayment(ctx context.Context, id string) error
+	GetPaymentsByReferences(ctx context.Context, references []string) ([]*model.Payment, error)
+	GetExpiredTransfers(ctx context.Context) ([]*model.Payment, error)
+	GetCancelledEventOrders(ctx context.Context, limit int) ([]*model.Payment, error)
+	GetPaymentById(ctx context.Context, id string) (*model.Payment, error)
+	GetPaymentLines(ctx context.Context, paymentId string) ([]*model.PaymentLine, error)
+	GetTicketsByPayment(ctx context.Context, paymentId string) ([]*modelTicket.Ticket, error)
+	IsAdmin(ctx context.Context, userId string) (bool, error)
+	GetEventById(ctx context.Context, eventId string) (*modelEvent.Event, error)
+	IsBeforeRefundDeadline(ctx context.Context, eventId string, occurrenceId *string, deadlineHours int) (bool, error)
+	GetRefundableTickets(ctx context.Context, paymentId string) ([]*modelTicket.Ticket, error)
+	GetRefunds(ctx context.Context, paymentId string) ([]*model.Refund, error)
+	CreateRefund(ctx context.Context, refund *model.Refund, ticketIds []string) error
+	FailRefund(ctx context.Context, refundId string) error
+	CompleteRefund(ctx context.Context, refund *model.Refund) error
+	GetPendingRefunds(ctx context.Context, limit int) ([]*model.Refund, error)
+	GetRefundById(ctx context.Context, id string) (*model.Refund, error)
+	SettleRefund(ctx context.Context, refundId string, paymentId string, userId string) (bool, error)
+	GetRefundPolicy(ctx context.Context, eventId string) (*model.RefundPolicy, error)
+	SaveRefundPolicy(ctx context.Context, policy *model.RefundPolicy) error
+	GetEventCoupon(ctx context.Context, eventId string, code string) (*modelCoupon.Coupon, error)
+	GetCouponUsage(ctx context.Context, couponId string, userId string, code string) (*modelCoupon.CouponUsage, error)
+	GetPricingPolicy(ctx context.Context, eventId string) (*model.PricingPolicy, error)
+	SavePricingPolicy(ctx context.Context, policy *model.PricingPolicy) error
+	GetLatestReschedule(ctx context.Context, eventId string, occurrenceId *string, orderedAt time.Time) (*modelEvent.EventReschedule, error)
+	HasRescheduleResponse(ctx context.Context, rescheduleId string, paymentId string) (bool, error)
+	CreateRescheduleResponse(ctx context.Context, response *model.RescheduleResponse) (bool, error)
 }
 
 type PaymentRepository struct {
@@ -32,8 +81,8 @@ func (p *PaymentRepository) GetTransactions(ctx context.Context, userId string,
 	query := make([]database.Query, 0)
 	args := make([]interface{}, 0)
 
-	queryString := "events.user_id = ?"
-	args = append(args, userId)
+	queryString := "events.user_id = ? AND payments.status IN ?"
+	args = append(args, userId, model.SettledPaymentStatuses)
 
 	if req.Search != "" {
 		queryString += " AND (customer_name ILIKE ? OR events.name ILIKE ?)"
@@ -101,8 +150,8 @@ func (p *PaymentRepository) GetOrders(ctx context.Context, userId string, req *d
 	query := make([]database.Query, 0)
 	args := make([]interface{}, 0)
 
-	queryString := "payments.user_id = ?"
-	args = append(args, userId)
+	queryString := "payments.user_id = ? AND payments.status IN ?"
+	args = append(args, userId, model.SettledPaymentStatuses)
 
 	if req.Search != "" {
 		queryString += " AND (events.name ILIKE ? OR users.user_name ILIKE ?)"
@@ -166,68 +215,167 @@ func (p *PaymentRepository) GetOrders(ctx context.Context, userId string, req *d
 	return transactions, pagination, nil
 }
 
-func (p *PaymentRepository) Checkout(ctx context.Context, req *dto.TicketCheckoutRequest) error {
+// CreatePayment records the order of a checkout session and holds its seats like CreateOrder, its tickets are
+// issued once the session is paid
+func (p *PaymentRepository) CreatePayment(ctx context.Context, payment *model.Payment, paymentLines []*model.PaymentLine) error {
 	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
 	defer cancel()
 
-	handler := func() error {
-		var payment model.Payment
-		utils.MapStruct(&payment, req)
+	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := takeSeats(tx, payment, paymentLines, true); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(payment).Error; err != nil {
+			return err
+		}
+
+		if payment.CouponId != nil {
+			if err := redeemCoupon(tx, payment); err != nil {
+				return err
+			}
+		}
+
+		for _, line := range paymentLines {
+			line.PaymentID = payment.ID
+			line.EventID = payment.EventID
+		}
+
+		return tx.Omit(clause.Associations).CreateInBatches(&paymentLines, len(paymentLines)).Error
+	})
+}
 
-		var totalQuantity int
-		for _, ticket := range req.TicketItems {
-			totalQuantity += ticket.Quantity
+func (p *PaymentRepository) GetPaymentBySession(ctx context.Context, sessionId string) (*model.Payment, error) {
+	var payment model.Payment
+	query := database.NewQuery("payment_session_id = ?", sessionId)
+	if err := p.db.FindOne(ctx, &payment, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// CompletePayment issues the tickets of a pending payment and records the sale minus the platform fee in the
+// ledger. It reports false when the payment was not pending anymore, so a webhook and the checkout callback
+// never issue tickets twice. Checkouts opened before seats were held at creation have no expiry, they take
+// their seats here.
+func (p *PaymentRepository) CompletePayment(ctx context.Context, paymentId string, fee money.Money) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	completed := false
+	err := p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Payment{}).
+			Where("id = ? AND status = ?", paymentId, model.PaymentStatusPending).
+			Update("status", model.PaymentStatusSuccess)
+		if result.Error != nil {
+			return result.Error
 		}
 
-		payment.ID = req.PaymentId
-		payment.TicketQuantity = totalQuantity
-		payment.PaymentSessionID = req.SessionId
-		payment.Status = "Success"
+		if result.RowsAffected == 0 {
+			return nil
+		}
 
-		if err := p.db.Create(ctx, &payment); err != nil {
+		var payment model.Payment
+		if err := tx.Where("id = ?", paymentId).First(&payment).Error; err != nil {
 			return err
 		}
 
 		var paymentLines []*model.PaymentLine
-		var tickets []*modelTicket.Ticket
-		for _, ticket := range req.TicketItems {
-			paymentLines = append(paymentLines, &model.PaymentLine{
-				PaymentID:    payment.ID,
-				EventID:      payment.EventID,
-				TicketTypeID: ticket.TicketTypeId,
-				Quantity:     ticket.Quantity,
-				Price:        ticket.Price,
-			})
+		if err := tx.Where("payment_id = ?", paymentId).Find(&paymentLines).Error; err != nil {
+			return err
+		}
 
-			for i := 0; i < ticket.Quantity; i++ {
-				tickets = append(tickets, &modelTicket.Ticket{
-					UserId:        payment.UserId,
-					CustomerName:  payment.CustomerName,
-					CustomerEmail: payment.CustomerEmail,
-					CustomerPhone: payment.CustomerPhone,
-					EventId:       payment.EventID,
-					PaymentId:     payment.ID,
-					TicketTypeId:  ticket.TicketTypeId,
-				})
+		if payment.ExpiresAt == nil {
+			if err := takeSeats(tx, &payment, paymentLines, false); err != nil {
+				return err
 			}
 		}
 
-		if err := p.db.CreateInBatches(ctx, &paymentLines, len(paymentLines)); err != nil {
+		if err := issueTickets(tx, &payment, paymentLines); err != nil {
 			return err
 		}
 
-		if err := p.db.CreateInBatches(ctx, &tickets, len(tickets)); err != nil {
+		if err := postSale(tx, &payment, fee); err != nil {
 			return err
 		}
 
+		completed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return completed, nil
+}
+
+// issueTickets creates one ticket per seat of each payment line, holding the buyer's details
+func issueTickets(tx *gorm.DB, payment *model.Payment, paymentLines []*model.PaymentLine) error {
+	var tickets []*modelTicket.Ticket
+	for _, line := range paymentLines {
+		for i := 0; i < line.Quantity; i++ {
+			tickets = append(tickets, &modelTicket.Ticket{
+				UserId:        payment.UserId,
+				CustomerName:  payment.CustomerName,
+				CustomerEmail: payment.CustomerEmail,
+				CustomerPhone: payment.CustomerPhone,
+				EventId:       payment.EventID,
+				OccurrenceId:  payment.OccurrenceId,
+				PaymentId:     payment.ID,
+				TicketTypeId:  line.TicketTypeID,
+			})
+		}
+	}
+
+	if len(tickets) == 0 {
 		return nil
 	}
 
-	err := p.db.WithTransaction(handler)
+	return tx.Omit(clause.Associations).CreateInBatches(&tickets, len(tickets)).Error
+}
 
-	if err != nil {
-		return err
+// ClosePayment moves a payment that is still pending to a final status such as failed or expired and gives
+// its seats back, checkouts without an expiry never took any
+func (p *PaymentRepository) ClosePayment(ctx context.Context, paymentId string, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Payment{}).
+			Where("id = ? AND status = ?", paymentId, model.PaymentStatusPending).
+			Update("status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var payment model.Payment
+		if err := tx.Where("id = ?", paymentId).First(&payment).Error; err != nil {
+			return err
+		}
+
+		if payment.ExpiresAt == nil {
+			return nil
+		}
+
+		return releaseSeats(tx, paymentId)
+	})
+}
+
+// GetExpiredCheckouts returns the checkouts still pending after their session expired
+func (p *PaymentRepository) GetExpiredCheckouts(ctx context.Context) ([]*model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var payments []*model.Payment
+	query := database.NewQuery("status = ? AND expires_at < NOW()", model.PaymentStatusPending)
+	if err := p.db.Find(ctx, &payments, database.WithQuery(query)); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return payments, nil
 }
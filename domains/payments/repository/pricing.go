@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/database"
+	modelCoupon "gohub/domains/coupons/model"
+	couponRepository "gohub/domains/coupons/repository"
+	"gohub/domains/payments/model"
+	"gohub/pkg/messages"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetEventCoupon finds a coupon the organizer applied to the event by its code or one of its generated
+// codes, coupons of other events do not count
+func (p *PaymentRepository) GetEventCoupon(ctx context.Context, eventId string, code string) (*modelCoupon.Coupon, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var coupon modelCoupon.Coupon
+	if err := p.db.GetDB().WithContext(ctx).Scopes(couponRepository.EventCode(eventId, code)).First(&coupon).Error; err != nil {
+		return nil, err
+	}
+
+	return &coupon, nil
+}
+
+func (p *PaymentRepository) GetCouponUsage(ctx context.Context, couponId string, userId string, code string) (*modelCoupon.CouponUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return couponRepository.CouponUsage(p.db.GetDB().WithContext(ctx), couponId, userId, code)
+}
+
+// redeemCoupon records the coupon of an order. The redemptions of a coupon are counted under a lock on it,
+// so concurrent orders can never redeem it past its limits.
+func redeemCoupon(tx *gorm.DB, payment *model.Payment) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "coupon:"+*payment.CouponId).Error; err != nil {
+		return err
+	}
+
+	var coupon modelCoupon.Coupon
+	if err := tx.Where("id = ?", *payment.CouponId).First(&coupon).Error; err != nil {
+		return errors.New(messages.CouponNotFound)
+	}
+
+	usage, err := couponRepository.CouponUsage(tx, coupon.ID, payment.UserId, payment.CouponCode)
+	if err != nil {
+		return err
+	}
+
+	if err := coupon.CheckUsage(payment.CouponCode, *usage); err != nil {
+		return err
+	}
+
+	return tx.Omit(clause.Associations).Create(&modelCoupon.CouponRedemption{
+		CouponId:  coupon.ID,
+		Code:      payment.CouponCode,
+		UserId:    payment.UserId,
+		PaymentId: payment.ID,
+		EventId:   payment.EventID,
+		Amount:    payment.DiscountPrice,
+	}).Error
+}
+
+func (p *PaymentRepository) GetPricingPolicy(ctx context.Context, eventId string) (*model.PricingPolicy, error) {
+	var policy model.PricingPolicy
+	query := database.NewQuery("event_id = ?", eventId)
+	if err := p.db.FindOne(ctx, &policy, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (p *PaymentRepository) SavePricingPolicy(ctx context.Context, policy *model.PricingPolicy) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return p.db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"platform_fee_percent", "processing_fee_percent", "tax_name", "tax_percent", "updated_at"}),
+	}).Create(policy).Error
+}
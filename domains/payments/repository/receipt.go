@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"gohub/database"
+	"gohub/domains/payments/model"
+	modelTicket "gohub/domains/tickets/model"
+)
+
+func (p *PaymentRepository) GetPaymentById(ctx context.Context, id string) (*model.Payment, error) {
+	var payment model.Payment
+	query := database.NewQuery("id = ?", id)
+	if err := p.db.FindOne(ctx, &payment, database.WithQuery(query), database.WithPreload([]string{"Event"})); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (p *PaymentRepository) GetPaymentLines(ctx context.Context, paymentId string) ([]*model.PaymentLine, error) {
+	var lines []*model.PaymentLine
+	query := database.NewQuery("payment_id = ?", paymentId)
+	if err := p.db.Find(ctx, &lines, database.WithQuery(query), database.WithOrder("created_at ASC"), database.WithPreload([]string{"TicketType"})); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func (p *PaymentRepository) GetTicketsByPayment(ctx context.Context, paymentId string) ([]*modelTicket.Ticket, error) {
+	var tickets []*modelTicket.Ticket
+	query := database.NewQuery("payment_id = ?", paymentId)
+	if err := p.db.Find(ctx, &tickets, database.WithQuery(query), database.WithOrder("created_at ASC"), database.WithPreload([]string{"Event", "Occurrence", "TicketType"})); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/database"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/model"
+	modelTicket "gohub/domains/tickets/model"
+	"gohub/pkg/messages"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (p *PaymentRepository) IsAdmin(ctx context.Context, userId string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var total int64
+	if err := p.db.GetDB().WithContext(ctx).
+		Table("user_roles").
+		Joins("INNER JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND roles.name = ? AND user_roles.deleted_at IS NULL", userId, configs.AdminRoleName).
+		Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+func (p *PaymentRepository) GetEventById(ctx context.Context, eventId string) (*modelEvent.Event, error) {
+	var event modelEvent.Event
+	query := database.NewQuery("id = ?", eventId)
+	if err := p.db.FindOne(ctx, &event, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// IsBeforeRefundDeadline compares the start time of the occurrence paid for, of the event for orders without one,
+// with the database clock, the same way the reminder jobs do
+func (p *PaymentRepository) IsBeforeRefundDeadline(ctx context.Context, eventId string, occurrenceId *string, deadlineHours int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var allowed bool
+	if err := p.db.GetDB().WithContext(ctx).Raw(`
+		SELECT COALESCE(event_occurrences.start_time, events.start_time) - @deadlineHours * INTERVAL '1 hour' > NOW()
+		FROM events
+		LEFT JOIN event_occurrences ON event_occurrences.id = @occurrenceId AND event_occurrences.event_id = events.id
+		WHERE events.id = @eventId
+	`, map[string]interface{}{
+		"eventId":       eventId,
+		"occurrenceId":  occurrenceId,
+		"deadlineHours": deadlineHours,
+	}).Scan(&allowed).Error; err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+// GetCancelledEventOrders returns the orders of cancelled events and of occurrences cancelled by hand still to
+// settle: the ones holding seats and the paid ones with tickets left to refund. A payment whose cancellation
+// refund the provider rejected is left to the organizer and the admins.
+func (p *PaymentRepository) GetCancelledEventOrders(ctx context.Context, limit int) ([]*model.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var payments []*model.Payment
+	if err := p.db.GetDB().WithContext(ctx).
+		Select("payments.*").
+		Preload("Event").
+		Preload("Occurrence").
+		Joins("INNER JOIN events ON events.id = payments.event_id").
+		Joins("LEFT JOIN event_occurrences ON event_occurrences.id = payments.occurrence_id").
+		Where("(events.state = @cancelled OR event_occurrences.status = @occurrenceCancelled)", map[string]interface{}{
+			"cancelled":           modelEvent.EventStateCancelled,
+			"occurrenceCancelled": modelEvent.OccurrenceStatusCancelled,
+		}).
+		Where(`(payments.status IN @held OR (payments.status IN @paid
+			AND EXISTS (SELECT 1 FROM tickets WHERE tickets.payment_id = payments.id AND tickets.refund_id IS NULL AND tickets.deleted_at IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM refunds WHERE refunds.payment_id = payments.id AND refunds.source = @source AND refunds.status = @failed)))`,
+			map[string]interface{}{
+				"held":   model.HeldPaymentStatuses,
+				"paid":   []string{model.PaymentStatusSuccess, model.PaymentStatusPartiallyRefunded},
+				"source": model.RefundSourceCancellation,
+				"failed": model.RefundStatusFailed,
+			}).
+		Order("payments.created_at ASC").
+		Limit(limit).
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// GetRefundableTickets returns the tickets of a payment that are neither voided nor part of a refund in flight
+func (p *PaymentRepository) GetRefundableTickets(ctx context.Context, paymentId string) ([]*modelTicket.Ticket, error) {
+	var tickets []*modelTicket.Ticket
+	query := database.NewQuery("payment_id = ? AND refund_id IS NULL", paymentId)
+	if err := p.db.Find(ctx, &tickets, database.WithQuery(query), database.WithOrder("created_at ASC")); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+func (p *PaymentRepository) GetRefunds(ctx context.Context, paymentId string) ([]*model.Refund, error) {
+	var refunds []*model.Refund
+	query := database.NewQuery("payment_id = ?", paymentId)
+	if err := p.db.Find(ctx, &refunds, database.WithQuery(query), database.WithOrder("created_at DESC"), database.WithPreload([]string{"InitiatedBy"})); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func (p *PaymentRepository) GetRefundById(ctx context.Context, id string) (*model.Refund, error) {
+	var refund model.Refund
+	query := database.NewQuery("id = ?", id)
+	if err := p.db.FindOne(ctx, &refund, database.WithQuery(query), database.WithPreload([]string{"InitiatedBy"})); err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// SettleRefund records that the money of a manual refund was paid back, it reports false when the refund of the
+// payment does not await a manual settlement anymore
+func (p *PaymentRepository) SettleRefund(ctx context.Context, refundId string, paymentId string, userId string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	result := p.db.GetDB().WithContext(ctx).Model(&model.Refund{}).
+		Where("id = ? AND payment_id = ? AND status = ?", refundId, paymentId, model.RefundStatusAwaitingManual).
+		Updates(map[string]interface{}{
+			"status":        model.RefundStatusSucceeded,
+			"settled_by_id": userId,
+			"settled_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// CreateRefund records a pending refund and reserves its tickets, so a concurrent refund cannot pay them back twice
+func (p *PaymentRepository) CreateRefund(ctx context.Context, refund *model.Refund, ticketIds []string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&modelTicket.Ticket{}).
+			Where("id IN ? AND payment_id = ? AND refund_id IS NULL", ticketIds, refund.PaymentID).
+			Update("refund_id", refund.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != int64(len(ticketIds)) {
+			return errors.New(messages.TicketNotRefundable)
+		}
+
+		return nil
+	})
+}
+
+// FailRefund marks a pending refund the provider rejected and gives its tickets back to the attendee
+func (p *PaymentRepository) FailRefund(ctx context.Context, refundId string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Refund{}).
+			Where("id = ? AND status = ?", refundId, model.RefundStatusPending).
+			Update("status", model.RefundStatusFailed)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		return tx.Model(&modelTicket.Ticket{}).
+			Where("refund_id = ?", refundId).
+			Update("refund_id", nil).Error
+	})
+}
+
+// CompleteRefund voids the tickets of a pending refund, returns their seats and moves the payment status. The refund
+// takes its status, a refund awaiting a manual settlement counts as refunded already so the tickets cannot be refunded
+// twice. A refund completed already by a concurrent run is left as it is.
+func (p *PaymentRepository) CompleteRefund(ctx context.Context, refund *model.Refund) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Refund{}).
+			Where("id = ? AND status = ?", refund.ID, model.RefundStatusPending).
+			Updates(map[string]interface{}{
+				"status":             refund.Status,
+				"provider_refund_id": refund.ProviderRefundId,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Exec(`
+			UPDATE ticket_types
+			SET sale = GREATEST(ticket_types.sale - refunded.quantity, 0)
+			FROM (
+				SELECT ticket_type_id, COUNT(*) AS quantity
+				FROM tickets
+				WHERE refund_id = @refundId AND deleted_at IS NULL
+				GROUP BY ticket_type_id
+			) AS refunded
+			WHERE ticket_types.id = refunded.ticket_type_id
+		`, map[string]interface{}{"refundId": refund.ID}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			UPDATE occurrence_ticket_inventories
+			SET sale = GREATEST(occurrence_ticket_inventories.sale - refunded.quantity, 0)
+			FROM (
+				SELECT occurrence_id, ticket_type_id, COUNT(*) AS quantity
+				FROM tickets
+				WHERE refund_id = @refundId AND occurrence_id IS NOT NULL AND deleted_at IS NULL
+				GROUP BY occurrence_id, ticket_type_id
+			) AS refunded
+			WHERE occurrence_ticket_inventories.occurrence_id = refunded.occurrence_id
+				AND occurrence_ticket_inventories.ticket_type_id = refunded.ticket_type_id
+		`, map[string]interface{}{"refundId": refund.ID}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&modelTicket.TicketTransfer{}).
+			Where("status = ? AND ticket_id IN (?)", modelTicket.TransferStatusPending,
+				tx.Model(&modelTicket.Ticket{}).Select("id").Where("refund_id = ?", refund.ID)).
+			Updates(map[string]interface{}{"status": modelTicket.TransferStatusCancelled, "responded_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("refund_id = ?", refund.ID).Delete(&modelTicket.Ticket{}).Error; err != nil {
+			return err
+		}
+
+		var remaining int64
+		if err := tx.Model(&modelTicket.Ticket{}).Where("payment_id = ?", refund.PaymentID).Count(&remaining).Error; err != nil {
+			return err
+		}
+
+		status := model.PaymentStatusPartiallyRefunded
+		if remaining == 0 {
+			status = model.PaymentStatusRefunded
+		}
+
+		if err := tx.Model(&model.Payment{}).
+			Where("id = ?", refund.PaymentID).
+			Updates(map[string]interface{}{
+				"refunded_amount_amount": gorm.Expr("refunded_amount_amount + ?", refund.Amount.Amount),
+				"status":                 status,
+			}).Error; err != nil {
+			return err
+		}
+
+		return postRefund(tx, refund)
+	})
+}
+
+// GetPendingRefunds returns the refunds left pending past their grace time, oldest first, with their payment
+func (p *PaymentRepository) GetPendingRefunds(ctx context.Context, limit int) ([]*model.Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var refunds []*model.Refund
+	if err := p.db.GetDB().WithContext(ctx).
+		Preload("Payment").
+		Where("status = ?", model.RefundStatusPending).
+		Where("created_at < NOW() - ? * INTERVAL '1 second'", configs.PendingRefundGrace.Seconds()).
+		Where("created_at > NOW() - ? * INTERVAL '1 second'", configs.PendingRefundRetryWindow.Seconds()).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func (p *PaymentRepository) GetRefundPolicy(ctx context.Context, eventId string) (*model.RefundPolicy, error) {
+	var policy model.RefundPolicy
+	query := database.NewQuery("event_id = ?", eventId)
+	if err := p.db.FindOne(ctx, &policy, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (p *PaymentRepository) SaveRefundPolicy(ctx context.Context, policy *model.RefundPolicy) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return p.db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"is_enabled", "deadline_hours", "percentage", "updated_at"}),
+	}).Create(policy).Error
+}
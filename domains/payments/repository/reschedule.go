@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"gohub/configs"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/model"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetLatestReschedule returns the last reschedule of the event or of the occurrence of the order made after
+// the order, the one its buyer answers
+func (p *PaymentRepository) GetLatestReschedule(ctx context.Context, eventId string, occurrenceId *string, orderedAt time.Time) (*modelEvent.EventReschedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var reschedule modelEvent.EventReschedule
+	if err := p.db.GetDB().WithContext(ctx).
+		Where("event_id = ? AND created_at > ?", eventId, orderedAt).
+		Where("(occurrence_id IS NULL OR occurrence_id = ?)", occurrenceId).
+		Order("created_at DESC").
+		First(&reschedule).Error; err != nil {
+		return nil, err
+	}
+
+	return &reschedule, nil
+}
+
+func (p *PaymentRepository) HasRescheduleResponse(ctx context.Context, rescheduleId string, paymentId string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var total int64
+	if err := p.db.GetDB().WithContext(ctx).
+		Model(&model.RescheduleResponse{}).
+		Where("reschedule_id = ? AND payment_id = ?", rescheduleId, paymentId).
+		Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+// CreateRescheduleResponse reports false when the order already answered the reschedule
+func (p *PaymentRepository) CreateRescheduleResponse(ctx context.Context, response *model.RescheduleResponse) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	result := p.db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(response)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/payments/dto"
+	"gohub/domains/payments/model"
+	modelUser "gohub/domains/users/model"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
+	"gohub/pkg/utils"
+	"io"
+	"mime/multipart"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var transferReferencePattern = regexp.MustCompile(model.TransferReferencePrefix + `\d{6}[A-Z0-9]{` + strconv.Itoa(utils.SecureRandLength) + `}`)
+
+func (s *PaymentService) GetPaymentMethods(ctx context.Context) ([]*model.PaymentMethod, error) {
+	return s.repoPayment.GetPaymentMethods(ctx)
+}
+
+func (s *PaymentService) GetPaymentAccounts(ctx context.Context, userId string) ([]*modelUser.UserPayment, error) {
+	return s.repoPayment.GetUserPayments(ctx, userId)
+}
+
+func (s *PaymentService) CreatePaymentAccount(ctx context.Context, userId string, req *dto.CreatePaymentAccountReq) (*modelUser.UserPayment, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repoPayment.GetPaymentMethodById(ctx, req.PaymentMethodId); err != nil {
+		return nil, errors.New(messages.PaymentMethodNotFound)
+	}
+
+	uploadUrl, err := utils.ImageUpload(req.QrCode, "/eventhub/payments")
+	if err != nil {
+		return nil, err
+	}
+
+	account := &modelUser.UserPayment{
+		UserId:                  userId,
+		PaymentMethodId:         req.PaymentMethodId,
+		PaymentAccountNumber:    strings.TrimSpace(req.AccountNumber),
+		PaymentAccountQrCodeUrl: uploadUrl,
+		CheckoutContent:         strings.TrimSpace(req.CheckoutContent),
+	}
+	if err := s.repoPayment.CreateUserPayment(ctx, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (s *PaymentService) DeletePaymentAccount(ctx context.Context, userId string, id string) error {
+	account, err := s.repoPayment.GetUserPaymentById(ctx, id)
+	if err != nil || account.UserId != userId {
+		return errors.New(messages.PaymentAccountNotFound)
+	}
+
+	return s.repoPayment.DeleteUserPayment(ctx, id)
+}
+
+// CreateBankTransfer places an order paid by bank transfer to the organizer, priced like a checkout with the
+// coupon, fees and tax of the event. The seats are held until the organizer confirms the money arrived or the
+// hold runs out.
+func (s *PaymentService) CreateBankTransfer(ctx context.Context, userId string, req *dto.BankTransferReq) (*dto.BankTransfer, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.repoPayment.GetEventById(ctx, req.EventId)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	account, err := s.transferAccount(ctx, event.UserId, req.PaymentAccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrenceId, err := s.bookedOccurrence(ctx, event, req.OccurrenceId)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.priceOrder(ctx, userId, event, req.TicketItems, req.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := utils.GenerateSecureCode(model.TransferReferencePrefix)
+	expiresAt := time.Now().Add(configs.BankTransferHoldTime)
+	payment := &model.Payment{
+		EventID:           event.ID,
+		OccurrenceId:      occurrenceId,
+		UserId:            userId,
+		CustomerName:      req.CustomerName,
+		CustomerEmail:     req.CustomerEmail,
+		CustomerPhone:     req.CustomerPhone,
+		Status:            model.PaymentStatusAwaitingTransfer,
+		TransferReference: &reference,
+		UserPaymentId:     &account.ID,
+		ExpiresAt:         &expiresAt,
+	}
+	paymentLines := quotedOrder(payment, quote)
+
+	if err := s.repoPayment.CreateOrder(ctx, payment, paymentLines, event.MaxTicketsPerUser); err != nil {
+		return nil, err
+	}
+
+	return s.bankTransfer(ctx, payment, account), nil
+}
+
+// GetBankTransfer returns the transfer instructions of an order again, for its buyer or the organizer
+func (s *PaymentService) GetBankTransfer(ctx context.Context, userId string, paymentId string) (*dto.BankTransfer, error) {
+	payment, err := s.repoPayment.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return nil, errors.New(messages.PaymentNotFound)
+	}
+
+	if payment.UserId != userId && (payment.Event == nil || payment.Event.UserId != userId) {
+		return nil, errors.New(messages.NotPaymentOwner)
+	}
+
+	if payment.TransferReference == nil {
+		return nil, errors.New(messages.NotAwaitingTransfer)
+	}
+
+	var account *modelUser.UserPayment
+	if payment.UserPaymentId != nil {
+		account, _ = s.repoPayment.GetUserPaymentById(ctx, *payment.UserPaymentId)
+	}
+
+	return s.bankTransfer(ctx, payment, account), nil
+}
+
+// ConfirmTransfer issues the tickets of an order once the organizer sees the money on their account
+func (s *PaymentService) ConfirmTransfer(ctx context.Context, userId string, paymentId string) (*model.Payment, error) {
+	if _, err := s.orderOwner(ctx, userId, paymentId); err != nil {
+		return nil, err
+	}
+
+	confirmed, err := s.repoPayment.ConfirmOrder(ctx, paymentId, model.PaymentStatusAwaitingTransfer, model.PaymentStatusSuccess)
+	if err != nil {
+		return nil, err
+	}
+
+	if !confirmed {
+		return nil, errors.New(messages.NotAwaitingTransfer)
+	}
+
+	go s.notifyConfirmation(paymentId)
+
+	return s.repoPayment.GetPaymentById(ctx, paymentId)
+}
+
+// ImportStatement confirms the orders whose reference and exact amount appear on a line of a bank statement.
+// Bank exports differ, so every cell of a line is searched instead of relying on column names.
+func (s *PaymentService) ImportStatement(ctx context.Context, userId string, req *dto.ImportStatementReq) (*dto.ImportStatementRes, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	rows, err := readStatement(req.Statement)
+	if err != nil {
+		logger.Error("Failed to read bank statement: ", err)
+		return nil, errors.New(messages.InvalidStatement)
+	}
+
+	res := &dto.ImportStatementRes{Matches: make([]*dto.StatementMatch, 0)}
+	lineReferences := make(map[int]string)
+	var references []string
+	for i, row := range rows {
+		reference := transferReferencePattern.FindString(strings.ToUpper(strings.Join(row, " ")))
+		if reference == "" {
+			res.Skipped++
+			continue
+		}
+		lineReferences[i] = reference
+		references = append(references, reference)
+	}
+
+	if len(references) == 0 {
+		return res, nil
+	}
+
+	payments, err := s.repoPayment.GetPaymentsByReferences(ctx, references)
+	if err != nil {
+		return nil, err
+	}
+
+	byReference := make(map[string]*model.Payment)
+	for _, payment := range payments {
+		// Orders of other organizers are reported as unmatched, a statement must not reveal them
+		if payment.Event != nil && payment.Event.UserId == userId {
+			byReference[*payment.TransferReference] = payment
+		}
+	}
+
+	for i, row := range rows {
+		reference, ok := lineReferences[i]
+		if !ok {
+			continue
+		}
+
+		match := &dto.StatementMatch{Line: i + 1, Reference: reference, Status: model.StatementMatchUnmatched}
+		res.Matches = append(res.Matches, match)
+
+		payment, ok := byReference[reference]
+		if !ok {
+			continue
+		}
+		match.PaymentId = payment.ID
+
+		switch payment.Status {
+		case model.PaymentStatusAwaitingTransfer:
+		case model.PaymentStatusExpired:
+			match.Status = model.StatementMatchExpired
+			continue
+		default:
+			match.Status = model.StatementMatchAlreadyPaid
+			continue
+		}
+
+		amount, paid := statementAmount(row, payment.FinalPrice)
+		match.Amount = amount
+		if !paid {
+			match.Status = model.StatementMatchAmountMismatch
+			continue
+		}
+
+		confirmed, err := s.repoPayment.ConfirmOrder(ctx, payment.ID, model.PaymentStatusAwaitingTransfer, model.PaymentStatusSuccess)
+		if err != nil {
+			return nil, err
+		}
+
+		if !confirmed {
+			match.Status = model.StatementMatchAlreadyPaid
+			continue
+		}
+
+		payment.Status = model.PaymentStatusSuccess
+		match.Status = model.StatementMatchConfirmed
+		res.Confirmed++
+		go s.notifyConfirmation(payment.ID)
+	}
+
+	return res, nil
+}
+
+// ExpireTransfers releases the seats of orders whose bank transfer never arrived
+func (s *PaymentService) ExpireTransfers(ctx context.Context) error {
+	payments, err := s.repoPayment.GetExpiredTransfers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range payments {
+		if _, err := s.repoPayment.ReleaseOrder(ctx, payment.ID, model.PaymentStatusAwaitingTransfer, model.PaymentStatusExpired); err != nil {
+			logger.Errorf("Failed to expire order %s: %v", payment.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *PaymentService) transferAccount(ctx context.Context, organizerId string, accountId string) (*modelUser.UserPayment, error) {
+	if accountId != "" {
+		account, err := s.repoPayment.GetUserPaymentById(ctx, accountId)
+		if err != nil || account.UserId != organizerId {
+			return nil, errors.New(messages.PaymentAccountNotFound)
+		}
+		return account, nil
+	}
+
+	accounts, err := s.repoPayment.GetUserPayments(ctx, organizerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accounts) == 0 {
+		return nil, errors.New(messages.NoPaymentAccount)
+	}
+
+	return accounts[0], nil
+}
+
+func (s *PaymentService) bankTransfer(ctx context.Context, payment *model.Payment, account *modelUser.UserPayment) *dto.BankTransfer {
+	res := &dto.BankTransfer{
+		PaymentId: payment.ID,
+		Status:    payment.Status,
+		Amount:    payment.FinalPrice,
+	}
+	if payment.TransferReference != nil {
+		res.Reference = *payment.TransferReference
+		res.TransferContent = res.Reference
+	}
+	if payment.ExpiresAt != nil {
+		res.ExpiresAt = payment.ExpiresAt.Format(time.RFC3339)
+	}
+
+	if account != nil {
+		res.AccountNumber = account.PaymentAccountNumber
+		res.QrCodeUrl = account.PaymentAccountQrCodeUrl
+		if account.CheckoutContent != "" {
+			res.TransferContent = account.CheckoutContent + " " + res.Reference
+		}
+		if method, err := s.repoPayment.GetPaymentMethodById(ctx, account.PaymentMethodId); err == nil {
+			res.MethodName = method.MethodName
+		}
+	}
+
+	return res
+}
+
+func readStatement(fileHeader *multipart.FileHeader) ([][]string, error) {
+	if fileHeader.Size > configs.MaxBankStatementSize {
+		return nil, errors.New("statement is too large")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, configs.MaxBankStatementSize))
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	return reader.ReadAll()
+}
+
+// statementAmount looks for a cell holding the amount due, it returns the first amount of the line otherwise
+func statementAmount(row []string, due money.Money) (money.Money, bool) {
+	first := money.Zero(due.Currency)
+	found := false
+	for _, cell := range row {
+		value, ok := parseAmount(cell)
+		if !ok {
+			continue
+		}
+
+		amount := money.FromMajor(value, due.Currency)
+		if amount.Cmp(due) == 0 {
+			return amount, true
+		}
+
+		if !found {
+			first = amount
+			found = true
+		}
+	}
+
+	return first, false
+}
+
+// parseAmount reads amounts written as 1,250,000 or 1.250.000 or 1250000.00, with or without a currency
+func parseAmount(cell string) (float64, bool) {
+	cell = strings.ToUpper(strings.TrimSpace(cell))
+	for _, suffix := range []string{"VND", "₫", "Đ"} {
+		cell = strings.TrimSpace(strings.TrimSuffix(cell, suffix))
+	}
+	cell = strings.TrimPrefix(cell, "+")
+	if cell == "" {
+		return 0, false
+	}
+
+	integer, fraction := cell, ""
+	if sep := strings.LastIndexAny(cell, ".,"); sep >= 0 && len(cell)-sep-1 > 0 && len(cell)-sep-1 <= 2 {
+		integer, fraction = cell[:sep], cell[sep+1:]
+	}
+
+	integer = strings.NewReplacer(",", "", ".", "", " ", "").Replace(integer)
+	if integer == "" {
+		return 0, false
+	}
+
+	for _, r := range integer + fraction {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+
+	if fraction != "" {
+		integer += "." + fraction
+	}
+
+	amount, err := strconv.ParseFloat(integer, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return amount, true
+}
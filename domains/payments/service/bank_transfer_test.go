@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/dto"
+	"gohub/domains/payments/model"
+	"gohub/domains/payments/repository"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/validation"
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
+	"mime/multipart"
+	"os"
+	"testing"
+)
+
+const (
+	organizerId = "organizer"
+	referenceA  = "TT250601AAAAAAAAAA"
+	referenceB  = "TT250601BBBBBBBBBB"
+	unknownRef  = "TT250601ZZZZZZZZZZ"
+	transferDue = 1250000
+)
+
+func TestMain(m *testing.M) {
+	logger.Initialize("test")
+	os.Exit(m.Run())
+}
+
+// statementRepo serves the orders of a statement import, ConfirmOrder succeeds once for every order that
+// awaits its transfer unless the order is listed in raced
+type statementRepo struct {
+	repository.IPaymentRepository
+	payments  []*model.Payment
+	raced     map[string]bool
+	confirmed []string
+}
+
+func (r *statementRepo) GetPaymentsByReferences(ctx context.Context, references []string) ([]*model.Payment, error) {
+	wanted := make(map[string]bool, len(references))
+	for _, reference := range references {
+		wanted[reference] = true
+	}
+
+	var payments []*model.Payment
+	for _, payment := range r.payments {
+		if wanted[*payment.TransferReference] {
+			copied := *payment
+			payments = append(payments, &copied)
+		}
+	}
+
+	return payments, nil
+}
+
+func (r *statementRepo) ConfirmOrder(ctx context.Context, paymentId string, from string, to string) (bool, error) {
+	if r.raced[paymentId] {
+		return false, nil
+	}
+
+	r.confirmed = append(r.confirmed, paymentId)
+	return true, nil
+}
+
+// GetPaymentById fails so the confirmation mail is skipped
+func (r *statementRepo) GetPaymentById(ctx context.Context, id string) (*model.Payment, error) {
+	return nil, errors.New(messages.PaymentNotFound)
+}
+
+func transferOrder(id string, reference string, status string, organizer string) *model.Payment {
+	return &model.Payment{
+		ID:                id,
+		Status:            status,
+		TransferReference: &reference,
+		FinalPrice:        money.New(transferDue, "VND"),
+		Event:             &modelEvent.Event{ID: "event", UserId: organizer},
+	}
+}
+
+func statementFile(t *testing.T, content string) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("statement", "statement.csv")
+	if err != nil {
+		t.Fatalf("CreateFormFile() error = %v", err)
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("ReadForm() error = %v", err)
+	}
+
+	return form.File["statement"][0]
+}
+
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		name          string
+		statement     string
+		payments      []*model.Payment
+		raced         []string
+		want          []dto.StatementMatch
+		wantConfirmed []string
+		wantSkipped   int
+	}{
+		{
+			name:          "reference and amount match",
+			statement:     "Date,Description,Amount\n01/06/2025,CK " + referenceA + " NGUYEN VAN A,\"1,250,000\"\n",
+			payments:      []*model.Payment{transferOrder("a", referenceA, model.PaymentStatusAwaitingTransfer, organizerId)},
+			want:          []dto.StatementMatch{{Line: 2, Reference: referenceA, PaymentId: "a", Amount: money.New(transferDue, "VND"), Status: model.StatementMatchConfirmed}},
+			wantConfirmed: []string{"a"},
+			wantSkipped:   1,
+		},
+		{
+			name:          "lower case reference inside the transfer note and dotted amount",
+			statement:     "01/06/2025;chuyen tien " + "tt250601aaaaaaaaaa" + " ve thu;1.250.000 VND\n",
+			payments:      []*model.Payment{transferOrder("a", referenceA, model.PaymentStatusAwaitingTransfer, organizerId)},
+			want:          []dto.StatementMatch{{Line: 1, Reference: referenceA, PaymentId: "a", Amount: money.New(transferDue, "VND"), Status: model.StatementMatchConfirmed}},
+			wantConfirmed: []string{"a"},
+		},
+		{
+			name:          "amount due in another cell of the line",
+			statement:     "01/06/2025," + referenceA + ",1250000.00,1000000\n",
+			payments:      []*model.Payment{transferOrder("a", referenceA, model.PaymentStatusAwaitingTransfer, organizerId)},
+			want:          []dto.StatementMatch{{Line: 1, Reference: referenceA, PaymentId: "a", Amount: money.New(transferDue, "VND"), Status: model.StatementMatchConfirmed}},
+			wantConfirmed: []string{"a"},
+		},
+		{
+			name:      "amount mismatch",
+			statement: "01/06/2025," + referenceA + ",1000000\n",
+			payments:  []*model.Payment{transferOrder("a", referenceA, model.PaymentStatusAwaitingTransfer, organizerId)},
+			want:      []dto.StatementMatch{{Line: 1, Reference: referenceA, PaymentId: "a", Amount: money.New(1000000, "VND"), Status: model.StatementMatchAmountMismatch}},
+		},
+		{
+			name:      "expired order",
+			statement: "01/06/2025," + referenceA + ",1250000\n",
+			payments:  []*model.Payment{transferOrder("a", referenceA, model.PaymentStatusExpired, organizerId)},
+			want:      []dto.StatementMatch{{Line: 1, Reference: referenceA, PaymentId: "a", Status: model.StatementMatchExpired}},
+		},
+		{
+			name:      "order already paid",
+			statement: "01/06/2025," + referenceA + ",1250000\n",
+			payments:  []*model.Payment{transferOrder("a", referenceA, model.PaymentStatusSuccess, organizerId)},
+			want:      []dto.StatementMatch{{Line: 1, Reference: referenceA, PaymentId: "a", Status: model.StatementMatchAlreadyPaid}},
+		},
+		{
+			name:      "order confirmed meanwhile",
+			statement: "01/06/2025," + referenceA + ",1250000\n",
+			payments:  []*model.Payment{transferOrder("a", referenceA, model.PaymentStatusAwaitingTransfer, organizerId)},
+			raced:     []string{"a"},
+			want:      []dto.StatementMatch{{Line: 1, Reference: referenceA, PaymentId: "a", Amount: money.New(transferDue, "VND"), Status: model.StatementMatchAlreadyPaid}},
+		},
+		{
+			name:      "order of another organizer",
+			statement: "01/06/2025," + referenceB + ",1250000\n",
+			payments:  []*model.Payment{transferOrder("b", referenceB, model.PaymentStatusAwaitingTransfer, "someone else")},
+			want:      []dto.StatementMatch{{Line: 1, Reference: referenceB, Status: model.StatementMatchUnmatched}},
+		},
+		{
+			name:      "unknown reference",
+			statement: "01/06/2025," + unknownRef + ",1250000\n",
+			want:      []dto.StatementMatch{{Line: 1, Reference: unknownRef, Status: model.StatementMatchUnmatched}},
+		},
+		{
+			name:        "no reference",
+			statement:   "01/06/2025,coffee,45000\n01/06/2025,TT2506,1250000\n",
+			wantSkipped: 2,
+		},
+		{
+			name:      "same order paid twice",
+			statement: "01/06/2025," + referenceA + ",1250000\n02/06/2025," + referenceA + ",1250000\n",
+			payments:  []*model.Payment{transferOrder("a", referenceA, model.PaymentStatusAwaitingTransfer, organizerId)},
+			want: []dto.StatementMatch{
+				{Line: 1, Reference: referenceA, PaymentId: "a", Amount: money.New(transferDue, "VND"), Status: model.StatementMatchConfirmed},
+				{Line: 2, Reference: referenceA, PaymentId: "a", Status: model.StatementMatchAlreadyPaid},
+			},
+			wantConfirmed: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &statementRepo{payments: tt.payments, raced: make(map[string]bool)}
+			for _, id := range tt.raced {
+				repo.raced[id] = true
+			}
+			s := &PaymentService{validator: validation.New(), repoPayment: repo}
+
+			res, err := s.ImportStatement(context.Background(), organizerId, &dto.ImportStatementReq{Statement: statementFile(t, tt.statement)})
+			if err != nil {
+				t.Fatalf("ImportStatement() error = %v", err)
+			}
+
+			if res.Skipped != tt.wantSkipped {
+				t.Errorf("Skipped = %d, want %d", res.Skipped, tt.wantSkipped)
+			}
+			if res.Confirmed != len(tt.wantConfirmed) || len(repo.confirmed) != len(tt.wantConfirmed) {
+				t.Errorf("confirmed %v (%d reported), want %v", repo.confirmed, res.Confirmed, tt.wantConfirmed)
+			}
+			if len(res.Matches) != len(tt.want) {
+				t.Fatalf("got %d matches, want %d", len(res.Matches), len(tt.want))
+			}
+			for i, match := range res.Matches {
+				if *match != tt.want[i] {
+					t.Errorf("match %d = %+v, want %+v", i, *match, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		cell   string
+		want   float64
+		wantOk bool
+	}{
+		{cell: "1250000", want: 1250000, wantOk: true},
+		{cell: "1,250,000", want: 1250000, wantOk: true},
+		{cell: "1.250.000", want: 1250000, wantOk: true},
+		{cell: "1 250 000", want: 1250000, wantOk: true},
+		{cell: "1250000.00", want: 1250000, wantOk: true},
+		{cell: "1.250.000,50", want: 1250000.5, wantOk: true},
+		{cell: "+1,250,000 VND", want: 1250000, wantOk: true},
+		{cell: "1.250.000đ", want: 1250000, wantOk: true},
+		{cell: "1.250.000 ₫", want: 1250000, wantOk: true},
+		{cell: "", wantOk: false},
+		{cell: "VND", wantOk: false},
+		{cell: "-500000", wantOk: false},
+		{cell: "01/06/2025", wantOk: false},
+		{cell: referenceA, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			got, ok := parseAmount(tt.cell)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("parseAmount(%q) = %v, %v, want %v, %v", tt.cell, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
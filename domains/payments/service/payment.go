@@ -2,31 +2,92 @@ package service
 
 import (
 	"context"
-	"github.com/stripe/stripe-go/v81"
-	"github.com/stripe/stripe-go/v81/checkout/session"
+	"errors"
+	"gohub/configs"
+	modelEvent "gohub/domains/events/model"
 	"gohub/domains/payments/dto"
 	"gohub/domains/payments/model"
 	"gohub/domains/payments/repository"
+	modelTicket "gohub/domains/tickets/model"
+	modelUser "gohub/domains/users/model"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/mailer"
+	"gohub/internal/libs/pdf"
+	"gohub/internal/libs/provider"
 	"gohub/internal/libs/validation"
+	"gohub/pkg/messages"
 	"gohub/pkg/paging"
+	"net/http"
+	"time"
 )
 
 type IPaymentService interface {
 	GetTransactions(ctx context.Context, userId string, req *dto.ListTransactionReq) ([]*model.Payment, *paging.Pagination, error)
 	GetOrders(ctx context.Context, userId string, req *dto.ListOrderReq) ([]*model.Payment, *paging.Pagination, error)
-	CreateSession(ctx context.Context, req *dto.TicketCheckoutRequest, stripeKey string) (string, string, string, error)
+	CreateSession(ctx context.Context, req *dto.TicketCheckoutRequest) (*provider.Session, *model.Payment, error)
 	Checkout(ctx context.Context, req *dto.TicketCheckoutRequest) error
+	HandleWebhook(ctx context.Context, payload []byte, header http.Header) error
+	GetReceipt(ctx context.Context, userId string, id string) ([]byte, error)
+	RefundPayment(ctx context.Context, userId string, paymentId string, req *dto.RefundReq) (*model.Refund, error)
+	CancelTickets(ctx context.Context, userId string, paymentId string, req *dto.CancelTicketReq) (*model.Refund, error)
+	GetRefunds(ctx context.Context, userId string, paymentId string) ([]*model.Refund, error)
+	SettleRefund(ctx context.Context, userId string, paymentId string, refundId string) (*model.Refund, error)
+	GetRefundPolicy(ctx context.Context, eventId string) (*model.RefundPolicy, error)
+	UpdateRefundPolicy(ctx context.Context, userId string, eventId string, req *dto.RefundPolicyReq) (*model.RefundPolicy, error)
+	Register(ctx context.Context, userId string, req *dto.RegisterReq) (*model.Payment, error)
+	GetRegistrations(ctx context.Context, userId string, eventId string, req *dto.ListRegistrationReq) ([]*model.Payment, error)
+	ApproveRegistration(ctx context.Context, userId string, paymentId string) (*model.Payment, error)
+	RejectRegistration(ctx context.Context, userId string, paymentId string) (*model.Payment, error)
+	GetPaymentMethods(ctx context.Context) ([]*model.PaymentMethod, error)
+	GetPaymentAccounts(ctx context.Context, userId string) ([]*modelUser.UserPayment, error)
+	CreatePaymentAccount(ctx context.Context, userId string, req *dto.CreatePaymentAccountReq) (*modelUser.UserPayment, error)
+	DeletePaymentAccount(ctx context.Context, userId string, id string) error
+	CreateBankTransfer(ctx context.Context, userId string, req *dto.BankTransferReq) (*dto.BankTransfer, error)
+	GetBankTransfer(ctx context.Context, userId string, paymentId string) (*dto.BankTransfer, error)
+	ConfirmTransfer(ctx context.Context, userId string, paymentId string) (*model.Payment, error)
+	ImportStatement(ctx context.Context, userId string, req *dto.ImportStatementReq) (*dto.ImportStatementRes, error)
+	ExpireTransfers(ctx context.Context) error
+	RefundCancelledEvents(ctx context.Context) error
+	Quote(ctx context.Context, userId string, req *dto.QuoteReq) (*dto.Quote, error)
+	GetPricingPolicy(ctx context.Context, eventId string) (*model.PricingPolicy, error)
+	UpdatePricingPolicy(ctx context.Context, userId string, eventId string, req *dto.PricingPolicyReq) (*model.PricingPolicy, error)
+	RespondToReschedule(ctx context.Context, userId string, paymentId string, req *dto.RescheduleResponseReq) (*model.RescheduleResponse, error)
+}
+
+// TicketRenderer renders the printable tickets attached to the confirmation email
+type TicketRenderer interface {
+	RenderTickets(ctx context.Context, tickets []*modelTicket.Ticket) ([]byte, error)
 }
 
 type PaymentService struct {
 	validator   validation.Validation
 	repoPayment repository.IPaymentRepository
+	tickets     TicketRenderer
+	mailer      mailer.Mailer
+	provider    provider.PaymentProvider
+	renderer    *pdf.Renderer
+	feePercent  float64
+	quoteSecret string
 }
 
-func NewPaymentService(validator validation.Validation, repoPayment repository.IPaymentRepository) *PaymentService {
+func NewPaymentService(
+	validator validation.Validation,
+	repoPayment repository.IPaymentRepository,
+	tickets TicketRenderer,
+	mailer mailer.Mailer,
+	provider provider.PaymentProvider,
+) *PaymentService {
+	cfg := configs.GetConfig()
+
 	return &PaymentService{
 		validator:   validator,
 		repoPayment: repoPayment,
+		tickets:     tickets,
+		mailer:      mailer,
+		provider:    provider,
+		renderer:    pdf.New(cfg.PdfFontPath),
+		feePercent:  cfg.PlatformFeePercent,
+		quoteSecret: cfg.QuoteSecret,
 	}
 }
 
@@ -48,58 +109,192 @@ func (s *PaymentService) GetOrders(ctx context.Context, userId string, req *dto.
 	return orders, pagination, nil
 }
 
-func (s *PaymentService) CreateSession(ctx context.Context, req *dto.TicketCheckoutRequest, stripeKey string) (string, string, string, error) {
-	stripe.Key = stripeKey
-
-	var lineItems []*stripe.CheckoutSessionLineItemParams
-	for _, item := range req.TicketItems {
-		lineItems = append(lineItems, &stripe.CheckoutSessionLineItemParams{
-			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-				Currency: stripe.String("vnd"),
-				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-					Name: stripe.String(item.Name),
-				},
-				UnitAmount: stripe.Int64(int64(item.Price)),
-			},
-			Quantity: stripe.Int64(int64(item.Quantity)),
+// CreateSession opens a checkout for a signed quote and records the pending order behind it, the buyer pays
+// the amounts of the quote and nothing the client computed
+func (s *PaymentService) CreateSession(ctx context.Context, req *dto.TicketCheckoutRequest) (*provider.Session, *model.Payment, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, nil, err
+	}
+
+	quote, err := s.verifyQuote(req.QuoteToken, req.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The quote may have been signed before the event stopped selling
+	event, err := s.repoPayment.GetEventById(ctx, quote.EventId)
+	if err != nil {
+		return nil, nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.State != modelEvent.EventStatePublished {
+		return nil, nil, errors.New(messages.EventNotOnSale)
+	}
+
+	// The seats are held until the session expires, the provider closes it then so it cannot be paid later
+	expiresAt := time.Now().Add(configs.CheckoutHoldTime)
+	payment := &model.Payment{
+		EventID:       quote.EventId,
+		UserId:        req.UserId,
+		CustomerName:  req.CustomerName,
+		CustomerEmail: req.CustomerEmail,
+		CustomerPhone: req.CustomerPhone,
+		Status:        model.PaymentStatusPending,
+		ExpiresAt:     &expiresAt,
+	}
+	if quote.OccurrenceId != "" {
+		payment.OccurrenceId = &quote.OccurrenceId
+	}
+	paymentLines := quotedOrder(payment, quote)
+
+	var lines []*provider.CheckoutLine
+	for _, line := range quote.Lines {
+		lines = append(lines, &provider.CheckoutLine{
+			Name:       line.Name,
+			UnitAmount: line.UnitPrice,
+			Quantity:   int64(line.Quantity),
 		})
 	}
 
-	params := &stripe.CheckoutSessionParams{
-		PaymentMethodTypes: stripe.StringSlice([]string{"card"}),
-		LineItems:          lineItems,
-		Mode:               stripe.String(string(stripe.CheckoutSessionModePayment)),
-		SuccessURL:         stripe.String("http://localhost:3000/payment/successfully"),
-		CancelURL:          stripe.String("http://localhost:3000/payment/failure"),
-		CustomerEmail:      stripe.String(req.CustomerEmail),
+	for _, fee := range quote.Fees {
+		lines = append(lines, &provider.CheckoutLine{
+			Name:       fee.Name,
+			UnitAmount: fee.Amount,
+			Quantity:   1,
+		})
 	}
 
-	if req.CouponId != "" {
-		params.Discounts = []*stripe.CheckoutSessionDiscountParams{
-			{
-				Coupon: stripe.String(req.CouponId),
-			},
+	// The provider applies the discount of the quote as a fixed amount, so both end up at the same total
+	var discountId string
+	if quote.Discount.IsPositive() {
+		discount, err := s.provider.CreateDiscount(ctx, &provider.DiscountParams{
+			Name:      "Coupon " + quote.CouponCode,
+			AmountOff: quote.Discount,
+		})
+		if err != nil {
+			return nil, nil, err
 		}
-	}
 
-	sessionCheckout, err := session.New(params)
+		discountId = discount.Id
+	}
 
+	session, err := s.provider.CreateCheckout(ctx, &provider.CheckoutParams{
+		Currency:      quote.Currency,
+		CustomerEmail: req.CustomerEmail,
+		Lines:         lines,
+		DiscountId:    discountId,
+		SuccessUrl:    "http://localhost:3000/payment/successfully",
+		CancelUrl:     "http://localhost:3000/payment/failure",
+		Metadata:      map[string]string{"eventId": quote.EventId, "userId": req.UserId},
+		ExpiresAt:     expiresAt,
+	})
 	if err != nil {
-		return "", "", "", err
+		return nil, nil, err
 	}
 
-	var paymentIntentID string
-	if sessionCheckout.PaymentIntent != nil {
-		paymentIntentID = sessionCheckout.PaymentIntent.ID
+	req.SessionId = session.Id
+	payment.PaymentSessionID = session.Id
+	if err := s.repoPayment.CreatePayment(ctx, payment, paymentLines); err != nil {
+		return nil, nil, err
 	}
 
-	return sessionCheckout.ID, sessionCheckout.URL, paymentIntentID, nil
+	return session, payment, nil
 }
 
+// Checkout confirms the payment of a session once the attendee is back from the checkout page
 func (s *PaymentService) Checkout(ctx context.Context, req *dto.TicketCheckoutRequest) error {
-	if err := s.repoPayment.Checkout(ctx, req); err != nil {
+	return s.completeSession(ctx, req.SessionId)
+}
+
+// HandleWebhook applies the outcome of a checkout the payment provider reports
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, header http.Header) error {
+	event, err := s.provider.VerifyWebhook(payload, header)
+	if err != nil {
+		return errors.New(messages.InvalidWebhookSignature)
+	}
+
+	switch event.Type {
+	case provider.EventCheckoutCompleted:
+		return s.completeSession(ctx, event.SessionId)
+	case provider.EventCheckoutFailed:
+		return s.closeSession(ctx, event.SessionId, model.PaymentStatusFailed)
+	case provider.EventCheckoutExpired:
+		return s.closeSession(ctx, event.SessionId, model.PaymentStatusExpired)
+	}
+
+	return nil
+}
+
+func (s *PaymentService) completeSession(ctx context.Context, sessionId string) error {
+	payment, err := s.repoPayment.GetPaymentBySession(ctx, sessionId)
+	if err != nil {
+		return errors.New(messages.PaymentNotFound)
+	}
+
+	session, err := s.provider.GetSession(ctx, sessionId)
+	if err != nil {
 		return err
 	}
 
+	if !session.IsPaid {
+		return errors.New(messages.PaymentNotCompleted)
+	}
+
+	// A session that charged something else than the order it was opened for is not settled
+	if session.AmountTotal.Currency != payment.FinalPrice.Currency || session.AmountTotal.Cmp(payment.FinalPrice) != 0 {
+		logger.Errorf("Session %s charged %s for payment %s of %s", sessionId, session.AmountTotal, payment.ID, payment.FinalPrice)
+		return errors.New(messages.PaymentAmountMismatch)
+	}
+
+	// The buyer fees of the quote go to the platform, the commission is taken on what the organizer earns
+	fee := payment.FinalPrice.Sub(payment.FeeAmount).Percent(s.feePercent)
+	completed, err := s.repoPayment.CompletePayment(ctx, payment.ID, fee)
+	if err != nil {
+		return err
+	}
+
+	if completed {
+		// Rendering downloads the event cover, the buyer should not wait for the email
+		go s.notifyConfirmation(payment.ID)
+	}
+
+	return nil
+}
+
+func (s *PaymentService) closeSession(ctx context.Context, sessionId string, status string) error {
+	payment, err := s.repoPayment.GetPaymentBySession(ctx, sessionId)
+	if err != nil {
+		return errors.New(messages.PaymentNotFound)
+	}
+
+	return s.repoPayment.ClosePayment(ctx, payment.ID, status)
+}
+
+// ExpireCheckouts settles the checkouts still pending after their session expired, in case the webhook of the
+// provider never came: a paid session issues its tickets and the others give their seats back
+func (s *PaymentService) ExpireCheckouts(ctx context.Context) error {
+	payments, err := s.repoPayment.GetExpiredCheckouts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range payments {
+		session, err := s.provider.GetSession(ctx, payment.PaymentSessionID)
+		if err != nil {
+			logger.Errorf("Failed to fetch session of checkout %s: %v", payment.ID, err)
+			continue
+		}
+
+		switch {
+		case session.IsPaid:
+			err = s.completeSession(ctx, payment.PaymentSessionID)
+		case session.Status == provider.SessionStatusExpired:
+			err = s.repoPayment.ClosePayment(ctx, payment.ID, model.PaymentStatusExpired)
+		}
+		if err != nil {
+			logger.Errorf("Failed to expire checkout %s: %v", payment.ID, err)
+		}
+	}
+
 	return nil
 }
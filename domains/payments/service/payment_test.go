@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/domains/payments/model"
+	"gohub/domains/payments/repository"
+	"gohub/internal/libs/provider"
+	"gohub/pkg/money"
+	"testing"
+)
+
+// checkoutRepo serves expired checkouts and records which of them were completed or closed
+type checkoutRepo struct {
+	repository.IPaymentRepository
+	payments  map[string]*model.Payment
+	completed []string
+	closed    map[string]string
+}
+
+func (r *checkoutRepo) GetExpiredCheckouts(ctx context.Context) ([]*model.Payment, error) {
+	var payments []*model.Payment
+	for _, id := range []string{"paid", "expired", "open", "unknown"} {
+		payments = append(payments, r.payments[id])
+	}
+
+	return payments, nil
+}
+
+func (r *checkoutRepo) GetPaymentBySession(ctx context.Context, sessionId string) (*model.Payment, error) {
+	for _, payment := range r.payments {
+		if payment.PaymentSessionID == sessionId {
+			return payment, nil
+		}
+	}
+
+	return nil, errors.New("record not found")
+}
+
+// CompletePayment reports false so the confirmation mail is skipped
+func (r *checkoutRepo) CompletePayment(ctx context.Context, paymentId string, fee money.Money) (bool, error) {
+	r.completed = append(r.completed, paymentId)
+	return false, nil
+}
+
+func (r *checkoutRepo) ClosePayment(ctx context.Context, paymentId string, status string) error {
+	r.closed[paymentId] = status
+	return nil
+}
+
+// sessionProvider answers the sessions it knows and fails for the others
+type sessionProvider struct {
+	provider.PaymentProvider
+	sessions map[string]*provider.Session
+}
+
+func (p *sessionProvider) GetSession(ctx context.Context, sessionId string) (*provider.Session, error) {
+	session, ok := p.sessions[sessionId]
+	if !ok {
+		return nil, errors.New("no such checkout session")
+	}
+
+	return session, nil
+}
+
+func TestExpireCheckouts(t *testing.T) {
+	total := money.New(200000, "VND")
+	checkout := func(id string) *model.Payment {
+		return &model.Payment{ID: id, PaymentSessionID: "cs_" + id, Status: model.PaymentStatusPending, FinalPrice: total}
+	}
+
+	repo := &checkoutRepo{
+		payments: map[string]*model.Payment{
+			"paid":    checkout("paid"),
+			"expired": checkout("expired"),
+			"open":    checkout("open"),
+			"unknown": checkout("unknown"),
+		},
+		closed: make(map[string]string),
+	}
+	payments := &sessionProvider{sessions: map[string]*provider.Session{
+		"cs_paid":    {Id: "cs_paid", Status: provider.SessionStatusComplete, IsPaid: true, AmountTotal: total},
+		"cs_expired": {Id: "cs_expired", Status: provider.SessionStatusExpired},
+		"cs_open":    {Id: "cs_open", Status: provider.SessionStatusOpen},
+	}}
+	s := &PaymentService{repoPayment: repo, provider: payments}
+
+	if err := s.ExpireCheckouts(context.Background()); err != nil {
+		t.Fatalf("ExpireCheckouts() error = %v", err)
+	}
+
+	// A session paid before it expired still issues its tickets, one the provider still holds open is left alone
+	if len(repo.completed) != 1 || repo.completed[0] != "paid" {
+		t.Errorf("completed = %v, want [paid]", repo.completed)
+	}
+
+	if len(repo.closed) != 1 || repo.closed["expired"] != model.PaymentStatusExpired {
+		t.Errorf("closed = %v, want map[expired:%s]", repo.closed, model.PaymentStatusExpired)
+	}
+}
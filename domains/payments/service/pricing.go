@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"gohub/configs"
+	modelCoupon "gohub/domains/coupons/model"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/dto"
+	"gohub/domains/payments/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
+	"gohub/pkg/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Quote prices an order from the ticket types, the coupon and the pricing policy of the event. The quote is
+// signed for the user asking, CreateSession charges exactly what it says until it expires.
+func (s *PaymentService) Quote(ctx context.Context, userId string, req *dto.QuoteReq) (*dto.Quote, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.repoPayment.GetEventById(ctx, req.EventId)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	occurrenceId, err := s.bookedOccurrence(ctx, event, req.OccurrenceId)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.priceOrder(ctx, userId, event, req.TicketItems, req.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if occurrenceId != nil {
+		quote.OccurrenceId = *occurrenceId
+	}
+	quote.UserId = userId
+	quote.ExpiresAt = time.Now().Add(configs.QuoteValidity).UTC().Truncate(time.Second)
+	token, err := s.signQuote(quote)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Token = token
+	return quote, nil
+}
+
+func (s *PaymentService) GetPricingPolicy(ctx context.Context, eventId string) (*model.PricingPolicy, error) {
+	if _, err := s.repoPayment.GetEventById(ctx, eventId); err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	// An event without a policy charges no fees and no tax
+	policy, err := s.repoPayment.GetPricingPolicy(ctx, eventId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.PricingPolicy{EventID: eventId, TaxName: "VAT"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (s *PaymentService) UpdatePricingPolicy(ctx context.Context, userId string, eventId string, req *dto.PricingPolicyReq) (*model.PricingPolicy, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.repoPayment.GetEventById(ctx, eventId)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		return nil, errors.New(messages.NotEventOwner)
+	}
+
+	if req.TaxName == "" {
+		req.TaxName = "VAT"
+	}
+
+	if err := s.repoPayment.SavePricingPolicy(ctx, &model.PricingPolicy{
+		EventID:              eventId,
+		PlatformFeePercent:   req.PlatformFeePercent,
+		ProcessingFeePercent: req.ProcessingFeePercent,
+		TaxName:              req.TaxName,
+		TaxPercent:           req.TaxPercent,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repoPayment.GetPricingPolicy(ctx, eventId)
+}
+
+// priceOrder adds up the ticket types at their current price, takes the coupon off and puts the fees and
+// the tax of the event on top of what is left
+func (s *PaymentService) priceOrder(ctx context.Context, userId string, event *modelEvent.Event, items []*dto.RegistrationItem, couponCode string) (*dto.Quote, error) {
+	ticketTypes, quantities, err := s.orderTicketTypes(ctx, event.ID, items)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &dto.Quote{
+		EventId:  event.ID,
+		Currency: event.Currency,
+		Subtotal: money.Zero(event.Currency),
+		Discount: money.Zero(event.Currency),
+	}
+
+	var couponLines []*modelCoupon.CouponLine
+	for _, ticketType := range ticketTypes {
+		total := ticketType.Price.Mul(int64(quantities[ticketType.ID]))
+		quote.Lines = append(quote.Lines, &dto.QuoteLine{
+			TicketTypeId: ticketType.ID,
+			Name:         ticketType.Name,
+			Quantity:     quantities[ticketType.ID],
+			UnitPrice:    ticketType.Price,
+			Total:        total,
+		})
+		couponLines = append(couponLines, &modelCoupon.CouponLine{
+			TicketTypeId: ticketType.ID,
+			Quantity:     quantities[ticketType.ID],
+			Total:        total,
+		})
+		quote.Subtotal = quote.Subtotal.Add(total)
+	}
+
+	if couponCode != "" {
+		coupon, err := s.repoPayment.GetEventCoupon(ctx, event.ID, couponCode)
+		if err != nil {
+			return nil, errors.New(messages.CouponNotFound)
+		}
+
+		quote.Discount, err = coupon.Discount(couponLines, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		quote.CouponId = coupon.ID
+		quote.CouponCode = strings.ToUpper(strings.TrimSpace(couponCode))
+		if err := s.checkCouponUsage(ctx, coupon, userId, quote.CouponCode); err != nil {
+			return nil, err
+		}
+	}
+
+	// A quote is signed and charged as it is, only a missing policy may price it without fees and tax
+	policy, err := s.repoPayment.GetPricingPolicy(ctx, event.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy, err = &model.PricingPolicy{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	discounted := quote.Subtotal.Sub(quote.Discount)
+	quote.Total = discounted
+	for _, fee := range []*dto.QuoteFee{
+		{Type: model.FeeTypePlatform, Name: "Service fee", Percent: policy.PlatformFeePercent},
+		{Type: model.FeeTypeProcessing, Name: "Processing fee", Percent: policy.ProcessingFeePercent},
+		{Type: model.FeeTypeTax, Name: policy.TaxName, Percent: policy.TaxPercent},
+	} {
+		fee.Amount = discounted.Percent(fee.Percent)
+		if !fee.Amount.IsPositive() {
+			continue
+		}
+
+		quote.Fees = append(quote.Fees, fee)
+		quote.Total = quote.Total.Add(fee.Amount)
+	}
+
+	return quote, nil
+}
+
+// quotedOrder sets the amounts and the coupon of the payment from the quote and returns its lines, so an order
+// costs the same whatever way it is paid
+func quotedOrder(payment *model.Payment, quote *dto.Quote) []*model.PaymentLine {
+	payment.TotalPrice = quote.Subtotal
+	payment.DiscountPrice = quote.Discount
+	payment.FinalPrice = quote.Total
+	payment.FeeAmount = money.Zero(quote.Currency)
+	payment.TaxAmount = money.Zero(quote.Currency)
+	if quote.CouponId != "" {
+		payment.CouponId = &quote.CouponId
+		payment.CouponCode = quote.CouponCode
+	}
+
+	for _, fee := range quote.Fees {
+		if fee.Type == model.FeeTypeTax {
+			payment.TaxAmount = payment.TaxAmount.Add(fee.Amount)
+		} else {
+			payment.FeeAmount = payment.FeeAmount.Add(fee.Amount)
+		}
+	}
+
+	var paymentLines []*model.PaymentLine
+	for _, line := range quote.Lines {
+		paymentLines = append(paymentLines, &model.PaymentLine{
+			EventID:      quote.EventId,
+			TicketTypeID: line.TicketTypeId,
+			Quantity:     line.Quantity,
+			Price:        line.UnitPrice,
+		})
+		payment.TicketQuantity += line.Quantity
+	}
+
+	return paymentLines
+}
+
+// checkCouponUsage tells whether the user can still redeem the coupon, the order checks it again under a lock
+func (s *PaymentService) checkCouponUsage(ctx context.Context, coupon *modelCoupon.Coupon, userId string, code string) error {
+	usage, err := s.repoPayment.GetCouponUsage(ctx, coupon.ID, userId, code)
+	if err != nil {
+		return err
+	}
+
+	return coupon.CheckUsage(code, *usage)
+}
+
+// signQuote encodes the quote with its signature, the token is all CreateSession needs to charge the order
+func (s *PaymentService) signQuote(quote *dto.Quote) (string, error) {
+	signed := *quote
+	signed.Token = ""
+	payload, err := json.Marshal(&signed)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + utils.Sign(s.quoteSecret, []byte(encoded)), nil
+}
+
+// verifyQuote reads back a quote signed for the user, it refuses tokens that were altered or ran out
+func (s *PaymentService) verifyQuote(token string, userId string) (*dto.Quote, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !utils.VerifySignature(s.quoteSecret, []byte(encoded), signature) {
+		return nil, errors.New(messages.InvalidQuote)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New(messages.InvalidQuote)
+	}
+
+	var quote dto.Quote
+	if err := json.Unmarshal(payload, &quote); err != nil || quote.UserId != userId || len(quote.Lines) == 0 {
+		return nil, errors.New(messages.InvalidQuote)
+	}
+
+	if time.Now().After(quote.ExpiresAt) {
+		return nil, errors.New(messages.QuoteExpired)
+	}
+
+	return &quote, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"gohub/domains/payments/model"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/mailer"
+	"gohub/internal/libs/pdf"
+	"gohub/pkg/messages"
+	"html/template"
+)
+
+var confirmationTemplate = template.Must(template.New("confirmation").Parse(`
+<p>Hi {{.CustomerName}},</p>
+<p>Thank you for your order for <b>{{.EventName}}</b>.</p>
+<p>Your {{.TicketQuantity}} ticket(s) and the receipt of your payment are attached. Present the QR code of each ticket at the entrance.</p>
+<p>EventHub</p>
+`))
+
+type confirmationData struct {
+	CustomerName   string
+	EventName      string
+	TicketQuantity int
+}
+
+// GetReceipt renders the receipt of a payment for its buyer or the organizer of the event
+func (s *PaymentService) GetReceipt(ctx context.Context, userId string, id string) ([]byte, error) {
+	payment, err := s.repoPayment.GetPaymentById(ctx, id)
+	if err != nil {
+		return nil, errors.New(messages.PaymentNotFound)
+	}
+
+	if payment.UserId != userId && (payment.Event == nil || payment.Event.UserId != userId) {
+		return nil, errors.New(messages.NotPaymentOwner)
+	}
+
+	return s.renderReceipt(ctx, payment)
+}
+
+func (s *PaymentService) renderReceipt(ctx context.Context, payment *model.Payment) ([]byte, error) {
+	lines, err := s.repoPayment.GetPaymentLines(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &pdf.Receipt{
+		Number:        payment.ID,
+		IssuedAt:      payment.CreatedAt,
+		Status:        payment.Status,
+		CustomerName:  payment.CustomerName,
+		CustomerEmail: payment.CustomerEmail,
+		CustomerPhone: payment.CustomerPhone,
+		TotalPrice:    payment.TotalPrice,
+		DiscountPrice: payment.DiscountPrice,
+		FinalPrice:    payment.FinalPrice,
+	}
+	if payment.Event != nil {
+		receipt.EventName = payment.Event.Name
+	}
+	for _, line := range lines {
+		name := line.TicketTypeID
+		if line.TicketType != nil {
+			name = line.TicketType.Name
+		}
+		receipt.Lines = append(receipt.Lines, &pdf.ReceiptLine{
+			Name:      name,
+			Quantity:  line.Quantity,
+			UnitPrice: line.Price,
+		})
+	}
+
+	return s.renderer.Receipt(receipt)
+}
+
+// sendConfirmation emails the tickets and the receipt of a completed payment to the buyer
+func (s *PaymentService) sendConfirmation(ctx context.Context, paymentId string) error {
+	payment, err := s.repoPayment.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := s.repoPayment.GetTicketsByPayment(ctx, paymentId)
+	if err != nil {
+		return err
+	}
+
+	ticketPdf, err := s.tickets.RenderTickets(ctx, tickets)
+	if err != nil {
+		return err
+	}
+
+	receiptPdf, err := s.renderReceipt(ctx, payment)
+	if err != nil {
+		return err
+	}
+
+	data := confirmationData{CustomerName: payment.CustomerName, TicketQuantity: payment.TicketQuantity}
+	if payment.Event != nil {
+		data.EventName = payment.Event.Name
+	}
+
+	var body bytes.Buffer
+	if err := confirmationTemplate.Execute(&body, data); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      []string{payment.CustomerEmail},
+		Subject: fmt.Sprintf("Your tickets for %s", data.EventName),
+		Body:    body.String(),
+		Attachments: []*mailer.Attachment{
+			{FileName: "tickets.pdf", ContentType: "application/pdf", Data: ticketPdf},
+			{FileName: "receipt.pdf", ContentType: "application/pdf", Data: receiptPdf},
+		},
+	})
+}
+
+func (s *PaymentService) notifyConfirmation(paymentId string) {
+	if err := s.sendConfirmation(context.Background(), paymentId); err != nil {
+		logger.Errorf("Failed to send confirmation of payment %s: %v", paymentId, err)
+	}
+}
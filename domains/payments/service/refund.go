@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/dto"
+	"gohub/domains/payments/model"
+	modelTicket "gohub/domains/tickets/model"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/provider"
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
+)
+
+// RefundPayment refunds the selected tickets or payment lines of a payment in full, or the whole payment when nothing is selected
+func (s *PaymentService) RefundPayment(ctx context.Context, userId string, paymentId string, req *dto.RefundReq) (*model.Refund, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.refundablePayment(ctx, paymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.refundSource(ctx, userId, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := s.repoPayment.GetRefundableTickets(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repoPayment.GetPaymentLines(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selectTickets(tickets, lines, req.TicketIds, req.PaymentLineIds)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := refundAmount(payment, lines, selected, 100)
+	if len(selected) == len(tickets) {
+		// The last refund of a payment gives back whatever is left, so rounding never strands money
+		amount = refundable(payment).Sub(payment.RefundedAmount)
+	}
+
+	return s.refund(ctx, payment, &model.Refund{
+		InitiatedById: userId,
+		Source:        source,
+		Reason:        req.Reason,
+	}, selected, amount)
+}
+
+// CancelTickets lets an attendee cancel their own tickets within the refund policy of the event
+func (s *PaymentService) CancelTickets(ctx context.Context, userId string, paymentId string, req *dto.CancelTicketReq) (*model.Refund, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.refundablePayment(ctx, paymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.UserId != userId {
+		return nil, errors.New(messages.NotPaymentOwner)
+	}
+
+	policy, err := s.repoPayment.GetRefundPolicy(ctx, payment.EventID)
+	if err != nil || !policy.IsEnabled {
+		return nil, errors.New(messages.RefundNotAllowed)
+	}
+
+	allowed, err := s.repoPayment.IsBeforeRefundDeadline(ctx, payment.EventID, payment.OccurrenceId, policy.DeadlineHours)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, errors.New(messages.RefundDeadlinePassed)
+	}
+
+	tickets, err := s.repoPayment.GetRefundableTickets(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tickets given away through a transfer belong to someone else now
+	var owned []*modelTicket.Ticket
+	for _, ticket := range tickets {
+		if ticket.UserId == userId {
+			owned = append(owned, ticket)
+		}
+	}
+
+	lines, err := s.repoPayment.GetPaymentLines(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selectTickets(owned, lines, req.TicketIds, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ticket := range selected {
+		if ticket.CheckedInAt != nil {
+			return nil, errors.New(messages.TicketAlreadyCheckedIn)
+		}
+	}
+
+	return s.refund(ctx, payment, &model.Refund{
+		InitiatedById: userId,
+		Source:        model.RefundSourceAttendee,
+		Reason:        req.Reason,
+	}, selected, refundAmount(payment, lines, selected, policy.Percentage))
+}
+
+// RefundCancelledEvents settles the orders of cancelled events and cancelled occurrences: held seats are
+// released and paid tickets are refunded in full, fees aside, whatever the refund policy of the event says
+func (s *PaymentService) RefundCancelledEvents(ctx context.Context) error {
+	payments, err := s.repoPayment.GetCancelledEventOrders(ctx, configs.CancellationRefundBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range payments {
+		switch payment.Status {
+		case model.PaymentStatusAwaitingApproval:
+			_, err = s.repoPayment.ReleaseOrder(ctx, payment.ID, payment.Status, model.PaymentStatusRejected)
+		case model.PaymentStatusAwaitingTransfer:
+			_, err = s.repoPayment.ReleaseOrder(ctx, payment.ID, payment.Status, model.PaymentStatusExpired)
+		default:
+			err = s.refundCancelled(ctx, payment)
+		}
+		if err != nil {
+			logger.Errorf("Failed to settle order %s of cancelled event %s: %v", payment.ID, payment.EventID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *PaymentService) refundCancelled(ctx context.Context, payment *model.Payment) error {
+	tickets, err := s.repoPayment.GetRefundableTickets(ctx, payment.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	reason, cancellationReason := "The event was cancelled", payment.Event.CancellationReason
+	if payment.Event.State != modelEvent.EventStateCancelled && payment.Occurrence != nil {
+		startTime := payment.Occurrence.StartTime.In(payment.Event.TimeLocation()).Format(modelEvent.DisplayTimeLayout)
+		reason, cancellationReason = "The event on "+startTime+" was cancelled", payment.Occurrence.CancellationReason
+	}
+	if cancellationReason != "" {
+		reason += ": " + cancellationReason
+	}
+
+	_, err = s.refund(ctx, payment, &model.Refund{
+		InitiatedById: payment.Event.UserId,
+		Source:        model.RefundSourceCancellation,
+		Reason:        reason,
+	}, tickets, refundable(payment).Sub(payment.RefundedAmount))
+	return err
+}
+
+func (s *PaymentService) GetRefunds(ctx context.Context, userId string, paymentId string) ([]*model.Refund, error) {
+	payment, err := s.repoPayment.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return nil, errors.New(messages.PaymentNotFound)
+	}
+
+	if payment.UserId != userId {
+		if _, err := s.refundSource(ctx, userId, payment); err != nil {
+			return nil, errors.New(messages.NotPaymentOwner)
+		}
+	}
+
+	return s.repoPayment.GetRefunds(ctx, payment.ID)
+}
+
+// GetRefundPolicy returns the refund policy of an event, events without one do not allow cancellations
+func (s *PaymentService) GetRefundPolicy(ctx context.Context, eventId string) (*model.RefundPolicy, error) {
+	if _, err := s.repoPayment.GetEventById(ctx, eventId); err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	policy, err := s.repoPayment.GetRefundPolicy(ctx, eventId)
+	if err != nil {
+		return &model.RefundPolicy{EventID: eventId, Percentage: 100}, nil
+	}
+
+	return policy, nil
+}
+
+func (s *PaymentService) UpdateRefundPolicy(ctx context.Context, userId string, eventId string, req *dto.RefundPolicyReq) (*model.RefundPolicy, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.repoPayment.GetEventById(ctx, eventId)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		return nil, errors.New(messages.NotEventOwner)
+	}
+
+	if err := s.repoPayment.SaveRefundPolicy(ctx, &model.RefundPolicy{
+		EventID:       eventId,
+		IsEnabled:     req.IsEnabled,
+		DeadlineHours: req.DeadlineHours,
+		Percentage:    req.Percentage,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repoPayment.GetRefundPolicy(ctx, eventId)
+}
+
+func (s *PaymentService) refundablePayment(ctx context.Context, paymentId string) (*model.Payment, error) {
+	payment, err := s.repoPayment.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return nil, errors.New(messages.PaymentNotFound)
+	}
+
+	if payment.Status != model.PaymentStatusSuccess && payment.Status != model.PaymentStatusPartiallyRefunded {
+		return nil, errors.New(messages.PaymentNotRefundable)
+	}
+
+	return payment, nil
+}
+
+// refundSource tells whether the user refunds as the organizer of the event or as an admin
+func (s *PaymentService) refundSource(ctx context.Context, userId string, payment *model.Payment) (string, error) {
+	if payment.Event != nil && payment.Event.UserId == userId {
+		return model.RefundSourceOrganizer, nil
+	}
+
+	isAdmin, err := s.repoPayment.IsAdmin(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+
+	if !isAdmin {
+		return "", errors.New(messages.NotRefundManager)
+	}
+
+	return model.RefundSourceAdmin, nil
+}
+
+// refund reserves the tickets, pays the money back through the provider, then voids the tickets. Orders paid
+// without the provider, like bank transfers, leave the refund awaiting the organizer to pay the money back by hand.
+func (s *PaymentService) refund(ctx context.Context, payment *model.Payment, refund *model.Refund, tickets []*modelTicket.Ticket, amount money.Money) (*model.Refund, error) {
+	amount = amount.Min(refundable(payment).Sub(payment.RefundedAmount))
+	if amount.IsNegative() {
+		amount = money.Zero(payment.FinalPrice.Currency)
+	}
+
+	ticketIds := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		ticketIds = append(ticketIds, ticket.ID)
+	}
+
+	refund.PaymentID = payment.ID
+	refund.TicketQuantity = len(tickets)
+	refund.Amount = amount
+	refund.Status = model.RefundStatusPending
+	if err := s.repoPayment.CreateRefund(ctx, refund, ticketIds); err != nil {
+		return nil, err
+	}
+
+	if err := s.completeRefund(ctx, payment, refund); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// completeRefund pays a pending refund back through the provider and voids its tickets. The idempotency key of the
+// refund makes the provider return the refund it made already when a pending refund is completed again.
+func (s *PaymentService) completeRefund(ctx context.Context, payment *model.Payment, refund *model.Refund) error {
+	if refund.Amount.IsPositive() && payment.PaymentSessionID != "" && refund.ProviderRefundId == "" {
+		result, err := s.provider.Refund(ctx, &provider.RefundParams{
+			SessionId:      payment.PaymentSessionID,
+			Amount:         refund.Amount,
+			Reason:         refund.Reason,
+			IdempotencyKey: refund.ID,
+		})
+		if err != nil {
+			logger.Errorf("Failed to refund payment %s: %v", payment.ID, err)
+			if err := s.repoPayment.FailRefund(ctx, refund.ID); err != nil {
+				logger.Errorf("Failed to release tickets of refund %s: %v", refund.ID, err)
+			}
+			return errors.New(messages.RefundProviderRejected)
+		}
+
+		refund.ProviderRefundId = result.Id
+	}
+
+	refund.Status = model.RefundStatusSucceeded
+	if refund.Amount.IsPositive() && payment.PaymentSessionID == "" {
+		refund.Status = model.RefundStatusAwaitingManual
+	}
+
+	// The money is back with the attendee or owed by hand here, a failure leaves the refund pending with its tickets
+	// reserved until CompletePendingRefunds picks it up
+	if err := s.repoPayment.CompleteRefund(ctx, refund); err != nil {
+		logger.Errorf("Failed to complete refund %s: %v", refund.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// CompletePendingRefunds completes the refunds cut off between the provider and the database, so their tickets
+// do not stay reserved and the money paid back is recorded
+func (s *PaymentService) CompletePendingRefunds(ctx context.Context) error {
+	refunds, err := s.repoPayment.GetPendingRefunds(ctx, configs.PendingRefundBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, refund := range refunds {
+		if refund.Payment == nil {
+			continue
+		}
+
+		if err := s.completeRefund(ctx, refund.Payment, refund); err != nil {
+			logger.Errorf("Failed to complete pending refund %s: %v", refund.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// SettleRefund records that the organizer or an admin paid the money of a manual refund back to the attendee
+func (s *PaymentService) SettleRefund(ctx context.Context, userId string, paymentId string, refundId string) (*model.Refund, error) {
+	payment, err := s.repoPayment.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return nil, errors.New(messages.PaymentNotFound)
+	}
+
+	if _, err := s.refundSource(ctx, userId, payment); err != nil {
+		return nil, err
+	}
+
+	settled, err := s.repoPayment.SettleRefund(ctx, refundId, payment.ID, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	refund, err := s.repoPayment.GetRefundById(ctx, refundId)
+	if err != nil || refund.PaymentID != payment.ID {
+		return nil, errors.New(messages.RefundNotFound)
+	}
+
+	if !settled {
+		return nil, errors.New(messages.RefundNotAwaitingManual)
+	}
+
+	return refund, nil
+}
+
+// selectTickets picks the requested tickets and every ticket of the requested payment lines, or all tickets when nothing is requested
+func selectTickets(tickets []*modelTicket.Ticket, lines []*model.PaymentLine, ticketIds []string, lineIds []string) ([]*modelTicket.Ticket, error) {
+	if len(ticketIds) == 0 && len(lineIds) == 0 {
+		if len(tickets) == 0 {
+			return nil, errors.New(messages.NoRefundableTickets)
+		}
+		return tickets, nil
+	}
+
+	ticketTypes := make(map[string]bool)
+	for _, id := range lineIds {
+		found := false
+		for _, line := range lines {
+			if line.ID == id {
+				ticketTypes[line.TicketTypeID] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.New(messages.TicketNotRefundable)
+		}
+	}
+
+	requested := make(map[string]bool)
+	for _, id := range ticketIds {
+		requested[id] = true
+	}
+
+	var selected []*modelTicket.Ticket
+	for _, ticket := range tickets {
+		if requested[ticket.ID] || ticketTypes[ticket.TicketTypeId] {
+			selected = append(selected, ticket)
+			delete(requested, ticket.ID)
+		}
+	}
+
+	if len(requested) > 0 {
+		return nil, errors.New(messages.TicketNotRefundable)
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New(messages.NoRefundableTickets)
+	}
+
+	return selected, nil
+}
+
+// refundable is what a payment can give back at most, the service and processing fees are not refunded
+func refundable(payment *model.Payment) money.Money {
+	return payment.FinalPrice.Sub(payment.FeeAmount.Min(payment.FinalPrice))
+}
+
+// refundAmount is the share of the refundable price the tickets were paid, scaled by the refund percentage
+func refundAmount(payment *model.Payment, lines []*model.PaymentLine, tickets []*modelTicket.Ticket, percentage float32) money.Money {
+	if !payment.TotalPrice.IsPositive() {
+		return money.Zero(payment.FinalPrice.Currency)
+	}
+
+	prices := make(map[string]money.Money)
+	for _, line := range lines {
+		prices[line.TicketTypeID] = line.Price
+	}
+
+	value := money.Zero(payment.TotalPrice.Currency)
+	for _, ticket := range tickets {
+		value = value.Add(prices[ticket.TicketTypeId])
+	}
+
+	return value.Scale(refundable(payment).Amount, payment.TotalPrice.Amount).Percent(float64(percentage))
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/domains/payments/model"
+	"gohub/domains/payments/repository"
+	"gohub/internal/libs/provider"
+	"gohub/pkg/money"
+	"testing"
+)
+
+// pendingRepo serves pending refunds and records how each of them was completed or failed
+type pendingRepo struct {
+	repository.IPaymentRepository
+	refunds   []*model.Refund
+	completed map[string]*model.Refund
+	failed    []string
+}
+
+func (r *pendingRepo) GetPendingRefunds(ctx context.Context, limit int) ([]*model.Refund, error) {
+	return r.refunds, nil
+}
+
+func (r *pendingRepo) CompleteRefund(ctx context.Context, refund *model.Refund) error {
+	copied := *refund
+	r.completed[refund.ID] = &copied
+	return nil
+}
+
+func (r *pendingRepo) FailRefund(ctx context.Context, refundId string) error {
+	r.failed = append(r.failed, refundId)
+	return nil
+}
+
+// refundProvider answers every refund with the key it was asked with, or rejects the sessions listed in rejected
+type refundProvider struct {
+	provider.PaymentProvider
+	rejected map[string]bool
+	keys     []string
+}
+
+func (p *refundProvider) Refund(ctx context.Context, params *provider.RefundParams) (*provider.Refund, error) {
+	p.keys = append(p.keys, params.IdempotencyKey)
+	if p.rejected[params.SessionId] {
+		return nil, errors.New("charge already refunded")
+	}
+
+	return &provider.Refund{Id: "re_" + params.IdempotencyKey, Status: "succeeded"}, nil
+}
+
+func pendingRefund(id string, amount int64, providerRefundId string, sessionId string) *model.Refund {
+	return &model.Refund{
+		ID:               id,
+		Amount:           money.New(amount, "VND"),
+		Status:           model.RefundStatusPending,
+		ProviderRefundId: providerRefundId,
+		Payment:          &model.Payment{ID: "payment-" + id, PaymentSessionID: sessionId},
+	}
+}
+
+func TestCompletePendingRefunds(t *testing.T) {
+	repo := &pendingRepo{
+		refunds: []*model.Refund{
+			pendingRefund("refunded", 100000, "re_known", "cs_paid"),
+			pendingRefund("cut-off", 100000, "", "cs_paid"),
+			pendingRefund("rejected", 100000, "", "cs_rejected"),
+			pendingRefund("manual", 100000, "", ""),
+			pendingRefund("free", 0, "", "cs_paid"),
+			{ID: "orphan", Status: model.RefundStatusPending},
+		},
+		completed: make(map[string]*model.Refund),
+	}
+	payments := &refundProvider{rejected: map[string]bool{"cs_rejected": true}}
+	s := &PaymentService{repoPayment: repo, provider: payments}
+
+	if err := s.CompletePendingRefunds(context.Background()); err != nil {
+		t.Fatalf("CompletePendingRefunds() error = %v", err)
+	}
+
+	want := map[string]struct {
+		status           string
+		providerRefundId string
+	}{
+		"refunded": {model.RefundStatusSucceeded, "re_known"},
+		"cut-off":  {model.RefundStatusSucceeded, "re_cut-off"},
+		"manual":   {model.RefundStatusAwaitingManual, ""},
+		"free":     {model.RefundStatusSucceeded, ""},
+	}
+	if len(repo.completed) != len(want) {
+		t.Errorf("completed %d refunds, want %d", len(repo.completed), len(want))
+	}
+	for id, expected := range want {
+		refund, ok := repo.completed[id]
+		if !ok {
+			t.Errorf("refund %s was not completed", id)
+			continue
+		}
+		if refund.Status != expected.status || refund.ProviderRefundId != expected.providerRefundId {
+			t.Errorf("refund %s completed as (%s, %q), want (%s, %q)",
+				id, refund.Status, refund.ProviderRefundId, expected.status, expected.providerRefundId)
+		}
+	}
+
+	if len(repo.failed) != 1 || repo.failed[0] != "rejected" {
+		t.Errorf("failed = %v, want [rejected]", repo.failed)
+	}
+
+	// The provider is only asked again for the refunds it never answered, with their own idempotency key
+	if len(payments.keys) != 2 || payments.keys[0] != "cut-off" || payments.keys[1] != "rejected" {
+		t.Errorf("provider keys = %v, want [cut-off rejected]", payments.keys)
+	}
+}
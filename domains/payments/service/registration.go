@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/dto"
+	"gohub/domains/payments/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Register gives free tickets without going through the payment provider. Events that require approval
+// hold the seats until the organizer approves the registration.
+func (s *PaymentService) Register(ctx context.Context, userId string, req *dto.RegisterReq) (*model.Payment, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.repoPayment.GetEventById(ctx, req.EventId)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	occurrenceId, err := s.bookedOccurrence(ctx, event, req.OccurrenceId)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketTypes, quantities, err := s.orderTicketTypes(ctx, event.ID, req.TicketItems)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &model.Payment{
+		EventID:       event.ID,
+		OccurrenceId:  occurrenceId,
+		UserId:        userId,
+		CustomerName:  req.CustomerName,
+		CustomerEmail: req.CustomerEmail,
+		CustomerPhone: req.CustomerPhone,
+		Status:        model.PaymentStatusFree,
+		TotalPrice:    money.Zero(event.Currency),
+	}
+	if event.RequiresApproval {
+		payment.Status = model.PaymentStatusAwaitingApproval
+	}
+
+	var paymentLines []*model.PaymentLine
+	for _, ticketType := range ticketTypes {
+		if !ticketType.Price.IsZero() {
+			return nil, errors.New(messages.TicketTypeNotFree)
+		}
+
+		payment.TicketQuantity += quantities[ticketType.ID]
+		paymentLines = append(paymentLines, &model.PaymentLine{
+			EventID:      event.ID,
+			TicketTypeID: ticketType.ID,
+			Quantity:     quantities[ticketType.ID],
+			Price:        ticketType.Price,
+		})
+	}
+
+	if err := s.repoPayment.CreateOrder(ctx, payment, paymentLines, event.MaxTicketsPerUser); err != nil {
+		return nil, err
+	}
+
+	if payment.Status == model.PaymentStatusFree {
+		go s.notifyConfirmation(payment.ID)
+	}
+
+	return payment, nil
+}
+
+func (s *PaymentService) GetRegistrations(ctx context.Context, userId string, eventId string, req *dto.ListRegistrationReq) ([]*model.Payment, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.repoPayment.GetEventById(ctx, eventId)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		return nil, errors.New(messages.NotEventOwner)
+	}
+
+	return s.repoPayment.GetRegistrations(ctx, eventId, req.Status)
+}
+
+func (s *PaymentService) ApproveRegistration(ctx context.Context, userId string, paymentId string) (*model.Payment, error) {
+	if _, err := s.orderOwner(ctx, userId, paymentId); err != nil {
+		return nil, err
+	}
+
+	approved, err := s.repoPayment.ConfirmOrder(ctx, paymentId, model.PaymentStatusAwaitingApproval, model.PaymentStatusFree)
+	if err != nil {
+		return nil, err
+	}
+
+	if !approved {
+		return nil, errors.New(messages.RegistrationNotPending)
+	}
+
+	go s.notifyConfirmation(paymentId)
+
+	return s.repoPayment.GetPaymentById(ctx, paymentId)
+}
+
+func (s *PaymentService) RejectRegistration(ctx context.Context, userId string, paymentId string) (*model.Payment, error) {
+	if _, err := s.orderOwner(ctx, userId, paymentId); err != nil {
+		return nil, err
+	}
+
+	rejected, err := s.repoPayment.ReleaseOrder(ctx, paymentId, model.PaymentStatusAwaitingApproval, model.PaymentStatusRejected)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rejected {
+		return nil, errors.New(messages.RegistrationNotPending)
+	}
+
+	return s.repoPayment.GetPaymentById(ctx, paymentId)
+}
+
+// orderTicketTypes loads the ticket types of an order, adding up the quantities of a ticket type listed twice
+func (s *PaymentService) orderTicketTypes(ctx context.Context, eventId string, items []*dto.RegistrationItem) ([]*modelEvent.TicketType, map[string]int, error) {
+	quantities := make(map[string]int)
+	var ticketTypeIds []string
+	for _, item := range items {
+		if _, ok := quantities[item.TicketTypeId]; !ok {
+			ticketTypeIds = append(ticketTypeIds, item.TicketTypeId)
+		}
+		quantities[item.TicketTypeId] += item.Quantity
+	}
+
+	ticketTypes, err := s.repoPayment.GetTicketTypesByIds(ctx, eventId, ticketTypeIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(ticketTypes) != len(ticketTypeIds) {
+		return nil, nil, errors.New(messages.TicketTypeNotFound)
+	}
+
+	return ticketTypes, quantities, nil
+}
+
+// bookedOccurrence picks the occurrence an order is for, only published events sell. A recurring event needs one to be chosen, a single
+// event books its only occurrence and events that have none sell from their ticket types alone.
+func (s *PaymentService) bookedOccurrence(ctx context.Context, event *modelEvent.Event, occurrenceId string) (*string, error) {
+	if event.State != modelEvent.EventStatePublished {
+		return nil, errors.New(messages.EventNotOnSale)
+	}
+
+	if occurrenceId == "" && event.RecurrenceRule != "" {
+		return nil, errors.New(messages.OccurrenceRequired)
+	}
+
+	occurrence, err := s.repoPayment.GetOccurrence(ctx, event.ID, occurrenceId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if occurrenceId == "" {
+			return nil, nil
+		}
+		return nil, errors.New(messages.OccurrenceNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if occurrence.Status != modelEvent.OccurrenceStatusScheduled {
+		return nil, errors.New(messages.OccurrenceCancelled)
+	}
+
+	if !occurrence.EndTime.After(time.Now()) {
+		return nil, errors.New(messages.OccurrenceEnded)
+	}
+
+	return &occurrence.ID, nil
+}
+
+func (s *PaymentService) orderOwner(ctx context.Context, userId string, paymentId string) (*model.Payment, error) {
+	payment, err := s.repoPayment.GetPaymentById(ctx, paymentId)
+	if err != nil {
+		return nil, errors.New(messages.PaymentNotFound)
+	}
+
+	if payment.Event == nil || payment.Event.UserId != userId {
+		return nil, errors.New(messages.NotEventOwner)
+	}
+
+	return payment, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/payments/dto"
+	"gohub/domains/payments/model"
+	modelTicket "gohub/domains/tickets/model"
+	"gohub/pkg/messages"
+	"time"
+)
+
+// RespondToReschedule lets a buyer keep the tickets of an order for the new times of the event, or have the
+// ones they still hold refunded in full whatever the refund policy says. Each order answers the last
+// reschedule made after it once, within its response window.
+func (s *PaymentService) RespondToReschedule(ctx context.Context, userId string, paymentId string, req *dto.RescheduleResponseReq) (*model.RescheduleResponse, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.refundablePayment(ctx, paymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.UserId != userId {
+		return nil, errors.New(messages.NotPaymentOwner)
+	}
+
+	// Orders of a cancelled event are refunded by the worker already
+	if payment.Event == nil || payment.Event.State == modelEvent.EventStateCancelled {
+		return nil, errors.New(messages.RescheduleNotFound)
+	}
+
+	reschedule, err := s.repoPayment.GetLatestReschedule(ctx, payment.EventID, payment.OccurrenceId, payment.CreatedAt)
+	if err != nil {
+		return nil, errors.New(messages.RescheduleNotFound)
+	}
+
+	if !reschedule.IsOpen(time.Now()) {
+		return nil, errors.New(messages.RescheduleWindowClosed)
+	}
+
+	answered, err := s.repoPayment.HasRescheduleResponse(ctx, reschedule.ID, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if answered {
+		return nil, errors.New(messages.RescheduleAnswered)
+	}
+
+	response := &model.RescheduleResponse{
+		RescheduleId: reschedule.ID,
+		PaymentId:    payment.ID,
+		UserId:       userId,
+		Decision:     req.Decision,
+	}
+
+	if req.Decision == model.RescheduleRefunded {
+		refund, err := s.refundRescheduled(ctx, userId, payment)
+		if err != nil {
+			return nil, err
+		}
+
+		response.RefundId = &refund.ID
+		response.Refund = refund
+	}
+
+	created, err := s.repoPayment.CreateRescheduleResponse(ctx, response)
+	if err != nil {
+		return nil, err
+	}
+
+	if !created {
+		return nil, errors.New(messages.RescheduleAnswered)
+	}
+
+	return response, nil
+}
+
+// refundRescheduled refunds the tickets of the order the buyer still holds, transferred ones belong to
+// someone else now
+func (s *PaymentService) refundRescheduled(ctx context.Context, userId string, payment *model.Payment) (*model.Refund, error) {
+	tickets, err := s.repoPayment.GetRefundableTickets(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []*modelTicket.Ticket
+	for _, ticket := range tickets {
+		if ticket.UserId == userId {
+			owned = append(owned, ticket)
+		}
+	}
+
+	if len(owned) == 0 {
+		return nil, errors.New(messages.NoRefundableTickets)
+	}
+
+	lines, err := s.repoPayment.GetPaymentLines(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := refundAmount(payment, lines, owned, 100)
+	if len(owned) == len(tickets) {
+		amount = refundable(payment).Sub(payment.RefundedAmount)
+	}
+
+	return s.refund(ctx, payment, &model.Refund{
+		InitiatedById: userId,
+		Source:        model.RefundSourceReschedule,
+		Reason:        "The event was rescheduled",
+	}, owned, amount)
+}
@@ -0,0 +1,119 @@
+package dto
+
+import (
+	"gohub/pkg/money"
+	"gohub/pkg/paging"
+	"time"
+)
+
+// Balance is what the organizer earned in one currency, amounts in different currencies are never summed
+type Balance struct {
+	Currency     string      `json:"currency"`
+	Available    money.Money `json:"available"`
+	Pending      money.Money `json:"pending"`
+	PaidOut      money.Money `json:"paidOut"`
+	TotalSales   money.Money `json:"totalSales"`
+	TotalFees    money.Money `json:"totalFees"`
+	TotalRefunds money.Money `json:"totalRefunds"`
+}
+
+type ListBalanceRes struct {
+	Balances []*Balance `json:"items"`
+}
+
+type LedgerEntry struct {
+	ID            string      `json:"id"`
+	TransactionId string      `json:"transactionId"`
+	Debit         money.Money `json:"debit"`
+	Credit        money.Money `json:"credit"`
+	SourceType    string      `json:"sourceType"`
+	SourceId      string      `json:"sourceId"`
+	Description   string      `json:"description"`
+	CreatedAt     time.Time   `json:"createdAt"`
+}
+
+type ListLedgerEntryReq struct {
+	Page    int64 `json:"-" form:"page"`
+	Limit   int64 `json:"-" form:"pageSize"`
+	TakeAll bool  `json:"-" form:"take_all"`
+}
+
+type ListLedgerEntryRes struct {
+	LedgerEntries []*LedgerEntry     `json:"items"`
+	Pagination    *paging.Pagination `json:"metadata"`
+}
+
+type Payout struct {
+	ID            string         `json:"id"`
+	UserId        string         `json:"userId"`
+	User          *Organizer     `json:"user"`
+	UserPaymentId string         `json:"userPaymentId"`
+	UserPayment   *PayoutAccount `json:"userPayment"`
+	Amount        money.Money    `json:"amount"`
+	Status        string         `json:"status"`
+	Reference     string         `json:"reference"`
+	FailureReason string         `json:"failureReason"`
+	PaidAt        *time.Time     `json:"paidAt"`
+	CreatedAt     time.Time      `json:"createdAt"`
+}
+
+type Organizer struct {
+	ID       string `json:"id"`
+	FullName string `json:"fullName"`
+	Email    string `json:"email"`
+}
+
+type PayoutAccount struct {
+	ID                   string `json:"id"`
+	PaymentMethodId      string `json:"paymentMethodId"`
+	PaymentAccountNumber string `json:"paymentAccountNumber"`
+}
+
+// ListPayoutReq lists the payouts of one organizer, or of everyone for finance when UserId is empty
+type ListPayoutReq struct {
+	UserId  string `json:"-"`
+	Status  string `json:"-" form:"status"`
+	Page    int64  `json:"-" form:"page"`
+	Limit   int64  `json:"-" form:"pageSize"`
+	TakeAll bool   `json:"-" form:"take_all"`
+}
+
+type ListPayoutRes struct {
+	Payouts    []*Payout          `json:"items"`
+	Pagination *paging.Pagination `json:"metadata"`
+}
+
+type SettlePayoutReq struct {
+	Reference string `json:"reference" validate:"required"`
+}
+
+type FailPayoutReq struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type ExportPayoutReq struct {
+	StartDate string `json:"-" form:"startDate"`
+	EndDate   string `json:"-" form:"endDate"`
+}
+
+// PayableOrganizer is an organizer with a positive balance in a currency, with the account it is paid to.
+// Balance is in minor units of Currency.
+type PayableOrganizer struct {
+	UserId        string
+	UserPaymentId string
+	Currency      string
+	Balance       int64
+}
+
+// SettledPayout is one line of the finance export
+type SettledPayout struct {
+	ID                   string
+	OrganizerName        string
+	OrganizerEmail       string
+	MethodName           string
+	PaymentAccountNumber string
+	Amount               int64
+	Currency             string
+	Reference            string
+	PaidAt               time.Time
+}
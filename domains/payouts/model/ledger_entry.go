@@ -0,0 +1,60 @@
+package model
+
+import (
+	modelUser "gohub/domains/users/model"
+	"gohub/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Accounts of the ledger, organizer entries also carry the organizer's user id
+const (
+	LedgerAccountOrganizer        = "Organizer"
+	LedgerAccountPlatformCash     = "PlatformCash"
+	LedgerAccountPlatformFees     = "PlatformFees"
+	LedgerAccountPayoutsInTransit = "PayoutsInTransit"
+)
+
+const (
+	LedgerSourceSale           = "Sale"
+	LedgerSourcePlatformFee    = "PlatformFee"
+	LedgerSourceRefund         = "Refund"
+	LedgerSourcePayout         = "Payout"
+	LedgerSourcePayoutSettled  = "PayoutSettled"
+	LedgerSourcePayoutReversal = "PayoutReversal"
+)
+
+// LedgerEntry is one leg of a double-entry transaction, the legs sharing a TransactionId always balance
+type LedgerEntry struct {
+	ID            string          `json:"id" gorm:"unique;not null;index;primary_key"`
+	TransactionId string          `json:"transactionId" gorm:"not null;index"`
+	Account       string          `json:"account" gorm:"not null;index:idx_ledger_entries_account_user"`
+	UserId        *string         `json:"userId" gorm:"index:idx_ledger_entries_account_user"`
+	User          *modelUser.User `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Debit         money.Money     `json:"debit" gorm:"embedded;embeddedPrefix:debit_"`
+	Credit        money.Money     `json:"credit" gorm:"embedded;embeddedPrefix:credit_"`
+	SourceType    string          `json:"sourceType" gorm:"not null"`
+	SourceId      string          `json:"sourceId" gorm:"not null;index"`
+	Description   string          `json:"description"`
+	CreatedAt     time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+func (l *LedgerEntry) BeforeCreate(tx *gorm.DB) error {
+	l.ID = uuid.New().String()
+
+	// A leg carries either a debit or a credit, the other side is a zero of the same currency
+	if l.Debit.Currency == "" {
+		l.Debit = money.Zero(l.Credit.Currency)
+	}
+	if l.Credit.Currency == "" {
+		l.Credit = money.Zero(l.Debit.Currency)
+	}
+
+	return nil
+}
+
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	modelUser "gohub/domains/users/model"
+	"gohub/pkg/money"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	PayoutStatusPending = "Pending"
+	PayoutStatusPaid    = "Paid"
+	PayoutStatusFailed  = "Failed"
+)
+
+type Payout struct {
+	ID            string                 `json:"id" gorm:"unique;not null;index;primary_key"`
+	UserId        string                 `json:"userId" gorm:"not null;index"`
+	User          *modelUser.User        `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserPaymentId string                 `json:"userPaymentId" gorm:"not null"`
+	UserPayment   *modelUser.UserPayment `json:"userPayment"`
+	Amount        money.Money            `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	Status        string                 `json:"status" gorm:"not null;default:'Pending';index"`
+	Reference     string                 `json:"reference"`
+	FailureReason string                 `json:"failureReason"`
+	PaidAt        *time.Time             `json:"paidAt"`
+	CreatedAt     time.Time              `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time              `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+func (p *Payout) BeforeCreate(tx *gorm.DB) error {
+	p.ID = uuid.New().String()
+
+	return nil
+}
+
+func (Payout) TableName() string {
+	return "payouts"
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"fmt"
+	"gohub/domains/payouts/dto"
+	"gohub/domains/payouts/service"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/response"
+	"gohub/pkg/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PayoutHandler struct {
+	service service.IPayoutService
+}
+
+func NewPayoutHandler(service service.IPayoutService) *PayoutHandler {
+	return &PayoutHandler{
+		service: service,
+	}
+}
+
+//		@Summary	 Retrieve the balance of the current organizer
+//	 @Description Fetches what the platform owes the authenticated organizer in each currency they sold in, with the payouts in flight and the totals of sales, fees and refunds.
+//		@Tags		 Payouts
+//		@Produce	 json
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the balance"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payouts/balance [get]
+func (h *PayoutHandler) GetBalance(c *gin.Context) {
+	balances, err := h.service.GetBalances(c, c.GetString("userId"))
+	if err != nil {
+		logger.Error("Failed to get balance: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	response.JSON(c, http.StatusOK, dto.ListBalanceRes{Balances: balances})
+}
+
+//		@Summary	 Retrieve the ledger of the current organizer
+//	 @Description Fetches a paginated list of the sales, fees, refunds and payouts booked on the authenticated organizer's account.
+//		@Tags		 Payouts
+//		@Produce	 json
+//		@Param		 page	query	int	false	"Page number"
+//		@Param		 pageSize	query	int	false	"Page size"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the ledger entries"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payouts/ledger [get]
+func (h *PayoutHandler) GetLedgerEntries(c *gin.Context) {
+	var req dto.ListLedgerEntryReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	entries, pagination, err := h.service.GetLedgerEntries(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to get ledger entries: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListLedgerEntryRes
+	utils.MapStruct(&res.LedgerEntries, &entries)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the payout history of the current organizer
+//	 @Description Fetches a paginated list of the payouts of the authenticated organizer.
+//		@Tags		 Payouts
+//		@Produce	 json
+//		@Param		 status	query	string	false	"Filter by status: Pending, Paid or Failed"
+//		@Param		 page	query	int	false	"Page number"
+//		@Param		 pageSize	query	int	false	"Page size"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the payouts"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payouts [get]
+func (h *PayoutHandler) GetPayouts(c *gin.Context) {
+	var req dto.ListPayoutReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	payouts, pagination, err := h.service.GetPayouts(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to get payouts: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListPayoutRes
+	utils.MapStruct(&res.Payouts, &payouts)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the payouts of every organizer
+//	 @Description Fetches a paginated list of the payouts of all organizers. Only admins can list them.
+//		@Tags		 Payouts
+//		@Produce	 json
+//		@Param		 status	query	string	false	"Filter by status: Pending, Paid or Failed"
+//		@Param		 page	query	int	false	"Page number"
+//		@Param		 pageSize	query	int	false	"Page size"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the payouts"
+//		@Failure	 400	{object}	response.Response	"BadRequest - Invalid input or request data"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not an admin"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payouts/all [get]
+func (h *PayoutHandler) GetAllPayouts(c *gin.Context) {
+	var req dto.ListPayoutReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	payouts, pagination, err := h.service.GetAllPayouts(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to get all payouts: ", err)
+		payoutError(c, err)
+		return
+	}
+
+	var res dto.ListPayoutRes
+	utils.MapStruct(&res.Payouts, &payouts)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Mark a payout as paid
+//	 @Description Records the bank reference of a pending payout once the money was sent to the organizer. Only admins can settle payouts.
+//		@Tags		 Payouts
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payout ID"
+//		@Param		 params	body	dto.SettlePayoutReq	true	"Bank reference of the transfer"
+//		@Success	 200	{object}	response.Response	"Payout marked as paid"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid parameters"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not an admin"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payout with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The payout is not pending"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payouts/{id}/paid [patch]
+func (h *PayoutHandler) SettlePayout(c *gin.Context) {
+	var req dto.SettlePayoutReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	payout, err := h.service.SettlePayout(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to settle payout: ", err)
+		payoutError(c, err)
+		return
+	}
+
+	var res dto.Payout
+	utils.MapStruct(&res, &payout)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Mark a payout as failed
+//	 @Description Records why a pending payout could not be paid, its amount goes back to the organizer's balance for the next batch. Only admins can fail payouts.
+//		@Tags		 Payouts
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Payout ID"
+//		@Param		 params	body	dto.FailPayoutReq	true	"Reason of the failure"
+//		@Success	 200	{object}	response.Response	"Payout marked as failed"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid parameters"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not an admin"
+//		@Failure	 404	{object}	response.Response	"Not Found - Payout with the specified ID not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The payout is not pending"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payouts/{id}/failed [patch]
+func (h *PayoutHandler) FailPayout(c *gin.Context) {
+	var req dto.FailPayoutReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	payout, err := h.service.FailPayout(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to fail payout: ", err)
+		payoutError(c, err)
+		return
+	}
+
+	var res dto.Payout
+	utils.MapStruct(&res, &payout)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Export the settled payouts
+//	 @Description Downloads the payouts paid between two dates as CSV, the current month by default. Only admins can export payouts.
+//		@Tags		 Payouts
+//		@Produce	 text/csv
+//		@Param		 startDate	query	string	false	"First day, YYYY-MM-DD"
+//		@Param		 endDate	query	string	false	"Last day, YYYY-MM-DD"
+//		@Success	 200	{file}	file	"CSV of the settled payouts"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid date range"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not an admin"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/payouts/export [get]
+func (h *PayoutHandler) ExportPayouts(c *gin.Context) {
+	var req dto.ExportPayoutReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	data, err := h.service.ExportPayouts(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to export payouts: ", err)
+		payoutError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=payouts-%s.csv", time.Now().Format("20060102")))
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+func payoutError(c *gin.Context, err error) {
+	switch err.Error() {
+	case messages.PayoutNotFound:
+		response.Error(c, http.StatusNotFound, err, messages.PayoutNotFound)
+	case messages.NotFinanceAdmin:
+		response.Error(c, http.StatusForbidden, err, messages.NotFinanceAdmin)
+	case messages.PayoutNotPending:
+		response.Error(c, http.StatusConflict, err, messages.PayoutNotPending)
+	case messages.InvalidDateRange:
+		response.Error(c, http.StatusBadRequest, err, messages.InvalidDateRange)
+	default:
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"gohub/database"
+	"gohub/domains/payouts/repository"
+	"gohub/domains/payouts/service"
+	"gohub/internal/libs/validation"
+	middleware "gohub/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.Validation) {
+	payoutRepository := repository.NewPayoutRepository(sqlDB)
+	payoutService := service.NewPayoutService(validator, payoutRepository)
+	payoutHandler := NewPayoutHandler(payoutService)
+
+	authMiddleware := middleware.JWTAuth()
+	payoutRoute := r.Group("/payouts").Use(authMiddleware)
+	{
+		payoutRoute.GET("/", payoutHandler.GetPayouts)
+		payoutRoute.GET("/balance", payoutHandler.GetBalance)
+		payoutRoute.GET("/ledger", payoutHandler.GetLedgerEntries)
+		payoutRoute.GET("/all", payoutHandler.GetAllPayouts)
+		payoutRoute.GET("/export", payoutHandler.ExportPayouts)
+		payoutRoute.PATCH("/:id/paid", payoutHandler.SettlePayout)
+		payoutRoute.PATCH("/:id/failed", payoutHandler.FailPayout)
+	}
+}
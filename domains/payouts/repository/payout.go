@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"context"
+	"gohub/configs"
+	"gohub/database"
+	"gohub/domains/payouts/dto"
+	"gohub/domains/payouts/model"
+	"gohub/pkg/money"
+	"gohub/pkg/paging"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IPayoutRepository interface {
+	IsAdmin(ctx context.Context, userId string) (bool, error)
+	GetBalances(ctx context.Context, userId string) ([]*dto.Balance, error)
+	GetLedgerEntries(ctx context.Context, userId string, req *dto.ListLedgerEntryReq) ([]*model.LedgerEntry, *paging.Pagination, error)
+	GetPayouts(ctx context.Context, req *dto.ListPayoutReq) ([]*model.Payout, *paging.Pagination, error)
+	GetPayoutById(ctx context.Context, id string) (*model.Payout, error)
+	GetPayableOrganizers(ctx context.Context) ([]*dto.PayableOrganizer, error)
+	CreatePayout(ctx context.Context, payout *model.Payout, minAmount money.Money) (bool, error)
+	SettlePayout(ctx context.Context, id string, reference string) (bool, error)
+	FailPayout(ctx context.Context, id string, reason string) (bool, error)
+	GetSettledPayouts(ctx context.Context, from time.Time, to time.Time) ([]*dto.SettledPayout, error)
+}
+
+type PayoutRepository struct {
+	db database.IDatabase
+}
+
+func NewPayoutRepository(db database.IDatabase) *PayoutRepository {
+	return &PayoutRepository{db: db}
+}
+
+func (p *PayoutRepository) IsAdmin(ctx context.Context, userId string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var total int64
+	if err := p.db.GetDB().WithContext(ctx).
+		Table("user_roles").
+		Joins("INNER JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND roles.name = ? AND user_roles.deleted_at IS NULL", userId, configs.AdminRoleName).
+		Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+// balanceRow is the balance of an organizer in one currency, amounts are in minor units
+type balanceRow struct {
+	Currency     string
+	Available    int64
+	Pending      int64
+	PaidOut      int64
+	TotalSales   int64
+	TotalFees    int64
+	TotalRefunds int64
+}
+
+// GetBalances sums the organizer's ledger account per currency, the pending and paid payouts come from the
+// payouts table
+func (p *PayoutRepository) GetBalances(ctx context.Context, userId string) ([]*dto.Balance, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var rows []*balanceRow
+	if err := p.db.GetDB().WithContext(ctx).Raw(`
+		SELECT
+			currency,
+			COALESCE(SUM(available), 0) AS available,
+			COALESCE(SUM(total_sales), 0) AS total_sales,
+			COALESCE(SUM(total_fees), 0) AS total_fees,
+			COALESCE(SUM(total_refunds), 0) AS total_refunds,
+			COALESCE(SUM(pending), 0) AS pending,
+			COALESCE(SUM(paid_out), 0) AS paid_out
+		FROM (
+			SELECT
+				credit_currency AS currency,
+				SUM(credit_amount - debit_amount) AS available,
+				SUM(credit_amount) FILTER (WHERE source_type = @sale) AS total_sales,
+				SUM(debit_amount) FILTER (WHERE source_type = @fee) AS total_fees,
+				SUM(debit_amount) FILTER (WHERE source_type = @refund) AS total_refunds,
+				0 AS pending,
+				0 AS paid_out
+			FROM ledger_entries
+			WHERE account = @account AND user_id = @userId
+			GROUP BY credit_currency
+			UNION ALL
+			SELECT
+				amount_currency AS currency,
+				0, 0, 0, 0,
+				SUM(amount_amount) FILTER (WHERE status = @pending),
+				SUM(amount_amount) FILTER (WHERE status = @paid)
+			FROM payouts
+			WHERE user_id = @userId
+			GROUP BY amount_currency
+		) AS balances
+		GROUP BY currency
+		ORDER BY currency
+	`, map[string]interface{}{
+		"userId":  userId,
+		"account": model.LedgerAccountOrganizer,
+		"sale":    model.LedgerSourceSale,
+		"fee":     model.LedgerSourcePlatformFee,
+		"refund":  model.LedgerSourceRefund,
+		"pending": model.PayoutStatusPending,
+		"paid":    model.PayoutStatusPaid,
+	}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	balances := make([]*dto.Balance, 0, len(rows))
+	for _, row := range rows {
+		balances = append(balances, &dto.Balance{
+			Currency:     row.Currency,
+			Available:    money.New(row.Available, row.Currency),
+			Pending:      money.New(row.Pending, row.Currency),
+			PaidOut:      money.New(row.PaidOut, row.Currency),
+			TotalSales:   money.New(row.TotalSales, row.Currency),
+			TotalFees:    money.New(row.TotalFees, row.Currency),
+			TotalRefunds: money.New(row.TotalRefunds, row.Currency),
+		})
+	}
+
+	return balances, nil
+}
+
+func (p *PayoutRepository) GetLedgerEntries(ctx context.Context, userId string, req *dto.ListLedgerEntryReq) ([]*model.LedgerEntry, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	query := database.NewQuery("account = ? AND user_id = ?", model.LedgerAccountOrganizer, userId)
+
+	var total int64
+	if err := p.db.Count(ctx, &model.LedgerEntry{}, &total, database.WithQuery(query)); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	if req.TakeAll {
+		pagination.PageSize = total
+	}
+
+	var entries []*model.LedgerEntry
+	if err := p.db.Find(
+		ctx,
+		&entries,
+		database.WithQuery(query),
+		database.WithLimit(int(pagination.PageSize)),
+		database.WithOffset(int(pagination.Skip)),
+		database.WithOrder("created_at DESC"),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return entries, pagination, nil
+}
+
+func (p *PayoutRepository) GetPayouts(ctx context.Context, req *dto.ListPayoutReq) ([]*model.Payout, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	db := p.db.GetDB().WithContext(ctx).Model(&model.Payout{})
+	if req.UserId != "" {
+		db = db.Where("user_id = ?", req.UserId)
+	}
+	if req.Status != "" {
+		db = db.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	if req.TakeAll {
+		pagination.PageSize = total
+	}
+
+	// Payouts keep pointing at accounts the organizer removed afterwards
+	var payouts []*model.Payout
+	if err := db.
+		Preload("User").
+		Preload("UserPayment", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Limit(int(pagination.PageSize)).
+		Offset(int(pagination.Skip)).
+		Order("created_at DESC").
+		Find(&payouts).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return payouts, pagination, nil
+}
+
+func (p *PayoutRepository) GetPayoutById(ctx context.Context, id string) (*model.Payout, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var payout model.Payout
+	if err := p.db.GetDB().WithContext(ctx).
+		Preload("User").
+		Preload("UserPayment", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("id = ?", id).
+		First(&payout).Error; err != nil {
+		return nil, err
+	}
+
+	return &payout, nil
+}
+
+// GetPayableOrganizers lists the positive balances of organizers per currency with nothing in flight in
+// that currency, paid out to the payment account they added last
+func (p *PayoutRepository) GetPayableOrganizers(ctx context.Context) ([]*dto.PayableOrganizer, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var organizers []*dto.PayableOrganizer
+	if err := p.db.GetDB().WithContext(ctx).Raw(`
+		SELECT balances.user_id, accounts.id AS user_payment_id, balances.currency, balances.balance
+		FROM (
+			SELECT user_id, credit_currency AS currency, SUM(credit_amount - debit_amount) AS balance
+			FROM ledger_entries
+			WHERE account = @account
+			GROUP BY user_id, credit_currency
+			HAVING SUM(credit_amount - debit_amount) > 0
+		) AS balances
+		INNER JOIN LATERAL (
+			SELECT id FROM user_payments
+			WHERE user_payments.user_id = balances.user_id AND user_payments.deleted_at IS NULL
+			ORDER BY created_at DESC
+			LIMIT 1
+		) AS accounts ON TRUE
+		WHERE NOT EXISTS (
+			SELECT 1 FROM payouts
+			WHERE payouts.user_id = balances.user_id AND payouts.amount_currency = balances.currency AND payouts.status = @pending
+		)
+	`, map[string]interface{}{
+		"account": model.LedgerAccountOrganizer,
+		"pending": model.PayoutStatusPending,
+	}).Scan(&organizers).Error; err != nil {
+		return nil, err
+	}
+
+	return organizers, nil
+}
+
+// CreatePayout moves the whole balance of the organizer in the currency of minAmount into a pending payout.
+// The balance is read again under a per organizer lock, it reports false when the balance dropped below
+// minAmount or a payout in that currency is already in flight.
+func (p *PayoutRepository) CreatePayout(ctx context.Context, payout *model.Payout, minAmount money.Money) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	created := false
+	err := p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "payout:"+payout.UserId).Error; err != nil {
+			return err
+		}
+
+		var pending int64
+		if err := tx.Model(&model.Payout{}).
+			Where("user_id = ? AND amount_currency = ? AND status = ?", payout.UserId, minAmount.Currency, model.PayoutStatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+
+		if pending > 0 {
+			return nil
+		}
+
+		var amount int64
+		if err := tx.Model(&model.LedgerEntry{}).
+			Select("COALESCE(SUM(credit_amount - debit_amount), 0)").
+			Where("account = ? AND user_id = ? AND credit_currency = ?", model.LedgerAccountOrganizer, payout.UserId, minAmount.Currency).
+			Scan(&amount).Error; err != nil {
+			return err
+		}
+
+		balance := money.New(amount, minAmount.Currency)
+		if !balance.IsPositive() || balance.Cmp(minAmount) < 0 {
+			return nil
+		}
+
+		payout.Amount = balance
+		payout.Status = model.PayoutStatusPending
+		if err := tx.Omit(clause.Associations).Create(payout).Error; err != nil {
+			return err
+		}
+
+		if err := postTransfer(tx, payout, model.LedgerSourcePayout,
+			model.LedgerAccountOrganizer, model.LedgerAccountPayoutsInTransit, "Payout scheduled"); err != nil {
+			return err
+		}
+
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+// SettlePayout records that the money reached the organizer, it leaves the platform cash for good
+func (p *PayoutRepository) SettlePayout(ctx context.Context, id string, reference string) (bool, error) {
+	return p.closePayout(ctx, id, map[string]interface{}{
+		"status":    model.PayoutStatusPaid,
+		"reference": reference,
+		"paid_at":   time.Now(),
+	}, func(tx *gorm.DB, payout *model.Payout) error {
+		return postTransfer(tx, payout, model.LedgerSourcePayoutSettled,
+			model.LedgerAccountPayoutsInTransit, model.LedgerAccountPlatformCash, "Payout paid")
+	})
+}
+
+// FailPayout gives the amount of a payout that could not be paid back to the organizer's balance
+func (p *PayoutRepository) FailPayout(ctx context.Context, id string, reason string) (bool, error) {
+	return p.closePayout(ctx, id, map[string]interface{}{
+		"status":         model.PayoutStatusFailed,
+		"failure_reason": reason,
+	}, func(tx *gorm.DB, payout *model.Payout) error {
+		return postTransfer(tx, payout, model.LedgerSourcePayoutReversal,
+			model.LedgerAccountPayoutsInTransit, model.LedgerAccountOrganizer, "Payout failed")
+	})
+}
+
+func (p *PayoutRepository) GetSettledPayouts(ctx context.Context, from time.Time, to time.Time) ([]*dto.SettledPayout, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var payouts []*dto.SettledPayout
+	if err := p.db.GetDB().WithContext(ctx).
+		Table("payouts").
+		Select(`payouts.id, users.full_name AS organizer_name, users.email AS organizer_email,
+			payment_methods.method_name, user_payments.payment_account_number,
+			payouts.amount_amount AS amount, payouts.amount_currency AS currency, payouts.reference, payouts.paid_at`).
+		Joins("INNER JOIN users ON users.id = payouts.user_id").
+		Joins("INNER JOIN user_payments ON user_payments.id = payouts.user_payment_id").
+		Joins("LEFT JOIN payment_methods ON payment_methods.id = user_payments.payment_method_id").
+		Where("payouts.status = ? AND payouts.paid_at >= ? AND payouts.paid_at < ?", model.PayoutStatusPaid, from, to).
+		Order("payouts.paid_at").
+		Scan(&payouts).Error; err != nil {
+		return nil, err
+	}
+
+	return payouts, nil
+}
+
+// closePayout moves a pending payout to its final status once and posts the matching ledger transaction
+func (p *PayoutRepository) closePayout(ctx context.Context, id string, updates map[string]interface{}, post func(tx *gorm.DB, payout *model.Payout) error) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	closed := false
+	err := p.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Payout{}).
+			Where("id = ? AND status = ?", id, model.PayoutStatusPending).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var payout model.Payout
+		if err := tx.Where("id = ?", id).First(&payout).Error; err != nil {
+			return err
+		}
+
+		if err := post(tx, &payout); err != nil {
+			return err
+		}
+
+		closed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return closed, nil
+}
+
+// postTransfer moves the amount of a payout from one account to another, the organizer account is the
+// only one carrying a user
+func postTransfer(tx *gorm.DB, payout *model.Payout, source string, from string, to string, description string) error {
+	transactionId := uuid.New().String()
+	entries := []*model.LedgerEntry{
+		{Account: from, Debit: payout.Amount},
+		{Account: to, Credit: payout.Amount},
+	}
+	for _, entry := range entries {
+		entry.TransactionId = transactionId
+		entry.SourceType = source
+		entry.SourceId = payout.ID
+		entry.Description = description
+		if entry.Account == model.LedgerAccountOrganizer {
+			entry.UserId = &payout.UserId
+		}
+	}
+
+	return tx.Omit("User").CreateInBatches(&entries, len(entries)).Error
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/payouts/dto"
+	"gohub/domains/payouts/model"
+	"gohub/domains/payouts/repository"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/validation"
+	"gohub/pkg/messages"
+	"gohub/pkg/money"
+	"gohub/pkg/paging"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IPayoutService interface {
+	GetBalances(ctx context.Context, userId string) ([]*dto.Balance, error)
+	GetLedgerEntries(ctx context.Context, userId string, req *dto.ListLedgerEntryReq) ([]*model.LedgerEntry, *paging.Pagination, error)
+	GetPayouts(ctx context.Context, userId string, req *dto.ListPayoutReq) ([]*model.Payout, *paging.Pagination, error)
+	GetAllPayouts(ctx context.Context, userId string, req *dto.ListPayoutReq) ([]*model.Payout, *paging.Pagination, error)
+	SettlePayout(ctx context.Context, userId string, id string, req *dto.SettlePayoutReq) (*model.Payout, error)
+	FailPayout(ctx context.Context, userId string, id string, req *dto.FailPayoutReq) (*model.Payout, error)
+	ExportPayouts(ctx context.Context, userId string, req *dto.ExportPayoutReq) ([]byte, error)
+	SchedulePayouts(ctx context.Context) error
+}
+
+type PayoutService struct {
+	validator  validation.Validation
+	repoPayout repository.IPayoutRepository
+}
+
+func NewPayoutService(validator validation.Validation, repoPayout repository.IPayoutRepository) *PayoutService {
+	return &PayoutService{
+		validator:  validator,
+		repoPayout: repoPayout,
+	}
+}
+
+func (s *PayoutService) GetBalances(ctx context.Context, userId string) ([]*dto.Balance, error) {
+	return s.repoPayout.GetBalances(ctx, userId)
+}
+
+func (s *PayoutService) GetLedgerEntries(ctx context.Context, userId string, req *dto.ListLedgerEntryReq) ([]*model.LedgerEntry, *paging.Pagination, error) {
+	return s.repoPayout.GetLedgerEntries(ctx, userId, req)
+}
+
+func (s *PayoutService) GetPayouts(ctx context.Context, userId string, req *dto.ListPayoutReq) ([]*model.Payout, *paging.Pagination, error) {
+	req.UserId = userId
+	return s.repoPayout.GetPayouts(ctx, req)
+}
+
+// GetAllPayouts lists the payouts of every organizer, finance uses it to find the pending ones to pay
+func (s *PayoutService) GetAllPayouts(ctx context.Context, userId string, req *dto.ListPayoutReq) ([]*model.Payout, *paging.Pagination, error) {
+	if err := s.ensureAdmin(ctx, userId); err != nil {
+		return nil, nil, err
+	}
+
+	req.UserId = ""
+	return s.repoPayout.GetPayouts(ctx, req)
+}
+
+func (s *PayoutService) SettlePayout(ctx context.Context, userId string, id string, req *dto.SettlePayoutReq) (*model.Payout, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	return s.closePayout(ctx, userId, id, func() (bool, error) {
+		return s.repoPayout.SettlePayout(ctx, id, req.Reference)
+	})
+}
+
+func (s *PayoutService) FailPayout(ctx context.Context, userId string, id string, req *dto.FailPayoutReq) (*model.Payout, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	return s.closePayout(ctx, userId, id, func() (bool, error) {
+		return s.repoPayout.FailPayout(ctx, id, req.Reason)
+	})
+}
+
+// ExportPayouts writes the payouts paid between the two dates as CSV, the current month when no date is given
+func (s *PayoutService) ExportPayouts(ctx context.Context, userId string, req *dto.ExportPayoutReq) ([]byte, error) {
+	if err := s.ensureAdmin(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := now
+	var err error
+	if req.StartDate != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, req.StartDate, now.Location()); err != nil {
+			return nil, errors.New(messages.InvalidDateRange)
+		}
+	}
+	if req.EndDate != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, req.EndDate, now.Location()); err != nil {
+			return nil, errors.New(messages.InvalidDateRange)
+		}
+	}
+	// The end date is inclusive
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return nil, errors.New(messages.InvalidDateRange)
+	}
+
+	payouts, err := s.repoPayout.GetSettledPayouts(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"Payout ID", "Organizer", "Email", "Method", "Account Number", "Amount", "Currency", "Reference", "Paid At"})
+	for _, payout := range payouts {
+		_ = writer.Write([]string{
+			payout.ID,
+			payout.OrganizerName,
+			payout.OrganizerEmail,
+			payout.MethodName,
+			payout.PaymentAccountNumber,
+			money.New(payout.Amount, payout.Currency).Decimal(),
+			payout.Currency,
+			payout.Reference,
+			payout.PaidAt.Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SchedulePayouts opens a payout batch for every organizer owed at least the minimum amount of a currency,
+// one failing organizer does not hold back the others
+func (s *PayoutService) SchedulePayouts(ctx context.Context) error {
+	organizers, err := s.repoPayout.GetPayableOrganizers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, organizer := range organizers {
+		minAmount, ok := configs.MinPayoutAmounts[organizer.Currency]
+		if !ok || organizer.Balance < minAmount {
+			continue
+		}
+
+		payout := &model.Payout{
+			UserId:        organizer.UserId,
+			UserPaymentId: organizer.UserPaymentId,
+		}
+		created, err := s.repoPayout.CreatePayout(ctx, payout, money.New(minAmount, organizer.Currency))
+		if err != nil {
+			logger.Errorf("Failed to schedule payout for organizer %s: %v", organizer.UserId, err)
+			continue
+		}
+
+		if created {
+			logger.Infof("Scheduled payout %s of %s for organizer %s", payout.ID, payout.Amount, organizer.UserId)
+		}
+	}
+
+	return nil
+}
+
+func (s *PayoutService) closePayout(ctx context.Context, userId string, id string, settle func() (bool, error)) (*model.Payout, error) {
+	if err := s.ensureAdmin(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repoPayout.GetPayoutById(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(messages.PayoutNotFound)
+		}
+		return nil, err
+	}
+
+	closed, err := settle()
+	if err != nil {
+		return nil, err
+	}
+
+	if !closed {
+		return nil, errors.New(messages.PayoutNotPending)
+	}
+
+	return s.repoPayout.GetPayoutById(ctx, id)
+}
+
+func (s *PayoutService) ensureAdmin(ctx context.Context, userId string) error {
+	isAdmin, err := s.repoPayout.IsAdmin(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return errors.New(messages.NotFinanceAdmin)
+	}
+
+	return nil
+}
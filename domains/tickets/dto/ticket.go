@@ -1,6 +1,10 @@
 package dto
 
-import "gohub/pkg/paging"
+import (
+	"gohub/pkg/money"
+	"gohub/pkg/paging"
+	"time"
+)
 
 type Ticket struct {
 	ID            string     `json:"id"`
@@ -10,6 +14,7 @@ type Ticket struct {
 	CustomerEmail string     `json:"customerEmail"`
 	Event         Event      `json:"event"`
 	TicketType    TicketType `json:"ticketType"`
+	CheckedInAt   *time.Time `json:"checkedInAt"`
 }
 
 type Event struct {
@@ -19,9 +24,10 @@ type Event struct {
 }
 
 type TicketType struct {
-	ID    string  `json:"id"`
-	Name  string  `json:"name"`
-	Price float64 `json:"price"`
+	ID                 string      `json:"id"`
+	Name               string      `json:"name"`
+	Price              money.Money `json:"price"`
+	IsTransferDisabled bool        `json:"isTransferDisabled"`
 }
 
 type ListTicketReq struct {
@@ -37,3 +43,145 @@ type ListTicketRes struct {
 	Ticket     []*Ticket          `json:"items"`
 	Pagination *paging.Pagination `json:"metadata"`
 }
+
+type User struct {
+	ID        string `json:"id"`
+	UserName  string `json:"userName"`
+	FullName  string `json:"fullName"`
+	AvatarUrl string `json:"avatarUrl"`
+}
+
+type CheckInReq struct {
+	Token    string `json:"token" validate:"required"`
+	DeviceId string `json:"deviceId"`
+}
+
+type CheckInRes struct {
+	ID            string     `json:"id"`
+	TicketNo      string     `json:"ticketNo"`
+	CustomerName  string     `json:"customerName"`
+	CustomerEmail string     `json:"customerEmail"`
+	EventId       string     `json:"eventId"`
+	TicketType    TicketType `json:"ticketType"`
+	CheckedInAt   *time.Time `json:"checkedInAt"`
+	CheckedInBy   *User      `json:"checkedInBy"`
+}
+
+// CheckInCounter is the live check-in progress of a ticket type
+type CheckInCounter struct {
+	EventId        string `json:"eventId"`
+	TicketTypeId   string `json:"ticketTypeId"`
+	TicketTypeName string `json:"ticketTypeName"`
+	Quantity       int64  `json:"quantity"`
+	Sold           int64  `json:"sold"`
+	CheckedIn      int64  `json:"checkedIn"`
+}
+
+type AddStaffReq struct {
+	UserId string `json:"userId" validate:"required"`
+}
+
+type EventStaff struct {
+	ID        string    `json:"id"`
+	EventId   string    `json:"eventId"`
+	UserId    string    `json:"userId"`
+	User      User      `json:"user"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ManifestTicket struct {
+	ID           string     `json:"id"`
+	Hash         string     `json:"hash"`
+	TicketTypeId string     `json:"ticketTypeId"`
+	CheckedInAt  *time.Time `json:"checkedInAt,omitempty"`
+}
+
+// Manifest lists the valid tickets of an event so door devices can check them in without connectivity
+type Manifest struct {
+	Version  int               `json:"version"`
+	EventId  string            `json:"eventId"`
+	IssuedAt time.Time         `json:"issuedAt"`
+	Tickets  []*ManifestTicket `json:"tickets"`
+}
+
+// ManifestRes carries no public key, devices verify the signature with the key pinned in them. The sync
+// token goes back with the scans made with the manifest.
+type ManifestRes struct {
+	Manifest  string `json:"manifest"`
+	Signature string `json:"signature"`
+	Algorithm string `json:"algorithm"`
+	SyncToken string `json:"syncToken"`
+}
+
+type OfflineScan struct {
+	TicketId  string    `json:"ticketId" validate:"required"`
+	DeviceId  string    `json:"deviceId"`
+	ScannedAt time.Time `json:"scannedAt" validate:"required"`
+}
+
+type SyncScansReq struct {
+	DeviceId  string         `json:"deviceId" validate:"required"`
+	SyncToken string         `json:"syncToken" validate:"required"`
+	Scans     []*OfflineScan `json:"scans" validate:"required,min=1,max=1000,dive"`
+}
+
+type ScanWinner struct {
+	DeviceId  string     `json:"deviceId"`
+	ScannedAt *time.Time `json:"scannedAt"`
+	ScannedBy *User      `json:"scannedBy"`
+}
+
+type ScanResult struct {
+	TicketId  string      `json:"ticketId"`
+	DeviceId  string      `json:"deviceId"`
+	ScannedAt time.Time   `json:"scannedAt"`
+	Status    string      `json:"status"`
+	Reason    string      `json:"reason,omitempty"`
+	Winner    *ScanWinner `json:"winner,omitempty"`
+}
+
+type SyncScansRes struct {
+	Accepted  int           `json:"accepted"`
+	Conflicts int           `json:"conflicts"`
+	Rejected  int           `json:"rejected"`
+	Results   []*ScanResult `json:"results"`
+}
+
+type CreateTransferReq struct {
+	Recipient string `json:"recipient" validate:"required"`
+}
+
+type TransferSettingReq struct {
+	IsTransferDisabled bool `json:"isTransferDisabled"`
+}
+
+type ListTransferReq struct {
+	Status  string `json:"-" form:"status" validate:"omitempty,oneof=Pending Accepted Declined Cancelled"`
+	Page    int64  `json:"-" form:"page"`
+	Limit   int64  `json:"-" form:"pageSize"`
+	TakeAll bool   `json:"-" form:"take_all"`
+}
+
+type TransferTicket struct {
+	ID         string     `json:"id"`
+	TicketNo   string     `json:"ticketNo"`
+	Event      Event      `json:"event"`
+	TicketType TicketType `json:"ticketType"`
+}
+
+type Transfer struct {
+	ID          string         `json:"id"`
+	TicketId    string         `json:"ticketId"`
+	Ticket      TransferTicket `json:"ticket"`
+	EventId     string         `json:"eventId"`
+	FromUser    User           `json:"fromUser"`
+	ToUser      User           `json:"toUser"`
+	Status      string         `json:"status"`
+	RespondedAt *time.Time     `json:"respondedAt"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
+type ListTransferRes struct {
+	Transfers  []*Transfer        `json:"items"`
+	Pagination *paging.Pagination `json:"metadata"`
+}
@@ -12,27 +12,36 @@ import (
 )
 
 type Ticket struct {
-	ID            string                 `json:"id" gorm:"unique;not null;index;primary_key"`
-	TicketNo      string                 `json:"ticketNo" gorm:"not null"`
-	CustomerName  string                 `json:"customerName" gorm:"not null"`
-	CustomerPhone string                 `json:"customerPhone" gorm:"not null"`
-	CustomerEmail string                 `json:"customerEmail" gorm:"not null"`
-	TicketTypeId  string                 `json:"ticketTypeId" gorm:"not null"`
-	TicketType    *modelEvent.TicketType `json:"ticketType" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	EventId       string                 `json:"eventId" gorm:"not null"`
-	Event         *modelEvent.Event      `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	UserId        string                 `json:"userId" gorm:"not null"`
-	User          *modelUser.User        `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	PaymentId     string                 `json:"paymentId" gorm:"not null"`
-	Payment       *modelPayment.Payment  `json:"payment" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	CreatedAt     time.Time              `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt     time.Time              `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt     gorm.DeletedAt         `json:"deletedAt" gorm:"index"`
+	ID                string                      `json:"id" gorm:"unique;not null;index;primary_key"`
+	TicketNo          string                      `json:"ticketNo" gorm:"not null"`
+	CustomerName      string                      `json:"customerName" gorm:"not null"`
+	CustomerPhone     string                      `json:"customerPhone" gorm:"not null"`
+	CustomerEmail     string                      `json:"customerEmail" gorm:"not null"`
+	TicketTypeId      string                      `json:"ticketTypeId" gorm:"not null"`
+	TicketType        *modelEvent.TicketType      `json:"ticketType" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	EventId           string                      `json:"eventId" gorm:"not null"`
+	Event             *modelEvent.Event           `json:"event" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OccurrenceId      *string                     `json:"occurrenceId" gorm:"index"`
+	Occurrence        *modelEvent.EventOccurrence `json:"occurrence" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	UserId            string                      `json:"userId" gorm:"not null"`
+	User              *modelUser.User             `json:"user" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PaymentId         string                      `json:"paymentId" gorm:"not null"`
+	Payment           *modelPayment.Payment       `json:"payment" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	QrSecret          string                      `json:"-"`
+	CheckedInAt       *time.Time                  `json:"checkedInAt"`
+	CheckedInById     *string                     `json:"checkedInById"`
+	CheckedInBy       *modelUser.User             `json:"checkedInBy" gorm:"foreignKey:CheckedInById;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CheckedInDeviceId *string                     `json:"checkedInDeviceId"`
+	RefundId          *string                     `json:"refundId" gorm:"index"`
+	CreatedAt         time.Time                   `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time                   `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt              `json:"deletedAt" gorm:"index"`
 }
 
 func (t *Ticket) BeforeCreate(tx *gorm.DB) error {
 	t.ID = uuid.New().String()
-	t.TicketNo = utils.GenerateCode("NO")
+	t.TicketNo = utils.GenerateSecureCode("NO")
+	t.QrSecret = utils.RandomHex(16)
 	return nil
 }
 
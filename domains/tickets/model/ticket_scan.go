@@ -0,0 +1,43 @@
+package model
+
+import (
+	modelUser "gohub/domains/users/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ScanSourceOnline  = "Online"
+	ScanSourceOffline = "Offline"
+
+	OnlineDeviceId = "online"
+
+	ScanStatusAccepted = "Accepted"
+	ScanStatusConflict = "Conflict"
+	ScanStatusRejected = "Rejected"
+)
+
+// TicketScan records every scan of a ticket at the door, the earliest one decides the check-in
+type TicketScan struct {
+	ID          string          `json:"id" gorm:"unique;not null;index;primary_key"`
+	TicketId    string          `json:"ticketId" gorm:"not null;uniqueIndex:idx_ticket_scans_ticket_device_time"`
+	Ticket      *Ticket         `json:"ticket" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	EventId     string          `json:"eventId" gorm:"not null;index"`
+	DeviceId    string          `json:"deviceId" gorm:"not null;uniqueIndex:idx_ticket_scans_ticket_device_time"`
+	ScannedAt   time.Time       `json:"scannedAt" gorm:"not null;uniqueIndex:idx_ticket_scans_ticket_device_time"`
+	ScannedById string          `json:"scannedById" gorm:"not null"`
+	ScannedBy   *modelUser.User `json:"scannedBy" gorm:"foreignKey:ScannedById;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Source      string          `json:"source" gorm:"not null"`
+	CreatedAt   time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+func (t *TicketScan) BeforeCreate(tx *gorm.DB) error {
+	t.ID = uuid.New().String()
+	return nil
+}
+
+func (TicketScan) TableName() string {
+	return "ticket_scans"
+}
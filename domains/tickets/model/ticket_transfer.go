@@ -0,0 +1,41 @@
+package model
+
+import (
+	modelUser "gohub/domains/users/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	TransferStatusPending   = "Pending"
+	TransferStatusAccepted  = "Accepted"
+	TransferStatusDeclined  = "Declined"
+	TransferStatusCancelled = "Cancelled"
+)
+
+// TicketTransfer is a request to hand a ticket over to another user, the rows are kept as the transfer history
+type TicketTransfer struct {
+	ID          string          `json:"id" gorm:"unique;not null;index;primary_key"`
+	TicketId    string          `json:"ticketId" gorm:"not null;index"`
+	Ticket      *Ticket         `json:"ticket" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	EventId     string          `json:"eventId" gorm:"not null;index"`
+	FromUserId  string          `json:"fromUserId" gorm:"not null"`
+	FromUser    *modelUser.User `json:"fromUser" gorm:"foreignKey:FromUserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ToUserId    string          `json:"toUserId" gorm:"not null;index"`
+	ToUser      *modelUser.User `json:"toUser" gorm:"foreignKey:ToUserId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Status      string          `json:"status" gorm:"not null;default:'Pending'"`
+	RespondedAt *time.Time      `json:"respondedAt"`
+	CreatedAt   time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+func (t *TicketTransfer) BeforeCreate(tx *gorm.DB) error {
+	t.ID = uuid.New().String()
+	return nil
+}
+
+func (TicketTransfer) TableName() string {
+	return "ticket_transfers"
+}
@@ -1,10 +1,12 @@
 package http
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"gohub/domains/tickets/dto"
 	"gohub/domains/tickets/service"
 	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
 	"gohub/pkg/response"
 	"gohub/pkg/utils"
 	"net/http"
@@ -51,3 +53,542 @@ func (h *TicketHandler) GetTicketByCreated(c *gin.Context) {
 	res.Pagination = pagination
 	response.JSON(c, http.StatusOK, res)
 }
+
+//		@Summary	 Retrieve the QR code of a ticket
+//	 @Description Renders the signed check-in code of a ticket owned by the authenticated user as a PNG image.
+//		@Tags		 Tickets
+//		@Produce	 png
+//		@Param		 id	path	string	true	"Ticket ID"
+//		@Success	 200	{file}		binary				"QR code image"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User does not own the ticket"
+//		@Failure	 404	{object}	response.Response	"Not Found - Ticket with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/{id}/qr [get]
+func (h *TicketHandler) GetTicketQr(c *gin.Context) {
+	png, err := h.service.GetTicketQr(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to get ticket qr: ", err)
+		switch err.Error() {
+		case messages.TicketNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.TicketNotFound)
+		case messages.NotTicketOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotTicketOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+//		@Summary	 Download a ticket
+//	 @Description Renders a ticket owned by the authenticated user as a printable PDF with its QR code.
+//		@Tags		 Tickets
+//		@Produce	 application/pdf
+//		@Param		 id	path	string	true	"Ticket ID"
+//		@Success	 200	{file}		binary				"Ticket PDF"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User does not own the ticket"
+//		@Failure	 404	{object}	response.Response	"Not Found - Ticket with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/{id}/pdf [get]
+func (h *TicketHandler) GetTicketPdf(c *gin.Context) {
+	document, err := h.service.GetTicketPdf(c, c.GetString("userId"), c.Param("id"))
+	if err != nil {
+		logger.Error("Failed to get ticket pdf: ", err)
+		switch err.Error() {
+		case messages.TicketNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.TicketNotFound)
+		case messages.NotTicketOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotTicketOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=ticket-%s.pdf", c.Param("id")))
+	c.Data(http.StatusOK, "application/pdf", document)
+}
+
+//		@Summary	 Check in a ticket
+//	 @Description Verifies a scanned ticket code and admits the ticket, a ticket that was already admitted is rejected along with who scanned it and when. Reserved to the organizer and the staff of the event.
+//		@Tags		 Tickets
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 params	body	dto.CheckInReq	true	"Scanned code"
+//		@Success	 200	{object}	response.Response	"Ticket checked in successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid ticket code"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not staff of the event"
+//		@Failure	 409	{object}	response.Response	"Conflict - Ticket already checked in"
+//		@Failure	 422	{object}	response.Response	"Unprocessable Entity - Ticket being refunded, cancelled or for another date"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/check-in [post]
+func (h *TicketHandler) CheckIn(c *gin.Context) {
+	var req dto.CheckInReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	ticket, err := h.service.CheckIn(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to check in ticket: ", err)
+		switch err.Error() {
+		case messages.InvalidTicketToken:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidTicketToken)
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventStaff:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventStaff)
+		case messages.TicketAlreadyCheckedIn:
+			var res dto.CheckInRes
+			utils.MapStruct(&res, &ticket)
+			response.ErrorWithData(c, http.StatusConflict, err, messages.TicketAlreadyCheckedIn, res)
+		case messages.TicketBeingRefunded, messages.TicketEventCancelled, messages.TicketDateCancelled, messages.TicketForOtherDate:
+			response.Error(c, http.StatusUnprocessableEntity, err, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.CheckInRes
+	utils.MapStruct(&res, &ticket)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the check-in counters of an event
+//	 @Description Returns the sold and checked in tickets of every ticket type of the event, updates are pushed live through the check_in_counter socket event.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the counters"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not staff of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/events/{eventId}/check-ins [get]
+func (h *TicketHandler) GetCheckInCounters(c *gin.Context) {
+	counters, err := h.service.GetCheckInCounters(c, c.GetString("userId"), c.Param("eventId"))
+	if err != nil {
+		logger.Error("Failed to get check-in counters: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventStaff:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventStaff)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, counters)
+}
+
+//		@Summary	 Retrieve the staff of an event
+//	 @Description Lists the users allowed to check in tickets for the event, reserved to its organizer.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the staff"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/events/{eventId}/staffs [get]
+func (h *TicketHandler) GetEventStaffs(c *gin.Context) {
+	staffs, err := h.service.GetEventStaffs(c, c.GetString("userId"), c.Param("eventId"))
+	if err != nil {
+		logger.Error("Failed to get event staffs: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res []*dto.EventStaff
+	utils.MapStruct(&res, &staffs)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Add a staff member to an event
+//	 @Description Allows a user to check in tickets for the event, reserved to its organizer.
+//		@Tags		 Tickets
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 params	body	dto.AddStaffReq	true	"Staff member"
+//		@Success	 200	{object}	response.Response	"Staff member added successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event or user not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - User is already staff of the event"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/events/{eventId}/staffs [post]
+func (h *TicketHandler) AddEventStaff(c *gin.Context) {
+	var req dto.AddStaffReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	staff, err := h.service.AddEventStaff(c, c.GetString("userId"), c.Param("eventId"), &req)
+	if err != nil {
+		logger.Error("Failed to add event staff: ", err)
+		switch err.Error() {
+		case messages.EventNotFound, messages.UserNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		case messages.StaffAlreadyExists:
+			response.Error(c, http.StatusConflict, err, messages.StaffAlreadyExists)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.EventStaff
+	utils.MapStruct(&res, &staff)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Remove a staff member from an event
+//	 @Description Revokes the check-in rights of a user for the event, reserved to its organizer.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 userId	path	string	true	"User ID"
+//		@Success	 200	{object}	response.Response	"Staff member removed successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event or staff member not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/events/{eventId}/staffs/{userId} [delete]
+func (h *TicketHandler) RemoveEventStaff(c *gin.Context) {
+	err := h.service.RemoveEventStaff(c, c.GetString("userId"), c.Param("eventId"), c.Param("userId"))
+	if err != nil {
+		logger.Error("Failed to remove event staff: ", err)
+		switch err.Error() {
+		case messages.EventNotFound, messages.StaffNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, "Remove staff successfully")
+}
+
+//		@Summary	 Export the offline check-in manifest of an event
+//	 @Description Returns the base64url encoded list of the valid tickets of the event with the hash of their codes, signed with Ed25519 so door devices can verify it offline with the public key pinned in them. The sync token is sent back with the scans made with the manifest. Reserved to the organizer and the staff of the event.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Success	 200	{object}	response.Response	"Successfully exported the manifest"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not staff of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/events/{eventId}/manifest [get]
+func (h *TicketHandler) ExportManifest(c *gin.Context) {
+	manifest, err := h.service.ExportManifest(c, c.GetString("userId"), c.Param("eventId"))
+	if err != nil {
+		logger.Error("Failed to export manifest: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventStaff:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventStaff)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, manifest)
+}
+
+//		@Summary	 Sync offline scans
+//	 @Description Stores a batch of scans made offline and reports the outcome of each, the earliest scan of a ticket wins the check-in and the smallest device id breaks ties. Scans made before the manifest of the sync token was exported, or too long ago, are rejected. Batches can safely be sent again.
+//		@Tags		 Tickets
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 params	body	dto.SyncScansReq	true	"Offline scans"
+//		@Success	 200	{object}	response.Response	"Scans synced successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - Invalid parameters"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not staff of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/events/{eventId}/sync [post]
+func (h *TicketHandler) SyncScans(c *gin.Context) {
+	var req dto.SyncScansReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	res, err := h.service.SyncScans(c, c.GetString("userId"), c.Param("eventId"), &req)
+	if err != nil {
+		logger.Error("Failed to sync scans: ", err)
+		switch err.Error() {
+		case messages.InvalidSyncToken:
+			response.Error(c, http.StatusBadRequest, err, messages.InvalidSyncToken)
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventStaff:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventStaff)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Transfer a ticket
+//	 @Description Offers a ticket of the authenticated user to another user found by email or username, the ticket moves once the recipient accepts.
+//		@Tags		 Tickets
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 id	path	string	true	"Ticket ID"
+//		@Param		 params	body	dto.CreateTransferReq	true	"Recipient email or username"
+//		@Success	 200	{object}	response.Response	"Transfer created successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The ticket cannot be transferred"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User does not own the ticket"
+//		@Failure	 404	{object}	response.Response	"Not Found - Ticket or recipient not found"
+//		@Failure	 409	{object}	response.Response	"Conflict - The ticket already has a pending transfer"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/{id}/transfers [post]
+func (h *TicketHandler) CreateTransfer(c *gin.Context) {
+	var req dto.CreateTransferReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	transfer, err := h.service.CreateTransfer(c, c.GetString("userId"), c.Param("id"), &req)
+	if err != nil {
+		logger.Error("Failed to create transfer: ", err)
+		switch err.Error() {
+		case messages.TicketNotFound, messages.UserNotFound:
+			response.Error(c, http.StatusNotFound, err, err.Error())
+		case messages.NotTicketOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotTicketOwner)
+		case messages.TicketAlreadyUsed, messages.TicketBeingRefunded, messages.TransferDisabled, messages.CannotTransferToSelf:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		case messages.TransferPending:
+			response.Error(c, http.StatusConflict, err, messages.TransferPending)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Transfer
+	utils.MapStruct(&res, &transfer)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the transfers of the current user
+//	 @Description Fetches a paginated list of the transfers the authenticated user sent or received.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 status	query	string	false	"Pending, Accepted, Declined or Cancelled"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the transfers"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/transfers [get]
+func (h *TicketHandler) GetTransfers(c *gin.Context) {
+	var req dto.ListTransferReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	transfers, pagination, err := h.service.GetTransfers(c, c.GetString("userId"), &req)
+	if err != nil {
+		logger.Error("Failed to get transfers: ", err)
+		response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		return
+	}
+
+	var res dto.ListTransferRes
+	utils.MapStruct(&res.Transfers, &transfers)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Accept a transfer
+//	 @Description Moves the ticket to the authenticated user and re-issues its QR code, the code of the sender stops working.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 transferId	path	string	true	"Transfer ID"
+//		@Success	 200	{object}	response.Response	"Transfer accepted successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The transfer can no longer be accepted"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the recipient"
+//		@Failure	 404	{object}	response.Response	"Not Found - Transfer with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/transfers/{transferId}/accept [patch]
+func (h *TicketHandler) AcceptTransfer(c *gin.Context) {
+	transfer, err := h.service.AcceptTransfer(c, c.GetString("userId"), c.Param("transferId"))
+	h.transferResponse(c, transfer, err)
+}
+
+//		@Summary	 Decline a transfer
+//	 @Description Declines a transfer offered to the authenticated user, the ticket stays with the sender.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 transferId	path	string	true	"Transfer ID"
+//		@Success	 200	{object}	response.Response	"Transfer declined successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The transfer is no longer pending"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the recipient"
+//		@Failure	 404	{object}	response.Response	"Not Found - Transfer with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/transfers/{transferId}/decline [patch]
+func (h *TicketHandler) DeclineTransfer(c *gin.Context) {
+	transfer, err := h.service.DeclineTransfer(c, c.GetString("userId"), c.Param("transferId"))
+	h.transferResponse(c, transfer, err)
+}
+
+//		@Summary	 Cancel a transfer
+//	 @Description Withdraws a pending transfer sent by the authenticated user.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 transferId	path	string	true	"Transfer ID"
+//		@Success	 200	{object}	response.Response	"Transfer cancelled successfully"
+//		@Failure	 400	{object}	response.Response	"Bad Request - The transfer is no longer pending"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the sender"
+//		@Failure	 404	{object}	response.Response	"Not Found - Transfer with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/transfers/{transferId} [delete]
+func (h *TicketHandler) CancelTransfer(c *gin.Context) {
+	transfer, err := h.service.CancelTransfer(c, c.GetString("userId"), c.Param("transferId"))
+	h.transferResponse(c, transfer, err)
+}
+
+func (h *TicketHandler) transferResponse(c *gin.Context, transfer interface{}, err error) {
+	if err != nil {
+		logger.Error("Failed to respond to transfer: ", err)
+		switch err.Error() {
+		case messages.TransferNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.TransferNotFound)
+		case messages.NotTransferRecipient, messages.NotTransferSender:
+			response.Error(c, http.StatusForbidden, err, err.Error())
+		case messages.TransferNotPending, messages.TransferDisabled, messages.TicketAlreadyUsed, messages.TicketBeingRefunded:
+			response.Error(c, http.StatusBadRequest, err, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.Transfer
+	utils.MapStruct(&res, transfer)
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Retrieve the transfer log of an event
+//	 @Description Fetches a paginated list of the ticket transfers of the event, reserved to its organizer.
+//		@Tags		 Tickets
+//		@Produce	 json
+//		@Param		 eventId	path	string	true	"Event ID"
+//		@Param		 status	query	string	false	"Pending, Accepted, Declined or Cancelled"
+//		@Success	 200	{object}	response.Response	"Successfully retrieved the transfers"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Event with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/events/{eventId}/transfers [get]
+func (h *TicketHandler) GetEventTransfers(c *gin.Context) {
+	var req dto.ListTransferReq
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Error("Failed to parse request query: ", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	transfers, pagination, err := h.service.GetEventTransfers(c, c.GetString("userId"), c.Param("eventId"), &req)
+	if err != nil {
+		logger.Error("Failed to get event transfers: ", err)
+		switch err.Error() {
+		case messages.EventNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.EventNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.ListTransferRes
+	utils.MapStruct(&res.Transfers, &transfers)
+	res.Pagination = pagination
+	response.JSON(c, http.StatusOK, res)
+}
+
+//		@Summary	 Enable or disable transfers of a ticket type
+//	 @Description Lets the organizer turn ticket transfers of a ticket type on or off, pending transfers can no longer be accepted once off.
+//		@Tags		 Tickets
+//		@Accept		 json
+//		@Produce	 json
+//		@Param		 ticketTypeId	path	string	true	"Ticket type ID"
+//		@Param		 params	body	dto.TransferSettingReq	true	"Transfer setting"
+//		@Success	 200	{object}	response.Response	"Ticket type updated successfully"
+//		@Failure	 401	{object}	response.Response	"Unauthorized - User not authenticated"
+//		@Failure	 403	{object}	response.Response	"Forbidden - User is not the organizer of the event"
+//		@Failure	 404	{object}	response.Response	"Not Found - Ticket type with the specified ID not found"
+//		@Failure	 500	{object}	response.Response	"Internal Server Error - An error occurred while processing the request"
+//		@Router		 /api/v1/tickets/ticket-types/{ticketTypeId}/transfer [patch]
+func (h *TicketHandler) UpdateTransferSetting(c *gin.Context) {
+	var req dto.TransferSettingReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to get body", err)
+		response.Error(c, http.StatusBadRequest, err, "Invalid parameters")
+		return
+	}
+
+	ticketType, err := h.service.UpdateTransferSetting(c, c.GetString("userId"), c.Param("ticketTypeId"), &req)
+	if err != nil {
+		logger.Error("Failed to update transfer setting: ", err)
+		switch err.Error() {
+		case messages.TicketTypeNotFound:
+			response.Error(c, http.StatusNotFound, err, messages.TicketTypeNotFound)
+		case messages.NotEventOwner:
+			response.Error(c, http.StatusForbidden, err, messages.NotEventOwner)
+		default:
+			response.Error(c, http.StatusInternalServerError, err, "Something went wrong")
+		}
+		return
+	}
+
+	var res dto.TicketType
+	utils.MapStruct(&res, &ticketType)
+	response.JSON(c, http.StatusOK, res)
+}
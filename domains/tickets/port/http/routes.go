@@ -6,12 +6,13 @@ import (
 	"gohub/domains/tickets/repository"
 	"gohub/domains/tickets/service"
 	"gohub/internal/libs/validation"
+	socketio "gohub/internal/libs/websocket"
 	middleware "gohub/pkg/middleware"
 )
 
-func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.Validation) {
+func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.Validation, notifier socketio.Notifier) {
 	TicketRepository := repository.NewTicketRepository(sqlDB)
-	TicketService := service.NewTicketService(validator, TicketRepository)
+	TicketService := service.NewTicketService(validator, TicketRepository, notifier)
 	TicketHandler := NewTicketHandler(TicketService)
 
 	authMiddleware := middleware.JWTAuth()
@@ -19,5 +20,21 @@ func Routes(r *gin.RouterGroup, sqlDB database.IDatabase, validator validation.V
 	expenseRoute := r.Group("/tickets").Use(authMiddleware)
 	{
 		expenseRoute.GET("/get-created-tickets", TicketHandler.GetTicketByCreated)
+		expenseRoute.GET("/:id/qr", TicketHandler.GetTicketQr)
+		expenseRoute.GET("/:id/pdf", TicketHandler.GetTicketPdf)
+		expenseRoute.POST("/:id/transfers", TicketHandler.CreateTransfer)
+		expenseRoute.GET("/transfers", TicketHandler.GetTransfers)
+		expenseRoute.PATCH("/transfers/:transferId/accept", TicketHandler.AcceptTransfer)
+		expenseRoute.PATCH("/transfers/:transferId/decline", TicketHandler.DeclineTransfer)
+		expenseRoute.DELETE("/transfers/:transferId", TicketHandler.CancelTransfer)
+		expenseRoute.PATCH("/ticket-types/:ticketTypeId/transfer", TicketHandler.UpdateTransferSetting)
+		expenseRoute.GET("/events/:eventId/transfers", TicketHandler.GetEventTransfers)
+		expenseRoute.POST("/check-in", TicketHandler.CheckIn)
+		expenseRoute.GET("/events/:eventId/check-ins", TicketHandler.GetCheckInCounters)
+		expenseRoute.GET("/events/:eventId/manifest", TicketHandler.ExportManifest)
+		expenseRoute.POST("/events/:eventId/sync", TicketHandler.SyncScans)
+		expenseRoute.GET("/events/:eventId/staffs", TicketHandler.GetEventStaffs)
+		expenseRoute.POST("/events/:eventId/staffs", TicketHandler.AddEventStaff)
+		expenseRoute.DELETE("/events/:eventId/staffs/:userId", TicketHandler.RemoveEventStaff)
 	}
 }
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"gohub/configs"
+	"gohub/database"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/tickets/dto"
+	"gohub/domains/tickets/model"
+	modelUser "gohub/domains/users/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (t *TicketRepository) GetTicketById(ctx context.Context, id string) (*model.Ticket, error) {
+	var ticket model.Ticket
+	query := database.NewQuery("id = ?", id)
+	if err := t.db.FindOne(ctx, &ticket, database.WithQuery(query), database.WithPreload([]string{"Event", "Occurrence", "TicketType", "CheckedInBy"})); err != nil {
+		return nil, err
+	}
+
+	return &ticket, nil
+}
+
+func (t *TicketRepository) UpdateTicket(ctx context.Context, ticket *model.Ticket) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return t.db.GetDB().WithContext(ctx).Omit(clause.Associations).Save(ticket).Error
+}
+
+// CheckIn marks the ticket as checked in unless it already is and records the scan with it, the check-ins that
+// ResolveCheckIns rebuilds from the scans must all have one. It reports whether this call did the check-in.
+func (t *TicketRepository) CheckIn(ctx context.Context, scan *model.TicketScan) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	checkedIn := false
+	err := t.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Ticket{}).
+			Where("id = ? AND checked_in_at IS NULL", scan.TicketId).
+			Updates(map[string]interface{}{
+				"checked_in_at":        scan.ScannedAt,
+				"checked_in_by_id":     scan.ScannedById,
+				"checked_in_device_id": scan.DeviceId,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(scan).Error; err != nil {
+			return err
+		}
+
+		checkedIn = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return checkedIn, nil
+}
+
+func (t *TicketRepository) GetEventById(ctx context.Context, id string) (*modelEvent.Event, error) {
+	var event modelEvent.Event
+	if err := t.db.FindById(ctx, id, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (t *TicketRepository) GetUserById(ctx context.Context, id string) (*modelUser.User, error) {
+	var user modelUser.User
+	if err := t.db.FindById(ctx, id, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (t *TicketRepository) IsEventStaff(ctx context.Context, eventId string, userId string) (bool, error) {
+	var total int64
+	query := database.NewQuery("event_id = ? AND user_id = ?", eventId, userId)
+	if err := t.db.Count(ctx, &modelEvent.EventStaff{}, &total, database.WithQuery(query)); err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+func (t *TicketRepository) GetEventStaffs(ctx context.Context, eventId string) ([]*modelEvent.EventStaff, error) {
+	var staffs []*modelEvent.EventStaff
+	query := database.NewQuery("event_id = ?", eventId)
+	if err := t.db.Find(ctx, &staffs, database.WithQuery(query), database.WithOrder("created_at ASC"), database.WithPreload([]string{"User"})); err != nil {
+		return nil, err
+	}
+
+	return staffs, nil
+}
+
+func (t *TicketRepository) CreateEventStaff(ctx context.Context, staff *modelEvent.EventStaff) error {
+	return t.db.Create(ctx, staff)
+}
+
+func (t *TicketRepository) DeleteEventStaff(ctx context.Context, eventId string, userId string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return t.db.GetDB().WithContext(ctx).Where("event_id = ? AND user_id = ?", eventId, userId).Delete(&modelEvent.EventStaff{}).Error
+}
+
+// GetCheckInCounters returns the sold and checked in tickets of every ticket type of the event, or only of the given ticket type
+func (t *TicketRepository) GetCheckInCounters(ctx context.Context, eventId string, ticketTypeId string) ([]*dto.CheckInCounter, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var counters []*dto.CheckInCounter
+	err := t.db.GetDB().WithContext(ctx).Raw(`
+		SELECT
+			ticket_types.event_id,
+			ticket_types.id AS ticket_type_id,
+			ticket_types.name AS ticket_type_name,
+			ticket_types.quantity,
+			COUNT(tickets.id) AS sold,
+			COUNT(tickets.checked_in_at) AS checked_in
+		FROM ticket_types
+		LEFT JOIN tickets ON tickets.ticket_type_id = ticket_types.id AND tickets.deleted_at IS NULL
+		WHERE ticket_types.event_id = @eventId AND ticket_types.deleted_at IS NULL
+			AND (@ticketTypeId = '' OR ticket_types.id = @ticketTypeId)
+		GROUP BY ticket_types.id
+		ORDER BY ticket_types.created_at ASC
+	`, map[string]interface{}{"eventId": eventId, "ticketTypeId": ticketTypeId}).Scan(&counters).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counters, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"gohub/configs"
+	"gohub/database"
+	"gohub/domains/tickets/model"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetEventTickets returns the tickets of the event that can still be checked in, tickets being refunded are left out
+func (t *TicketRepository) GetEventTickets(ctx context.Context, eventId string) ([]*model.Ticket, error) {
+	var tickets []*model.Ticket
+	query := database.NewQuery("event_id = ? AND refund_id IS NULL", eventId)
+	if err := t.db.Find(ctx, &tickets, database.WithQuery(query), database.WithOrder("created_at ASC")); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+func (t *TicketRepository) GetTicketsByIds(ctx context.Context, eventId string, ids []string) ([]*model.Ticket, error) {
+	var tickets []*model.Ticket
+	query := database.NewQuery("event_id = ? AND id IN ?", eventId, ids)
+	if err := t.db.Find(ctx, &tickets, database.WithQuery(query), database.WithPreload([]string{"CheckedInBy"})); err != nil {
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+// CreateScans stores the scans, a scan already synced by the same device is skipped so batches can be retried
+func (t *TicketRepository) CreateScans(ctx context.Context, scans []*model.TicketScan) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return t.db.GetDB().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&scans).Error
+}
+
+// ResolveCheckIns sets the check-in of each ticket to its earliest scan, ties go to the smallest device id,
+// so the outcome does not depend on the order devices sync in. A ticket refunded meanwhile is not checked in.
+func (t *TicketRepository) ResolveCheckIns(ctx context.Context, ticketIds []string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return t.db.GetDB().WithContext(ctx).Exec(`
+		UPDATE tickets
+		SET checked_in_at = winners.scanned_at,
+			checked_in_by_id = winners.scanned_by_id,
+			checked_in_device_id = winners.device_id,
+			updated_at = NOW()
+		FROM (
+			SELECT DISTINCT ON (ticket_id) ticket_id, scanned_at, scanned_by_id, device_id
+			FROM ticket_scans
+			WHERE ticket_id IN @ticketIds
+			ORDER BY ticket_id, scanned_at ASC, device_id ASC
+		) AS winners
+		WHERE tickets.id = winners.ticket_id AND tickets.refund_id IS NULL
+	`, map[string]interface{}{"ticketIds": ticketIds}).Error
+}
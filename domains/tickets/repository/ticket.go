@@ -4,13 +4,39 @@ import (
 	"context"
 	"gohub/configs"
 	"gohub/database"
+	modelEvent "gohub/domains/events/model"
 	"gohub/domains/tickets/dto"
 	"gohub/domains/tickets/model"
+	modelUser "gohub/domains/users/model"
 	"gohub/pkg/paging"
 )
 
 type ITicketRepository interface {
 	GetCreatedTickets(ctx context.Context, userId string, req *dto.ListTicketReq) ([]*model.Ticket, *paging.Pagination, error)
+	GetTicketById(ctx context.Context, id string) (*model.Ticket, error)
+	UpdateTicket(ctx context.Context, ticket *model.Ticket) error
+	CheckIn(ctx context.Context, scan *model.TicketScan) (bool, error)
+	GetEventById(ctx context.Context, id string) (*modelEvent.Event, error)
+	GetUserById(ctx context.Context, id string) (*modelUser.User, error)
+	IsEventStaff(ctx context.Context, eventId string, userId string) (bool, error)
+	GetEventStaffs(ctx context.Context, eventId string) ([]*modelEvent.EventStaff, error)
+	CreateEventStaff(ctx context.Context, staff *modelEvent.EventStaff) error
+	DeleteEventStaff(ctx context.Context, eventId string, userId string) error
+	GetCheckInCounters(ctx context.Context, eventId string, ticketTypeId string) ([]*dto.CheckInCounter, error)
+	GetEventTickets(ctx context.Context, eventId string) ([]*model.Ticket, error)
+	GetTicketsByIds(ctx context.Context, eventId string, ids []string) ([]*model.Ticket, error)
+	CreateScans(ctx context.Context, scans []*model.TicketScan) error
+	ResolveCheckIns(ctx context.Context, ticketIds []string) error
+	GetUserByEmailOrUserName(ctx context.Context, identifier string) (*modelUser.User, error)
+	GetTicketTypeById(ctx context.Context, id string) (*modelEvent.TicketType, error)
+	SetTransferDisabled(ctx context.Context, ticketTypeId string, disabled bool) error
+	HasPendingTransfer(ctx context.Context, ticketId string) (bool, error)
+	CreateTransfer(ctx context.Context, transfer *model.TicketTransfer) error
+	GetTransferById(ctx context.Context, id string) (*model.TicketTransfer, error)
+	RespondTransfer(ctx context.Context, id string, status string) error
+	AcceptTransfer(ctx context.Context, transfer *model.TicketTransfer, recipient *modelUser.User, qrSecret string) error
+	GetTransfersByUser(ctx context.Context, userId string, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error)
+	GetTransfersByEvent(ctx context.Context, eventId string, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error)
 }
 
 type TicketRepository struct {
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	"gohub/database"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/tickets/dto"
+	"gohub/domains/tickets/model"
+	modelUser "gohub/domains/users/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (t *TicketRepository) GetUserByEmailOrUserName(ctx context.Context, identifier string) (*modelUser.User, error) {
+	var user modelUser.User
+	query := database.NewQuery("email = ? OR user_name = ?", identifier, identifier)
+	if err := t.db.FindOne(ctx, &user, database.WithQuery(query)); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (t *TicketRepository) GetTicketTypeById(ctx context.Context, id string) (*modelEvent.TicketType, error) {
+	var ticketType modelEvent.TicketType
+	query := database.NewQuery("id = ?", id)
+	if err := t.db.FindOne(ctx, &ticketType, database.WithQuery(query), database.WithPreload([]string{"Event"})); err != nil {
+		return nil, err
+	}
+
+	return &ticketType, nil
+}
+
+func (t *TicketRepository) SetTransferDisabled(ctx context.Context, ticketTypeId string, disabled bool) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return t.db.GetDB().WithContext(ctx).
+		Model(&modelEvent.TicketType{}).
+		Where("id = ?", ticketTypeId).
+		Update("is_transfer_disabled", disabled).Error
+}
+
+func (t *TicketRepository) HasPendingTransfer(ctx context.Context, ticketId string) (bool, error) {
+	var total int64
+	query := database.NewQuery("ticket_id = ? AND status = ?", ticketId, model.TransferStatusPending)
+	if err := t.db.Count(ctx, &model.TicketTransfer{}, &total, database.WithQuery(query)); err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+func (t *TicketRepository) CreateTransfer(ctx context.Context, transfer *model.TicketTransfer) error {
+	return t.db.Create(ctx, transfer)
+}
+
+func (t *TicketRepository) GetTransferById(ctx context.Context, id string) (*model.TicketTransfer, error) {
+	var transfer model.TicketTransfer
+	query := database.NewQuery("id = ?", id)
+	if err := t.db.FindOne(
+		ctx,
+		&transfer,
+		database.WithQuery(query),
+		database.WithPreload([]string{"Ticket", "Ticket.Event", "Ticket.TicketType", "FromUser", "ToUser"}),
+	); err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// RespondTransfer moves a pending transfer to the given status, it fails if the transfer was answered meanwhile
+func (t *TicketRepository) RespondTransfer(ctx context.Context, id string, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return respondTransfer(t.db.GetDB().WithContext(ctx), id, status)
+}
+
+// AcceptTransfer hands the ticket over to the recipient with a new QR secret, so the code of the sender stops working.
+// A ticket checked in or reserved by a refund in the meantime stays with the sender.
+func (t *TicketRepository) AcceptTransfer(ctx context.Context, transfer *model.TicketTransfer, recipient *modelUser.User, qrSecret string) error {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	return t.db.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := respondTransfer(tx, transfer.ID, model.TransferStatusAccepted); err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.Ticket{}).
+			Where("id = ? AND user_id = ? AND checked_in_at IS NULL AND refund_id IS NULL", transfer.TicketId, transfer.FromUserId).
+			Updates(map[string]interface{}{
+				"user_id":        recipient.ID,
+				"customer_name":  recipient.FullName,
+				"customer_email": recipient.Email,
+				"customer_phone": recipient.PhoneNumber,
+				"qr_secret":      qrSecret,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			var refunding int64
+			if err := tx.Model(&model.Ticket{}).Where("id = ? AND refund_id IS NOT NULL", transfer.TicketId).Count(&refunding).Error; err != nil {
+				return err
+			}
+
+			if refunding > 0 {
+				return errors.New(messages.TicketBeingRefunded)
+			}
+
+			return errors.New(messages.TicketAlreadyUsed)
+		}
+
+		return nil
+	})
+}
+
+func respondTransfer(db *gorm.DB, id string, status string) error {
+	result := db.Model(&model.TicketTransfer{}).
+		Where("id = ? AND status = ?", id, model.TransferStatusPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New(messages.TransferNotPending)
+	}
+
+	return nil
+}
+
+func (t *TicketRepository) GetTransfersByUser(ctx context.Context, userId string, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error) {
+	queryString := "(from_user_id = ? OR to_user_id = ?)"
+	args := []interface{}{userId, userId}
+
+	if req.Status != "" {
+		queryString += " AND status = ?"
+		args = append(args, req.Status)
+	}
+
+	return t.getTransfers(ctx, database.NewQuery(queryString, args...), req)
+}
+
+func (t *TicketRepository) GetTransfersByEvent(ctx context.Context, eventId string, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error) {
+	queryString := "event_id = ?"
+	args := []interface{}{eventId}
+
+	if req.Status != "" {
+		queryString += " AND status = ?"
+		args = append(args, req.Status)
+	}
+
+	return t.getTransfers(ctx, database.NewQuery(queryString, args...), req)
+}
+
+func (t *TicketRepository) getTransfers(ctx context.Context, query database.Query, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, configs.DatabaseTimeout)
+	defer cancel()
+
+	var total int64
+	if err := t.db.Count(ctx, &model.TicketTransfer{}, &total, database.WithQuery(query)); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := paging.NewPagination(req.Page, req.Limit, total)
+
+	if req.TakeAll {
+		pagination.PageSize = total
+	}
+
+	var transfers []*model.TicketTransfer
+	if err := t.db.Find(
+		ctx,
+		&transfers,
+		database.WithQuery(query),
+		database.WithLimit(int(pagination.PageSize)),
+		database.WithOffset(int(pagination.Skip)),
+		database.WithOrder("created_at DESC"),
+		database.WithPreload([]string{"Ticket", "Ticket.Event", "Ticket.TicketType", "FromUser", "ToUser"}),
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return transfers, pagination, nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/tickets/dto"
+	"gohub/domains/tickets/model"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/messages"
+	"gohub/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// ticketToken signs the ticket id with its QR secret, rotating the secret invalidates every code issued before
+func (s *TicketService) ticketToken(ticket *model.Ticket) string {
+	return ticket.ID + "." + utils.Sign(s.secret, []byte(ticket.ID+"."+ticket.QrSecret))
+}
+
+// verifyToken returns the ticket a token was issued for
+func (s *TicketService) verifyToken(ctx context.Context, token string) (*model.Ticket, error) {
+	ticketId, signature, found := strings.Cut(strings.TrimSpace(token), ".")
+	if !found {
+		return nil, errors.New(messages.InvalidTicketToken)
+	}
+
+	ticket, err := s.repoTicket.GetTicketById(ctx, ticketId)
+	if err != nil || ticket.QrSecret == "" {
+		return nil, errors.New(messages.InvalidTicketToken)
+	}
+
+	if !utils.VerifySignature(s.secret, []byte(ticket.ID+"."+ticket.QrSecret), signature) {
+		return nil, errors.New(messages.InvalidTicketToken)
+	}
+
+	return ticket, nil
+}
+
+// GetTicketQr renders the signed check-in code of the ticket as a PNG
+func (s *TicketService) GetTicketQr(ctx context.Context, userId string, id string) ([]byte, error) {
+	ticket, err := s.repoTicket.GetTicketById(ctx, id)
+	if err != nil {
+		return nil, errors.New(messages.TicketNotFound)
+	}
+
+	if ticket.UserId != userId {
+		return nil, errors.New(messages.NotTicketOwner)
+	}
+
+	// Tickets sold before signed codes existed get their secret on first use
+	if ticket.QrSecret == "" {
+		ticket.QrSecret = utils.RandomHex(16)
+		if err := s.repoTicket.UpdateTicket(ctx, ticket); err != nil {
+			return nil, err
+		}
+	}
+
+	return qrcode.Encode(s.ticketToken(ticket), qrcode.Medium, configs.TicketQrSize)
+}
+
+// CheckIn admits the ticket behind a scanned code, a ticket that was already admitted is returned with the error
+// so the door staff can see who scanned it and when
+func (s *TicketService) CheckIn(ctx context.Context, userId string, req *dto.CheckInReq) (*model.Ticket, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.verifyToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.checkInStaff(ctx, userId, ticket.EventId); err != nil {
+		return nil, err
+	}
+
+	if err := admissible(ticket, time.Now()); err != nil {
+		return nil, err
+	}
+
+	deviceId := req.DeviceId
+	if deviceId == "" {
+		deviceId = model.OnlineDeviceId
+	}
+
+	checkedIn, err := s.repoTicket.CheckIn(ctx, &model.TicketScan{
+		TicketId:    ticket.ID,
+		EventId:     ticket.EventId,
+		DeviceId:    deviceId,
+		ScannedAt:   time.Now().Truncate(time.Microsecond),
+		ScannedById: userId,
+		Source:      model.ScanSourceOnline,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err = s.repoTicket.GetTicketById(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !checkedIn {
+		return ticket, errors.New(messages.TicketAlreadyCheckedIn)
+	}
+
+	s.emitCounter(ctx, ticket.Event.UserId, ticket.EventId, ticket.TicketTypeId)
+
+	return ticket, nil
+}
+
+// admissible refuses the tickets that cannot get in: the ones with a refund in flight, the ones of a cancelled
+// event or occurrence and, on a recurring event, the ones for another date than the occurrence running at now
+func admissible(ticket *model.Ticket, now time.Time) error {
+	if ticket.RefundId != nil {
+		return errors.New(messages.TicketBeingRefunded)
+	}
+
+	if ticket.Event != nil && ticket.Event.State == modelEvent.EventStateCancelled {
+		return errors.New(messages.TicketEventCancelled)
+	}
+
+	occurrence := ticket.Occurrence
+	if occurrence != nil && occurrence.Status == modelEvent.OccurrenceStatusCancelled {
+		return errors.New(messages.TicketDateCancelled)
+	}
+
+	if ticket.Event == nil || ticket.Event.RecurrenceRule == "" {
+		return nil
+	}
+
+	if occurrence == nil || now.Before(occurrence.StartTime.Add(-configs.CheckInOpensBefore)) || now.After(occurrence.EndTime) {
+		return errors.New(messages.TicketForOtherDate)
+	}
+
+	return nil
+}
+
+func (s *TicketService) GetCheckInCounters(ctx context.Context, userId string, eventId string) ([]*dto.CheckInCounter, error) {
+	if _, err := s.checkInStaff(ctx, userId, eventId); err != nil {
+		return nil, err
+	}
+
+	return s.repoTicket.GetCheckInCounters(ctx, eventId, "")
+}
+
+// emitCounter pushes the new counter of the ticket type to the organizer and the staff of the event
+func (s *TicketService) emitCounter(ctx context.Context, organizerId string, eventId string, ticketTypeId string) {
+	counters, err := s.repoTicket.GetCheckInCounters(ctx, eventId, ticketTypeId)
+	if err != nil || len(counters) == 0 {
+		logger.Error("Failed to get check-in counter: ", err)
+		return
+	}
+
+	staffs, err := s.repoTicket.GetEventStaffs(ctx, eventId)
+	if err != nil {
+		logger.Error("Failed to get event staffs: ", err)
+		return
+	}
+
+	s.notifier.EmitToUser(organizerId, "check_in_counter", counters[0])
+	for _, staff := range staffs {
+		s.notifier.EmitToUser(staff.UserId, "check_in_counter", counters[0])
+	}
+}
+
+// checkInStaff allows the organizer of the event and its staff
+func (s *TicketService) checkInStaff(ctx context.Context, userId string, eventId string) (*modelEvent.Event, error) {
+	event, err := s.repoTicket.GetEventById(ctx, eventId)
+	if err != nil {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId == userId {
+		return event, nil
+	}
+
+	isStaff, err := s.repoTicket.IsEventStaff(ctx, eventId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isStaff {
+		return nil, errors.New(messages.NotEventStaff)
+	}
+
+	return event, nil
+}
+
+func (s *TicketService) eventOwner(ctx context.Context, userId string, eventId string) error {
+	event, err := s.repoTicket.GetEventById(ctx, eventId)
+	if err != nil {
+		return errors.New(messages.EventNotFound)
+	}
+
+	if event.UserId != userId {
+		return errors.New(messages.NotEventOwner)
+	}
+
+	return nil
+}
+
+func (s *TicketService) GetEventStaffs(ctx context.Context, userId string, eventId string) ([]*modelEvent.EventStaff, error) {
+	if err := s.eventOwner(ctx, userId, eventId); err != nil {
+		return nil, err
+	}
+
+	return s.repoTicket.GetEventStaffs(ctx, eventId)
+}
+
+func (s *TicketService) AddEventStaff(ctx context.Context, userId string, eventId string, req *dto.AddStaffReq) (*modelEvent.EventStaff, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.eventOwner(ctx, userId, eventId); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repoTicket.GetUserById(ctx, req.UserId)
+	if err != nil {
+		return nil, errors.New(messages.UserNotFound)
+	}
+
+	isStaff, err := s.repoTicket.IsEventStaff(ctx, eventId, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isStaff {
+		return nil, errors.New(messages.StaffAlreadyExists)
+	}
+
+	staff := &modelEvent.EventStaff{EventId: eventId, UserId: user.ID}
+	if err := s.repoTicket.CreateEventStaff(ctx, staff); err != nil {
+		return nil, err
+	}
+
+	staff.User = user
+	return staff, nil
+}
+
+func (s *TicketService) RemoveEventStaff(ctx context.Context, userId string, eventId string, staffId string) error {
+	if err := s.eventOwner(ctx, userId, eventId); err != nil {
+		return err
+	}
+
+	isStaff, err := s.repoTicket.IsEventStaff(ctx, eventId, staffId)
+	if err != nil {
+		return err
+	}
+
+	if !isStaff {
+		return errors.New(messages.StaffNotFound)
+	}
+
+	return s.repoTicket.DeleteEventStaff(ctx, eventId, staffId)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/tickets/model"
+	"gohub/pkg/messages"
+	"testing"
+	"time"
+)
+
+func TestAdmissible(t *testing.T) {
+	now := time.Date(2026, 6, 6, 19, 0, 0, 0, time.UTC)
+	single := &modelEvent.Event{State: modelEvent.EventStatePublished}
+	recurring := &modelEvent.Event{State: modelEvent.EventStatePublished, RecurrenceRule: "FREQ=WEEKLY;COUNT=4"}
+	cancelled := &modelEvent.Event{State: modelEvent.EventStateCancelled}
+	occurrence := func(start time.Time, status string) *modelEvent.EventOccurrence {
+		return &modelEvent.EventOccurrence{StartTime: start, EndTime: start.Add(2 * time.Hour), Status: status}
+	}
+	refundId := "refund"
+
+	tests := []struct {
+		name   string
+		ticket *model.Ticket
+		want   string
+	}{
+		{
+			name:   "single event",
+			ticket: &model.Ticket{Event: single, Occurrence: occurrence(now.Add(-48*time.Hour), modelEvent.OccurrenceStatusScheduled)},
+		},
+		{
+			name:   "refund in flight",
+			ticket: &model.Ticket{Event: single, RefundId: &refundId},
+			want:   messages.TicketBeingRefunded,
+		},
+		{
+			name:   "cancelled event",
+			ticket: &model.Ticket{Event: cancelled},
+			want:   messages.TicketEventCancelled,
+		},
+		{
+			name:   "cancelled occurrence",
+			ticket: &model.Ticket{Event: recurring, Occurrence: occurrence(now, modelEvent.OccurrenceStatusCancelled)},
+			want:   messages.TicketDateCancelled,
+		},
+		{
+			name:   "occurrence running",
+			ticket: &model.Ticket{Event: recurring, Occurrence: occurrence(now.Add(-time.Hour), modelEvent.OccurrenceStatusScheduled)},
+		},
+		{
+			name:   "doors open",
+			ticket: &model.Ticket{Event: recurring, Occurrence: occurrence(now.Add(time.Hour), modelEvent.OccurrenceStatusScheduled)},
+		},
+		{
+			name:   "next week",
+			ticket: &model.Ticket{Event: recurring, Occurrence: occurrence(now.Add(7*24*time.Hour), modelEvent.OccurrenceStatusScheduled)},
+			want:   messages.TicketForOtherDate,
+		},
+		{
+			name:   "last week",
+			ticket: &model.Ticket{Event: recurring, Occurrence: occurrence(now.Add(-7*24*time.Hour), modelEvent.OccurrenceStatusScheduled)},
+			want:   messages.TicketForOtherDate,
+		},
+		{
+			name:   "occurrence removed",
+			ticket: &model.Ticket{Event: recurring},
+			want:   messages.TicketForOtherDate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := admissible(tt.ticket, now)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("admissible() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("admissible() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gohub/configs"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/tickets/model"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/pdf"
+	"gohub/pkg/messages"
+	"gohub/pkg/utils"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// GetTicketPdf renders the printable ticket of its holder
+func (s *TicketService) GetTicketPdf(ctx context.Context, userId string, id string) ([]byte, error) {
+	ticket, err := s.repoTicket.GetTicketById(ctx, id)
+	if err != nil {
+		return nil, errors.New(messages.TicketNotFound)
+	}
+
+	if ticket.UserId != userId {
+		return nil, errors.New(messages.NotTicketOwner)
+	}
+
+	return s.RenderTickets(ctx, []*model.Ticket{ticket})
+}
+
+// RenderTickets renders one page per ticket, the tickets must have their event and ticket type loaded. The
+// times are those of the occurrence of the ticket when it is loaded too.
+func (s *TicketService) RenderTickets(ctx context.Context, tickets []*model.Ticket) ([]byte, error) {
+	covers := make(map[string][]byte)
+	documents := make([]*pdf.Ticket, 0, len(tickets))
+	for _, ticket := range tickets {
+		if ticket.QrSecret == "" {
+			ticket.QrSecret = utils.RandomHex(16)
+			if err := s.repoTicket.UpdateTicket(ctx, ticket); err != nil {
+				return nil, err
+			}
+		}
+
+		qr, err := qrcode.Encode(s.ticketToken(ticket), qrcode.Medium, configs.TicketQrSize)
+		if err != nil {
+			return nil, err
+		}
+
+		document := &pdf.Ticket{
+			TicketNo:     ticket.TicketNo,
+			CustomerName: ticket.CustomerName,
+			QrCode:       qr,
+		}
+		if ticket.TicketType != nil {
+			document.TicketType = ticket.TicketType.Name
+		}
+		if event := ticket.Event; event != nil {
+			document.EventName = event.Name
+			start, end := event.StartTime, event.EndTime
+			if occurrence := ticket.Occurrence; occurrence != nil {
+				start, end = occurrence.StartTime.In(event.TimeLocation()), occurrence.EndTime.In(event.TimeLocation())
+			}
+			document.StartTime = start.Format(modelEvent.DisplayTimeLayout)
+			document.EndTime = end.Format(modelEvent.DisplayTimeLayout)
+			document.Location = event.Location
+
+			if _, ok := covers[event.ID]; !ok {
+				covers[event.ID] = s.cover(ctx, event.CoverImageUrl)
+			}
+			document.Cover = covers[event.ID]
+		}
+
+		documents = append(documents, document)
+	}
+
+	return s.renderer.Tickets(documents)
+}
+
+// cover downloads the cover of the event as a JPEG, a ticket is still rendered without it
+func (s *TicketService) cover(ctx context.Context, url string) []byte {
+	if url == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, configs.PdfDownloadTimeout)
+	defer cancel()
+
+	data, err := utils.Download(ctx, url, configs.PdfCoverMaxSize)
+	if err != nil {
+		logger.Warnf("Failed to download event cover %s: %v", url, err)
+		return nil
+	}
+
+	cover, err := utils.Thumbnail(data, configs.PdfCoverWidth)
+	if err != nil {
+		logger.Warnf("Failed to decode event cover %s: %v", url, err)
+		return nil
+	}
+
+	return cover
+}
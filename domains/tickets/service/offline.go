@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"gohub/configs"
+	"gohub/domains/tickets/dto"
+	"gohub/domains/tickets/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ticketHash is what door devices compare a scanned code against, the code itself never leaves the server
+func ticketHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// syncToken binds the export time of a manifest to its event, the scans made with the manifest are synced with it
+func (s *TicketService) syncToken(eventId string, issuedAt time.Time) string {
+	exported := strconv.FormatInt(issuedAt.Unix(), 10)
+	return exported + "." + utils.Sign(s.secret, []byte("manifest."+eventId+"."+exported))
+}
+
+// manifestIssuedAt reads back the export time of the manifest a sync token was issued with
+func (s *TicketService) manifestIssuedAt(eventId string, token string) (time.Time, error) {
+	exported, signature, found := strings.Cut(token, ".")
+	if !found || !utils.VerifySignature(s.secret, []byte("manifest."+eventId+"."+exported), signature) {
+		return time.Time{}, errors.New(messages.InvalidSyncToken)
+	}
+
+	seconds, err := strconv.ParseInt(exported, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New(messages.InvalidSyncToken)
+	}
+
+	return time.Unix(seconds, 0), nil
+}
+
+// ExportManifest returns the Ed25519 signed list of the valid tickets of the event for offline check-in
+func (s *TicketService) ExportManifest(ctx context.Context, userId string, eventId string) (*dto.ManifestRes, error) {
+	if _, err := s.checkInStaff(ctx, userId, eventId); err != nil {
+		return nil, err
+	}
+
+	tickets, err := s.repoTicket.GetEventTickets(ctx, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := dto.Manifest{
+		Version:  configs.ManifestVersion,
+		EventId:  eventId,
+		IssuedAt: time.Now().Truncate(time.Second),
+		Tickets:  make([]*dto.ManifestTicket, 0, len(tickets)),
+	}
+	for _, ticket := range tickets {
+		if ticket.QrSecret == "" {
+			ticket.QrSecret = utils.RandomHex(16)
+			if err := s.repoTicket.UpdateTicket(ctx, ticket); err != nil {
+				return nil, err
+			}
+		}
+
+		manifest.Tickets = append(manifest.Tickets, &dto.ManifestTicket{
+			ID:           ticket.ID,
+			Hash:         ticketHash(s.ticketToken(ticket)),
+			TicketTypeId: ticket.TicketTypeId,
+			CheckedInAt:  ticket.CheckedInAt,
+		})
+	}
+
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ManifestRes{
+		Manifest:  base64.RawURLEncoding.EncodeToString(payload),
+		Signature: utils.SignEd25519(s.scanKey, payload),
+		Algorithm: "Ed25519",
+		SyncToken: s.syncToken(eventId, manifest.IssuedAt),
+	}, nil
+}
+
+// SyncScans stores a batch of offline scans and reports, for each scan, whether it won the check-in of its ticket.
+// The earliest scan of a ticket wins whatever device or batch it came from, the smallest device id breaks ties.
+// Scans older than the manifest they were made with, or than MaxOfflineScanAge, are refused.
+func (s *TicketService) SyncScans(ctx context.Context, userId string, eventId string, req *dto.SyncScansReq) (*dto.SyncScansRes, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	event, err := s.checkInStaff(ctx, userId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt, err := s.manifestIssuedAt(eventId, req.SyncToken)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(req.Scans))
+	for _, scan := range req.Scans {
+		ids = append(ids, scan.TicketId)
+	}
+
+	tickets, err := s.repoTicket.GetTicketsByIds(ctx, eventId, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]*model.Ticket, len(tickets))
+	for _, ticket := range tickets {
+		known[ticket.ID] = ticket
+	}
+
+	now := time.Now()
+	latest := now.Add(configs.MaxScanClockSkew)
+	exported := issuedAt.Add(-configs.MaxScanClockSkew)
+	oldest := now.Add(-configs.MaxOfflineScanAge)
+	res := &dto.SyncScansRes{Results: make([]*dto.ScanResult, 0, len(req.Scans))}
+	scans := make([]*model.TicketScan, 0, len(req.Scans))
+	for _, scan := range req.Scans {
+		if scan.DeviceId == "" {
+			scan.DeviceId = req.DeviceId
+		}
+		scan.ScannedAt = scan.ScannedAt.Truncate(time.Microsecond)
+
+		result := &dto.ScanResult{TicketId: scan.TicketId, DeviceId: scan.DeviceId, ScannedAt: scan.ScannedAt}
+		res.Results = append(res.Results, result)
+
+		switch {
+		case known[scan.TicketId] == nil:
+			result.Status, result.Reason = model.ScanStatusRejected, messages.TicketNotFound
+		case known[scan.TicketId].RefundId != nil:
+			result.Status, result.Reason = model.ScanStatusRejected, messages.TicketBeingRefunded
+		case scan.ScannedAt.After(latest):
+			result.Status, result.Reason = model.ScanStatusRejected, messages.ScanInFuture
+		case scan.ScannedAt.Before(oldest):
+			result.Status, result.Reason = model.ScanStatusRejected, messages.ScanTooOld
+		case scan.ScannedAt.Before(exported):
+			result.Status, result.Reason = model.ScanStatusRejected, messages.ScanBeforeManifest
+		default:
+			scans = append(scans, &model.TicketScan{
+				TicketId:    scan.TicketId,
+				EventId:     eventId,
+				DeviceId:    scan.DeviceId,
+				ScannedAt:   scan.ScannedAt,
+				ScannedById: userId,
+				Source:      model.ScanSourceOffline,
+			})
+		}
+	}
+
+	if len(scans) > 0 {
+		if err := s.repoTicket.CreateScans(ctx, scans); err != nil {
+			return nil, err
+		}
+
+		scannedIds := make([]string, 0, len(scans))
+		for _, scan := range scans {
+			scannedIds = append(scannedIds, scan.TicketId)
+		}
+
+		if err := s.repoTicket.ResolveCheckIns(ctx, scannedIds); err != nil {
+			return nil, err
+		}
+
+		if tickets, err = s.repoTicket.GetTicketsByIds(ctx, eventId, scannedIds); err != nil {
+			return nil, err
+		}
+		for _, ticket := range tickets {
+			known[ticket.ID] = ticket
+		}
+	}
+
+	ticketTypes := make(map[string]bool)
+	for _, result := range res.Results {
+		if result.Status == model.ScanStatusRejected {
+			res.Rejected++
+			continue
+		}
+
+		ticket := known[result.TicketId]
+		ticketTypes[ticket.TicketTypeId] = true
+		if ticket.CheckedInDeviceId != nil && *ticket.CheckedInDeviceId == result.DeviceId &&
+			ticket.CheckedInAt != nil && ticket.CheckedInAt.Equal(result.ScannedAt) {
+			result.Status = model.ScanStatusAccepted
+			res.Accepted++
+			continue
+		}
+
+		result.Status = model.ScanStatusConflict
+		result.Winner = &dto.ScanWinner{ScannedAt: ticket.CheckedInAt}
+		if ticket.CheckedInDeviceId != nil {
+			result.Winner.DeviceId = *ticket.CheckedInDeviceId
+		}
+		if ticket.CheckedInBy != nil {
+			result.Winner.ScannedBy = &dto.User{
+				ID:        ticket.CheckedInBy.ID,
+				UserName:  ticket.CheckedInBy.UserName,
+				FullName:  ticket.CheckedInBy.FullName,
+				AvatarUrl: ticket.CheckedInBy.AvatarUrl,
+			}
+		}
+		res.Conflicts++
+	}
+
+	for ticketTypeId := range ticketTypes {
+		s.emitCounter(ctx, event.UserId, eventId, ticketTypeId)
+	}
+
+	return res, nil
+}
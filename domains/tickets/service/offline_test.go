@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/tickets/dto"
+	"gohub/domains/tickets/model"
+	"gohub/domains/tickets/repository"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/validation"
+	"gohub/pkg/messages"
+	"os"
+	"testing"
+	"time"
+)
+
+const (
+	scanEventId = "event"
+	organizerId = "organizer"
+)
+
+func TestMain(m *testing.M) {
+	logger.Initialize("test")
+	os.Exit(m.Run())
+}
+
+// scanRepo keeps the tickets and scans of one event in memory, ResolveCheckIns applies the rule of the
+// database: the earliest scan wins and the smallest device id breaks ties
+type scanRepo struct {
+	repository.ITicketRepository
+	tickets map[string]*model.Ticket
+	scans   []*model.TicketScan
+}
+
+func newScanRepo(ticketIds ...string) *scanRepo {
+	repo := &scanRepo{tickets: make(map[string]*model.Ticket)}
+	for _, id := range ticketIds {
+		repo.tickets[id] = &model.Ticket{ID: id, EventId: scanEventId, TicketTypeId: "general"}
+	}
+
+	return repo
+}
+
+func (r *scanRepo) GetEventById(ctx context.Context, id string) (*modelEvent.Event, error) {
+	if id != scanEventId {
+		return nil, errors.New(messages.EventNotFound)
+	}
+
+	return &modelEvent.Event{ID: id, UserId: organizerId}, nil
+}
+
+func (r *scanRepo) GetTicketsByIds(ctx context.Context, eventId string, ids []string) ([]*model.Ticket, error) {
+	var tickets []*model.Ticket
+	for _, id := range ids {
+		if ticket, ok := r.tickets[id]; ok && ticket.EventId == eventId {
+			copied := *ticket
+			tickets = append(tickets, &copied)
+		}
+	}
+
+	return tickets, nil
+}
+
+func (r *scanRepo) CreateScans(ctx context.Context, scans []*model.TicketScan) error {
+	for _, scan := range scans {
+		duplicate := false
+		for _, stored := range r.scans {
+			if stored.TicketId == scan.TicketId && stored.DeviceId == scan.DeviceId && stored.ScannedAt.Equal(scan.ScannedAt) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			r.scans = append(r.scans, scan)
+		}
+	}
+
+	return nil
+}
+
+func (r *scanRepo) ResolveCheckIns(ctx context.Context, ticketIds []string) error {
+	for _, id := range ticketIds {
+		var winner *model.TicketScan
+		for _, scan := range r.scans {
+			if scan.TicketId != id {
+				continue
+			}
+			if winner == nil || scan.ScannedAt.Before(winner.ScannedAt) ||
+				(scan.ScannedAt.Equal(winner.ScannedAt) && scan.DeviceId < winner.DeviceId) {
+				winner = scan
+			}
+		}
+
+		if winner != nil {
+			ticket := r.tickets[id]
+			scannedAt, deviceId, scannedById := winner.ScannedAt, winner.DeviceId, winner.ScannedById
+			ticket.CheckedInAt, ticket.CheckedInDeviceId, ticket.CheckedInById = &scannedAt, &deviceId, &scannedById
+		}
+	}
+
+	return nil
+}
+
+// GetCheckInCounters has nothing to report, the counters are not pushed to anyone
+func (r *scanRepo) GetCheckInCounters(ctx context.Context, eventId string, ticketTypeId string) ([]*dto.CheckInCounter, error) {
+	return nil, nil
+}
+
+func newScanService(repo *scanRepo) *TicketService {
+	return &TicketService{
+		validator:  validation.New(),
+		repoTicket: repo,
+		secret:     "ticket-secret",
+		scanKey:    ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)),
+	}
+}
+
+type testScan struct {
+	ticketId string
+	deviceId string
+	// after is the time of the scan from the export of the manifest
+	after time.Duration
+}
+
+type wantScan struct {
+	status       string
+	reason       string
+	winnerDevice string
+}
+
+func TestSyncScans(t *testing.T) {
+	tests := []struct {
+		name string
+		// exported is how long before now the manifest was exported
+		exported time.Duration
+		synced   [][]testScan
+		scans    []testScan
+		want     []wantScan
+	}{
+		{
+			name:     "first scan of a ticket",
+			exported: 2 * time.Hour,
+			scans:    []testScan{{ticketId: "t1", deviceId: "door-a", after: time.Hour}},
+			want:     []wantScan{{status: model.ScanStatusAccepted}},
+		},
+		{
+			name:     "earliest scan of the batch wins",
+			exported: 2 * time.Hour,
+			scans: []testScan{
+				{ticketId: "t1", deviceId: "door-a", after: time.Hour},
+				{ticketId: "t1", deviceId: "door-b", after: 30 * time.Minute},
+			},
+			want: []wantScan{
+				{status: model.ScanStatusConflict, winnerDevice: "door-b"},
+				{status: model.ScanStatusAccepted},
+			},
+		},
+		{
+			name:     "smallest device id breaks a tie",
+			exported: 2 * time.Hour,
+			scans: []testScan{
+				{ticketId: "t1", deviceId: "door-b", after: time.Hour},
+				{ticketId: "t1", deviceId: "door-a", after: time.Hour},
+			},
+			want: []wantScan{
+				{status: model.ScanStatusConflict, winnerDevice: "door-a"},
+				{status: model.ScanStatusAccepted},
+			},
+		},
+		{
+			name:     "earlier scan of a later batch takes the check-in over",
+			exported: 2 * time.Hour,
+			synced:   [][]testScan{{{ticketId: "t1", deviceId: "door-a", after: time.Hour}}},
+			scans:    []testScan{{ticketId: "t1", deviceId: "door-b", after: 10 * time.Minute}},
+			want:     []wantScan{{status: model.ScanStatusAccepted}},
+		},
+		{
+			name:     "later scan of a later batch loses",
+			exported: 2 * time.Hour,
+			synced:   [][]testScan{{{ticketId: "t1", deviceId: "door-a", after: 10 * time.Minute}}},
+			scans:    []testScan{{ticketId: "t1", deviceId: "door-b", after: time.Hour}},
+			want:     []wantScan{{status: model.ScanStatusConflict, winnerDevice: "door-a"}},
+		},
+		{
+			name:     "batch sent again",
+			exported: 2 * time.Hour,
+			synced:   [][]testScan{{{ticketId: "t1", deviceId: "door-a", after: time.Hour}}},
+			scans:    []testScan{{ticketId: "t1", deviceId: "door-a", after: time.Hour}},
+			want:     []wantScan{{status: model.ScanStatusAccepted}},
+		},
+		{
+			name:     "tickets are resolved independently",
+			exported: 2 * time.Hour,
+			scans: []testScan{
+				{ticketId: "t1", deviceId: "door-b", after: time.Hour},
+				{ticketId: "t2", deviceId: "door-b", after: time.Hour},
+				{ticketId: "t2", deviceId: "door-a", after: 2 * time.Hour},
+			},
+			want: []wantScan{
+				{status: model.ScanStatusAccepted},
+				{status: model.ScanStatusAccepted},
+				{status: model.ScanStatusConflict, winnerDevice: "door-b"},
+			},
+		},
+		{
+			name:     "unknown ticket",
+			exported: 2 * time.Hour,
+			scans:    []testScan{{ticketId: "other", deviceId: "door-a", after: time.Hour}},
+			want:     []wantScan{{status: model.ScanStatusRejected, reason: messages.TicketNotFound}},
+		},
+		{
+			name:     "ticket being refunded",
+			exported: 2 * time.Hour,
+			scans:    []testScan{{ticketId: "refunding", deviceId: "door-a", after: time.Hour}},
+			want:     []wantScan{{status: model.ScanStatusRejected, reason: messages.TicketBeingRefunded}},
+		},
+		{
+			name:     "scan in the future",
+			exported: 2 * time.Hour,
+			scans:    []testScan{{ticketId: "t1", deviceId: "door-a", after: 3 * time.Hour}},
+			want:     []wantScan{{status: model.ScanStatusRejected, reason: messages.ScanInFuture}},
+		},
+		{
+			name:     "device clock slightly behind the server",
+			exported: 2 * time.Hour,
+			scans:    []testScan{{ticketId: "t1", deviceId: "door-a", after: -time.Minute}},
+			want:     []wantScan{{status: model.ScanStatusAccepted}},
+		},
+		{
+			name:     "scan before the manifest was exported",
+			exported: 2 * time.Hour,
+			scans:    []testScan{{ticketId: "t1", deviceId: "door-a", after: -time.Hour}},
+			want:     []wantScan{{status: model.ScanStatusRejected, reason: messages.ScanBeforeManifest}},
+		},
+		{
+			name:     "scan older than an offline device may be",
+			exported: 100 * time.Hour,
+			scans:    []testScan{{ticketId: "t1", deviceId: "door-a", after: 20 * time.Hour}},
+			want:     []wantScan{{status: model.ScanStatusRejected, reason: messages.ScanTooOld}},
+		},
+		{
+			name:     "rejected scans do not take part in the check-in",
+			exported: 2 * time.Hour,
+			scans: []testScan{
+				{ticketId: "t1", deviceId: "door-a", after: -time.Hour},
+				{ticketId: "t1", deviceId: "door-b", after: time.Hour},
+			},
+			want: []wantScan{
+				{status: model.ScanStatusRejected, reason: messages.ScanBeforeManifest},
+				{status: model.ScanStatusAccepted},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newScanRepo("t1", "t2", "refunding")
+			refundId := "refund"
+			repo.tickets["refunding"].RefundId = &refundId
+			s := newScanService(repo)
+			issuedAt := time.Now().Add(-tt.exported).Truncate(time.Second)
+			token := s.syncToken(scanEventId, issuedAt)
+
+			sync := func(scans []testScan) *dto.SyncScansRes {
+				req := &dto.SyncScansReq{DeviceId: "door-x", SyncToken: token}
+				for _, scan := range scans {
+					req.Scans = append(req.Scans, &dto.OfflineScan{TicketId: scan.ticketId, DeviceId: scan.deviceId, ScannedAt: issuedAt.Add(scan.after)})
+				}
+
+				res, err := s.SyncScans(context.Background(), organizerId, scanEventId, req)
+				if err != nil {
+					t.Fatalf("SyncScans() error = %v", err)
+				}
+				return res
+			}
+
+			for _, batch := range tt.synced {
+				sync(batch)
+			}
+			res := sync(tt.scans)
+
+			if len(res.Results) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(res.Results), len(tt.want))
+			}
+			for i, result := range res.Results {
+				want := tt.want[i]
+				if result.Status != want.status || result.Reason != want.reason {
+					t.Errorf("result %d = %s %q, want %s %q", i, result.Status, result.Reason, want.status, want.reason)
+				}
+				if want.winnerDevice != "" && (result.Winner == nil || result.Winner.DeviceId != want.winnerDevice) {
+					t.Errorf("result %d winner = %+v, want device %s", i, result.Winner, want.winnerDevice)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncScansToken(t *testing.T) {
+	s := newScanService(newScanRepo("t1"))
+	issuedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	valid := s.syncToken(scanEventId, issuedAt)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "valid", token: valid},
+		{name: "other event", token: s.syncToken("other-event", issuedAt), wantErr: messages.InvalidSyncToken},
+		{name: "moved export time", token: "1" + valid, wantErr: messages.InvalidSyncToken},
+		{name: "other secret", token: (&TicketService{secret: "other"}).syncToken(scanEventId, issuedAt), wantErr: messages.InvalidSyncToken},
+		{name: "no signature", token: "1700000000", wantErr: messages.InvalidSyncToken},
+		{name: "not a time", token: "soon." + valid, wantErr: messages.InvalidSyncToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &dto.SyncScansReq{
+				DeviceId:  "door-a",
+				SyncToken: tt.token,
+				Scans:     []*dto.OfflineScan{{TicketId: "t1", ScannedAt: issuedAt.Add(time.Minute)}},
+			}
+
+			_, err := s.SyncScans(context.Background(), organizerId, scanEventId, req)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("SyncScans() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("SyncScans() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,26 +2,60 @@ package service
 
 import (
 	"context"
+	"crypto/ed25519"
+	"gohub/configs"
+	modelEvent "gohub/domains/events/model"
 	"gohub/domains/tickets/dto"
 	"gohub/domains/tickets/model"
 	"gohub/domains/tickets/repository"
+	"gohub/internal/libs/pdf"
 	"gohub/internal/libs/validation"
+	socketio "gohub/internal/libs/websocket"
 	"gohub/pkg/paging"
 )
 
 type ITicketService interface {
 	GetCreatedTickets(ctx context.Context, userId string, req *dto.ListTicketReq) ([]*model.Ticket, *paging.Pagination, error)
+	GetTicketQr(ctx context.Context, userId string, id string) ([]byte, error)
+	CheckIn(ctx context.Context, userId string, req *dto.CheckInReq) (*model.Ticket, error)
+	GetCheckInCounters(ctx context.Context, userId string, eventId string) ([]*dto.CheckInCounter, error)
+	GetEventStaffs(ctx context.Context, userId string, eventId string) ([]*modelEvent.EventStaff, error)
+	AddEventStaff(ctx context.Context, userId string, eventId string, req *dto.AddStaffReq) (*modelEvent.EventStaff, error)
+	RemoveEventStaff(ctx context.Context, userId string, eventId string, staffId string) error
+	ExportManifest(ctx context.Context, userId string, eventId string) (*dto.ManifestRes, error)
+	SyncScans(ctx context.Context, userId string, eventId string, req *dto.SyncScansReq) (*dto.SyncScansRes, error)
+	GetTicketPdf(ctx context.Context, userId string, id string) ([]byte, error)
+	RenderTickets(ctx context.Context, tickets []*model.Ticket) ([]byte, error)
+	CreateTransfer(ctx context.Context, userId string, ticketId string, req *dto.CreateTransferReq) (*model.TicketTransfer, error)
+	AcceptTransfer(ctx context.Context, userId string, id string) (*model.TicketTransfer, error)
+	DeclineTransfer(ctx context.Context, userId string, id string) (*model.TicketTransfer, error)
+	CancelTransfer(ctx context.Context, userId string, id string) (*model.TicketTransfer, error)
+	GetTransfers(ctx context.Context, userId string, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error)
+	GetEventTransfers(ctx context.Context, userId string, eventId string, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error)
+	UpdateTransferSetting(ctx context.Context, userId string, ticketTypeId string, req *dto.TransferSettingReq) (*modelEvent.TicketType, error)
 }
 
 type TicketService struct {
 	validator  validation.Validation
 	repoTicket repository.ITicketRepository
+	notifier   socketio.Notifier
+	renderer   *pdf.Renderer
+	secret     string
+	scanKey    ed25519.PrivateKey
 }
 
-func NewTicketService(validator validation.Validation, repoTicket repository.ITicketRepository) *TicketService {
+func NewTicketService(validator validation.Validation, repoTicket repository.ITicketRepository, notifier socketio.Notifier) *TicketService {
+	cfg := configs.GetConfig()
+	// The key was checked at startup
+	scanKey, _ := cfg.ScanKey()
+
 	return &TicketService{
 		validator:  validator,
 		repoTicket: repoTicket,
+		notifier:   notifier,
+		renderer:   pdf.New(cfg.PdfFontPath),
+		secret:     cfg.TicketSecret,
+		scanKey:    scanKey,
 	}
 }
 
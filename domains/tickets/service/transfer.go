@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	modelEvent "gohub/domains/events/model"
+	"gohub/domains/tickets/dto"
+	"gohub/domains/tickets/model"
+	"gohub/pkg/messages"
+	"gohub/pkg/paging"
+	"gohub/pkg/utils"
+	"strings"
+)
+
+// CreateTransfer offers a ticket of the user to another user found by email or username
+func (s *TicketService) CreateTransfer(ctx context.Context, userId string, ticketId string, req *dto.CreateTransferReq) (*model.TicketTransfer, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.repoTicket.GetTicketById(ctx, ticketId)
+	if err != nil {
+		return nil, errors.New(messages.TicketNotFound)
+	}
+
+	if ticket.UserId != userId {
+		return nil, errors.New(messages.NotTicketOwner)
+	}
+
+	if ticket.CheckedInAt != nil {
+		return nil, errors.New(messages.TicketAlreadyUsed)
+	}
+
+	if ticket.RefundId != nil {
+		return nil, errors.New(messages.TicketBeingRefunded)
+	}
+
+	if ticket.TicketType != nil && ticket.TicketType.IsTransferDisabled {
+		return nil, errors.New(messages.TransferDisabled)
+	}
+
+	recipient, err := s.repoTicket.GetUserByEmailOrUserName(ctx, strings.TrimSpace(req.Recipient))
+	if err != nil {
+		return nil, errors.New(messages.UserNotFound)
+	}
+
+	if recipient.ID == userId {
+		return nil, errors.New(messages.CannotTransferToSelf)
+	}
+
+	pending, err := s.repoTicket.HasPendingTransfer(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending {
+		return nil, errors.New(messages.TransferPending)
+	}
+
+	transfer := &model.TicketTransfer{
+		TicketId:   ticket.ID,
+		EventId:    ticket.EventId,
+		FromUserId: userId,
+		ToUserId:   recipient.ID,
+		Status:     model.TransferStatusPending,
+	}
+	if err := s.repoTicket.CreateTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	transfer, err = s.repoTicket.GetTransferById(ctx, transfer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.emitTransfer(transfer.ToUserId, transfer)
+
+	return transfer, nil
+}
+
+// AcceptTransfer moves the ticket to the recipient and re-issues its QR code
+func (s *TicketService) AcceptTransfer(ctx context.Context, userId string, id string) (*model.TicketTransfer, error) {
+	transfer, err := s.repoTicket.GetTransferById(ctx, id)
+	if err != nil {
+		return nil, errors.New(messages.TransferNotFound)
+	}
+
+	if transfer.ToUserId != userId {
+		return nil, errors.New(messages.NotTransferRecipient)
+	}
+
+	if transfer.Status != model.TransferStatusPending {
+		return nil, errors.New(messages.TransferNotPending)
+	}
+
+	if transfer.Ticket.TicketType != nil && transfer.Ticket.TicketType.IsTransferDisabled {
+		return nil, errors.New(messages.TransferDisabled)
+	}
+
+	if err := s.repoTicket.AcceptTransfer(ctx, transfer, transfer.ToUser, utils.RandomHex(16)); err != nil {
+		return nil, err
+	}
+
+	return s.respondedTransfer(ctx, transfer.ID, transfer.FromUserId)
+}
+
+func (s *TicketService) DeclineTransfer(ctx context.Context, userId string, id string) (*model.TicketTransfer, error) {
+	transfer, err := s.repoTicket.GetTransferById(ctx, id)
+	if err != nil {
+		return nil, errors.New(messages.TransferNotFound)
+	}
+
+	if transfer.ToUserId != userId {
+		return nil, errors.New(messages.NotTransferRecipient)
+	}
+
+	if err := s.repoTicket.RespondTransfer(ctx, transfer.ID, model.TransferStatusDeclined); err != nil {
+		return nil, err
+	}
+
+	return s.respondedTransfer(ctx, transfer.ID, transfer.FromUserId)
+}
+
+func (s *TicketService) CancelTransfer(ctx context.Context, userId string, id string) (*model.TicketTransfer, error) {
+	transfer, err := s.repoTicket.GetTransferById(ctx, id)
+	if err != nil {
+		return nil, errors.New(messages.TransferNotFound)
+	}
+
+	if transfer.FromUserId != userId {
+		return nil, errors.New(messages.NotTransferSender)
+	}
+
+	if err := s.repoTicket.RespondTransfer(ctx, transfer.ID, model.TransferStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	return s.respondedTransfer(ctx, transfer.ID, transfer.ToUserId)
+}
+
+// respondedTransfer reloads an answered transfer and lets the other party know
+func (s *TicketService) respondedTransfer(ctx context.Context, id string, notifyUserId string) (*model.TicketTransfer, error) {
+	transfer, err := s.repoTicket.GetTransferById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.emitTransfer(notifyUserId, transfer)
+
+	return transfer, nil
+}
+
+func (s *TicketService) emitTransfer(userId string, transfer *model.TicketTransfer) {
+	var res dto.Transfer
+	utils.MapStruct(&res, transfer)
+	s.notifier.EmitToUser(userId, "ticket_transfer", res)
+}
+
+func (s *TicketService) GetTransfers(ctx context.Context, userId string, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, nil, err
+	}
+
+	return s.repoTicket.GetTransfersByUser(ctx, userId, req)
+}
+
+// GetEventTransfers returns the transfer log of the event for its organizer
+func (s *TicketService) GetEventTransfers(ctx context.Context, userId string, eventId string, req *dto.ListTransferReq) ([]*model.TicketTransfer, *paging.Pagination, error) {
+	if err := s.validator.ValidateStruct(req); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.eventOwner(ctx, userId, eventId); err != nil {
+		return nil, nil, err
+	}
+
+	return s.repoTicket.GetTransfersByEvent(ctx, eventId, req)
+}
+
+// UpdateTransferSetting lets the organizer turn transfers of a ticket type on or off, pending transfers can no longer be accepted once off
+func (s *TicketService) UpdateTransferSetting(ctx context.Context, userId string, ticketTypeId string, req *dto.TransferSettingReq) (*modelEvent.TicketType, error) {
+	ticketType, err := s.repoTicket.GetTicketTypeById(ctx, ticketTypeId)
+	if err != nil {
+		return nil, errors.New(messages.TicketTypeNotFound)
+	}
+
+	if ticketType.Event == nil || ticketType.Event.UserId != userId {
+		return nil, errors.New(messages.NotEventOwner)
+	}
+
+	if err := s.repoTicket.SetTransferDisabled(ctx, ticketType.ID, req.IsTransferDisabled); err != nil {
+		return nil, err
+	}
+
+	ticketType.IsTransferDisabled = req.IsTransferDisabled
+	return ticketType, nil
+}
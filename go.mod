@@ -6,6 +6,7 @@ require (
 	github.com/cloudinary/cloudinary-go v1.7.0
 	github.com/gin-contrib/cors v1.7.2
 	github.com/gin-gonic/gin v1.10.0
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/go-playground/locales v0.14.1
 	github.com/go-playground/universal-translator v0.18.1
 	github.com/go-playground/validator/v10 v10.22.1
@@ -15,6 +16,7 @@ require (
 	github.com/gorilla/sessions v1.1.1
 	github.com/jackc/pgx/v5 v5.5.5
 	github.com/markbates/goth v1.80.0
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/viper v1.19.0
 	github.com/stripe/stripe-go/v81 v81.2.0
 	github.com/swaggo/files v1.0.1
@@ -22,6 +24,8 @@ require (
 	github.com/swaggo/swag v1.16.4
 	go.uber.org/zap v1.27.0
 	golang.org/x/crypto v0.28.0
+	golang.org/x/image v0.21.0
+	golang.org/x/text v0.19.0
 	google.golang.org/grpc v1.69.2
 	google.golang.org/protobuf v1.35.1
 	gorm.io/driver/postgres v1.5.9
@@ -85,7 +89,6 @@ require (
 	golang.org/x/oauth2 v0.23.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.26.0 // indirect
-	golang.org/x/text v0.19.0 // indirect
 	golang.org/x/tools v0.26.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20241015192408-796eee8c2d53 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
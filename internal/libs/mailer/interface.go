@@ -0,0 +1,23 @@
+package mailer
+
+import "context"
+
+// Mailer interface
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message is a single email, Body is rendered as HTML
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []*Attachment
+}
+
+// Attachment is a file attached to a Message
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+
+	"gohub/internal/libs/logger"
+)
+
+type smtpMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	sender   string
+}
+
+type logMailer struct{}
+
+// New returns an SMTP mailer, or a mailer that only logs when no host is configured
+func New(host string, port int, username string, password string, sender string) Mailer {
+	if host == "" {
+		logger.Warn("SMTP host is not configured, emails will only be logged")
+		return &logMailer{}
+	}
+
+	if sender == "" {
+		sender = username
+	}
+
+	return &smtpMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		sender:   sender,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	return smtp.SendMail(fmt.Sprintf("%s:%d", m.host, m.port), auth, m.sender, msg.To, data)
+}
+
+func (m *smtpMailer) build(msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	buf.WriteString(fmt.Sprintf("From: %s\r\n", m.sender))
+	buf.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(msg.To, ", ")))
+	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject)))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary()))
+
+	body, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := body.Write(encodeBase64([]byte(msg.Body))); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range msg.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf("%s; name=%q", attachment.ContentType, attachment.FileName)},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachment.FileName)},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(encodeBase64(attachment.Data)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeBase64 wraps the encoded data at 76 characters as required by RFC 2045
+func encodeBase64(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+
+	return buf.Bytes()
+}
+
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	logger.Infof("Email to %v with subject %q and %d attachment(s)", msg.To, msg.Subject, len(msg.Attachments))
+	return nil
+}
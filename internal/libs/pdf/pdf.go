@@ -0,0 +1,127 @@
+package pdf
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"gohub/internal/libs/logger"
+	"gohub/pkg/money"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	fontFamily = "body"
+	pageMargin = 15.0
+)
+
+// Renderer builds the printable documents of the platform
+type Renderer struct {
+	regular []byte
+	bold    []byte
+	glyphs  *sfnt.Font
+}
+
+// New loads the TrueType font at fontPath, the Go fonts are used when it is empty or cannot be read.
+// The Go fonts have no Vietnamese glyphs, characters they miss are printed without their diacritics.
+func New(fontPath string) *Renderer {
+	r := &Renderer{regular: goregular.TTF, bold: gobold.TTF}
+	if fontPath != "" {
+		data, err := os.ReadFile(fontPath)
+		if err != nil {
+			logger.Warnf("Failed to read pdf font %s, falling back to the default font: %v", fontPath, err)
+		} else {
+			r.regular, r.bold = data, data
+		}
+	}
+
+	glyphs, err := sfnt.Parse(r.regular)
+	if err != nil {
+		logger.Warnf("Failed to parse pdf font, falling back to the default font: %v", err)
+		r.regular, r.bold = goregular.TTF, gobold.TTF
+		glyphs, _ = sfnt.Parse(r.regular)
+	}
+	r.glyphs = glyphs
+
+	return r
+}
+
+func (r *Renderer) newDocument() *fpdf.Fpdf {
+	doc := fpdf.New("P", "mm", "A4", "")
+	doc.SetMargins(pageMargin, pageMargin, pageMargin)
+	doc.SetAutoPageBreak(true, pageMargin)
+	doc.AddUTF8FontFromBytes(fontFamily, "", r.regular)
+	doc.AddUTF8FontFromBytes(fontFamily, "B", r.bold)
+	return doc
+}
+
+func output(doc *fpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := doc.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// text replaces the characters the font cannot draw by their base letter
+func (r *Renderer) text(s string) string {
+	var buf sfnt.Buffer
+	var out strings.Builder
+	for _, c := range s {
+		if c < 0x80 || r.hasGlyph(&buf, c) {
+			out.WriteRune(c)
+			continue
+		}
+
+		switch c {
+		case 'đ':
+			out.WriteRune('d')
+			continue
+		case 'Đ':
+			out.WriteRune('D')
+			continue
+		}
+
+		for _, d := range norm.NFD.String(string(c)) {
+			if d < 0x80 || r.hasGlyph(&buf, d) {
+				out.WriteRune(d)
+				break
+			}
+		}
+	}
+
+	return out.String()
+}
+
+func (r *Renderer) hasGlyph(buf *sfnt.Buffer, c rune) bool {
+	index, err := r.glyphs.GlyphIndex(buf, c)
+	return err == nil && index != 0
+}
+
+// formatMoney writes an amount with dot separated thousands and a decimal comma, like 1.250.000 VND or 12,50 USD
+func formatMoney(amount money.Money) string {
+	digits, fraction, _ := strings.Cut(amount.Decimal(), ".")
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	var out strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out.WriteRune('.')
+		}
+		out.WriteRune(d)
+	}
+
+	if fraction != "" {
+		out.WriteString("," + fraction)
+	}
+
+	return sign + out.String() + " " + amount.Currency
+}
@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"gohub/pkg/money"
+	"strconv"
+	"time"
+)
+
+type ReceiptLine struct {
+	Name      string
+	Quantity  int
+	UnitPrice money.Money
+}
+
+// Receipt is the content of the receipt of a payment
+type Receipt struct {
+	Number        string
+	IssuedAt      time.Time
+	Status        string
+	CustomerName  string
+	CustomerEmail string
+	CustomerPhone string
+	EventName     string
+	Lines         []*ReceiptLine
+	TotalPrice    money.Money
+	DiscountPrice money.Money
+	FinalPrice    money.Money
+}
+
+func (r *Renderer) Receipt(receipt *Receipt) ([]byte, error) {
+	doc := r.newDocument()
+	doc.AddPage()
+	width, _ := doc.GetPageSize()
+	contentWidth := width - 2*pageMargin
+
+	doc.SetFont(fontFamily, "B", 20)
+	doc.CellFormat(contentWidth, 10, "Receipt", "", 1, "L", false, 0, "")
+	doc.SetFont(fontFamily, "", 10)
+	doc.CellFormat(contentWidth, 5, "No. "+receipt.Number, "", 1, "L", false, 0, "")
+	doc.CellFormat(contentWidth, 5, "Issued "+receipt.IssuedAt.Format("02/01/2006 15:04"), "", 1, "L", false, 0, "")
+	doc.CellFormat(contentWidth, 5, "Status "+receipt.Status, "", 1, "L", false, 0, "")
+	doc.Ln(6)
+
+	doc.SetFont(fontFamily, "B", 11)
+	doc.CellFormat(contentWidth, 6, "Billed to", "", 1, "L", false, 0, "")
+	doc.SetFont(fontFamily, "", 10)
+	for _, line := range []string{receipt.CustomerName, receipt.CustomerEmail, receipt.CustomerPhone} {
+		if line == "" {
+			continue
+		}
+		doc.CellFormat(contentWidth, 5, r.text(line), "", 1, "L", false, 0, "")
+	}
+	doc.Ln(4)
+
+	doc.SetFont(fontFamily, "B", 11)
+	doc.MultiCell(contentWidth, 6, r.text(receipt.EventName), "", "L", false)
+	doc.Ln(2)
+
+	columns := []float64{contentWidth - 95, 20, 35, 40}
+	doc.SetFillColor(240, 240, 240)
+	doc.SetFont(fontFamily, "B", 10)
+	for i, header := range []string{"Ticket type", "Qty", "Unit price", "Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		doc.CellFormat(columns[i], 8, header, "B", 0, align, true, 0, "")
+	}
+	doc.Ln(-1)
+
+	doc.SetFont(fontFamily, "", 10)
+	for _, line := range receipt.Lines {
+		doc.CellFormat(columns[0], 7, r.text(line.Name), "B", 0, "L", false, 0, "")
+		doc.CellFormat(columns[1], 7, strconv.Itoa(line.Quantity), "B", 0, "R", false, 0, "")
+		doc.CellFormat(columns[2], 7, formatMoney(line.UnitPrice), "B", 0, "R", false, 0, "")
+		doc.CellFormat(columns[3], 7, formatMoney(line.UnitPrice.Mul(int64(line.Quantity))), "B", 1, "R", false, 0, "")
+	}
+	doc.Ln(4)
+
+	labelWidth := contentWidth - columns[3]
+	totals := [][2]string{
+		{"Subtotal", formatMoney(receipt.TotalPrice)},
+		{"Discount", formatMoney(receipt.DiscountPrice.Mul(-1))},
+	}
+	for _, total := range totals {
+		doc.CellFormat(labelWidth, 6, total[0], "", 0, "R", false, 0, "")
+		doc.CellFormat(columns[3], 6, total[1], "", 1, "R", false, 0, "")
+	}
+	doc.SetFont(fontFamily, "B", 12)
+	doc.CellFormat(labelWidth, 8, "Total", "", 0, "R", false, 0, "")
+	doc.CellFormat(columns[3], 8, formatMoney(receipt.FinalPrice), "", 1, "R", false, 0, "")
+
+	return output(doc)
+}
@@ -0,0 +1,86 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Ticket is the content of a printable ticket, Cover is a JPEG and QrCode a PNG
+type Ticket struct {
+	EventName    string
+	StartTime    string
+	EndTime      string
+	Location     string
+	TicketType   string
+	TicketNo     string
+	CustomerName string
+	Cover        []byte
+	QrCode       []byte
+}
+
+// Tickets renders one page per ticket
+func (r *Renderer) Tickets(tickets []*Ticket) ([]byte, error) {
+	doc := r.newDocument()
+	for i, ticket := range tickets {
+		r.ticketPage(doc, fmt.Sprintf("ticket-%d", i), ticket)
+	}
+
+	return output(doc)
+}
+
+func (r *Renderer) ticketPage(doc *fpdf.Fpdf, name string, ticket *Ticket) {
+	doc.AddPage()
+	width, _ := doc.GetPageSize()
+	contentWidth := width - 2*pageMargin
+
+	if len(ticket.Cover) > 0 {
+		options := fpdf.ImageOptions{ImageType: "JPG"}
+		info := doc.RegisterImageOptionsReader(name+"-cover", options, bytes.NewReader(ticket.Cover))
+		if doc.Ok() && info != nil {
+			height := contentWidth * info.Height() / info.Width()
+			if height > 90 {
+				height = 90
+			}
+			doc.ImageOptions(name+"-cover", pageMargin, doc.GetY(), contentWidth, height, false, options, 0, "")
+			doc.SetY(doc.GetY() + height + 8)
+		}
+		// A broken cover must not cost the attendee their ticket
+		doc.ClearError()
+	}
+
+	doc.SetFont(fontFamily, "B", 20)
+	doc.MultiCell(contentWidth, 9, r.text(ticket.EventName), "", "L", false)
+	doc.Ln(4)
+
+	top := doc.GetY()
+	qrSize := 60.0
+	detailsWidth := contentWidth - qrSize - 10
+
+	rows := [][2]string{
+		{"Time", ticket.StartTime + " - " + ticket.EndTime},
+		{"Location", ticket.Location},
+		{"Ticket type", ticket.TicketType},
+		{"Attendee", ticket.CustomerName},
+		{"Ticket No", ticket.TicketNo},
+	}
+	for _, row := range rows {
+		doc.SetFont(fontFamily, "", 9)
+		doc.SetTextColor(110, 110, 110)
+		doc.CellFormat(detailsWidth, 5, r.text(row[0]), "", 1, "L", false, 0, "")
+		doc.SetFont(fontFamily, "B", 12)
+		doc.SetTextColor(0, 0, 0)
+		doc.MultiCell(detailsWidth, 6, r.text(row[1]), "", "L", false)
+		doc.Ln(2)
+	}
+
+	if len(ticket.QrCode) > 0 {
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		doc.RegisterImageOptionsReader(name+"-qr", options, bytes.NewReader(ticket.QrCode))
+		doc.ImageOptions(name+"-qr", width-pageMargin-qrSize, top, qrSize, qrSize, false, options, 0, "")
+		doc.SetXY(width-pageMargin-qrSize, top+qrSize+1)
+		doc.SetFont(fontFamily, "", 8)
+		doc.CellFormat(qrSize, 4, "Present this code at the entrance", "", 1, "C", false, 0, "")
+	}
+}
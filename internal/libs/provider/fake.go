@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"gohub/internal/libs/logger"
+	"gohub/pkg/money"
+)
+
+const (
+	FakeOutcomeSuccess = "success"
+	FakeOutcomeFailure = "failure"
+	FakeOutcomeExpiry  = "expiry"
+
+	FakeSignatureHeader = "Fake-Signature"
+
+	fakeResolveDelay   = time.Second
+	fakeWebhookTimeout = 10 * time.Second
+)
+
+// FakeProvider keeps checkouts, refunds and discounts in memory. Sessions stay open until Complete, Fail
+// or Expire is called, or resolve on their own when an outcome is configured. Each resolution emits a
+// signed webhook to the handler set with SetWebhookHandler.
+type FakeProvider struct {
+	mu            sync.Mutex
+	sessions      map[string]*fakeSession
+	discounts     map[string]*DiscountParams
+	webhookSecret string
+	outcome       string
+	webhook       func(payload []byte, header http.Header)
+}
+
+type fakeSession struct {
+	session  *Session
+	refunded money.Money
+	refunds  map[string]*Refund
+}
+
+type fakeEvent struct {
+	Id        string `json:"id"`
+	Type      string `json:"type"`
+	SessionId string `json:"sessionId"`
+}
+
+// NewFake returns an in-memory provider, webhooks are posted to webhookUrl when it is set
+func NewFake(webhookSecret string, webhookUrl string, outcome string) *FakeProvider {
+	f := &FakeProvider{
+		sessions:      make(map[string]*fakeSession),
+		discounts:     make(map[string]*DiscountParams),
+		webhookSecret: webhookSecret,
+		outcome:       outcome,
+	}
+
+	if webhookUrl != "" {
+		client := &http.Client{Timeout: fakeWebhookTimeout}
+		f.webhook = func(payload []byte, header http.Header) {
+			req, err := http.NewRequest(http.MethodPost, webhookUrl, bytes.NewReader(payload))
+			if err != nil {
+				logger.Errorf("Failed to build fake webhook: %v", err)
+				return
+			}
+			req.Header = header
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := client.Do(req)
+			if err != nil {
+				logger.Errorf("Failed to deliver fake webhook: %v", err)
+				return
+			}
+			_ = res.Body.Close()
+		}
+	}
+
+	return f
+}
+
+// SetWebhookHandler replaces where the webhooks of the fake are delivered
+func (f *FakeProvider) SetWebhookHandler(handler func(payload []byte, header http.Header)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.webhook = handler
+}
+
+func (f *FakeProvider) CreateCheckout(ctx context.Context, params *CheckoutParams) (*Session, error) {
+	if len(params.Lines) == 0 {
+		return nil, errors.New("checkout has no line items")
+	}
+
+	total := money.Zero(params.Currency)
+	for _, line := range params.Lines {
+		if line.UnitAmount.IsNegative() || line.Quantity <= 0 || line.UnitAmount.Currency != total.Currency {
+			return nil, fmt.Errorf("invalid line item %q", line.Name)
+		}
+		total = total.Add(line.UnitAmount.Mul(line.Quantity))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if params.DiscountId != "" {
+		discount, ok := f.discounts[params.DiscountId]
+		if !ok {
+			return nil, fmt.Errorf("no such discount: %s", params.DiscountId)
+		}
+		if discount.AmountOff.IsPositive() {
+			total = total.Sub(discount.AmountOff.Min(total))
+		} else {
+			total = total.Sub(total.Percent(discount.PercentOff))
+		}
+	}
+
+	url := params.SuccessUrl
+	if f.outcome == FakeOutcomeFailure || f.outcome == FakeOutcomeExpiry {
+		url = params.CancelUrl
+	}
+
+	session := &Session{
+		Id:          "cs_fake_" + fakeId(),
+		Url:         url,
+		Status:      SessionStatusOpen,
+		AmountTotal: total,
+		Metadata:    params.Metadata,
+	}
+	f.sessions[session.Id] = &fakeSession{session: session, refunded: money.Zero(total.Currency), refunds: make(map[string]*Refund)}
+
+	if f.outcome != "" {
+		outcome := f.outcome
+		time.AfterFunc(fakeResolveDelay, func() {
+			if err := f.resolve(session.Id, outcome); err != nil {
+				logger.Errorf("Failed to resolve fake session %s: %v", session.Id, err)
+			}
+		})
+	}
+
+	copied := *session
+	return &copied, nil
+}
+
+func (f *FakeProvider) GetSession(ctx context.Context, sessionId string) (*Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stored, ok := f.sessions[sessionId]
+	if !ok {
+		return nil, fmt.Errorf("no such checkout session: %s", sessionId)
+	}
+
+	copied := *stored.session
+	return &copied, nil
+}
+
+// Complete simulates a successful payment of an open session
+func (f *FakeProvider) Complete(sessionId string) error {
+	return f.resolve(sessionId, FakeOutcomeSuccess)
+}
+
+// Fail simulates a payment the bank declined after the attendee left the checkout page
+func (f *FakeProvider) Fail(sessionId string) error {
+	return f.resolve(sessionId, FakeOutcomeFailure)
+}
+
+// Expire simulates an attendee who never finished the checkout
+func (f *FakeProvider) Expire(sessionId string) error {
+	return f.resolve(sessionId, FakeOutcomeExpiry)
+}
+
+func (f *FakeProvider) resolve(sessionId string, outcome string) error {
+	f.mu.Lock()
+	stored, ok := f.sessions[sessionId]
+	if !ok {
+		f.mu.Unlock()
+		return fmt.Errorf("no such checkout session: %s", sessionId)
+	}
+
+	if stored.session.Status != SessionStatusOpen {
+		f.mu.Unlock()
+		return fmt.Errorf("checkout session %s is %s", sessionId, stored.session.Status)
+	}
+
+	var eventType string
+	switch outcome {
+	case FakeOutcomeSuccess:
+		stored.session.Status = SessionStatusComplete
+		stored.session.IsPaid = true
+		stored.session.PaymentIntentId = "pi_fake_" + fakeId()
+		eventType = EventCheckoutCompleted
+	case FakeOutcomeFailure:
+		stored.session.Status = SessionStatusComplete
+		eventType = EventCheckoutFailed
+	case FakeOutcomeExpiry:
+		stored.session.Status = SessionStatusExpired
+		eventType = EventCheckoutExpired
+	default:
+		f.mu.Unlock()
+		return fmt.Errorf("unknown outcome %q", outcome)
+	}
+	handler := f.webhook
+	f.mu.Unlock()
+
+	if handler == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(&fakeEvent{Id: "evt_fake_" + fakeId(), Type: eventType, SessionId: sessionId})
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, f.sign(payload))
+	handler(payload, header)
+
+	return nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, params *RefundParams) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stored, ok := f.sessions[params.SessionId]
+	if !ok {
+		return nil, fmt.Errorf("no such checkout session: %s", params.SessionId)
+	}
+
+	if refund, ok := stored.refunds[params.IdempotencyKey]; ok && params.IdempotencyKey != "" {
+		return refund, nil
+	}
+
+	if !stored.session.IsPaid {
+		return nil, errors.New("checkout session has no payment to refund")
+	}
+
+	refundable := stored.session.AmountTotal.Sub(stored.refunded)
+	if params.Amount.Currency != refundable.Currency || !params.Amount.IsPositive() || params.Amount.Cmp(refundable) > 0 {
+		return nil, fmt.Errorf("refund amount %s exceeds the refundable amount %s", params.Amount, refundable)
+	}
+
+	refund := &Refund{Id: "re_fake_" + fakeId(), Status: "succeeded"}
+	stored.refunded = stored.refunded.Add(params.Amount)
+	stored.refunds[params.IdempotencyKey] = refund
+
+	return refund, nil
+}
+
+func (f *FakeProvider) CreateDiscount(ctx context.Context, params *DiscountParams) (*Discount, error) {
+	if params.AmountOff.IsNegative() || (params.AmountOff.IsZero() && (params.PercentOff <= 0 || params.PercentOff > 100)) {
+		return nil, fmt.Errorf("invalid discount %v%% %s", params.PercentOff, params.AmountOff)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	discount := &Discount{Id: "coupon_fake_" + fakeId()}
+	copied := *params
+	f.discounts[discount.Id] = &copied
+
+	return discount, nil
+}
+
+func (f *FakeProvider) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	// Anyone can compute the signature of an empty secret
+	if f.webhookSecret == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, f.mac(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event fakeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	return &WebhookEvent{Id: event.Id, Type: event.Type, SessionId: event.SessionId}, nil
+}
+
+func (f *FakeProvider) sign(payload []byte) string {
+	return hex.EncodeToString(f.mac(payload))
+}
+
+func (f *FakeProvider) mac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(f.webhookSecret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func fakeId() string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gohub/pkg/money"
+)
+
+const testWebhookSecret = "whsec_test"
+
+type webhookCall struct {
+	payload []byte
+	header  http.Header
+}
+
+func newTestFake(t *testing.T) (*FakeProvider, *[]webhookCall) {
+	t.Helper()
+
+	var calls []webhookCall
+	fake := NewFake(testWebhookSecret, "", "")
+	fake.SetWebhookHandler(func(payload []byte, header http.Header) {
+		calls = append(calls, webhookCall{payload: payload, header: header})
+	})
+
+	return fake, &calls
+}
+
+func testCheckout(t *testing.T, fake *FakeProvider) *Session {
+	t.Helper()
+
+	session, err := fake.CreateCheckout(context.Background(), &CheckoutParams{
+		Currency: "USD",
+		Lines: []*CheckoutLine{
+			{Name: "General", UnitAmount: money.New(1250, "USD"), Quantity: 2},
+			{Name: "VIP", UnitAmount: money.New(4000, "USD"), Quantity: 1},
+		},
+		SuccessUrl: "https://example.com/success",
+		CancelUrl:  "https://example.com/cancel",
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckout() error = %v", err)
+	}
+
+	return session
+}
+
+func TestFakeCheckoutTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount *DiscountParams
+		want     money.Money
+	}{
+		{name: "no discount", want: money.New(6500, "USD")},
+		{name: "percentage", discount: &DiscountParams{Name: "10%", PercentOff: 10}, want: money.New(5850, "USD")},
+		{name: "fixed amount", discount: &DiscountParams{Name: "5 off", AmountOff: money.New(500, "USD")}, want: money.New(6000, "USD")},
+		{name: "amount above the total", discount: &DiscountParams{Name: "all", AmountOff: money.New(10000, "USD")}, want: money.New(0, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, _ := newTestFake(t)
+
+			params := &CheckoutParams{
+				Currency: "USD",
+				Lines: []*CheckoutLine{
+					{Name: "General", UnitAmount: money.New(1250, "USD"), Quantity: 2},
+					{Name: "VIP", UnitAmount: money.New(4000, "USD"), Quantity: 1},
+				},
+			}
+			if tt.discount != nil {
+				discount, err := fake.CreateDiscount(context.Background(), tt.discount)
+				if err != nil {
+					t.Fatalf("CreateDiscount() error = %v", err)
+				}
+				params.DiscountId = discount.Id
+			}
+
+			session, err := fake.CreateCheckout(context.Background(), params)
+			if err != nil {
+				t.Fatalf("CreateCheckout() error = %v", err)
+			}
+
+			if session.AmountTotal != tt.want {
+				t.Errorf("AmountTotal = %s, want %s", session.AmountTotal, tt.want)
+			}
+			if session.Status != SessionStatusOpen || session.IsPaid {
+				t.Errorf("session is %s, paid %v, want an unpaid open session", session.Status, session.IsPaid)
+			}
+		})
+	}
+}
+
+func TestFakeCheckoutRejectsInvalidLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []*CheckoutLine
+	}{
+		{name: "no lines"},
+		{name: "negative price", lines: []*CheckoutLine{{Name: "General", UnitAmount: money.New(-1, "USD"), Quantity: 1}}},
+		{name: "no quantity", lines: []*CheckoutLine{{Name: "General", UnitAmount: money.New(100, "USD"), Quantity: 0}}},
+		{name: "other currency", lines: []*CheckoutLine{{Name: "General", UnitAmount: money.New(100, "EUR"), Quantity: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, _ := newTestFake(t)
+
+			if _, err := fake.CreateCheckout(context.Background(), &CheckoutParams{Currency: "USD", Lines: tt.lines}); err == nil {
+				t.Error("CreateCheckout() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestFakeCheckoutWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolve    func(f *FakeProvider, sessionId string) error
+		wantType   string
+		wantStatus string
+		wantPaid   bool
+	}{
+		{name: "completed", resolve: (*FakeProvider).Complete, wantType: EventCheckoutCompleted, wantStatus: SessionStatusComplete, wantPaid: true},
+		{name: "failed", resolve: (*FakeProvider).Fail, wantType: EventCheckoutFailed, wantStatus: SessionStatusComplete},
+		{name: "expired", resolve: (*FakeProvider).Expire, wantType: EventCheckoutExpired, wantStatus: SessionStatusExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, calls := newTestFake(t)
+			session := testCheckout(t, fake)
+
+			if err := tt.resolve(fake, session.Id); err != nil {
+				t.Fatalf("resolve error = %v", err)
+			}
+			if len(*calls) != 1 {
+				t.Fatalf("got %d webhooks, want 1", len(*calls))
+			}
+
+			call := (*calls)[0]
+			event, err := fake.VerifyWebhook(call.payload, call.header)
+			if err != nil {
+				t.Fatalf("VerifyWebhook() error = %v", err)
+			}
+			if event.Type != tt.wantType || event.SessionId != session.Id {
+				t.Errorf("event = %s for %s, want %s for %s", event.Type, event.SessionId, tt.wantType, session.Id)
+			}
+
+			stored, err := fake.GetSession(context.Background(), session.Id)
+			if err != nil {
+				t.Fatalf("GetSession() error = %v", err)
+			}
+			if stored.Status != tt.wantStatus || stored.IsPaid != tt.wantPaid {
+				t.Errorf("session is %s, paid %v, want %s, paid %v", stored.Status, stored.IsPaid, tt.wantStatus, tt.wantPaid)
+			}
+
+			if err := fake.Complete(session.Id); err == nil {
+				t.Error("resolving the session twice error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestFakeVerifyWebhookRejectsForgeries(t *testing.T) {
+	fake, calls := newTestFake(t)
+	session := testCheckout(t, fake)
+	if err := fake.Complete(session.Id); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	call := (*calls)[0]
+
+	tests := []struct {
+		name     string
+		provider *FakeProvider
+		payload  []byte
+		header   http.Header
+	}{
+		{name: "tampered payload", provider: fake, payload: append([]byte(" "), call.payload...), header: call.header},
+		{name: "missing signature", provider: fake, payload: call.payload, header: http.Header{}},
+		{name: "other secret", provider: NewFake("whsec_other", "", ""), payload: call.payload, header: call.header},
+		{name: "empty secret", provider: NewFake("", "", ""), payload: call.payload, header: emptySecretSignature(call.payload)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.provider.VerifyWebhook(tt.payload, tt.header); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifyWebhook() error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+// emptySecretSignature signs the payload the way anyone could without knowing a secret
+func emptySecretSignature(payload []byte) http.Header {
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, NewFake("", "", "").sign(payload))
+	return header
+}
+
+func TestFakeRefund(t *testing.T) {
+	type refundCall struct {
+		amount  money.Money
+		key     string
+		wantErr bool
+	}
+
+	tests := []struct {
+		name    string
+		unpaid  bool
+		refunds []refundCall
+	}{
+		{
+			name:    "full refund",
+			refunds: []refundCall{{amount: money.New(6500, "USD"), key: "a"}},
+		},
+		{
+			name: "partial refunds up to the total",
+			refunds: []refundCall{
+				{amount: money.New(4000, "USD"), key: "a"},
+				{amount: money.New(2500, "USD"), key: "b"},
+				{amount: money.New(1, "USD"), key: "c", wantErr: true},
+			},
+		},
+		{
+			name: "retry with the same key is not refunded twice",
+			refunds: []refundCall{
+				{amount: money.New(6500, "USD"), key: "a"},
+				{amount: money.New(6500, "USD"), key: "a"},
+			},
+		},
+		{
+			name:    "above the total",
+			refunds: []refundCall{{amount: money.New(6501, "USD"), key: "a", wantErr: true}},
+		},
+		{
+			name:    "other currency",
+			refunds: []refundCall{{amount: money.New(100, "EUR"), key: "a", wantErr: true}},
+		},
+		{
+			name:    "zero amount",
+			refunds: []refundCall{{amount: money.New(0, "USD"), key: "a", wantErr: true}},
+		},
+		{
+			name:    "unpaid session",
+			unpaid:  true,
+			refunds: []refundCall{{amount: money.New(100, "USD"), key: "a", wantErr: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, _ := newTestFake(t)
+			session := testCheckout(t, fake)
+			if !tt.unpaid {
+				if err := fake.Complete(session.Id); err != nil {
+					t.Fatalf("Complete() error = %v", err)
+				}
+			}
+
+			ids := map[string]string{}
+			for i, call := range tt.refunds {
+				refund, err := fake.Refund(context.Background(), &RefundParams{SessionId: session.Id, Amount: call.amount, IdempotencyKey: call.key})
+				if (err != nil) != call.wantErr {
+					t.Fatalf("refund %d error = %v, want error %v", i, err, call.wantErr)
+				}
+				if err != nil {
+					continue
+				}
+
+				if id, ok := ids[call.key]; ok && id != refund.Id {
+					t.Errorf("refund %d with key %s = %s, want the earlier refund %s", i, call.key, refund.Id, id)
+				}
+				ids[call.key] = refund.Id
+			}
+		})
+	}
+}
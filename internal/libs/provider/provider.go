@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gohub/internal/libs/logger"
+	"gohub/pkg/money"
+)
+
+const (
+	Stripe = "stripe"
+	Fake   = "fake"
+
+	SessionStatusOpen     = "open"
+	SessionStatusComplete = "complete"
+	SessionStatusExpired  = "expired"
+
+	EventCheckoutCompleted = "checkout.completed"
+	EventCheckoutFailed    = "checkout.failed"
+	EventCheckoutExpired   = "checkout.expired"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// PaymentProvider is the payment processor that takes the money of the attendees
+type PaymentProvider interface {
+	CreateCheckout(ctx context.Context, params *CheckoutParams) (*Session, error)
+	GetSession(ctx context.Context, sessionId string) (*Session, error)
+	Refund(ctx context.Context, params *RefundParams) (*Refund, error)
+	CreateDiscount(ctx context.Context, params *DiscountParams) (*Discount, error)
+	// VerifyWebhook checks the signature of a webhook call and returns the event it carries,
+	// events the application does not act on come back with an empty Type
+	VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+}
+
+// CheckoutParams describes a hosted checkout page, every line is in the currency of the checkout. A zero
+// ExpiresAt leaves the session open for as long as the provider allows.
+type CheckoutParams struct {
+	Currency      string
+	CustomerEmail string
+	Lines         []*CheckoutLine
+	DiscountId    string
+	SuccessUrl    string
+	CancelUrl     string
+	Metadata      map[string]string
+	ExpiresAt     time.Time
+}
+
+type CheckoutLine struct {
+	Name       string
+	UnitAmount money.Money
+	Quantity   int64
+}
+
+type Session struct {
+	Id              string
+	Url             string
+	Status          string
+	IsPaid          bool
+	PaymentIntentId string
+	AmountTotal     money.Money
+	Metadata        map[string]string
+}
+
+// RefundParams refunds part of the payment made through a checkout session
+type RefundParams struct {
+	SessionId      string
+	Amount         money.Money
+	Reason         string
+	IdempotencyKey string
+}
+
+type Refund struct {
+	Id     string
+	Status string
+}
+
+// DiscountParams creates a one-off discount that can be applied to a checkout, either a percentage or a
+// fixed amount when AmountOff is set
+type DiscountParams struct {
+	Name       string
+	PercentOff float64
+	AmountOff  money.Money
+}
+
+type Discount struct {
+	Id string
+}
+
+type WebhookEvent struct {
+	Id        string
+	Type      string
+	SessionId string
+}
+
+// New returns the configured payment provider, Stripe unless the in-memory fake is asked for by name.
+// A provider missing its secrets is refused rather than replaced, the webhooks could not be verified.
+func New(name string, secretKey string, webhookSecret string, webhookUrl string, fakeOutcome string) (PaymentProvider, error) {
+	switch name {
+	case Fake:
+		if webhookSecret == "" {
+			return nil, errors.New("the fake payment provider needs a webhook secret")
+		}
+
+		logger.Warn("Payments are simulated in memory by the fake provider")
+		return NewFake(webhookSecret, webhookUrl, fakeOutcome), nil
+	case Stripe, "":
+		if secretKey == "" || webhookSecret == "" {
+			return nil, errors.New("stripe needs both a secret key and a webhook secret")
+		}
+
+		return NewStripe(secretKey, webhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", name)
+	}
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"os"
+	"testing"
+
+	"gohub/internal/libs/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Initialize("test")
+	os.Exit(m.Run())
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name          string
+		provider      string
+		secretKey     string
+		webhookSecret string
+		wantFake      bool
+		wantErr       bool
+	}{
+		{name: "stripe", provider: Stripe, secretKey: "sk_test", webhookSecret: "whsec_test"},
+		{name: "stripe by default", secretKey: "sk_test", webhookSecret: "whsec_test"},
+		{name: "stripe without a key", provider: Stripe, webhookSecret: "whsec_test", wantErr: true},
+		{name: "stripe without a webhook secret", provider: Stripe, secretKey: "sk_test", wantErr: true},
+		{name: "nothing configured", wantErr: true},
+		{name: "fake", provider: Fake, webhookSecret: "whsec_test", wantFake: true},
+		{name: "fake without a webhook secret", provider: Fake, wantErr: true},
+		{name: "unknown provider", provider: "paypal", secretKey: "sk_test", webhookSecret: "whsec_test", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.provider, tt.secretKey, tt.webhookSecret, "", "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if _, isFake := got.(*FakeProvider); isFake != tt.wantFake {
+				t.Errorf("New() = %T, want fake %v", got, tt.wantFake)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"gohub/pkg/money"
+
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/client"
+	"github.com/stripe/stripe-go/v81/webhook"
+)
+
+type stripeProvider struct {
+	client        *client.API
+	webhookSecret string
+}
+
+// NewStripe returns a PaymentProvider backed by Stripe, each provider holds its own key
+func NewStripe(secretKey string, webhookSecret string) PaymentProvider {
+	api := &client.API{}
+	api.Init(secretKey, nil)
+
+	return &stripeProvider{client: api, webhookSecret: webhookSecret}
+}
+
+func (p *stripeProvider) CreateCheckout(ctx context.Context, params *CheckoutParams) (*Session, error) {
+	var lineItems []*stripe.CheckoutSessionLineItemParams
+	for _, line := range params.Lines {
+		lineItems = append(lineItems, &stripe.CheckoutSessionLineItemParams{
+			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+				Currency: stripe.String(strings.ToLower(params.Currency)),
+				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+					Name: stripe.String(line.Name),
+				},
+				UnitAmount: stripe.Int64(toStripeAmount(line.UnitAmount)),
+			},
+			Quantity: stripe.Int64(line.Quantity),
+		})
+	}
+
+	sessionParams := &stripe.CheckoutSessionParams{
+		PaymentMethodTypes: stripe.StringSlice([]string{"card"}),
+		LineItems:          lineItems,
+		Mode:               stripe.String(string(stripe.CheckoutSessionModePayment)),
+		SuccessURL:         stripe.String(params.SuccessUrl),
+		CancelURL:          stripe.String(params.CancelUrl),
+		CustomerEmail:      stripe.String(params.CustomerEmail),
+		Metadata:           params.Metadata,
+	}
+	sessionParams.Context = ctx
+
+	if !params.ExpiresAt.IsZero() {
+		sessionParams.ExpiresAt = stripe.Int64(params.ExpiresAt.Unix())
+	}
+
+	if params.DiscountId != "" {
+		sessionParams.Discounts = []*stripe.CheckoutSessionDiscountParams{
+			{Coupon: stripe.String(params.DiscountId)},
+		}
+	}
+
+	session, err := p.client.CheckoutSessions.New(sessionParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSession(session), nil
+}
+
+func (p *stripeProvider) GetSession(ctx context.Context, sessionId string) (*Session, error) {
+	params := &stripe.CheckoutSessionParams{}
+	params.Context = ctx
+	session, err := p.client.CheckoutSessions.Get(sessionId, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSession(session), nil
+}
+
+func (p *stripeProvider) Refund(ctx context.Context, params *RefundParams) (*Refund, error) {
+	session, err := p.GetSession(ctx, params.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.PaymentIntentId == "" {
+		return nil, errors.New("checkout session has no payment to refund")
+	}
+
+	refundParams := &stripe.RefundParams{
+		PaymentIntent: stripe.String(session.PaymentIntentId),
+		Amount:        stripe.Int64(toStripeAmount(params.Amount)),
+		Metadata:      map[string]string{"reason": params.Reason},
+	}
+	refundParams.Context = ctx
+	refundParams.SetIdempotencyKey(params.IdempotencyKey)
+
+	refund, err := p.client.Refunds.New(refundParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Refund{Id: refund.ID, Status: string(refund.Status)}, nil
+}
+
+func (p *stripeProvider) CreateDiscount(ctx context.Context, params *DiscountParams) (*Discount, error) {
+	couponParams := &stripe.CouponParams{
+		Duration: stripe.String(string(stripe.CouponDurationOnce)),
+		Name:     stripe.String(params.Name),
+	}
+	if params.AmountOff.IsPositive() {
+		couponParams.AmountOff = stripe.Int64(toStripeAmount(params.AmountOff))
+		couponParams.Currency = stripe.String(strings.ToLower(params.AmountOff.Currency))
+		couponParams.MaxRedemptions = stripe.Int64(1)
+	} else {
+		couponParams.PercentOff = stripe.Float64(params.PercentOff)
+	}
+	couponParams.Context = ctx
+
+	coupon, err := p.client.Coupons.New(couponParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Discount{Id: coupon.ID}, nil
+}
+
+func (p *stripeProvider) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	event, err := webhook.ConstructEventWithOptions(payload, header.Get("Stripe-Signature"), p.webhookSecret,
+		webhook.ConstructEventOptions{IgnoreAPIVersionMismatch: true})
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	result := &WebhookEvent{Id: event.ID}
+
+	var eventType string
+	switch event.Type {
+	case stripe.EventTypeCheckoutSessionCompleted, stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded:
+		eventType = EventCheckoutCompleted
+	case stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
+		eventType = EventCheckoutFailed
+	case stripe.EventTypeCheckoutSessionExpired:
+		eventType = EventCheckoutExpired
+	default:
+		return result, nil
+	}
+
+	var session stripe.CheckoutSession
+	if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
+		return nil, err
+	}
+
+	// Delayed payment methods complete the session before the money arrives, the async event follows
+	if eventType == EventCheckoutCompleted && session.PaymentStatus == stripe.CheckoutSessionPaymentStatusUnpaid {
+		return result, nil
+	}
+
+	result.Type = eventType
+	result.SessionId = session.ID
+	return result, nil
+}
+
+func toSession(session *stripe.CheckoutSession) *Session {
+	result := &Session{
+		Id:          session.ID,
+		Url:         session.URL,
+		Status:      string(session.Status),
+		IsPaid:      session.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid || session.PaymentStatus == stripe.CheckoutSessionPaymentStatusNoPaymentRequired,
+		AmountTotal: fromStripeAmount(session.AmountTotal, string(session.Currency)),
+		Metadata:    session.Metadata,
+	}
+	if session.PaymentIntent != nil {
+		result.PaymentIntentId = session.PaymentIntent.ID
+	}
+
+	return result
+}
+
+// stripeZeroDecimal and stripeThreeDecimal are the currencies Stripe does not count in cents,
+// see https://docs.stripe.com/currencies#zero-decimal
+var (
+	stripeZeroDecimal  = []string{"BIF", "CLP", "DJF", "GNF", "JPY", "KMF", "KRW", "MGA", "PYG", "RWF", "UGX", "VND", "VUV", "XAF", "XOF", "XPF"}
+	stripeThreeDecimal = []string{"BHD", "JOD", "KWD", "OMR", "TND"}
+)
+
+func stripeExponent(currency string) int {
+	currency = strings.ToUpper(currency)
+	for _, code := range stripeZeroDecimal {
+		if code == currency {
+			return 0
+		}
+	}
+	for _, code := range stripeThreeDecimal {
+		if code == currency {
+			return 3
+		}
+	}
+
+	return 2
+}
+
+// toStripeAmount converts minor units to the unit Stripe expects for the currency, they differ for
+// currencies like ISK that Stripe keeps two decimals for
+func toStripeAmount(amount money.Money) int64 {
+	shift := stripeExponent(amount.Currency) - money.Exponent(amount.Currency)
+	if shift < 0 {
+		return amount.Scale(1, pow10(-shift)).Amount
+	}
+
+	return amount.Amount * pow10(shift)
+}
+
+func fromStripeAmount(amount int64, currency string) money.Money {
+	shift := money.Exponent(currency) - stripeExponent(currency)
+	if shift < 0 {
+		return money.New(amount, currency).Scale(1, pow10(-shift))
+	}
+
+	return money.New(amount*pow10(shift), currency)
+}
+
+func pow10(exponent int) int64 {
+	value := int64(1)
+	for ; exponent > 0; exponent-- {
+		value *= 10
+	}
+
+	return value
+}
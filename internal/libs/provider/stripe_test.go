@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"testing"
+
+	"gohub/pkg/money"
+)
+
+func TestStripeAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount money.Money
+		stripe int64
+		back   money.Money
+	}{
+		{name: "cents", amount: money.New(1250, "USD"), stripe: 1250, back: money.New(1250, "USD")},
+		{name: "zero decimal", amount: money.New(150000, "VND"), stripe: 150000, back: money.New(150000, "VND")},
+		{name: "zero decimal yen", amount: money.New(1200, "JPY"), stripe: 1200, back: money.New(1200, "JPY")},
+		{name: "lowercase currency", amount: money.Money{Amount: 1200, Currency: "jpy"}, stripe: 1200, back: money.New(1200, "JPY")},
+		{name: "three decimal", amount: money.New(1250, "KWD"), stripe: 12500, back: money.New(1250, "KWD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toStripeAmount(tt.amount); got != tt.stripe {
+				t.Errorf("toStripeAmount(%v) = %d, want %d", tt.amount, got, tt.stripe)
+			}
+			if got := fromStripeAmount(tt.stripe, tt.amount.Currency); got != tt.back {
+				t.Errorf("fromStripeAmount(%d) = %v, want %v", tt.stripe, got, tt.back)
+			}
+		})
+	}
+}
+
+func TestStripeExponent(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     int
+	}{
+		{currency: "USD", want: 2},
+		{currency: "EUR", want: 2},
+		{currency: "VND", want: 0},
+		{currency: "jpy", want: 0},
+		{currency: "KRW", want: 0},
+		{currency: "KWD", want: 3},
+		{currency: "BHD", want: 3},
+		{currency: "ISK", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			if got := stripeExponent(tt.currency); got != tt.want {
+				t.Errorf("stripeExponent(%s) = %d, want %d", tt.currency, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package socketio
 
 import (
+	"errors"
 	"github.com/googollee/go-socket.io/engineio"
 	"github.com/googollee/go-socket.io/engineio/transport"
 	"github.com/googollee/go-socket.io/engineio/transport/polling"
 	"github.com/googollee/go-socket.io/engineio/transport/websocket"
 	"gohub/internal/libs/logger"
+	"gohub/pkg/jwt"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	socketio "github.com/googollee/go-socket.io"
@@ -17,11 +20,26 @@ type Server struct {
 	server *socketio.Server
 }
 
+// Notifier pushes real-time events to connected clients
+type Notifier interface {
+	EmitToUser(userId string, event string, data interface{})
+	EmitToRoom(room string, event string, data interface{})
+	LeaveRoom(userId string, room string)
+}
+
 var allowOriginFunc = func(r *http.Request) bool {
 	return true
 }
 
-var socketConnect = make(map[string]string)
+var (
+	errMissingToken = errors.New("missing access token")
+	errInvalidToken = errors.New("invalid access token")
+)
+
+var (
+	socketConnect = make(map[string]string)
+	socketMutex   sync.RWMutex
+)
 
 // NewServer creates a new instance of Socket.IO server
 func NewServer() (*Server, error) {
@@ -38,17 +56,20 @@ func NewServer() (*Server, error) {
 		},
 	})
 
-	// Handle connection event
+	// Handle connection event, the user is the one of the access token and connections without one are refused
 	server.OnConnect("/", func(s socketio.Conn) error {
-		url := s.URL()
-		clientID := (&url).Query().Get("id")
-		if clientID != "" {
-			logger.Info("User connected with ID:", clientID)
-			logger.Info("Client connected with ID:", s.ID())
-			socketConnect[clientID] = s.ID()
-		} else {
-			logger.Info("Client connected without ID")
+		clientID, err := authenticate(s)
+		if err != nil {
+			logger.Info("Client refused: ", s.ID(), " ", err)
+			return err
 		}
+
+		logger.Info("User connected with ID:", clientID)
+		logger.Info("Client connected with ID:", s.ID())
+		s.SetContext(clientID)
+		socketMutex.Lock()
+		socketConnect[clientID] = s.ID()
+		socketMutex.Unlock()
 		return nil
 	})
 
@@ -58,7 +79,7 @@ func NewServer() (*Server, error) {
 		followeeID := data["followee_id"]
 		logger.Info("Follow user")
 
-		server.BroadcastToRoom("/", socketConnect[followeeID], "notify_follow", map[string]string{
+		server.BroadcastToRoom("/", socketID(followeeID), "notify_follow", map[string]string{
 			"follower_id": followerID,
 		})
 	})
@@ -68,7 +89,7 @@ func NewServer() (*Server, error) {
 		organizerId := data["organizer_id"]
 		logger.Info("Review Event")
 
-		server.BroadcastToRoom("/", socketConnect[organizerId], "notify_review", map[string]string{
+		server.BroadcastToRoom("/", socketID(organizerId), "notify_review", map[string]string{
 			"userName": userName,
 		})
 	})
@@ -79,7 +100,7 @@ func NewServer() (*Server, error) {
 
 		for _, inviteeId := range inviteeIds {
 			logger.Info("Invitation User")
-			server.BroadcastToRoom("/", socketConnect[inviteeId], "notify_invitation", map[string]string{
+			server.BroadcastToRoom("/", socketID(inviteeId), "notify_invitation", map[string]string{
 				"message": "You have a new invitation",
 			})
 		}
@@ -90,32 +111,11 @@ func NewServer() (*Server, error) {
 		organizerId := data["organizer_id"]
 		logger.Info("Buy Tickets")
 
-		server.BroadcastToRoom("/", socketConnect[organizerId], "notify_buy_tickets", map[string]string{
+		server.BroadcastToRoom("/", socketID(organizerId), "notify_buy_tickets", map[string]string{
 			"userName": userName,
 		})
 	})
 
-	// Join Room
-	server.OnEvent("/", "join_conversation", func(s socketio.Conn, conversationID string) {
-		logger.Info("Client joined conversation:", conversationID)
-		s.Join(conversationID)
-	})
-
-	// Handle private messages
-	server.OnEvent("/", "send_message", func(s socketio.Conn, data map[string]string) {
-		senderID := data["sender_id"]
-		conversationID := data["conversation_id"]
-		message := data["message"]
-
-		logger.Info("Send message from userId: ", senderID, " to conversationId :", conversationID, " with message: ", message)
-
-		// Send message to all clients in the room
-		server.BroadcastToRoom("/", conversationID, "receive_message", map[string]string{
-			"sender_id": senderID,
-			"message":   message,
-		})
-	})
-
 	//Logout
 	server.OnEvent("/", "logout", func(s socketio.Conn) {
 		log.Println("Client logged out:", s.ID())
@@ -126,25 +126,107 @@ func NewServer() (*Server, error) {
 
 	// Handle disconnection event
 	server.OnDisconnect("/", func(s socketio.Conn, reason string) {
+		socketMutex.Lock()
 		for key, value := range socketConnect {
 			if value == s.ID() {
 				delete(socketConnect, key)
 				break
 			}
 		}
+		socketMutex.Unlock()
 		log.Println("Disconnected:", s.ID(), "Reason:", reason)
 	})
 
+	return &Server{server: server}, nil
+}
+
+// authenticate reads the user id out of the access token sent in the Authorization header, or in the token
+// query parameter for clients that cannot set headers
+func authenticate(s socketio.Conn) (string, error) {
+	token := s.RemoteHeader().Get("Authorization")
+	if token == "" {
+		url := s.URL()
+		token = (&url).Query().Get("token")
+	}
+
+	if token == "" {
+		return "", errMissingToken
+	}
+
+	payload, err := jwt.ValidateToken(token)
+	if err != nil || payload == nil || payload["type"] != jwt.AccessTokenType {
+		return "", errInvalidToken
+	}
+
+	userId, _ := payload["id"].(string)
+	if userId == "" {
+		return "", errInvalidToken
+	}
+
+	return userId, nil
+}
+
+// OnEvent registers a handler for an event sent by clients, it lets domains handle their own events
+func (s *Server) OnEvent(event string, f interface{}) {
+	s.server.OnEvent("/", event, f)
+}
+
+// UserId returns the id of the user the connection was opened for, or an empty string for anonymous clients
+func UserId(conn socketio.Conn) string {
+	userId, _ := conn.Context().(string)
+	return userId
+}
+
+// EmitToUser sends an event to the socket of a connected user, it does nothing when the user is offline
+func (s *Server) EmitToUser(userId string, event string, data interface{}) {
+	id := socketID(userId)
+	if id == "" {
+		return
+	}
+
+	s.server.BroadcastToRoom("/", id, event, data)
+}
+
+// EmitToRoom sends an event to every client that joined the room
+func (s *Server) EmitToRoom(room string, event string, data interface{}) {
+	s.server.BroadcastToRoom("/", room, event, data)
+}
+
+// LeaveRoom removes the socket of a connected user from the room
+func (s *Server) LeaveRoom(userId string, room string) {
+	id := socketID(userId)
+	if id == "" {
+		return
+	}
+
+	// Leaving inside ForEach would deadlock on the room lock
+	var target socketio.Conn
+	s.server.ForEach("/", room, func(conn socketio.Conn) {
+		if conn.ID() == id {
+			target = conn
+		}
+	})
+
+	if target != nil {
+		target.Leave(room)
+	}
+}
+
+func socketID(userId string) string {
+	socketMutex.RLock()
+	defer socketMutex.RUnlock()
+
+	return socketConnect[userId]
+}
+
+// Run the Socket.IO server on port 9000, the event handlers must be registered before
+func (s *Server) Run(port int) error {
 	go func() {
-		if err := server.Serve(); err != nil {
+		if err := s.server.Serve(); err != nil {
 			log.Fatalf("Socket.IO listen error: %s\n", err)
 		}
 	}()
-	return &Server{server: server}, nil
-}
 
-// Run the Socket.IO server on port 9000
-func (s *Server) Run(port int) error {
 	logger.Info("Socket.IO server is listening on PORT: ", port)
 	return http.ListenAndServe(":9000", s.server)
 }
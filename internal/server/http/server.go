@@ -10,11 +10,14 @@ import (
 	categoryHttp "gohub/domains/categories/port/http"
 	commandHttp "gohub/domains/commands/port/http"
 	conversationHttp "gohub/domains/conversations/port/http"
+	conversationSocket "gohub/domains/conversations/port/socket"
 	couponHttp "gohub/domains/coupons/port/http"
 	eventHttp "gohub/domains/events/port/http"
 	expenseHttp "gohub/domains/expense/port/http"
 	functionHttp "gohub/domains/functions/port/http"
+	notificationHttp "gohub/domains/notifications/port/http"
 	paymentHttp "gohub/domains/payments/port/http"
+	payoutHttp "gohub/domains/payouts/port/http"
 	permissionHttp "gohub/domains/permissions/port/http"
 	reviewHttp "gohub/domains/reviews/port/http"
 	routeHttp "gohub/domains/roles/port/http"
@@ -27,7 +30,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"gohub/internal/libs/logger"
+	"gohub/internal/libs/mailer"
+	"gohub/internal/libs/provider"
 	"gohub/internal/libs/validation"
+	socketio "gohub/internal/libs/websocket"
 
 	"gohub/configs"
 	"gohub/database"
@@ -39,14 +45,20 @@ type Server struct {
 	cfg       *configs.Config
 	validator validation.Validation
 	db        database.IDatabase
+	socket    *socketio.Server
+	mailer    mailer.Mailer
+	payments  provider.PaymentProvider
 }
 
-func NewServer(validator validation.Validation, db database.IDatabase) *Server {
+func NewServer(validator validation.Validation, db database.IDatabase, socket *socketio.Server, mailer mailer.Mailer, payments provider.PaymentProvider) *Server {
 	return &Server{
 		engine:    gin.Default(),
 		cfg:       configs.GetConfig(),
 		validator: validator,
 		db:        db,
+		socket:    socket,
+		mailer:    mailer,
+		payments:  payments,
 	}
 }
 
@@ -95,7 +107,7 @@ func (s Server) MapRoutes() error {
 	authHttp.Routes(routesV1, s.db, s.validator)
 	userHttp.Routes(routesV1, s.db, s.validator)
 	reviewHttp.Routes(routesV1, s.db, s.validator)
-	conversationHttp.Routes(routesV1, s.db, s.validator)
+	conversationHttp.Routes(routesV1, s.db, s.validator, s.socket)
 	categoryHttp.Routes(routesV1, s.db, s.validator)
 	eventHttp.Routes(routesV1, s.db, s.validator)
 	routeHttp.Routes(routesV1, s.db, s.validator)
@@ -105,8 +117,16 @@ func (s Server) MapRoutes() error {
 	couponHttp.Routes(routesV1, s.db, s.validator)
 	expenseHttp.Routes(routesV1, s.db, s.validator)
 	statisticHttp.Routes(routesV1, s.db, s.validator)
-	ticketHttp.Routes(routesV1, s.db, s.validator)
-	paymentHttp.Routes(routesV1, s.db, s.validator)
+	ticketHttp.Routes(routesV1, s.db, s.validator, s.socket)
+	paymentHttp.Routes(routesV1, s.db, s.validator, s.socket, s.mailer, s.payments)
+	notificationHttp.Routes(routesV1, s.db, s.validator, s.socket, s.mailer)
+	payoutHttp.Routes(routesV1, s.db, s.validator)
+
+	return nil
+}
+
+func (s Server) MapSocketEvents() error {
+	conversationSocket.Routes(s.socket, s.db, s.validator)
 
 	return nil
 }
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"gohub/configs"
+	"gohub/database"
+	eventRepo "gohub/domains/events/repository"
+	eventService "gohub/domains/events/service"
+	notificationRepo "gohub/domains/notifications/repository"
+	notificationService "gohub/domains/notifications/service"
+	paymentRepo "gohub/domains/payments/repository"
+	paymentService "gohub/domains/payments/service"
+	payoutRepo "gohub/domains/payouts/repository"
+	payoutService "gohub/domains/payouts/service"
+	ticketRepo "gohub/domains/tickets/repository"
+	ticketService "gohub/domains/tickets/service"
+	"gohub/internal/libs/logger"
+	"gohub/internal/libs/mailer"
+	"gohub/internal/libs/provider"
+	"gohub/internal/libs/validation"
+	socketio "gohub/internal/libs/websocket"
+	"sync"
+	"time"
+)
+
+// Job is a background task run on a fixed interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Worker struct {
+	jobs []*Job
+}
+
+func NewWorker(validator validation.Validation, db database.IDatabase, notifier socketio.Notifier, mailer mailer.Mailer, payments provider.PaymentProvider) *Worker {
+	notificationSvc := notificationService.NewNotificationService(
+		validator,
+		notificationRepo.NewNotificationRepository(db),
+		notifier,
+		mailer,
+	)
+
+	paymentSvc := paymentService.NewPaymentService(
+		validator,
+		paymentRepo.NewPaymentRepository(db),
+		ticketService.NewTicketService(validator, ticketRepo.NewTicketRepository(db), notifier),
+		mailer,
+		payments,
+	)
+
+	payoutSvc := payoutService.NewPayoutService(validator, payoutRepo.NewPayoutRepository(db))
+
+	eventSvc := eventService.NewEventService(validator, eventRepo.NewEventRepository(db))
+
+	return &Worker{
+		jobs: []*Job{
+			{Name: "event reminders", Interval: time.Minute, Run: notificationSvc.SendDueReminders},
+			{Name: "bank transfer expiry", Interval: configs.BankTransferExpiryTick, Run: paymentSvc.ExpireTransfers},
+			{Name: "checkout expiry", Interval: configs.CheckoutExpiryTick, Run: paymentSvc.ExpireCheckouts},
+			{Name: "organizer payouts", Interval: configs.PayoutScheduleTick, Run: payoutSvc.SchedulePayouts},
+			{Name: "event lifecycle", Interval: configs.EventLifecycleTick, Run: eventSvc.AdvanceEventStates},
+			{Name: "cancelled event refunds", Interval: configs.EventLifecycleTick, Run: paymentSvc.RefundCancelledEvents},
+			{Name: "pending refunds", Interval: configs.PendingRefundGrace, Run: paymentSvc.CompletePendingRefunds},
+			{Name: "cancellation notices", Interval: time.Minute, Run: notificationSvc.NotifyCancelledEvents},
+			{Name: "reschedule notices", Interval: time.Minute, Run: notificationSvc.NotifyRescheduledEvents},
+		},
+	}
+}
+
+// Run starts every job and blocks until the context is cancelled
+func (w *Worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, job := range w.jobs {
+		wg.Add(1)
+		go func(job *Job) {
+			defer wg.Done()
+			w.loop(ctx, job)
+		}(job)
+	}
+
+	logger.Info("Worker is running with jobs: ", len(w.jobs))
+	wg.Wait()
+	return nil
+}
+
+func (w *Worker) loop(ctx context.Context, job *Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx); err != nil {
+			logger.Errorf("Worker job %s fail, error: %s", job.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"github.com/gorilla/sessions"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/google"
+	"gohub/internal/libs/mailer"
+	"gohub/internal/libs/provider"
 	socketioServer "gohub/internal/libs/websocket"
 	httpServer "gohub/internal/server/http"
+	"gohub/internal/server/worker"
+	"gohub/pkg/utils"
 	"log"
 	"sync"
+	// Event timezones are resolved from the embedded database, hosts and images may not ship one
+	_ "time/tzdata"
 
 	"gohub/internal/libs/logger"
 	"gohub/internal/libs/validation"
@@ -42,6 +49,13 @@ func main() {
 	cfg := configs.LoadConfig(".")
 	logger.Initialize(cfg.Environment)
 
+	if err := cfg.CheckSecrets(); err != nil {
+		logger.Fatal("Invalid configuration", err)
+	}
+
+	scanKey, _ := cfg.ScanKey()
+	logger.Infof("Offline manifests are signed with the Ed25519 public key %s, pin it in the door devices", utils.Ed25519PublicKey(scanKey))
+
 	db, err := database.NewDatabase(cfg.DatabaseURI)
 	if err != nil {
 		logger.Fatal("Cannot connect to database", err)
@@ -64,8 +78,12 @@ func main() {
 
 	validator := validation.New()
 
-	// Initialize HTTP server
-	httpSvr := httpServer.NewServer(validator, db)
+	mail := mailer.New(cfg.SmtpHost, cfg.SmtpPort, cfg.SmtpUsername, cfg.SmtpPassword, cfg.SmtpSender)
+
+	payments, err := provider.New(cfg.PaymentProvider, cfg.StripeSecretKey, cfg.StripeWebhookSecret, cfg.PaymentWebhookUrl, cfg.PaymentFakeOutcome)
+	if err != nil {
+		logger.Fatal("Cannot initialize payment provider", err)
+	}
 
 	// Initialize Socket.IO server
 	socketSvr, err := socketioServer.NewServer()
@@ -73,9 +91,20 @@ func main() {
 		logger.Fatal("Cannot initialize Socket.IO server", err)
 	}
 
-	// Run both servers in separate goroutines
+	// Initialize HTTP server
+	httpSvr := httpServer.NewServer(validator, db, socketSvr, mail, payments)
+
+	// The socket event handlers are registered before the Socket.IO server starts serving
+	if err := httpSvr.MapSocketEvents(); err != nil {
+		logger.Fatal("MapSocketEvents Error:", err)
+	}
+
+	// Initialize background worker
+	workerSvr := worker.NewWorker(validator, db, socketSvr, mail, payments)
+
+	// Run the servers and the worker in separate goroutines
 	var wg sync.WaitGroup
-	wg.Add(2)
+	wg.Add(3)
 
 	// Run HTTP server
 	go func() {
@@ -93,5 +122,13 @@ func main() {
 		}
 	}()
 
+	// Run background jobs
+	go func() {
+		defer wg.Done()
+		if err := workerSvr.Run(context.Background()); err != nil {
+			logger.Fatal("Running worker error:", err)
+		}
+	}()
+
 	wg.Wait()
 }
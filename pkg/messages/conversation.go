@@ -0,0 +1,20 @@
+package messages
+
+const (
+	ConversationNotFound  = "conversation not found"
+	NotConversationMember = "you are not a member of this conversation"
+	NotConversationOwner  = "you can only access your own conversations"
+	CannotContactYourself = "you cannot start a conversation about your own event"
+	MessageNotFound       = "message not found"
+	NotMessageSender      = "you can only change your own messages"
+	TooManyAttachments    = "too many attachments"
+	AttachmentTooLarge    = "attachment is too large"
+	AttachmentNotAllowed  = "only images and PDF files can be attached"
+	NotGroupConversation  = "this action is only available in group conversations"
+	NotGroupModerator     = "only moderators of the group can do this"
+	AnnouncementOnly      = "only moderators can post in this conversation"
+	ParticipantSilenced   = "you have been muted in this conversation"
+	ParticipantNotFound   = "participant not found"
+	CannotModerateOwner   = "the owner of the group cannot be moderated"
+	InvalidSearchQuery    = "the search needs at least 2 characters"
+)
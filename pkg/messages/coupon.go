@@ -2,4 +2,18 @@ package messages
 
 const (
 	CouponNameAlreadyExists = "coupon name already exists"
+	CouponCodeAlreadyExists = "coupon code already exists"
+	CouponNotFound          = "coupon not found"
+	CouponNotStarted        = "coupon is not valid yet"
+	CouponExpired           = "coupon has expired"
+	CouponNotApplicable     = "coupon does not apply to this order"
+	CouponUsageLimitReached = "coupon has reached its usage limit"
+	CouponUserLimitReached  = "you have already used this coupon"
+	CouponCodeUsed          = "coupon code has already been used"
+	NotCouponOwner          = "you are not the owner of this coupon"
+	InvalidCodePrefix       = "code prefix may only contain letters, digits, dashes and underscores"
+	CouponCodesExhausted    = "could not generate enough unique codes, try a longer code"
+	InvalidCouponCode       = "coupon code may only contain letters, digits, dashes and underscores"
+	InvalidCouponWindow     = "coupon validity window is invalid"
+	InvalidCouponValue      = "coupon needs a percentage between 0 and 100 or a positive amount off"
 )
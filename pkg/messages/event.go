@@ -4,4 +4,32 @@ const (
 	EventFavouriteAlreadyExists = "you have added this event to your favorites list"
 	EventNameAlreadyExists      = "event name already exists"
 	ApplyCouponAlreadyExists    = "already coupon has been applied"
+	UnsupportedCurrency         = "unsupported currency"
+	InvalidPrice                = "invalid price"
+	CurrencyLocked              = "the currency cannot change once tickets were ordered"
+	InvalidEventTime            = "start and end times are invalid"
+	InvalidTimezone             = "timezone must be an IANA time zone like Asia/Ho_Chi_Minh"
+	InvalidRecurrenceRule       = "recurrence rule is invalid"
+	InvalidExcludedDate         = "excluded dates must be formatted as YYYY-MM-DD"
+	TooManyOccurrences          = "recurrence rule has too many occurrences"
+	OccurrenceNotFound          = "occurrence not found"
+	OccurrenceCancelled         = "occurrence is cancelled"
+	OccurrenceEnded             = "occurrence has already ended"
+	OccurrenceRequired          = "choose an occurrence of the recurring event"
+	QuantityBelowSale           = "quantity cannot be less than the tickets already sold"
+	InvalidEventTransition      = "the event cannot move to this state"
+	EventNotEditable            = "cancelled and completed events cannot be edited"
+	EventNotOnSale              = "the event is not on sale"
+	NotEventModerator           = "only admins can review events"
+	InvalidPublishTime          = "publish time must be in the future"
+	EventHasValidTickets        = "the event has valid tickets, cancel it instead"
+	RescheduleRequired          = "the event has tickets, reschedule it to change its times"
+	ReviewedContentLocked       = "a published event keeps the name, description, cover image, location and categories it was approved with"
+	RecurringEventReschedule    = "move the occurrences of a recurring event instead"
+	NoGalleryImages             = "choose at least one image"
+	GalleryFull                 = "the gallery of an event holds at most 20 images"
+	GalleryImageTooLarge        = "gallery images must be at most 10MB"
+	GalleryImageNotAllowed      = "gallery images must be JPEG, PNG, GIF or WebP"
+	SubImageNotFound            = "image not found"
+	InvalidImageOrder           = "the order must list every image of the gallery once"
 )
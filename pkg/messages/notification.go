@@ -0,0 +1,8 @@
+package messages
+
+const (
+	NotificationNotFound    = "notification not found"
+	EventNotFound           = "event not found"
+	NotEventOwner           = "you are not the organizer of this event"
+	AnnouncementRateLimited = "an announcement was sent recently, please try again later"
+)
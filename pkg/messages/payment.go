@@ -0,0 +1,32 @@
+package messages
+
+const (
+	PaymentNotFound         = "payment not found"
+	NotPaymentOwner         = "you are not allowed to access this payment"
+	NotRefundManager        = "you are not allowed to refund this payment"
+	PaymentNotRefundable    = "this payment cannot be refunded"
+	TicketNotRefundable     = "one or more tickets cannot be refunded"
+	NoRefundableTickets     = "there are no tickets left to refund"
+	RefundNotAllowed        = "this event does not allow cancellations"
+	RefundDeadlinePassed    = "the cancellation deadline of this event has passed"
+	RefundProviderRejected  = "the payment provider rejected the refund"
+	RefundNotFound          = "refund not found"
+	RefundNotAwaitingManual = "this refund is not awaiting a manual refund"
+	RescheduleNotFound      = "the event was not rescheduled since this order"
+	RescheduleWindowClosed  = "the time to answer the reschedule has passed"
+	RescheduleAnswered      = "this order already answered the reschedule"
+	PaymentNotCompleted     = "the payment has not been completed"
+	InvalidWebhookSignature = "invalid webhook signature"
+	TicketTypeNotFree       = "registration is only available for free tickets"
+	TicketSoldOut           = "not enough tickets left"
+	TicketLimitExceeded     = "you have reached the ticket limit of this event"
+	RegistrationNotPending  = "this registration is not awaiting approval"
+	PaymentMethodNotFound   = "payment method not found"
+	PaymentAccountNotFound  = "payment account not found"
+	NoPaymentAccount        = "the organizer has no account to receive bank transfers"
+	NotAwaitingTransfer     = "this order is not awaiting a bank transfer"
+	InvalidStatement        = "the bank statement could not be read"
+	InvalidQuote            = "the quote is invalid"
+	QuoteExpired            = "the quote has expired, please review your order again"
+	PaymentAmountMismatch   = "the amount paid does not match the order"
+)
@@ -0,0 +1,8 @@
+package messages
+
+const (
+	PayoutNotFound   = "payout not found"
+	NotFinanceAdmin  = "only admins can manage payouts"
+	PayoutNotPending = "payout is not pending"
+	InvalidDateRange = "invalid date range"
+)
@@ -0,0 +1,28 @@
+package messages
+
+const (
+	TicketNotFound         = "ticket not found"
+	NotTicketOwner         = "you do not own this ticket"
+	InvalidTicketToken     = "invalid ticket code"
+	TicketAlreadyCheckedIn = "ticket has already been checked in"
+	TicketBeingRefunded    = "this ticket is being refunded"
+	TicketEventCancelled   = "the event of this ticket is cancelled"
+	TicketDateCancelled    = "the date of this ticket is cancelled"
+	TicketForOtherDate     = "this ticket is for another date of the event"
+	NotEventStaff          = "you are not allowed to check in tickets for this event"
+	StaffAlreadyExists     = "user is already a staff member of this event"
+	StaffNotFound          = "staff member not found"
+	ScanInFuture           = "scan time is in the future"
+	ScanBeforeManifest     = "scan time is before the manifest was exported"
+	ScanTooOld             = "scan is too old to be synced"
+	InvalidSyncToken       = "invalid manifest sync token"
+	TicketTypeNotFound     = "ticket type not found"
+	TransferNotFound       = "transfer not found"
+	TransferDisabled       = "transfers are disabled for this ticket type"
+	TransferPending        = "this ticket already has a pending transfer"
+	TransferNotPending     = "this transfer is no longer pending"
+	CannotTransferToSelf   = "you cannot transfer a ticket to yourself"
+	TicketAlreadyUsed      = "a checked in ticket cannot be transferred"
+	NotTransferRecipient   = "you are not the recipient of this transfer"
+	NotTransferSender      = "you are not the sender of this transfer"
+)
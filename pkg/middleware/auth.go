@@ -36,3 +36,19 @@ func JWT(tokenType string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// JWTOptional identifies the user when a valid access token is sent and lets anonymous requests through
+func JWTOptional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("Authorization")
+		if token != "" {
+			payload, err := jwt.ValidateToken(token)
+			if err == nil && payload != nil && payload["type"] == jwt.AccessTokenType {
+				c.Set("userId", payload["id"])
+				c.Set("role", payload["role"])
+				c.Set("token", token)
+			}
+		}
+		c.Next()
+	}
+}
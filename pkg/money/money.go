@@ -0,0 +1,286 @@
+// Package money holds amounts as a whole number of minor units of an ISO 4217 currency, so totals never
+// pick up the rounding errors of floating point prices
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "VND"
+
+var (
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+)
+
+// exponents are the number of digits after the decimal point of each supported currency
+var exponents = map[string]int{
+	"AUD": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"MYR": 2,
+	"PHP": 2,
+	"SGD": 2,
+	"THB": 2,
+	"TWD": 2,
+	"USD": 2,
+	"VND": 0,
+}
+
+// Money is an amount in the minor units of its currency, cents for USD and dong for VND. The fields map
+// to a numeric and a currency column when the type is embedded in a model.
+type Money struct {
+	Amount   int64  `gorm:"type:numeric(20,0);not null;default:0"`
+	Currency string `gorm:"type:varchar(3);not null;default:'VND'"`
+}
+
+// IsSupported reports whether amounts in the currency can be parsed and formatted
+func IsSupported(currency string) bool {
+	_, ok := exponents[strings.ToUpper(currency)]
+	return ok
+}
+
+// Exponent is the number of minor unit digits of the currency, 2 for unknown currencies like ISO 4217 does
+func Exponent(currency string) int {
+	if exponent, ok := exponents[strings.ToUpper(currency)]; ok {
+		return exponent
+	}
+
+	return 2
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse reads a decimal amount in major units like "12.50", it refuses more decimals than the currency has
+func Parse(value string, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	if !IsSupported(currency) {
+		return Money{}, ErrUnsupportedCurrency
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	integer, fraction, _ := strings.Cut(value, ".")
+	exponent := Exponent(currency)
+	if integer == "" || len(fraction) > exponent || !isDigits(integer) || !isDigits(fraction) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	amount, err := strconv.ParseInt(integer+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return New(amount, currency), nil
+}
+
+// FromMajor converts a floating point amount in major units, rounding half away from zero. It is only
+// meant for values that come from outside, like a bank statement, amounts are never computed in floats.
+func FromMajor(value float64, currency string) Money {
+	return New(int64(math.Round(value*math.Pow10(Exponent(currency)))), currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add sums two amounts of the same currency, mixing currencies is a programming error and panics
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return New(m.Amount+other.Amount, m.currency(other))
+}
+
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return New(m.Amount-other.Amount, m.currency(other))
+}
+
+func (m Money) Mul(quantity int64) Money {
+	return New(m.Amount*quantity, m.Currency)
+}
+
+// Scale multiplies the amount by numerator/denominator, rounding half away from zero to a minor unit
+func (m Money) Scale(numerator int64, denominator int64) Money {
+	if denominator == 0 {
+		return Zero(m.Currency)
+	}
+
+	value := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(numerator)), big.NewInt(denominator))
+	return New(round(value), m.Currency)
+}
+
+// Percent takes a percentage of the amount, rounding half away from zero to a minor unit
+func (m Money) Percent(percent float64) Money {
+	rate := new(big.Rat)
+	if _, ok := rate.SetString(strconv.FormatFloat(percent, 'f', -1, 64)); !ok {
+		return Zero(m.Currency)
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	return New(round(value.Quo(value, big.NewRat(100, 1))), m.Currency)
+}
+
+// Cmp compares two amounts of the same currency, it returns -1, 0 or 1
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	}
+
+	return 0
+}
+
+func (m Money) Min(other Money) Money {
+	if m.Cmp(other) > 0 {
+		return other
+	}
+
+	return m
+}
+
+// Decimal formats the amount in major units with the digits of its currency, like "12.50"
+func (m Money) Decimal() string {
+	exponent := Exponent(m.Currency)
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Float is the amount in major units, for display and charts only
+func (m Money) Float() float64 {
+	return float64(m.Amount) / math.Pow10(Exponent(m.Currency))
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+type jsonMoney struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON writes the amount in major units with the exact digits of the currency, {"amount": 12.50, "currency": "USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	return json.Marshal(jsonMoney{Amount: json.Number(Money{Amount: m.Amount, Currency: currency}.Decimal()), Currency: currency})
+}
+
+// UnmarshalJSON reads what MarshalJSON writes, the amount can be a string or a number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value jsonMoney
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	currency := value.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	amount := value.Amount.String()
+	if amount == "" {
+		amount = "0"
+	}
+
+	parsed, err := Parse(amount, currency)
+	if err != nil {
+		return fmt.Errorf("money: %w: %s %s", err, amount, currency)
+	}
+
+	*m = parsed
+	return nil
+}
+
+func (m Money) mustMatch(other Money) {
+	if m.Currency != "" && other.Currency != "" && m.Currency != other.Currency {
+		panic(fmt.Sprintf("money: currency mismatch %s and %s", m.Currency, other.Currency))
+	}
+}
+
+// currency picks the currency of either amount, a zero Money{} takes the currency of the other one
+func (m Money) currency(other Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+
+	return other.Currency
+}
+
+func round(value *big.Rat) int64 {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		if value.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient.Int64()
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
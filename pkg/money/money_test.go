@@ -0,0 +1,203 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{value: "12.50", currency: "USD", want: New(1250, "USD")},
+		{value: "12.5", currency: "usd", want: New(1250, "USD")},
+		{value: "12", currency: "USD", want: New(1200, "USD")},
+		{value: "0.01", currency: "EUR", want: New(1, "EUR")},
+		{value: "-3.20", currency: "USD", want: New(-320, "USD")},
+		{value: " +7 ", currency: "USD", want: New(700, "USD")},
+		{value: "150000", currency: "VND", want: New(150000, "VND")},
+		{value: "1200", currency: "JPY", want: New(1200, "JPY")},
+		{value: "12.505", currency: "USD", wantErr: ErrInvalidAmount},
+		{value: "1.5", currency: "VND", wantErr: ErrInvalidAmount},
+		{value: ".50", currency: "USD", wantErr: ErrInvalidAmount},
+		{value: "1,50", currency: "USD", wantErr: ErrInvalidAmount},
+		{value: "1e3", currency: "USD", wantErr: ErrInvalidAmount},
+		{value: "", currency: "USD", wantErr: ErrInvalidAmount},
+		{value: "99999999999999999999", currency: "USD", wantErr: ErrInvalidAmount},
+		{value: "10", currency: "XYZ", wantErr: ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.currency, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: New(1250, "USD"), want: "12.50"},
+		{money: New(5, "USD"), want: "0.05"},
+		{money: New(0, "USD"), want: "0.00"},
+		{money: New(-5, "USD"), want: "-0.05"},
+		{money: New(-1250, "EUR"), want: "-12.50"},
+		{money: New(150000, "VND"), want: "150000"},
+		{money: New(-300, "JPY"), want: "-300"},
+		{money: New(123, "XYZ"), want: "1.23"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want+" "+tt.money.Currency, func(t *testing.T) {
+			if got := tt.money.Decimal(); got != tt.want {
+				t.Errorf("Decimal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{name: "add", got: New(1250, "USD").Add(New(75, "USD")), want: New(1325, "USD")},
+		{name: "add to zero value", got: Money{}.Add(New(75, "USD")), want: New(75, "USD")},
+		{name: "sub below zero", got: New(100, "USD").Sub(New(250, "USD")), want: New(-150, "USD")},
+		{name: "mul", got: New(1250, "USD").Mul(3), want: New(3750, "USD")},
+		{name: "scale rounds down", got: New(100, "USD").Scale(1, 3), want: New(33, "USD")},
+		{name: "scale rounds half up", got: New(5, "USD").Scale(1, 2), want: New(3, "USD")},
+		{name: "scale rounds half away from zero", got: New(-5, "USD").Scale(1, 2), want: New(-3, "USD")},
+		{name: "scale by zero", got: New(100, "USD").Scale(1, 0), want: New(0, "USD")},
+		{name: "percent", got: New(6500, "USD").Percent(10), want: New(650, "USD")},
+		{name: "percent rounds half up", got: New(125, "USD").Percent(10), want: New(13, "USD")},
+		{name: "fractional percent", got: New(100000, "VND").Percent(2.5), want: New(2500, "VND")},
+		{name: "percent of a float that is not exact", got: New(1000, "USD").Percent(0.1), want: New(1, "USD")},
+		{name: "min", got: New(100, "USD").Min(New(99, "USD")), want: New(99, "USD")},
+		{name: "from major", got: FromMajor(19.99, "USD"), want: New(1999, "USD")},
+		{name: "from major rounds", got: FromMajor(0.125, "EUR"), want: New(13, "EUR")},
+		{name: "from major without decimals", got: FromMajor(150000, "VND"), want: New(150000, "VND")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCmp(t *testing.T) {
+	tests := []struct {
+		a, b Money
+		want int
+	}{
+		{a: New(1, "USD"), b: New(2, "USD"), want: -1},
+		{a: New(2, "USD"), b: New(2, "USD"), want: 0},
+		{a: New(3, "USD"), b: New(2, "USD"), want: 1},
+		{a: Money{}, b: New(2, "USD"), want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a.String()+" "+tt.b.String(), func(t *testing.T) {
+			if got := tt.a.Cmp(tt.b); got != tt.want {
+				t.Errorf("Cmp() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrencyMismatchPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func()
+	}{
+		{name: "add", op: func() { New(1, "USD").Add(New(1, "EUR")) }},
+		{name: "sub", op: func() { New(1, "USD").Sub(New(1, "EUR")) }},
+		{name: "cmp", op: func() { New(1, "USD").Cmp(New(1, "EUR")) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("mixing currencies did not panic")
+				}
+			}()
+			tt.op()
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		money Money
+		json  string
+	}{
+		{money: New(1250, "USD"), json: `{"amount":12.50,"currency":"USD"}`},
+		{money: New(150000, "VND"), json: `{"amount":150000,"currency":"VND"}`},
+		{money: New(-5, "EUR"), json: `{"amount":-0.05,"currency":"EUR"}`},
+		{money: Money{Amount: 100}, json: `{"amount":100,"currency":"VND"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			data, err := json.Marshal(tt.money)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.json {
+				t.Errorf("Marshal() = %s, want %s", data, tt.json)
+			}
+
+			var got Money
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got.Amount != tt.money.Amount {
+				t.Errorf("Unmarshal() = %v, want %v", got, tt.money)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    Money
+		wantErr bool
+	}{
+		{json: `{"amount":"12.50","currency":"USD"}`, want: New(1250, "USD")},
+		{json: `{"amount":150000}`, want: New(150000, "VND")},
+		{json: `{"currency":"USD"}`, want: New(0, "USD")},
+		{json: `{"amount":12.505,"currency":"USD"}`, wantErr: true},
+		{json: `{"amount":10,"currency":"XYZ"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Unmarshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
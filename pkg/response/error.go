@@ -22,3 +22,18 @@ func Error(c *gin.Context, status int, err error, message string) {
 
 	c.JSON(status, ErrorResponse{Data: errorRes})
 }
+
+// ErrorWithData works like Error and also returns data describing the failure, such as the conflicting resource
+func ErrorWithData(c *gin.Context, status int, err error, message string, data interface{}) {
+	cfg := configs.GetConfig()
+	errorRes := map[string]interface{}{
+		"message": message,
+		"data":    data,
+	}
+
+	if cfg.Environment != configs.ProductionEnv {
+		errorRes["debug"] = err.Error()
+	}
+
+	c.JSON(status, ErrorResponse{Data: errorRes})
+}
@@ -0,0 +1,335 @@
+// Package rrule reads and expands the subset of RFC 5545 recurrence rules events repeat with: daily, weekly
+// on given weekdays and monthly on given days of the month, bounded by a count or an until date
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// MaxOccurrences bounds how many occurrences a rule may expand to, a series longer than that is an input error
+const MaxOccurrences = 500
+
+// maxPeriods stops the expansion of rules that can never match, like the 30th of February every year
+const maxPeriods = 10000
+
+var (
+	ErrInvalidRule         = errors.New("invalid recurrence rule")
+	ErrUnboundedRule       = errors.New("recurrence rule needs a count or an until date")
+	ErrTooManyOccurrences  = errors.New("recurrence rule has too many occurrences")
+	ErrUnsupportedProperty = errors.New("unsupported recurrence rule property")
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var untilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+
+// Rule is a parsed RRULE. ByDay only applies to weekly rules and ByMonthDay to monthly ones, when empty the
+// weekday or day of the month of the first occurrence is used.
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+	// untilDate is set when UNTIL was a date, it then ends the series at the end of that day wherever it runs
+	untilDate bool
+}
+
+// Parse reads a rule like "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10", with or without the "RRULE:" prefix. An until
+// date without a time covers that whole day.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	if value == "" {
+		return nil, ErrInvalidRule
+	}
+
+	rule := Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		name, param, ok := strings.Cut(part, "=")
+		if !ok || param == "" || seen[name] {
+			return nil, ErrInvalidRule
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			if param != Daily && param != Weekly && param != Monthly {
+				return nil, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedProperty, param)
+			}
+			rule.Freq = param
+		case "INTERVAL":
+			rule.Interval, err = positive(param)
+		case "COUNT":
+			rule.Count, err = positive(param)
+		case "UNTIL":
+			rule.Until, rule.untilDate, err = parseUntil(param)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(param)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseByMonthDay(param)
+		case "WKST":
+			if param != "MO" {
+				return nil, fmt.Errorf("%w: WKST=%s", ErrUnsupportedProperty, param)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedProperty, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case rule.Freq == "":
+		return nil, ErrInvalidRule
+	case rule.Count > 0 && rule.Until != nil:
+		return nil, ErrInvalidRule
+	case rule.Count == 0 && rule.Until == nil:
+		return nil, ErrUnboundedRule
+	case len(rule.ByDay) > 0 && rule.Freq != Weekly:
+		return nil, fmt.Errorf("%w: BYDAY with FREQ=%s", ErrUnsupportedProperty, rule.Freq)
+	case len(rule.ByMonthDay) > 0 && rule.Freq != Monthly:
+		return nil, fmt.Errorf("%w: BYMONTHDAY with FREQ=%s", ErrUnsupportedProperty, rule.Freq)
+	case rule.Count > MaxOccurrences:
+		return nil, ErrTooManyOccurrences
+	}
+
+	return &rule, nil
+}
+
+// String writes the rule back in RFC 5545 form, the until date in UTC
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, dayName(day))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil && r.untilDate {
+		parts = append(parts, "UNTIL="+r.Until.Format(untilLayouts[2]))
+	} else if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayouts[0]))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Expand lists the starts of the series that begins at start, in order and in the location of start so
+// occurrences keep their wall clock time across daylight saving changes. Like EXDATE in RFC 5545 the
+// excluded dates still count toward COUNT, an exclusion matches every occurrence on that calendar day.
+func (r *Rule) Expand(start time.Time, excluded []time.Time) ([]time.Time, error) {
+	skip := make(map[string]bool, len(excluded))
+	for _, date := range excluded {
+		skip[date.Format(time.DateOnly)] = true
+	}
+
+	var starts []time.Time
+	matched := 0
+	for period := 0; period < maxPeriods; period++ {
+		candidates := r.period(start, period)
+		if len(candidates) == 0 {
+			continue
+		}
+
+		for _, candidate := range candidates {
+			if candidate.Before(start) {
+				continue
+			}
+
+			if r.after(candidate) || (r.Count > 0 && matched == r.Count) {
+				return starts, nil
+			}
+			matched++
+
+			if skip[candidate.Format(time.DateOnly)] {
+				continue
+			}
+
+			if len(starts) == MaxOccurrences {
+				return nil, ErrTooManyOccurrences
+			}
+			starts = append(starts, candidate)
+		}
+	}
+
+	return starts, nil
+}
+
+// SetUntil ends the series at until, replacing its count
+func (r *Rule) SetUntil(until time.Time) {
+	r.Count = 0
+	r.Until = &until
+	r.untilDate = false
+}
+
+// after reports whether the start is past the end of the series
+func (r *Rule) after(start time.Time) bool {
+	if r.Until == nil {
+		return false
+	}
+
+	if r.untilDate {
+		return start.Format(time.DateOnly) > r.Until.Format(time.DateOnly)
+	}
+
+	return start.After(*r.Until)
+}
+
+// period lists the candidate starts of the nth period of the rule, a day, a week or a month
+func (r *Rule) period(start time.Time, n int) []time.Time {
+	hour, minute, second := start.Clock()
+	step := n * r.Interval
+
+	switch r.Freq {
+	case Daily:
+		return []time.Time{start.AddDate(0, 0, step)}
+	case Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+
+		monday := start.AddDate(0, 0, -offset(start.Weekday())+7*step)
+		candidates := make([]time.Time, 0, len(days))
+		for _, day := range days {
+			date := monday.AddDate(0, 0, offset(day))
+			candidates = append(candidates, time.Date(date.Year(), date.Month(), date.Day(), hour, minute, second, 0, start.Location()))
+		}
+		return candidates
+	case Monthly:
+		days := r.ByMonthDay
+		if len(days) == 0 {
+			days = []int{start.Day()}
+		}
+
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, hour, minute, second, 0, start.Location())
+		length := first.AddDate(0, 1, -1).Day()
+		candidates := make([]time.Time, 0, len(days))
+		seen := make(map[int]bool, len(days))
+		for _, day := range days {
+			if day < 0 {
+				day = length + day + 1
+			}
+			if day < 1 || day > length || seen[day] {
+				continue
+			}
+			seen[day] = true
+			candidates = append(candidates, time.Date(first.Year(), first.Month(), day, hour, minute, second, 0, start.Location()))
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+		return candidates
+	}
+
+	return nil
+}
+
+func positive(value string) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		return 0, ErrInvalidRule
+	}
+
+	return number, nil
+}
+
+func parseUntil(value string) (*time.Time, bool, error) {
+	for i, layout := range untilLayouts {
+		until, err := time.Parse(layout, value)
+		if err == nil {
+			return &until, i == len(untilLayouts)-1, nil
+		}
+	}
+
+	return nil, false, ErrInvalidRule
+}
+
+func parseByDay(value string) ([]time.Weekday, error) {
+	seen := make(map[time.Weekday]bool)
+	var days []time.Weekday
+	for _, name := range strings.Split(value, ",") {
+		day, ok := weekdays[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: BYDAY=%s", ErrUnsupportedProperty, name)
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return offset(days[i]) < offset(days[j]) })
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	seen := make(map[int]bool)
+	var days []int
+	for _, part := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(part)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, ErrInvalidRule
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+
+	return days, nil
+}
+
+// offset is the position of the day in a week starting on Monday
+func offset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func dayName(day time.Weekday) string {
+	for name, weekday := range weekdays {
+		if weekday == day {
+			return name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,182 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr error
+	}{
+		{value: "FREQ=DAILY;COUNT=5", want: "FREQ=DAILY;COUNT=5"},
+		{value: "RRULE:freq=weekly;byday=we,mo;count=10", want: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"},
+		{value: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;UNTIL=20250301T000000Z", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;UNTIL=20250301T000000Z"},
+		{value: "FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20251231", want: "FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20251231"},
+		{value: "FREQ=MONTHLY;BYMONTHDAY=1,15;COUNT=6;WKST=MO", want: "FREQ=MONTHLY;BYMONTHDAY=1,15;COUNT=6"},
+		{value: "", wantErr: ErrInvalidRule},
+		{value: "COUNT=5", wantErr: ErrInvalidRule},
+		{value: "FREQ=DAILY", wantErr: ErrUnboundedRule},
+		{value: "FREQ=DAILY;COUNT=5;UNTIL=20250101", wantErr: ErrInvalidRule},
+		{value: "FREQ=DAILY;COUNT=5;COUNT=6", wantErr: ErrInvalidRule},
+		{value: "FREQ=DAILY;COUNT=0", wantErr: ErrInvalidRule},
+		{value: "FREQ=DAILY;INTERVAL=-1;COUNT=5", wantErr: ErrInvalidRule},
+		{value: "FREQ=DAILY;UNTIL=tomorrow", wantErr: ErrInvalidRule},
+		{value: "FREQ=DAILY;COUNT=501", wantErr: ErrTooManyOccurrences},
+		{value: "FREQ=YEARLY;COUNT=5", wantErr: ErrUnsupportedProperty},
+		{value: "FREQ=DAILY;BYDAY=MO;COUNT=5", wantErr: ErrUnsupportedProperty},
+		{value: "FREQ=WEEKLY;BYDAY=1MO;COUNT=5", wantErr: ErrUnsupportedProperty},
+		{value: "FREQ=WEEKLY;BYMONTHDAY=1;COUNT=5", wantErr: ErrUnsupportedProperty},
+		{value: "FREQ=MONTHLY;BYMONTHDAY=0;COUNT=5", wantErr: ErrInvalidRule},
+		{value: "FREQ=MONTHLY;BYMONTHDAY=32;COUNT=5", wantErr: ErrInvalidRule},
+		{value: "FREQ=WEEKLY;WKST=SU;COUNT=5", wantErr: ErrUnsupportedProperty},
+		{value: "FREQ=DAILY;BYHOUR=9;COUNT=5", wantErr: ErrUnsupportedProperty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rule, err := Parse(tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && rule.String() != tt.want {
+				t.Errorf("Parse().String() = %s, want %s", rule.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	saigon, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		excluded []time.Time
+		want     []string
+	}{
+		{
+			name:  "daily across the spring daylight saving change",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: time.Date(2024, 3, 9, 10, 0, 0, 0, newYork),
+			want:  []string{"2024-03-09T10:00:00-05:00", "2024-03-10T10:00:00-04:00", "2024-03-11T10:00:00-04:00"},
+		},
+		{
+			name:  "weekly across the autumn daylight saving change",
+			rule:  "FREQ=WEEKLY;COUNT=2",
+			start: time.Date(2024, 10, 31, 19, 30, 0, 0, newYork),
+			want:  []string{"2024-10-31T19:30:00-04:00", "2024-11-07T19:30:00-05:00"},
+		},
+		{
+			name:  "weekly on several days starting mid week",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=4",
+			start: time.Date(2025, 1, 8, 18, 0, 0, 0, saigon),
+			want:  []string{"2025-01-08T18:00:00+07:00", "2025-01-10T18:00:00+07:00", "2025-01-13T18:00:00+07:00", "2025-01-15T18:00:00+07:00"},
+		},
+		{
+			name:  "every other week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			start: time.Date(2025, 1, 6, 9, 0, 0, 0, saigon),
+			want:  []string{"2025-01-06T09:00:00+07:00", "2025-01-20T09:00:00+07:00", "2025-02-03T09:00:00+07:00"},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=4",
+			start: time.Date(2024, 1, 31, 20, 0, 0, 0, saigon),
+			want:  []string{"2024-01-31T20:00:00+07:00", "2024-02-29T20:00:00+07:00", "2024-03-31T20:00:00+07:00", "2024-04-30T20:00:00+07:00"},
+		},
+		{
+			name:  "the 31st skips the short months",
+			rule:  "FREQ=MONTHLY;COUNT=4",
+			start: time.Date(2025, 1, 31, 20, 0, 0, 0, saigon),
+			want:  []string{"2025-01-31T20:00:00+07:00", "2025-03-31T20:00:00+07:00", "2025-05-31T20:00:00+07:00", "2025-07-31T20:00:00+07:00"},
+		},
+		{
+			name:  "days of the month before the start are not listed",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1,15;COUNT=3",
+			start: time.Date(2025, 1, 10, 8, 0, 0, 0, saigon),
+			want:  []string{"2025-01-15T08:00:00+07:00", "2025-02-01T08:00:00+07:00", "2025-02-15T08:00:00+07:00"},
+		},
+		{
+			name:     "excluded dates count toward the count",
+			rule:     "FREQ=DAILY;COUNT=4",
+			start:    time.Date(2025, 1, 1, 9, 0, 0, 0, saigon),
+			excluded: []time.Time{time.Date(2025, 1, 2, 0, 0, 0, 0, saigon), time.Date(2025, 1, 4, 0, 0, 0, 0, saigon)},
+			want:     []string{"2025-01-01T09:00:00+07:00", "2025-01-03T09:00:00+07:00"},
+		},
+		{
+			name:     "excluded dates past the count change nothing",
+			rule:     "FREQ=DAILY;COUNT=2",
+			start:    time.Date(2025, 1, 1, 9, 0, 0, 0, saigon),
+			excluded: []time.Time{time.Date(2025, 1, 3, 0, 0, 0, 0, saigon)},
+			want:     []string{"2025-01-01T09:00:00+07:00", "2025-01-02T09:00:00+07:00"},
+		},
+		{
+			name:  "until date covers the whole day",
+			rule:  "FREQ=DAILY;UNTIL=20250103",
+			start: time.Date(2025, 1, 1, 21, 0, 0, 0, saigon),
+			want:  []string{"2025-01-01T21:00:00+07:00", "2025-01-02T21:00:00+07:00", "2025-01-03T21:00:00+07:00"},
+		},
+		{
+			name:  "until time is an instant",
+			rule:  "FREQ=DAILY;UNTIL=20250103T000000Z",
+			start: time.Date(2025, 1, 1, 9, 0, 0, 0, saigon),
+			want:  []string{"2025-01-01T09:00:00+07:00", "2025-01-02T09:00:00+07:00"},
+		},
+		{
+			name:  "a day that never comes",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31;UNTIL=20250430",
+			start: time.Date(2025, 4, 1, 9, 0, 0, 0, saigon),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			starts, err := rule.Expand(tt.start, tt.excluded)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+
+			got := make([]string, 0, len(starts))
+			for _, start := range starts {
+				got = append(got, start.Format(time.RFC3339))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expand() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expand()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExpandTooManyOccurrences(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;UNTIL=20300101")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if _, err := rule.Expand(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), nil); !errors.Is(err, ErrTooManyOccurrences) {
+		t.Errorf("Expand() error = %v, want %v", err, ErrTooManyOccurrences)
+	}
+}
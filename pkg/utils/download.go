@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohub/configs"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Download fetches the content at rawUrl, refusing responses larger than maxSize bytes. Only the files of the
+// storage are fetched, redirects included, so a URL saved by a user cannot reach the internal network.
+func Download(ctx context.Context, rawUrl string, maxSize int64) ([]byte, error) {
+	target, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isStorageUrl(target) {
+		return nil, fmt.Errorf("download %s: not a storage url", rawUrl)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !isStorageUrl(req.URL) {
+				return fmt.Errorf("redirect to %s: not a storage url", req.URL)
+			}
+			return nil
+		},
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: unexpected status %d", rawUrl, res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("download %s: larger than %d bytes", rawUrl, maxSize)
+	}
+
+	return data, nil
+}
+
+func isStorageUrl(target *url.URL) bool {
+	return target.Scheme == "https" && target.Hostname() == configs.StorageHost && target.Port() == "" && target.User == nil
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDownloadRefusesOtherHosts(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "metadata service", url: "http://169.254.169.254/latest/meta-data/"},
+		{name: "loopback", url: "https://127.0.0.1/cover.jpg"},
+		{name: "plain http", url: "http://res.cloudinary.com/demo/image/upload/cover.jpg"},
+		{name: "other port", url: "https://res.cloudinary.com:8443/demo/image/upload/cover.jpg"},
+		{name: "lookalike host", url: "https://res.cloudinary.com.example.org/cover.jpg"},
+		{name: "credentials", url: "https://user@res.cloudinary.com/demo/image/upload/cover.jpg"},
+		{name: "relative", url: "/internal/cover.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Download(context.Background(), tt.url, 1<<20)
+			if err == nil || !strings.Contains(err.Error(), "not a storage url") {
+				t.Errorf("Download(%q) error = %v, want a refusal", tt.url, err)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	cryptoRand "crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"strings"
 	"time"
@@ -10,6 +13,8 @@ import (
 const (
 	Charset    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	RandLength = 5
+
+	SecureRandLength = 10
 )
 
 var seededRand *rand.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -38,6 +43,45 @@ func GenerateCode(prefix string) string {
 	return strings.ToUpper(code)
 }
 
+// GenerateSecureCode works like GenerateCode but draws a longer suffix from crypto/rand, use it for codes that must not be guessable
+func GenerateSecureCode(prefix string) string {
+	t := time.Now()
+	b := make([]byte, SecureRandLength)
+	for i := range b {
+		n, err := cryptoRand.Int(cryptoRand.Reader, big.NewInt(int64(len(Charset))))
+		if err != nil {
+			panic(err)
+		}
+		b[i] = Charset[n.Int64()]
+	}
+
+	return strings.ToUpper(fmt.Sprintf("%s%s%s", prefix, t.Format("060102"), b))
+}
+
+// RandomCode returns length crypto random characters of Charset, for codes people type
+func RandomCode(length int) string {
+	b := make([]byte, length)
+	for i := range b {
+		n, err := cryptoRand.Int(cryptoRand.Reader, big.NewInt(int64(len(Charset))))
+		if err != nil {
+			panic(err)
+		}
+		b[i] = Charset[n.Int64()]
+	}
+
+	return string(b)
+}
+
+// RandomHex returns n crypto random bytes hex encoded
+func RandomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := cryptoRand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
 func stringWithCharset(length int) string {
 	b := make([]byte, length)
 	for i := range b {
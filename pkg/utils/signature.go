@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// Sign returns the url safe HMAC-SHA256 signature of data
+func Sign(secret string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a signature produced by Sign in constant time
+func VerifySignature(secret string, data []byte, signature string) bool {
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// SignEd25519 signs data with an Ed25519 key, so clients can verify it offline with the public key only
+func SignEd25519(key ed25519.PrivateKey, data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, data))
+}
+
+// Ed25519PublicKey returns the url safe public key matching SignEd25519
+func Ed25519PublicKey(key ed25519.PrivateKey) string {
+	return base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
@@ -5,23 +5,72 @@ import (
 	"github.com/cloudinary/cloudinary-go"
 	"github.com/cloudinary/cloudinary-go/api/uploader"
 	"gohub/configs"
+	"io"
 	"mime/multipart"
 	"time"
 )
 
+// StoredFile is an uploaded object, its public id is what removes it again
+type StoredFile struct {
+	Url      string
+	PublicId string
+}
+
 func ImageUpload(fileHeader *multipart.FileHeader, folder string) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return FileUpload(file, folder)
+}
+
+// ImageStore uploads the image like ImageUpload and keeps the public id of the object
+func ImageStore(fileHeader *multipart.FileHeader, folder string) (*StoredFile, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return StoreFile(file, folder)
+}
+
+// FileUpload stores any content supported by Cloudinary (images, PDF) and returns its secure URL
+func FileUpload(file io.Reader, folder string) (string, error) {
+	stored, err := StoreFile(file, folder)
+	if err != nil {
+		return "", err
+	}
+
+	return stored.Url, nil
+}
+
+// StoreFile stores the content like FileUpload and keeps the public id of the object
+func StoreFile(file io.Reader, folder string) (*StoredFile, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
-	file, _ := fileHeader.Open()
-
 	cfg := configs.GetConfig()
 	cldService, _ := cloudinary.NewFromURL(cfg.UrlCloudinary)
 	result, err := cldService.Upload.Upload(ctx, file, uploader.UploadParams{Folder: folder})
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return result.SecureURL, nil
+	return &StoredFile{Url: result.SecureURL, PublicId: result.PublicID}, nil
+}
+
+// FileDelete removes a stored object by its public id
+func FileDelete(publicId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	cfg := configs.GetConfig()
+	cldService, _ := cloudinary.NewFromURL(cfg.UrlCloudinary)
+	_, err := cldService.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicId})
+
+	return err
 }